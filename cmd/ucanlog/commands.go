@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+
+	logSvc "github.com/relves/ucanlog/pkg/log"
+	"github.com/relves/ucanlog/pkg/ucan"
+)
+
+// runCommand runs an admin command against the local data directory and
+// returns the process exit code.
+func runCommand(ctx context.Context, args []string, logService *logSvc.LogService) int {
+	switch args[0] {
+	case "rebuild-revocations":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: ucanlog rebuild-revocations <logID>")
+			return 2
+		}
+		return rebuildRevocations(ctx, logService, args[1])
+	case "restore":
+		return restore(ctx, logService, args[1:])
+	case "migrate":
+		return migrate(ctx, logService, args[1:])
+	case "fsck":
+		return fsck(ctx, logService, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		fmt.Fprintln(os.Stderr, "commands:")
+		fmt.Fprintln(os.Stderr, "  rebuild-revocations <logID>  Restore the revocations table from the revocation log")
+		fmt.Fprintln(os.Stderr, "  restore [flags] <logID>      Restore a log from its index CAR")
+		fmt.Fprintln(os.Stderr, "  migrate [flags] <logID>      Take over a log from another service")
+		fmt.Fprintln(os.Stderr, "  fsck [flags] <logID>         Check a log's stored tiles, bundles and checkpoint")
+		return 2
+	}
+}
+
+func rebuildRevocations(ctx context.Context, logService *logSvc.LogService, logID string) int {
+	res, err := logService.RebuildRevocations(ctx, logID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to rebuild revocations for %s: %v\n", logID, err)
+		return 1
+	}
+	fmt.Printf("Rebuilt revocations for %s: %d entries scanned, %d revocations restored\n", logID, res.Scanned, res.Added)
+	return 0
+}
+
+func restore(ctx context.Context, logService *logSvc.LogService, args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	indexCID := fs.String("index", "", "root CID of the log's index CAR (required)")
+	revocationIndexCID := fs.String("revocation-index", "", "root CID of the revocation log's index CAR (required unless the revocation log exists locally)")
+	dlgPath := fs.String("delegation", "", "file holding the base64-encoded space delegation (required for Storacha storage)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: ucanlog restore -index <cid> [-revocation-index <cid>] [-delegation <file>] <logID>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || *indexCID == "" {
+		if err == nil {
+			fs.Usage()
+		}
+		return 2
+	}
+	logID := fs.Arg(0)
+
+	dlg, err := readDelegationFlag(*dlgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	res, err := logService.RestoreLog(ctx, logID, *indexCID, *revocationIndexCID, dlg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to restore %s: %v\n", logID, err)
+		return 1
+	}
+	if res.RevocationIndexCID != "" {
+		fmt.Printf("Restored revocation log from index %s\n", res.RevocationIndexCID)
+	}
+	fmt.Printf("Restored %s from index %s: tree size %d, root %s, %d paths\n",
+		res.LogID, res.IndexCID, res.TreeSize, base64.StdEncoding.EncodeToString(res.Root), res.Paths)
+	return 0
+}
+
+func migrate(ctx context.Context, logService *logSvc.LogService, args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	previousKey := fs.String("previous-key", "", "verifier key of the previous service's checkpoints for the log (required)")
+	indexCID := fs.String("index", "", "root CID of the log's index CAR (required)")
+	revocationIndexCID := fs.String("revocation-index", "", "root CID of the revocation log's index CAR (required unless the revocation log exists locally)")
+	dlgPath := fs.String("delegation", "", "file holding the base64-encoded space delegation (required for Storacha storage)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: ucanlog migrate -previous-key <vkey> -index <cid> [-revocation-index <cid>] [-delegation <file>] <logID>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || *previousKey == "" || *indexCID == "" {
+		if err == nil {
+			fs.Usage()
+		}
+		return 2
+	}
+	logID := fs.Arg(0)
+
+	dlg, err := readDelegationFlag(*dlgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	res, err := logService.MigrateLog(ctx, logID, *indexCID, *revocationIndexCID, *previousKey, dlg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to migrate %s: %v\n", logID, err)
+		return 1
+	}
+	fmt.Printf("Migrated %s from %s at tree size %d, root %s\n",
+		res.LogID, res.PreviousOrigin, res.TreeSize, base64.StdEncoding.EncodeToString(res.Root))
+	fmt.Printf("  checkpoint:       %s (previous %s)\n", res.Checkpoint, res.PreviousCheckpoint)
+	fmt.Printf("  migration record: %s\n", res.MigrationCID)
+	fmt.Printf("  index:            %s\n", res.IndexCID)
+	if rev := res.RevocationLog; rev != nil {
+		fmt.Printf("Migrated revocation log %s at tree size %d\n", rev.LogID, rev.TreeSize)
+		fmt.Printf("  migration record: %s\n", rev.MigrationCID)
+		fmt.Printf("  index:            %s\n", rev.IndexCID)
+	}
+	return 0
+}
+
+func fsck(ctx context.Context, logService *logSvc.LogService, args []string) int {
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	repair := fs.Bool("repair", false, "re-derive missing or corrupt hash tiles from the entry bundles")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	dlgPath := fs.String("delegation", "", "file holding the base64-encoded space delegation (required for -repair)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: ucanlog fsck [-repair] [-json] [-delegation <file>] <logID>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		if err == nil {
+			fs.Usage()
+		}
+		return 2
+	}
+	logID := fs.Arg(0)
+
+	dlg, err := readDelegationFlag(*dlgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	report, err := logService.Fsck(ctx, logID, *repair, dlg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check %s: %v\n", logID, err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode report: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Printf("Checked %s: tree size %d, checkpoint size %d, %d bundles, %d tiles\n",
+			report.LogDID, report.TreeSize, report.CheckpointSize, report.BundlesChecked, report.TilesChecked)
+		for _, p := range report.Problems {
+			fmt.Printf("  %-13s %s: %s\n", p.Kind, p.Path, p.Detail)
+		}
+		for _, path := range report.Repaired {
+			fmt.Printf("  repaired      %s\n", path)
+		}
+		if report.OK() {
+			fmt.Println("No problems found")
+		}
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// readDelegationFlag reads the delegation file named by a -delegation flag.
+// An empty path yields no delegation.
+func readDelegationFlag(path string) (delegation.Delegation, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delegation: %w", err)
+	}
+	dlg, err := parseDelegationFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse delegation: %w", err)
+	}
+	return dlg, nil
+}
+
+// parseDelegationFile parses a delegation stored either as the base64 string
+// accepted in capability caveats or as a raw CAR.
+func parseDelegationFile(data []byte) (delegation.Delegation, error) {
+	if dlg, err := ucan.ParseDelegation(strings.TrimSpace(string(data))); err == nil {
+		return dlg, nil
+	}
+	return ucan.ParseDelegationFromCAR(data)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/hex"
@@ -9,15 +10,22 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/storacha/go-ucanto/principal/ed25519/signer"
 	thttp "github.com/storacha/go-ucanto/transport/http"
 
+	"github.com/relves/ucanlog/internal/storage/blobcache"
 	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/gc"
 	logSvc "github.com/relves/ucanlog/pkg/log"
 	"github.com/relves/ucanlog/pkg/server"
 	"github.com/relves/ucanlog/pkg/tlog"
 	"github.com/relves/ucanlog/pkg/ucan"
+	"github.com/relves/ucanlog/pkg/webhook"
 )
 
 func main() {
@@ -67,6 +75,60 @@ func main() {
 	// Get origin prefix from environment or use default
 	originPrefix := getEnv("TLOG_ORIGIN_PREFIX", "ucanlog")
 
+	// Select the blob storage backend: customer Storacha spaces, or a local
+	// directory for development and air-gapped deployments
+	storageBackend := getEnv("STORAGE_BACKEND", "storacha")
+	blobPath := getEnv("LOCAL_BLOB_PATH", filepath.Join(basePath, "blobs"))
+	var storageClient storacha.StorachaClient
+	switch storageBackend {
+	case "storacha":
+	case "local":
+		storageClient, err = storacha.NewFSClient(blobPath)
+		if err != nil {
+			logger.Error("failed to create local blob storage", "error", err)
+			os.Exit(1)
+		}
+	default:
+		logger.Error("unknown storage backend", "backend", storageBackend)
+		os.Exit(1)
+	}
+
+	// Optional on-disk blob cache shared by all logs and the tlog-tiles API,
+	// so tiles survive restarts instead of being re-fetched from the gateway
+	var blobCache *blobcache.Cache
+	if cacheMB := getEnv("BLOB_CACHE_MAX_MB", "0"); cacheMB != "0" {
+		mb, err := strconv.ParseInt(cacheMB, 10, 64)
+		if err != nil || mb < 0 {
+			logger.Error("invalid BLOB_CACHE_MAX_MB", "value", cacheMB)
+			os.Exit(1)
+		}
+		blobCache, err = blobcache.New(getEnv("BLOB_CACHE_PATH", filepath.Join(basePath, "blob-cache")), mb<<20, logger)
+		if err != nil {
+			logger.Error("failed to open blob cache", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Scheduled GC collects logs whose owner stored a remove delegation with
+	// tlog/gc/schedule; each run processes at most GC_MAX_BUNDLES bundles
+	gcInterval, err := time.ParseDuration(getEnv("GC_SCHEDULE_INTERVAL", "1h"))
+	if err != nil || gcInterval <= 0 {
+		logger.Error("invalid GC_SCHEDULE_INTERVAL", "value", os.Getenv("GC_SCHEDULE_INTERVAL"))
+		os.Exit(1)
+	}
+	gcMaxBundles, err := strconv.ParseUint(getEnv("GC_MAX_BUNDLES", "100"), 10, 0)
+	if err != nil || gcMaxBundles == 0 {
+		logger.Error("invalid GC_MAX_BUNDLES", "value", os.Getenv("GC_MAX_BUNDLES"))
+		os.Exit(1)
+	}
+	// GC keeps the checkpoints and index CARs of the most recent heads when
+	// the retention is positive; the default of 0 keeps every head
+	gcHeadRetention, err := strconv.Atoi(getEnv("GC_HEAD_RETENTION", "0"))
+	if err != nil || gcHeadRetention < 0 {
+		logger.Error("invalid GC_HEAD_RETENTION", "value", os.Getenv("GC_HEAD_RETENTION"))
+		os.Exit(1)
+	}
+
 	// Create tlog manager with delegated storage model
 	// Each customer provides their own Storacha delegation - no service-owned space needed
 	tlogMgr, err := tlog.NewDelegatedManager(tlog.DelegatedManagerConfig{
@@ -77,20 +139,64 @@ func main() {
 		ServiceSigner: serviceSigner,
 		CIDStore:      cidStore,
 		Logger:        logger,
+		StorageClient: storageClient,
+		BlobCache:     blobCache,
+		GC:            gc.Config{MaxBundles: uint(gcMaxBundles), HeadRetention: gcHeadRetention},
 	})
 	if err != nil {
 		logger.Error("failed to create delegated tlog manager", "error", err)
 		os.Exit(1)
 	}
 
-	logger.Info("using customer-delegated Storacha storage for transparency logs")
+	if storageClient != nil {
+		logger.Info("using local blob storage for transparency logs")
+	} else {
+		logger.Info("using customer-delegated Storacha storage for transparency logs")
+	}
+
+	// Webhook notifications are queued in each log's outbox and delivered
+	// with retries; UCAN-signed payloads are signed with the service key
+	maxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", strconv.Itoa(webhook.DefaultMaxAttempts)))
+	if err != nil || maxAttempts < 1 {
+		logger.Error("invalid WEBHOOK_MAX_ATTEMPTS", "value", os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+		os.Exit(1)
+	}
+	webhooks, err := webhook.NewDispatcher(webhook.Config{
+		StoreManager: storeManager,
+		Logs:         tlogMgr,
+		Signer:       serviceSigner,
+		MaxAttempts:  maxAttempts,
+		Logger:       logger,
+	})
+	if err != nil {
+		logger.Error("failed to create webhook dispatcher", "error", err)
+		os.Exit(1)
+	}
 
 	logService := logSvc.NewLogServiceWithConfig(logSvc.LogServiceConfig{
 		TlogManager:  tlogMgr,
 		UcanIssuer:   ucanIssuer,
 		StoreManager: storeManager,
+		Webhooks:     webhooks,
 	})
 
+	// Admin commands run against the local data directory instead of serving
+	if len(os.Args) > 1 {
+		code := runCommand(context.Background(), os.Args[1:], logService)
+		storeManager.CloseAll()
+		os.Exit(code)
+	}
+
+	// Resume deliveries left in the webhook outboxes by a previous run
+	if err := webhooks.Start(context.Background()); err != nil {
+		logger.Error("failed to start webhook dispatcher", "error", err)
+		os.Exit(1)
+	}
+	if err := logService.StartGCScheduler(context.Background(), gcInterval); err != nil {
+		logger.Error("failed to start GC scheduler", "error", err)
+		os.Exit(1)
+	}
+
 	// Create ucanto server
 	ucantoServer, err := server.NewServer(
 		server.WithSigner(serviceSigner),
@@ -103,14 +209,35 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create tlog-tiles API handler using IPFS gateway proxy
-	// Tiles are stored in customer spaces and retrieved via IPFS gateway
+	// Create tlog-tiles API handler
+	// Paths are resolved through the live CID index and blobs are fetched by
+	// CID from the blob cache, then the IPFS gateway. Logs without local state
+	// are proxied to the gateway via their latest index CAR. Local blobs are
+	// not on IPFS, so they are read from the local blob storage instead.
 	gatewayURL := getEnv("IPFS_GATEWAY_URL", "https://w3s.link")
-	tlogHandler := server.NewTlogIPFSHandler(cidStore, gatewayURL, http.DefaultClient)
+	var tlogHandler *server.TlogHybridHandler
+	if storageClient != nil {
+		tlogHandler = server.NewTlogHybridHandler(storeManager, storageClient, nil)
+	} else {
+		ipfsHandler := server.NewTlogIPFSHandler(cidStore, gatewayURL, http.DefaultClient)
+		if blobCache != nil {
+			ipfsHandler.WithBlobCache(blobCache)
+		}
+		tlogHandler = server.NewTlogHybridHandler(storeManager, storacha.NewGatewayClient(gatewayURL), ipfsHandler)
+	}
+	if blobCache != nil {
+		tlogHandler.WithBlobCache(blobCache)
+	}
 
 	// Create HTTP handler for head endpoint
 	httpHandler := server.NewHTTPHandler(storeManager)
 
+	// Create HTTP handler for Merkle proof endpoints
+	proofHandler := server.NewProofHandler(logService)
+
+	// Create Server-Sent Events handler for checkpoint streams
+	streamHandler := server.NewCheckpointStreamHandler(logService)
+
 	// HTTP routes
 	mux := http.NewServeMux()
 
@@ -141,6 +268,10 @@ func main() {
 
 	// tlog-tiles API endpoints (GET) - public for witness validation
 	mux.HandleFunc("GET /logs/{logID}/head", httpHandler.HandleGetHead)
+	mux.HandleFunc("GET /logs/{logID}/proof/inclusion", proofHandler.HandleInclusionProof)
+	mux.HandleFunc("GET /logs/{logID}/proof/consistency", proofHandler.HandleConsistencyProof)
+	mux.HandleFunc("GET /logs/{logID}/checkpoints/stream", streamHandler.HandleLogStream)
+	mux.HandleFunc("GET /checkpoints/stream", streamHandler.HandleStream)
 	mux.HandleFunc("GET /logs/{logID}/checkpoint", tlogHandler.HandleCheckpoint)
 	mux.HandleFunc("GET /logs/{logID}/tile/{level}/{tilePath...}", tlogHandler.HandleTile)
 	mux.HandleFunc("GET /logs/{logID}/tile/entries/{entryPath...}", tlogHandler.HandleEntries)
@@ -157,8 +288,15 @@ func main() {
 	} else {
 		fmt.Println("Key Source: Ephemeral (generated on startup)")
 	}
-	fmt.Println("Storage Backend: Customer-delegated Storacha spaces")
-	fmt.Printf("IPFS Gateway: %s\n", gatewayURL)
+	if storageClient != nil {
+		fmt.Printf("Storage Backend: Local blob storage (%s)\n", blobPath)
+	} else {
+		fmt.Println("Storage Backend: Customer-delegated Storacha spaces")
+		fmt.Printf("IPFS Gateway: %s\n", gatewayURL)
+	}
+	if blobCache != nil {
+		fmt.Printf("Blob Cache: %s (%d MB)\n", getEnv("BLOB_CACHE_PATH", filepath.Join(basePath, "blob-cache")), blobCache.MaxBytes()>>20)
+	}
 	fmt.Println()
 	fmt.Println("UCAN RPC Endpoint (authenticated):")
 	fmt.Printf("  POST http://localhost:%s/\n", port)
@@ -166,16 +304,34 @@ func main() {
 	fmt.Println("UCAN Capabilities:")
 	fmt.Println("  tlog/create      - Create new transparent log")
 	fmt.Println("  tlog/append      - Append entries")
+	fmt.Println("  tlog/append-batch - Append multiple entries contiguously")
 	fmt.Println("  tlog/read        - Read entries")
+	fmt.Println("  tlog/prove       - Prove entry inclusion")
+	fmt.Println("  tlog/consistency - Prove consistency between tree sizes")
 	fmt.Println("  tlog/revoke      - Revoke delegations")
+	fmt.Println("  tlog/revocations/rebuild - Restore revocations from the revocation log")
+	fmt.Println("  tlog/attribution - Look up who appended entries")
+	fmt.Println("  tlog/restore     - Restore a log from its index CAR")
+	fmt.Println("  tlog/migrate     - Take over a log from another service")
+	fmt.Println("  tlog/webhook/add - Register a webhook for log events")
+	fmt.Println("  tlog/webhook/remove - Remove a webhook")
+	fmt.Println("  tlog/webhook/list - List a log's webhooks")
+	fmt.Println("  tlog/gc/schedule - Store a remove delegation for background GC")
+	fmt.Println("  tlog/gc/unschedule - Stop background GC of a log")
 	fmt.Println()
 	fmt.Println("Log State API:")
 	fmt.Printf("  GET http://localhost:%s/logs/{logID}/head\n", port)
+	fmt.Printf("  GET http://localhost:%s/logs/{logID}/proof/inclusion?index={N}&size={M}\n", port)
+	fmt.Printf("  GET http://localhost:%s/logs/{logID}/proof/consistency?from={N}&to={M}\n", port)
+	fmt.Printf("  GET http://localhost:%s/logs/{logID}/checkpoints/stream\n", port)
+	fmt.Printf("  GET http://localhost:%s/checkpoints/stream?log={logID}&log={logID}\n", port)
 	fmt.Println()
 	fmt.Println("Public tlog-tiles API (for witness validation):")
 	fmt.Printf("  GET http://localhost:%s/logs/{logID}/checkpoint\n", port)
 	fmt.Printf("  GET http://localhost:%s/logs/{logID}/tile/{level}/{path}\n", port)
 	fmt.Printf("  GET http://localhost:%s/logs/{logID}/tile/entries/{path}\n", port)
+	fmt.Println()
+	fmt.Println("Named logs are addressed as {spaceDID}%2F{name} in place of {logID}")
 
 	if err := http.ListenAndServe(addr, mux); err != nil {
 		logger.Error("server stopped", "error", err)
@@ -14,12 +14,26 @@ type StateStore interface {
 	// CID index
 	GetCIDIndex(ctx context.Context, logDID string) (map[string]string, error)
 	SetCID(ctx context.Context, logDID, path, cid string) error
+	SetCIDWithSize(ctx context.Context, logDID, path, cid string, size uint64) error
+	GetCIDSizes(ctx context.Context, logDID string) (map[string]uint64, error)
 	SetCIDs(ctx context.Context, logDID string, mappings map[string]string) error
 	DeleteCIDsWithPrefix(ctx context.Context, logDID, prefix string) error
 
 	// Tree state
 	GetTreeState(ctx context.Context, logDID string) (size uint64, root []byte, err error)
 	SetTreeState(ctx context.Context, logDID string, size uint64, root []byte) error
+	// SetTreeStateWithAttributions sets the tree state and records the
+	// attribution of the entries it integrates in one transaction.
+	SetTreeStateWithAttributions(ctx context.Context, logDID string, size uint64, root []byte, attributions []Attribution) error
+
+	// Head history
+	RecordHead(ctx context.Context, logDID string, treeSize uint64, root []byte, checkpointCID string, checkpointSize uint64) error
+	SetHeadIndexCID(ctx context.Context, logDID, checkpointCID, indexCID string) error
+
+	// Index CARs and the blobs of past heads
+	RecordIndexCAR(ctx context.Context, logDID string, car IndexCAR) error
+	GetStaleHeads(ctx context.Context, logDID string, retain int) (*StaleHeads, error)
+	DeleteHeadBlobs(ctx context.Context, logDID string, checkpointCIDs, indexRootCIDs []string) error
 
 	// Revocations
 	AddRevocation(ctx context.Context, delegationCID string) error
@@ -35,9 +49,43 @@ type StateStore interface {
 	SetGCProgress(ctx context.Context, logDID string, fromSize uint64) error
 }
 
+// Attribution records who appended a log entry and under which authority.
+type Attribution struct {
+	Index         uint64
+	InvocationCID string
+	IssuerDID     string
+	DelegationCID string
+	ReceivedAt    time.Time
+}
+
 // IndexPersistenceMeta holds metadata about index persistence to Storacha.
 type IndexPersistenceMeta struct {
 	LastUploadTime   time.Time
 	LastUploadedSize uint64
 	LastUploadedCID  string
 }
+
+// IndexCAR describes an uploaded index CAR.
+type IndexCAR struct {
+	RootCID       string   // Root of the UnixFS directory
+	ShardCID      string   // CAR blob holding the directory blocks new in this CAR
+	Size          uint64   // Size of the CAR blob in bytes
+	CheckpointCID string   // Checkpoint included in the index
+	Shards        []string // Earlier index CAR blobs holding blocks this CAR reuses
+	UploadedAt    time.Time
+}
+
+// HeadBlob is the checkpoint or index CAR of a past head.
+type HeadBlob struct {
+	TreeSize uint64 // Tree size of the head
+	CID      string // Checkpoint CID, or root CID of the index CAR
+	ShardCID string // CAR blob of an index CAR, empty for checkpoints
+	Size     uint64 // Blob size in bytes, zero if unknown
+}
+
+// StaleHeads lists the blobs of heads older than a retention window that no
+// retained head, nor the current checkpoint or index CAR, still uses.
+type StaleHeads struct {
+	Checkpoints []HeadBlob
+	IndexCARs   []HeadBlob
+}
@@ -0,0 +1,235 @@
+// Package blobcache provides an on-disk cache of content-addressed blobs.
+package blobcache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+// Cache is a disk-backed LRU cache of blobs keyed by CID, bounded by a byte
+// budget. Blobs are immutable, so entries never need invalidation; the cache
+// survives restarts and is safe to share between logs and goroutines.
+//
+// Every blob is checked against its CID before it is stored, so a corrupt
+// gateway response is never persisted.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // Least recently used at the front
+	entries map[string]*list.Element
+}
+
+type entry struct {
+	cid  string
+	size int64
+}
+
+// New opens the cache in dir, creating it if needed, with a budget of
+// maxBytes. Blobs left by a previous run are kept, most recently used last,
+// and trimmed to the budget.
+func New(dir string, maxBytes int64, logger *slog.Logger) (*Cache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("blob cache budget must be positive")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create blob cache directory: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		logger:   logger,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the blobs already on disk by modification time, which Get
+// refreshes on every hit.
+func (c *Cache) load() error {
+	type file struct {
+		cid     string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if _, err := cid.Decode(name); err != nil || path != c.path(name) {
+			return nil // Temporary or foreign file
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, file{cid: name, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan blob cache: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		c.entries[f.cid] = c.order.PushBack(&entry{cid: f.cid, size: f.size})
+		c.size += f.size
+	}
+	c.evict(0)
+	return nil
+}
+
+// path returns the file of a blob, sharded by the CID's last two characters.
+func (c *Cache) path(cidStr string) string {
+	return filepath.Join(c.dir, cidStr[len(cidStr)-2:], cidStr)
+}
+
+// Get returns the blob with the given CID, if cached.
+func (c *Cache) Get(cidStr string) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[cidStr]
+	if ok {
+		c.order.MoveToBack(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	path := c.path(cidStr)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.logger.Warn("failed to read cached blob", "cid", cidStr, "error", err)
+		c.remove(cidStr)
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores a blob under its CID, evicting the least recently used blobs to
+// stay within the budget. Blobs that do not match the CID are rejected, and
+// blobs larger than the whole budget are not cached.
+func (c *Cache) Put(cidStr string, data []byte) error {
+	if err := Verify(cidStr, data); err != nil {
+		return err
+	}
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[cidStr]; ok {
+		c.order.MoveToBack(elem)
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	path := c.path(cidStr)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create blob cache shard: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cached blob: %w", err)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store cached blob: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[cidStr]; ok {
+		c.order.MoveToBack(elem) // Stored concurrently
+		return nil
+	}
+	c.evict(size)
+	c.entries[cidStr] = c.order.PushBack(&entry{cid: cidStr, size: size})
+	c.size += size
+	return nil
+}
+
+// Size returns the bytes currently cached.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// MaxBytes returns the cache's byte budget.
+func (c *Cache) MaxBytes() int64 {
+	return c.maxBytes
+}
+
+// evict removes least recently used blobs until incoming more bytes fit in
+// the budget. The caller holds c.mu.
+func (c *Cache) evict(incoming int64) {
+	for c.size+incoming > c.maxBytes && c.order.Len() > 0 {
+		e := c.order.Remove(c.order.Front()).(*entry)
+		delete(c.entries, e.cid)
+		c.size -= e.size
+		if err := os.Remove(c.path(e.cid)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.logger.Warn("failed to evict cached blob", "cid", e.cid, "error", err)
+		}
+	}
+}
+
+// remove drops a blob whose file is unreadable.
+func (c *Cache) remove(cidStr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[cidStr]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, cidStr)
+		c.size -= elem.Value.(*entry).size
+	}
+	os.Remove(c.path(cidStr))
+}
+
+// Verify checks that data hashes to the given CID.
+func Verify(cidStr string, data []byte) error {
+	parsed, err := cid.Decode(cidStr)
+	if err != nil {
+		return fmt.Errorf("invalid CID %s: %w", cidStr, err)
+	}
+	sum, err := parsed.Prefix().Sum(data)
+	if err != nil {
+		return fmt.Errorf("failed to hash blob %s: %w", cidStr, err)
+	}
+	if !sum.Equals(parsed) {
+		return fmt.Errorf("blob does not match CID %s", cidStr)
+	}
+	return nil
+}
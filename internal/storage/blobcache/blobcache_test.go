@@ -0,0 +1,81 @@
+package blobcache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+)
+
+func rawCID(t *testing.T, data []byte) string {
+	t.Helper()
+	hash, err := mh.Sum(data, mh.SHA2_256, -1)
+	require.NoError(t, err)
+	return cid.NewCidV1(cid.Raw, hash).String()
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c, err := New(t.TempDir(), 1<<20, nil)
+	require.NoError(t, err)
+
+	data := []byte("tile")
+	key := rawCID(t, data)
+	_, ok := c.Get(key)
+	require.False(t, ok)
+
+	require.NoError(t, c.Put(key, data))
+	got, ok := c.Get(key)
+	require.True(t, ok)
+	require.Equal(t, data, got)
+	require.Equal(t, int64(len(data)), c.Size())
+
+	// Content not matching the CID is never stored
+	require.ErrorContains(t, c.Put(key, []byte("forged")), "does not match")
+	require.Error(t, c.Put("../../etc/passwd", data))
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := New(t.TempDir(), 30, nil)
+	require.NoError(t, err)
+
+	keys := make([]string, 3)
+	for i := range keys {
+		data := []byte(fmt.Sprintf("blob %d....", i)) // 10 bytes each
+		keys[i] = rawCID(t, data)
+		require.NoError(t, c.Put(keys[i], data))
+	}
+	_, ok := c.Get(keys[0]) // keys[1] is now least recently used
+	require.True(t, ok)
+
+	require.NoError(t, c.Put(rawCID(t, []byte("blob 3...!")), []byte("blob 3...!")))
+	_, ok = c.Get(keys[1])
+	require.False(t, ok)
+	_, ok = c.Get(keys[0])
+	require.True(t, ok)
+	require.Equal(t, int64(30), c.Size())
+}
+
+func TestCache_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 1<<20, nil)
+	require.NoError(t, err)
+	data := []byte("checkpoint")
+	key := rawCID(t, data)
+	require.NoError(t, c.Put(key, data))
+
+	reopened, err := New(dir, 1<<20, nil)
+	require.NoError(t, err)
+	got, ok := reopened.Get(key)
+	require.True(t, ok)
+	require.Equal(t, data, got)
+	require.Equal(t, int64(len(data)), reopened.Size())
+
+	// A smaller budget trims the cache on open
+	trimmed, err := New(dir, 4, nil)
+	require.NoError(t, err)
+	_, ok = trimmed.Get(key)
+	require.False(t, ok)
+	require.Zero(t, trimmed.Size())
+}
@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"errors"
+	"io/fs"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -76,6 +78,31 @@ func (m *StoreManager) BasePath() string {
 	return m.basePath
 }
 
+// LogDIDs returns the DIDs of the main logs with a database under the base
+// path, including logs whose store is not open.
+func (m *StoreManager) LogDIDs() ([]string, error) {
+	logsDir := filepath.Join(m.basePath, "logs")
+	var logDIDs []string
+	err := filepath.WalkDir(logsDir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == logsDir {
+			return filepath.SkipAll
+		}
+		if err != nil || d.IsDir() || d.Name() != "log.db" {
+			return err
+		}
+		rel, err := filepath.Rel(logsDir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		logDIDs = append(logDIDs, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logDIDs, nil
+}
+
 // GetStateStore returns the StateStore for the given log DID.
 // This is a convenience method that returns storage.StateStore interface.
 func (m *StoreManager) GetStateStore(logDID string) (storage.StateStore, error) {
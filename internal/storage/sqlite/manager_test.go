@@ -84,3 +84,20 @@ func TestStoreManager_CloseAll(t *testing.T) {
 	err = manager.CloseAll()
 	assert.NoError(t, err)
 }
+
+func TestStoreManager_LogDIDs(t *testing.T) {
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+
+	logDIDs, err := manager.LogDIDs()
+	require.NoError(t, err)
+	assert.Empty(t, logDIDs)
+
+	for _, logDID := range []string{"did:key:z6MkMain", "did:key:z6MkMain-revocations", "did:key:z6MkMain/evidence"} {
+		_, err := manager.GetStore(logDID)
+		require.NoError(t, err)
+	}
+	logDIDs, err = manager.LogDIDs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"did:key:z6MkMain", "did:key:z6MkMain/evidence"}, logDIDs)
+}
@@ -12,10 +12,12 @@ CREATE TABLE IF NOT EXISTS logs (
 );
 
 -- CID index: maps Tessera paths to Storacha CIDs
+-- size is the blob size in bytes, NULL when unknown (restored or migrated logs)
 CREATE TABLE IF NOT EXISTS cid_index (
     log_did TEXT NOT NULL,
     path TEXT NOT NULL,
     cid TEXT NOT NULL,
+    size INTEGER,
     PRIMARY KEY (log_did, path),
     FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
 );
@@ -34,6 +36,15 @@ CREATE TABLE IF NOT EXISTS revocations (
     revoked_at TEXT NOT NULL
 );
 
+-- Scoped revocations: every delegation issued by or to a revoked account DID,
+-- or granting a revoked ability, is revoked. Shared across the log pair.
+CREATE TABLE IF NOT EXISTS scoped_revocations (
+    type TEXT NOT NULL,
+    target TEXT NOT NULL,
+    revoked_at TEXT NOT NULL,
+    PRIMARY KEY (type, target)
+);
+
 -- Index persistence metadata: tracks when index was last persisted to Storacha
 CREATE TABLE IF NOT EXISTS index_persistence (
     log_did TEXT PRIMARY KEY,
@@ -51,6 +62,106 @@ CREATE TABLE IF NOT EXISTS gc_progress (
     FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
 );
 
+-- Head history: every head transition, for reading the log as of a past checkpoint
+CREATE TABLE IF NOT EXISTS head_history (
+    log_did TEXT NOT NULL,
+    tree_size INTEGER NOT NULL,
+    root BLOB,
+    checkpoint_cid TEXT,
+    checkpoint_size INTEGER,
+    index_cid TEXT,
+    recorded_at TEXT NOT NULL,
+    PRIMARY KEY (log_did, tree_size),
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
+-- Index CARs: every uploaded index CAR, so superseded ones can be garbage
+-- collected. shard_cid is the CAR blob; tree_size is the size of the head whose
+-- checkpoint the index includes, NULL if that head was not recorded.
+CREATE TABLE IF NOT EXISTS index_cars (
+    log_did TEXT NOT NULL,
+    root_cid TEXT NOT NULL,
+    shard_cid TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    checkpoint_cid TEXT,
+    tree_size INTEGER,
+    uploaded_at TEXT NOT NULL,
+    PRIMARY KEY (log_did, root_cid),
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
+-- Index CAR shards: the earlier CAR blobs whose blocks an incremental index
+-- CAR reuses, which must be kept as long as the CAR is
+CREATE TABLE IF NOT EXISTS index_car_shards (
+    log_did TEXT NOT NULL,
+    root_cid TEXT NOT NULL,
+    shard_cid TEXT NOT NULL,
+    PRIMARY KEY (log_did, root_cid, shard_cid),
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
+-- Entry attribution: the principal and authority behind each appended entry
+CREATE TABLE IF NOT EXISTS entry_attribution (
+    log_did TEXT NOT NULL,
+    entry_index INTEGER NOT NULL,
+    invocation_cid TEXT NOT NULL,
+    issuer_did TEXT NOT NULL,
+    delegation_cid TEXT NOT NULL,
+    received_at TEXT NOT NULL,
+    PRIMARY KEY (log_did, entry_index),
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
+-- Webhooks: HTTP endpoints notified of events on a log
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    log_did TEXT NOT NULL,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL,             -- Comma-separated event types
+    signing TEXT NOT NULL,            -- "hmac" or "ucan"
+    secret TEXT NOT NULL DEFAULT '',  -- Hex HMAC key for "hmac" signing
+    created_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
+-- Webhook outbox: notifications awaiting delivery, kept across restarts
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_error TEXT,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+-- Webhook cursor: tree size up to which append events have been queued
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    log_did TEXT PRIMARY KEY,
+    tree_size INTEGER NOT NULL
+);
+
+-- Scheduled GC: the space owner's stored space/blob/remove delegation
+CREATE TABLE IF NOT EXISTS gc_schedule (
+    log_did TEXT PRIMARY KEY,
+    delegation TEXT NOT NULL,         -- Base64 delegation, as sent by the owner
+    delegation_cid TEXT NOT NULL,
+    expires_at TEXT,                  -- NULL if the delegation never expires
+    created_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    last_run_at TEXT,
+    last_error TEXT,
+    FOREIGN KEY (log_did) REFERENCES logs(log_did) ON DELETE CASCADE
+);
+
 -- Indexes for common queries
 CREATE INDEX IF NOT EXISTS idx_cid_index_log_did ON cid_index(log_did);
 CREATE INDEX IF NOT EXISTS idx_revocations_revoked_at ON revocations(revoked_at);
+CREATE INDEX IF NOT EXISTS idx_head_history_checkpoint_cid ON head_history(log_did, checkpoint_cid);
+CREATE INDEX IF NOT EXISTS idx_head_history_index_cid ON head_history(log_did, index_cid);
+CREATE INDEX IF NOT EXISTS idx_entry_attribution_issuer ON entry_attribution(log_did, issuer_did, entry_index);
+CREATE INDEX IF NOT EXISTS idx_webhooks_log_did ON webhooks(log_did);
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_next_attempt ON webhook_outbox(next_attempt_at);
@@ -52,6 +52,10 @@ func OpenLogStore(basePath, logDID string) (*LogStore, error) {
 		db.Close()
 		return nil, fmt.Errorf("initialize schema: %w", err)
 	}
+	if err := migrateSchema(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate schema: %w", err)
+	}
 
 	return &LogStore{
 		db:     db,
@@ -60,6 +64,48 @@ func OpenLogStore(basePath, logDID string) (*LogStore, error) {
 	}, nil
 }
 
+// addedColumns lists the columns added to tables after their creation, which
+// CREATE TABLE IF NOT EXISTS leaves out of existing databases.
+var addedColumns = []struct{ table, column, definition string }{
+	{"cid_index", "size", "INTEGER"},
+	{"head_history", "checkpoint_size", "INTEGER"},
+}
+
+// migrateSchema adds missing columns to a database created by an older version.
+func migrateSchema(db *sql.DB) error {
+	for _, c := range addedColumns {
+		exists, err := hasColumn(db, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("add %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 func (s *LogStore) Close() error {
 	return s.db.Close()
 }
@@ -142,14 +188,63 @@ func (s *LogStore) GetCIDIndex(ctx context.Context, logDID string) (map[string]s
 	return index, rows.Err()
 }
 
+// GetCID returns the CID stored at path in a log's CID index.
+// Returns ErrNotFound if the path is not indexed.
+func (s *LogStore) GetCID(ctx context.Context, logDID, path string) (string, error) {
+	var cid string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT cid FROM cid_index WHERE log_did = ? AND path = ?`,
+		logDID, path).Scan(&cid)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return cid, nil
+}
+
 func (s *LogStore) SetCID(ctx context.Context, logDID, path, cid string) error {
 	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO cid_index (log_did, path, cid) VALUES (?, ?, ?)
-		 ON CONFLICT(log_did, path) DO UPDATE SET cid = excluded.cid`,
+		 ON CONFLICT(log_did, path) DO UPDATE SET cid = excluded.cid, size = NULL`,
 		logDID, path, cid)
 	return err
 }
 
+// SetCIDWithSize stores a CID mapping along with the size of the blob in bytes.
+func (s *LogStore) SetCIDWithSize(ctx context.Context, logDID, path, cid string, size uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO cid_index (log_did, path, cid, size) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(log_did, path) DO UPDATE SET cid = excluded.cid, size = excluded.size`,
+		logDID, path, cid, int64(size))
+	return err
+}
+
+// GetCIDSizes returns the blob sizes of a log's CID index by path. Paths
+// whose size is unknown are left out.
+func (s *LogStore) GetCIDSizes(ctx context.Context, logDID string) (map[string]uint64, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, size FROM cid_index WHERE log_did = ? AND size IS NOT NULL`,
+		logDID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]uint64)
+	for rows.Next() {
+		var path string
+		var size int64
+		if err := rows.Scan(&path, &size); err != nil {
+			return nil, err
+		}
+		sizes[path] = uint64(size)
+	}
+
+	return sizes, rows.Err()
+}
+
 func (s *LogStore) SetCIDs(ctx context.Context, logDID string, mappings map[string]string) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -159,7 +254,7 @@ func (s *LogStore) SetCIDs(ctx context.Context, logDID string, mappings map[stri
 
 	stmt, err := tx.PrepareContext(ctx,
 		`INSERT INTO cid_index (log_did, path, cid) VALUES (?, ?, ?)
-		 ON CONFLICT(log_did, path) DO UPDATE SET cid = excluded.cid`)
+		 ON CONFLICT(log_did, path) DO UPDATE SET cid = excluded.cid, size = NULL`)
 	if err != nil {
 		return err
 	}
@@ -211,11 +306,16 @@ func (s *LogStore) SetTreeState(ctx context.Context, logDID string, size uint64,
 
 // AddRevocation marks a delegation as revoked. Idempotent.
 func (s *LogStore) AddRevocation(ctx context.Context, delegationCID string) error {
-	now := time.Now().UTC().Format(time.RFC3339)
+	return s.AddRevocationAt(ctx, delegationCID, time.Now())
+}
+
+// AddRevocationAt marks a delegation as revoked at the given time. Idempotent.
+// Used when restoring revocations recorded earlier in the revocation log.
+func (s *LogStore) AddRevocationAt(ctx context.Context, delegationCID string, revokedAt time.Time) error {
 	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO revocations (delegation_cid, revoked_at) VALUES (?, ?)
 		 ON CONFLICT(delegation_cid) DO NOTHING`,
-		delegationCID, now)
+		delegationCID, revokedAt.UTC().Format(time.RFC3339))
 	return err
 }
 
@@ -252,6 +352,82 @@ func (s *LogStore) IsRevoked(ctx context.Context, delegationCID string) (bool, e
 	return count > 0, nil
 }
 
+// ScopedRevocation is an account-wide or capability-scoped revocation.
+type ScopedRevocation struct {
+	Type      string // "account" (Target is a DID) or "capability" (Target is an ability)
+	Target    string
+	RevokedAt time.Time
+}
+
+// AddScopedRevocation records an account-wide or capability-scoped revocation. Idempotent.
+func (s *LogStore) AddScopedRevocation(ctx context.Context, revType, target string, revokedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO scoped_revocations (type, target, revoked_at) VALUES (?, ?, ?)
+		 ON CONFLICT(type, target) DO NOTHING`,
+		revType, target, revokedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// IsScopedRevoked checks if an account or ability has been revoked.
+func (s *LogStore) IsScopedRevoked(ctx context.Context, revType, target string) (bool, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM scoped_revocations WHERE type = ? AND target = ?`,
+		revType, target).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasScopedRevocation reports whether any of targets has been revoked with
+// revocation type revType, in one lookup on the revocation key.
+func (s *LogStore) HasScopedRevocation(ctx context.Context, revType string, targets []string) (bool, error) {
+	if len(targets) == 0 {
+		return false, nil
+	}
+	args := make([]any, 0, len(targets)+1)
+	args = append(args, revType)
+	for _, target := range targets {
+		args = append(args, target)
+	}
+	var found int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM scoped_revocations WHERE type = ? AND target IN (?`+
+			strings.Repeat(", ?", len(targets)-1)+`))`,
+		args...).Scan(&found)
+	if err != nil {
+		return false, err
+	}
+	return found == 1, nil
+}
+
+// GetScopedRevocations returns all account-wide and capability-scoped revocations.
+func (s *LogStore) GetScopedRevocations(ctx context.Context) ([]ScopedRevocation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT type, target, revoked_at FROM scoped_revocations ORDER BY revoked_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revocations []ScopedRevocation
+	for rows.Next() {
+		var rev ScopedRevocation
+		var revokedAt string
+		if err := rows.Scan(&rev.Type, &rev.Target, &revokedAt); err != nil {
+			return nil, err
+		}
+		rev.RevokedAt, err = time.Parse(time.RFC3339, revokedAt)
+		if err != nil {
+			slog.Warn("failed to parse revoked_at timestamp", "value", revokedAt, "error", err)
+		}
+		revocations = append(revocations, rev)
+	}
+
+	return revocations, rows.Err()
+}
+
 // GetIndexPersistence retrieves index persistence metadata.
 // Returns nil if no metadata exists yet.
 func (s *LogStore) GetIndexPersistence(ctx context.Context, logDID string) (*storage.IndexPersistenceMeta, error) {
@@ -326,3 +502,756 @@ func (s *LogStore) SetGCProgress(ctx context.Context, logDID string, fromSize ui
 	)
 	return err
 }
+
+// HeadRecord is a single entry in a log's head history.
+type HeadRecord struct {
+	TreeSize      uint64
+	Root          []byte
+	CheckpointCID string
+	IndexCID      string
+	RecordedAt    time.Time
+}
+
+// RecordHead records a head transition in the head history (upsert by tree size).
+// The index CID is preserved when a head is re-recorded. checkpointSize is
+// the size of the checkpoint blob in bytes, zero if unknown.
+func (s *LogStore) RecordHead(ctx context.Context, logDID string, treeSize uint64, root []byte, checkpointCID string, checkpointSize uint64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var size sql.NullInt64
+	if checkpointSize > 0 {
+		size = sql.NullInt64{Int64: int64(checkpointSize), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO head_history (log_did, tree_size, root, checkpoint_cid, checkpoint_size, recorded_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(log_did, tree_size) DO UPDATE SET
+		   root = excluded.root,
+		   checkpoint_cid = excluded.checkpoint_cid,
+		   checkpoint_size = excluded.checkpoint_size,
+		   recorded_at = excluded.recorded_at`,
+		logDID, treeSize, root, checkpointCID, size, now)
+	return err
+}
+
+// SetHeadIndexCID attaches an uploaded index CAR CID to the head whose
+// checkpoint it contains. Does nothing if no such head was recorded.
+func (s *LogStore) SetHeadIndexCID(ctx context.Context, logDID, checkpointCID, indexCID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE head_history SET index_cid = ? WHERE log_did = ? AND checkpoint_cid = ?`,
+		indexCID, logDID, checkpointCID)
+	return err
+}
+
+// GetHeadHistory returns all recorded heads for a log, oldest first.
+func (s *LogStore) GetHeadHistory(ctx context.Context, logDID string) ([]HeadRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT tree_size, root, checkpoint_cid, index_cid, recorded_at
+		 FROM head_history WHERE log_did = ? ORDER BY tree_size`,
+		logDID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []HeadRecord
+	for rows.Next() {
+		record, err := scanHeadRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	return records, rows.Err()
+}
+
+// GetHeadAtSize returns the head recorded at exactly the given tree size.
+// Returns ErrNotFound if no checkpoint was published at that size.
+func (s *LogStore) GetHeadAtSize(ctx context.Context, logDID string, treeSize uint64) (*HeadRecord, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT tree_size, root, checkpoint_cid, index_cid, recorded_at
+		 FROM head_history WHERE log_did = ? AND tree_size = ?`,
+		logDID, treeSize)
+	return scanHeadRecord(row)
+}
+
+// GetHeadByCID returns the head whose checkpoint CID or index CAR CID matches cid.
+// Returns ErrNotFound if no such head was recorded.
+func (s *LogStore) GetHeadByCID(ctx context.Context, logDID, cid string) (*HeadRecord, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT tree_size, root, checkpoint_cid, index_cid, recorded_at
+		 FROM head_history WHERE log_did = ? AND (checkpoint_cid = ? OR index_cid = ?)
+		 ORDER BY tree_size LIMIT 1`,
+		logDID, cid, cid)
+	return scanHeadRecord(row)
+}
+
+// RecordIndexCAR records an uploaded index CAR, linked to the head whose
+// checkpoint it includes, and the earlier CARs it reuses blocks from.
+func (s *LogStore) RecordIndexCAR(ctx context.Context, logDID string, car storage.IndexCAR) error {
+	uploadedAt := car.UploadedAt
+	if uploadedAt.IsZero() {
+		uploadedAt = time.Now()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO index_cars (log_did, root_cid, shard_cid, size, checkpoint_cid, tree_size, uploaded_at)
+		 VALUES (?1, ?2, ?3, ?4, ?5,
+		   (SELECT tree_size FROM head_history WHERE log_did = ?1 AND checkpoint_cid = ?5), ?6)
+		 ON CONFLICT(log_did, root_cid) DO UPDATE SET
+		   shard_cid = excluded.shard_cid,
+		   size = excluded.size,
+		   checkpoint_cid = excluded.checkpoint_cid,
+		   tree_size = excluded.tree_size,
+		   uploaded_at = excluded.uploaded_at`,
+		logDID, car.RootCID, car.ShardCID, int64(car.Size), car.CheckpointCID,
+		uploadedAt.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM index_car_shards WHERE log_did = ? AND root_cid = ?`,
+		logDID, car.RootCID); err != nil {
+		return err
+	}
+	for _, shard := range car.Shards {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO index_car_shards (log_did, root_cid, shard_cid) VALUES (?, ?, ?)`,
+			logDID, car.RootCID, shard); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetStaleHeads returns the checkpoints and index CARs of the heads older
+// than the retain most recent ones. Blobs still used by a retained head, and
+// the current checkpoint and index CAR, are left out, as are index CARs whose
+// blocks a kept index CAR reuses.
+func (s *LogStore) GetStaleHeads(ctx context.Context, logDID string, retain int) (*storage.StaleHeads, error) {
+	if retain < 1 {
+		return nil, fmt.Errorf("head retention must be at least 1, got %d", retain)
+	}
+	stale := &storage.StaleHeads{}
+
+	// Heads below the oldest retained one are stale
+	var cutoff int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT tree_size FROM head_history WHERE log_did = ?
+		 ORDER BY tree_size DESC LIMIT 1 OFFSET ?`,
+		logDID, retain-1).Scan(&cutoff)
+	if err == sql.ErrNoRows {
+		return stale, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT tree_size, checkpoint_cid, checkpoint_size FROM head_history
+		 WHERE log_did = ?1 AND tree_size < ?2 AND checkpoint_cid IS NOT NULL AND checkpoint_cid != ''
+		   AND checkpoint_cid NOT IN (
+		     SELECT checkpoint_cid FROM head_history
+		     WHERE log_did = ?1 AND tree_size >= ?2 AND checkpoint_cid IS NOT NULL
+		     UNION SELECT cid FROM cid_index WHERE log_did = ?1 AND path = 'checkpoint')
+		 ORDER BY tree_size`,
+		logDID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var blob storage.HeadBlob
+		var size sql.NullInt64
+		if err := rows.Scan(&blob.TreeSize, &blob.CID, &size); err != nil {
+			return nil, err
+		}
+		blob.Size = uint64(size.Int64)
+		stale.Checkpoints = append(stale.Checkpoints, blob)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	carRows, err := s.db.QueryContext(ctx,
+		`WITH kept AS (
+		   SELECT index_cid AS root_cid FROM head_history
+		   WHERE log_did = ?1 AND tree_size >= ?2 AND index_cid IS NOT NULL
+		   UNION SELECT last_uploaded_cid FROM index_persistence
+		   WHERE log_did = ?1 AND last_uploaded_cid IS NOT NULL
+		   UNION SELECT root_cid FROM index_cars
+		   WHERE log_did = ?1 AND (tree_size IS NULL OR tree_size >= ?2))
+		 SELECT tree_size, root_cid, shard_cid, size FROM index_cars
+		 WHERE log_did = ?1 AND tree_size < ?2
+		   AND root_cid NOT IN (SELECT root_cid FROM kept)
+		   AND shard_cid NOT IN (
+		     SELECT shard_cid FROM index_car_shards
+		     WHERE log_did = ?1 AND root_cid IN (SELECT root_cid FROM kept))
+		 ORDER BY tree_size, uploaded_at`,
+		logDID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer carRows.Close()
+	for carRows.Next() {
+		var blob storage.HeadBlob
+		var size int64
+		if err := carRows.Scan(&blob.TreeSize, &blob.CID, &blob.ShardCID, &size); err != nil {
+			return nil, err
+		}
+		blob.Size = uint64(size)
+		stale.IndexCARs = append(stale.IndexCARs, blob)
+	}
+
+	return stale, carRows.Err()
+}
+
+// DeleteHeadBlobs forgets collected head blobs: the checkpoint and index CIDs
+// are cleared from the head history, which keeps the heads' tree sizes and
+// roots, and the index CAR records are dropped.
+func (s *LogStore) DeleteHeadBlobs(ctx context.Context, logDID string, checkpointCIDs, indexRootCIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, cid := range checkpointCIDs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE head_history SET checkpoint_cid = NULL, checkpoint_size = NULL
+			 WHERE log_did = ? AND checkpoint_cid = ?`,
+			logDID, cid); err != nil {
+			return err
+		}
+	}
+	for _, cid := range indexRootCIDs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE head_history SET index_cid = NULL WHERE log_did = ? AND index_cid = ?`,
+			logDID, cid); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM index_cars WHERE log_did = ? AND root_cid = ?`,
+			logDID, cid); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM index_car_shards WHERE log_did = ? AND root_cid = ?`,
+			logDID, cid); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RestoredLog is the state of a log read from an index CAR.
+type RestoredLog struct {
+	Index          map[string]string // Path->CID mappings
+	TreeSize       uint64
+	Root           []byte
+	CheckpointCID  string
+	CheckpointSize uint64
+	IndexCID       string // Root CID of the index CAR the state was read from
+}
+
+// RestoreLog writes the log record, CID index, tree state, head and index
+// persistence rows of a restored log in a single transaction, so a failed
+// restore leaves no trace and can be retried. Fails if the log already exists.
+func (s *LogStore) RestoreLog(ctx context.Context, logDID string, r RestoredLog) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO logs (log_did, created_at, updated_at) VALUES (?, ?, ?)`,
+		logDID, now, now); err != nil {
+		return fmt.Errorf("failed to create log record: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO cid_index (log_did, path, cid) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for path, cid := range r.Index {
+		if _, err := stmt.ExecContext(ctx, logDID, path, cid); err != nil {
+			return fmt.Errorf("failed to restore CID index: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tree_state (log_did, size, root) VALUES (?, ?, ?)`,
+		logDID, r.TreeSize, r.Root); err != nil {
+		return fmt.Errorf("failed to restore tree state: %w", err)
+	}
+
+	var size sql.NullInt64
+	if r.CheckpointSize > 0 {
+		size = sql.NullInt64{Int64: int64(r.CheckpointSize), Valid: true}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO head_history (log_did, tree_size, root, checkpoint_cid, checkpoint_size, index_cid, recorded_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		logDID, r.TreeSize, r.Root, r.CheckpointCID, size, r.IndexCID, now); err != nil {
+		return fmt.Errorf("failed to record head: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO index_persistence (log_did, last_upload_time, last_uploaded_size, last_uploaded_cid)
+		 VALUES (?, ?, ?, ?)`,
+		logDID, now, uint64(len(r.Index)), r.IndexCID); err != nil {
+		return fmt.Errorf("failed to record index persistence: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func scanHeadRecord(row interface{ Scan(...any) error }) (*HeadRecord, error) {
+	var record HeadRecord
+	var checkpointCID, indexCID sql.NullString
+	var recordedAt string
+
+	err := row.Scan(&record.TreeSize, &record.Root, &checkpointCID, &indexCID, &recordedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.CheckpointCID = checkpointCID.String
+	record.IndexCID = indexCID.String
+	record.RecordedAt, err = time.Parse(time.RFC3339, recordedAt)
+	if err != nil {
+		slog.Warn("failed to parse recorded_at timestamp", "value", recordedAt, "error", err)
+	}
+
+	return &record, nil
+}
+
+// Attribution records who appended a log entry and under which authority.
+type Attribution = storage.Attribution
+
+// AddAttributions records the attribution of appended entries in one transaction.
+// Re-recording an index replaces its attribution.
+func (s *LogStore) AddAttributions(ctx context.Context, logDID string, attributions []Attribution) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addAttributions(ctx, tx, logDID, attributions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetTreeStateWithAttributions sets the Merkle tree state for a log and
+// records the attribution of the entries it integrates in one transaction,
+// so an integrated entry is never left without its attribution.
+func (s *LogStore) SetTreeStateWithAttributions(ctx context.Context, logDID string, size uint64, root []byte, attributions []Attribution) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tree_state (log_did, size, root) VALUES (?, ?, ?)
+		 ON CONFLICT(log_did) DO UPDATE SET size = excluded.size, root = excluded.root`,
+		logDID, size, root); err != nil {
+		return err
+	}
+	if err := addAttributions(ctx, tx, logDID, attributions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addAttributions(ctx context.Context, tx *sql.Tx, logDID string, attributions []Attribution) error {
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO entry_attribution (log_did, entry_index, invocation_cid, issuer_did, delegation_cid, received_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(log_did, entry_index) DO UPDATE SET
+		   invocation_cid = excluded.invocation_cid,
+		   issuer_did = excluded.issuer_did,
+		   delegation_cid = excluded.delegation_cid,
+		   received_at = excluded.received_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range attributions {
+		if _, err := stmt.ExecContext(ctx, logDID, a.Index, a.InvocationCID, a.IssuerDID, a.DelegationCID,
+			a.ReceivedAt.UTC().Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAttribution returns the attribution of the entry at index.
+// Returns ErrNotFound if none was recorded.
+func (s *LogStore) GetAttribution(ctx context.Context, logDID string, index uint64) (*Attribution, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT entry_index, invocation_cid, issuer_did, delegation_cid, received_at
+		 FROM entry_attribution WHERE log_did = ? AND entry_index = ?`,
+		logDID, index)
+	return scanAttribution(row)
+}
+
+// GetAttributionsByIssuer returns the attributions of entries appended by
+// issuerDID, in index order, skipping the first offset and returning at most limit.
+func (s *LogStore) GetAttributionsByIssuer(ctx context.Context, logDID, issuerDID string, offset, limit int64) ([]Attribution, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT entry_index, invocation_cid, issuer_did, delegation_cid, received_at
+		 FROM entry_attribution WHERE log_did = ? AND issuer_did = ?
+		 ORDER BY entry_index LIMIT ? OFFSET ?`,
+		logDID, issuerDID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attributions []Attribution
+	for rows.Next() {
+		a, err := scanAttribution(rows)
+		if err != nil {
+			return nil, err
+		}
+		attributions = append(attributions, *a)
+	}
+
+	return attributions, rows.Err()
+}
+
+func scanAttribution(row interface{ Scan(...any) error }) (*Attribution, error) {
+	var a Attribution
+	var receivedAt string
+
+	err := row.Scan(&a.Index, &a.InvocationCID, &a.IssuerDID, &a.DelegationCID, &receivedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.ReceivedAt, err = time.Parse(time.RFC3339, receivedAt)
+	if err != nil {
+		slog.Warn("failed to parse received_at timestamp", "value", receivedAt, "error", err)
+	}
+
+	return &a, nil
+}
+
+// Webhook is an HTTP endpoint notified of events on a log.
+type Webhook struct {
+	ID        string
+	URL       string
+	Events    []string // Event types delivered
+	Signing   string   // "hmac" or "ucan"
+	Secret    string   // Hex HMAC key, for "hmac" signing
+	CreatedBy string   // DID that registered the webhook
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is a notification in a log's webhook outbox.
+type WebhookDelivery struct {
+	ID        int64
+	Webhook   Webhook
+	EventID   string
+	Event     string
+	Payload   []byte
+	Attempts  int
+	LastError string
+}
+
+// AddWebhook registers a webhook for a log.
+func (s *LogStore) AddWebhook(ctx context.Context, logDID string, w Webhook) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhooks (id, log_did, url, events, signing, secret, created_by, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.ID, logDID, w.URL, strings.Join(w.Events, ","), w.Signing, w.Secret, w.CreatedBy,
+		w.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// RemoveWebhook removes a webhook and its pending deliveries.
+// Returns ErrNotFound if the log has no such webhook.
+func (s *LogStore) RemoveWebhook(ctx context.Context, logDID, id string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM webhooks WHERE log_did = ? AND id = ?`,
+		logDID, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhooks returns the webhooks of a log in registration order.
+func (s *LogStore) ListWebhooks(ctx context.Context, logDID string) ([]Webhook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, url, events, signing, secret, created_by, created_at
+		 FROM webhooks WHERE log_did = ? ORDER BY created_at, id`,
+		logDID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		var w Webhook
+		var events, createdAt string
+		if err := rows.Scan(&w.ID, &w.URL, &events, &w.Signing, &w.Secret, &w.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		w.Events = strings.Split(events, ",")
+		w.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			slog.Warn("failed to parse created_at timestamp", "value", createdAt, "error", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// EnqueueWebhookEvent adds a notification to the outbox of every webhook of
+// the log subscribed to event, and returns the number of deliveries queued.
+func (s *LogStore) EnqueueWebhookEvent(ctx context.Context, logDID, eventID, event string, payload []byte) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_outbox (webhook_id, event_id, event, payload, next_attempt_at)
+		 SELECT id, ?, ?, ?, ? FROM webhooks
+		 WHERE log_did = ? AND ',' || events || ',' LIKE '%,' || ? || ',%'`,
+		eventID, event, payload, time.Now().UTC().Format(time.RFC3339), logDID, event)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DueWebhookDeliveries returns up to limit deliveries whose next attempt is
+// due at now, oldest first.
+func (s *LogStore) DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT o.id, o.event_id, o.event, o.payload, o.attempts, COALESCE(o.last_error, ''),
+		        w.id, w.url, w.events, w.signing, w.secret, w.created_by
+		 FROM webhook_outbox o JOIN webhooks w ON w.id = o.webhook_id
+		 WHERE o.next_attempt_at <= ?
+		 ORDER BY o.id LIMIT ?`,
+		now.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var events string
+		if err := rows.Scan(&d.ID, &d.EventID, &d.Event, &d.Payload, &d.Attempts, &d.LastError,
+			&d.Webhook.ID, &d.Webhook.URL, &events, &d.Webhook.Signing, &d.Webhook.Secret, &d.Webhook.CreatedBy); err != nil {
+			return nil, err
+		}
+		d.Webhook.Events = strings.Split(events, ",")
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CountWebhookDeliveries returns the number of deliveries in the outbox,
+// or of one webhook when webhookID is not empty.
+func (s *LogStore) CountWebhookDeliveries(ctx context.Context, webhookID string) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM webhook_outbox WHERE ? = '' OR webhook_id = ?`,
+		webhookID, webhookID).Scan(&n)
+	return n, err
+}
+
+// CompleteWebhookDelivery removes a delivered, or abandoned, notification
+// from the outbox.
+func (s *LogStore) CompleteWebhookDelivery(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM webhook_outbox WHERE id = ?`,
+		id)
+	return err
+}
+
+// RetryWebhookDelivery records a failed delivery attempt and schedules the
+// next one.
+func (s *LogStore) RetryWebhookDelivery(ctx context.Context, id int64, next time.Time, lastError string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+		 WHERE id = ?`,
+		next.UTC().Format(time.RFC3339), lastError, id)
+	return err
+}
+
+// PostponeWebhookDeliveries moves the next attempt of a webhook's deliveries
+// due before next to next, e.g. while its endpoint is failing.
+func (s *LogStore) PostponeWebhookDeliveries(ctx context.Context, webhookID string, next time.Time) error {
+	at := next.UTC().Format(time.RFC3339)
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET next_attempt_at = ?
+		 WHERE webhook_id = ? AND next_attempt_at < ?`,
+		at, webhookID, at)
+	return err
+}
+
+// GetWebhookCursor returns the tree size up to which append events of the log
+// have been queued. Returns ErrNotFound if no checkpoint has been seen yet.
+func (s *LogStore) GetWebhookCursor(ctx context.Context, logDID string) (uint64, error) {
+	var size uint64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT tree_size FROM webhook_cursor WHERE log_did = ?`,
+		logDID).Scan(&size)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	return size, err
+}
+
+// SetWebhookCursor records the tree size up to which append events of the log
+// have been queued.
+func (s *LogStore) SetWebhookCursor(ctx context.Context, logDID string, size uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_cursor (log_did, tree_size) VALUES (?, ?)
+		 ON CONFLICT(log_did) DO UPDATE SET tree_size = excluded.tree_size`,
+		logDID, size)
+	return err
+}
+
+// GCSchedule is a log's stored space/blob/remove delegation, used to collect
+// garbage in the background without an invocation from the space owner.
+type GCSchedule struct {
+	Delegation    string     // Base64-encoded delegation
+	DelegationCID string     // CID of the delegation, to match revocations
+	ExpiresAt     *time.Time // Nil if the delegation never expires
+	CreatedBy     string     // DID that scheduled collection
+	CreatedAt     time.Time
+	LastRunAt     *time.Time // Start of the last scheduled run, nil before the first
+	LastError     string     // Error of the last scheduled run, if it failed
+}
+
+// SetGCSchedule stores the delegation used for scheduled GC of a log,
+// replacing any previous one and resetting its run history.
+func (s *LogStore) SetGCSchedule(ctx context.Context, logDID string, sched GCSchedule) error {
+	var expiresAt *string
+	if sched.ExpiresAt != nil {
+		v := sched.ExpiresAt.UTC().Format(time.RFC3339)
+		expiresAt = &v
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO gc_schedule (log_did, delegation, delegation_cid, expires_at, created_by, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(log_did) DO UPDATE SET
+		   delegation = excluded.delegation,
+		   delegation_cid = excluded.delegation_cid,
+		   expires_at = excluded.expires_at,
+		   created_by = excluded.created_by,
+		   created_at = excluded.created_at,
+		   last_run_at = NULL,
+		   last_error = NULL`,
+		logDID, sched.Delegation, sched.DelegationCID, expiresAt, sched.CreatedBy,
+		sched.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// GetGCSchedule returns the stored GC delegation of a log.
+// Returns ErrNotFound if collection is not scheduled for the log.
+func (s *LogStore) GetGCSchedule(ctx context.Context, logDID string) (*GCSchedule, error) {
+	var sched GCSchedule
+	var expiresAt, lastRunAt sql.NullString
+	var createdAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT delegation, delegation_cid, expires_at, created_by, created_at, last_run_at, COALESCE(last_error, '')
+		 FROM gc_schedule WHERE log_did = ?`,
+		logDID,
+	).Scan(&sched.Delegation, &sched.DelegationCID, &expiresAt, &sched.CreatedBy, &createdAt, &lastRunAt, &sched.LastError)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sched.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		slog.Warn("failed to parse created_at timestamp", "value", createdAt, "error", err)
+	}
+	if expiresAt.Valid {
+		t, err := time.Parse(time.RFC3339, expiresAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at timestamp %q: %w", expiresAt.String, err)
+		}
+		sched.ExpiresAt = &t
+	}
+	if lastRunAt.Valid {
+		t, err := time.Parse(time.RFC3339, lastRunAt.String)
+		if err != nil {
+			slog.Warn("failed to parse last_run_at timestamp", "value", lastRunAt.String, "error", err)
+		} else {
+			sched.LastRunAt = &t
+		}
+	}
+	return &sched, nil
+}
+
+// DeleteGCSchedule removes the stored GC delegation of a log.
+// Returns ErrNotFound if collection is not scheduled for the log.
+func (s *LogStore) DeleteGCSchedule(ctx context.Context, logDID string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM gc_schedule WHERE log_did = ?`,
+		logDID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordGCScheduleRun records the start time and outcome of a scheduled GC
+// run. An empty runErr clears the last error.
+func (s *LogStore) RecordGCScheduleRun(ctx context.Context, logDID string, startedAt time.Time, runErr string) error {
+	var lastError *string
+	if runErr != "" {
+		lastError = &runErr
+	}
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE gc_schedule SET last_run_at = ?, last_error = ? WHERE log_did = ?`,
+		startedAt.UTC().Format(time.RFC3339), lastError, logDID)
+	return err
+}
@@ -2,6 +2,8 @@ package sqlite_test
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/relves/ucanlog/internal/storage"
 	"github.com/relves/ucanlog/internal/storage/sqlite"
 )
 
@@ -178,6 +181,12 @@ func TestLogStore_CIDIndex_SetAndGet(t *testing.T) {
 	assert.Equal(t, "bafyCID1", index["tile/0/000"])
 	assert.Equal(t, "bafyCID2", index["tile/0/001"])
 	assert.Equal(t, "bafyCheckpoint", index["checkpoint"])
+
+	cid, err := store.GetCID(ctx, logDID, "checkpoint")
+	require.NoError(t, err)
+	assert.Equal(t, "bafyCheckpoint", cid)
+	_, err = store.GetCID(ctx, logDID, "tile/0/002")
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
 }
 
 func TestLogStore_CIDIndex_Upsert(t *testing.T) {
@@ -202,6 +211,70 @@ func TestLogStore_CIDIndex_Upsert(t *testing.T) {
 	assert.Equal(t, "bafyNew", index["checkpoint"])
 }
 
+func TestLogStore_CIDIndex_Sizes(t *testing.T) {
+	store, err := sqlite.OpenLogStore(t.TempDir(), "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	require.NoError(t, store.SetCIDWithSize(ctx, logDID, "tile/0/000", "bafyTile", 1024))
+	require.NoError(t, store.SetCIDWithSize(ctx, logDID, "checkpoint", "bafyOld", 100))
+	require.NoError(t, store.SetCIDs(ctx, logDID, map[string]string{"tile/0/001": "bafyRestored"}))
+
+	sizes, err := store.GetCIDSizes(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"tile/0/000": 1024, "checkpoint": 100}, sizes)
+
+	// Replacing a CID without a size forgets the old size
+	require.NoError(t, store.SetCID(ctx, logDID, "checkpoint", "bafyNew"))
+	sizes, err = store.GetCIDSizes(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"tile/0/000": 1024}, sizes)
+
+	index, err := store.GetCIDIndex(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyTile", index["tile/0/000"])
+	assert.Equal(t, "bafyNew", index["checkpoint"])
+}
+
+func TestOpenLogStore_MigratesCIDIndexSize(t *testing.T) {
+	basePath := t.TempDir()
+	logDID := "did:key:z6MkMain"
+
+	// A database created before cid_index had a size column
+	logDir := filepath.Join(basePath, "logs", logDID)
+	require.NoError(t, os.MkdirAll(logDir, 0755))
+	db, err := sql.Open("sqlite", filepath.Join(logDir, "log.db"))
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE cid_index (
+		log_did TEXT NOT NULL,
+		path TEXT NOT NULL,
+		cid TEXT NOT NULL,
+		PRIMARY KEY (log_did, path)
+	);
+	INSERT INTO cid_index (log_did, path, cid) VALUES ('did:key:z6MkMain', 'checkpoint', 'bafyOld');`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := sqlite.OpenLogStore(basePath, logDID)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+	sizes, err := store.GetCIDSizes(ctx, logDID)
+	require.NoError(t, err)
+	assert.Empty(t, sizes)
+
+	require.NoError(t, store.SetCIDWithSize(ctx, logDID, "tile/0/000", "bafyTile", 512))
+	sizes, err = store.GetCIDSizes(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"tile/0/000": 512}, sizes)
+}
+
 func TestLogStore_CIDIndex_EmptyForNewLog(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
 	require.NoError(t, err)
@@ -365,6 +438,58 @@ func TestLogStore_Revocations_Idempotent(t *testing.T) {
 	assert.Len(t, revocations, 1)
 }
 
+func TestLogStore_ScopedRevocations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	revokedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, store.AddScopedRevocation(ctx, "account", "did:key:z6MkAccount", revokedAt))
+	require.NoError(t, store.AddScopedRevocation(ctx, "capability", "tlog/append", revokedAt.Add(time.Second)))
+	// Adding the same revocation twice is a no-op
+	require.NoError(t, store.AddScopedRevocation(ctx, "account", "did:key:z6MkAccount", revokedAt))
+
+	revoked, err := store.IsScopedRevoked(ctx, "account", "did:key:z6MkAccount")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// The type is part of the key
+	revoked, err = store.IsScopedRevoked(ctx, "capability", "did:key:z6MkAccount")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = store.HasScopedRevocation(ctx, "capability", []string{"tlog/read", "tlog/append"})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = store.HasScopedRevocation(ctx, "capability", []string{"tlog/read"})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = store.HasScopedRevocation(ctx, "account", nil)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	scoped, err := store.GetScopedRevocations(ctx)
+	require.NoError(t, err)
+	require.Len(t, scoped, 2)
+	assert.Equal(t, "account", scoped[0].Type)
+	assert.Equal(t, "did:key:z6MkAccount", scoped[0].Target)
+	assert.True(t, revokedAt.Equal(scoped[0].RevokedAt))
+	assert.Equal(t, "tlog/append", scoped[1].Target)
+
+	// CID revocations are kept separately
+	revocations, err := store.GetRevocations(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, revocations)
+}
+
 func TestLogStore_IndexPersistence_SetAndGet(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
 	require.NoError(t, err)
@@ -443,3 +568,459 @@ func TestLogStore_IndexPersistence_Update(t *testing.T) {
 	assert.Equal(t, "bafyNewCID", meta.LastUploadedCID)
 	assert.WithinDuration(t, uploadTime2, meta.LastUploadTime, time.Second)
 }
+
+func TestLogStore_HeadHistory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	// Record three heads; only the second gets an index CAR
+	require.NoError(t, store.RecordHead(ctx, logDID, 1, []byte{0x01}, "bafyCheckpoint1", 0))
+	require.NoError(t, store.RecordHead(ctx, logDID, 2, []byte{0x02}, "bafyCheckpoint2", 0))
+	require.NoError(t, store.RecordHead(ctx, logDID, 3, []byte{0x03}, "bafyCheckpoint3", 0))
+	require.NoError(t, store.SetHeadIndexCID(ctx, logDID, "bafyCheckpoint2", "bafyIndex2"))
+
+	history, err := store.GetHeadHistory(ctx, logDID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, uint64(1), history[0].TreeSize)
+	assert.Equal(t, []byte{0x03}, history[2].Root)
+	assert.Empty(t, history[0].IndexCID)
+	assert.Equal(t, "bafyIndex2", history[1].IndexCID)
+	assert.WithinDuration(t, time.Now(), history[1].RecordedAt, time.Minute)
+
+	// Lookup by size
+	head, err := store.GetHeadAtSize(ctx, logDID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyCheckpoint2", head.CheckpointCID)
+	assert.Equal(t, []byte{0x02}, head.Root)
+
+	// Lookup by checkpoint CID and by index CID
+	head, err = store.GetHeadByCID(ctx, logDID, "bafyCheckpoint3")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), head.TreeSize)
+
+	head, err = store.GetHeadByCID(ctx, logDID, "bafyIndex2")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), head.TreeSize)
+
+	// Unknown heads
+	_, err = store.GetHeadAtSize(ctx, logDID, 4)
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+	_, err = store.GetHeadByCID(ctx, logDID, "bafyUnknown")
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+}
+
+func TestLogStore_HeadHistory_RerecordKeepsIndexCID(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	require.NoError(t, store.RecordHead(ctx, logDID, 5, []byte{0x05}, "bafyCheckpoint", 0))
+	require.NoError(t, store.SetHeadIndexCID(ctx, logDID, "bafyCheckpoint", "bafyIndex"))
+	require.NoError(t, store.RecordHead(ctx, logDID, 5, []byte{0x05}, "bafyCheckpoint", 0))
+
+	head, err := store.GetHeadAtSize(ctx, logDID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyIndex", head.IndexCID)
+}
+
+func TestLogStore_RestoreLog(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	restored := sqlite.RestoredLog{
+		Index:          map[string]string{"checkpoint": "bafyCheckpoint", "tile/0/000.p/5": "bafyTile"},
+		TreeSize:       5,
+		Root:           []byte{0x05},
+		CheckpointCID:  "bafyCheckpoint",
+		CheckpointSize: 120,
+		IndexCID:       "bafyIndex",
+	}
+	require.NoError(t, store.RestoreLog(ctx, logDID, restored))
+
+	index, err := store.GetCIDIndex(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, restored.Index, index)
+	headCID, size, err := store.GetHead(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyIndex", headCID)
+	assert.Equal(t, uint64(5), size)
+	head, err := store.GetHeadAtSize(ctx, logDID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyCheckpoint", head.CheckpointCID)
+	assert.Equal(t, "bafyIndex", head.IndexCID)
+
+	// Restoring over an existing log fails without touching it
+	err = store.RestoreLog(ctx, logDID, sqlite.RestoredLog{
+		Index:    map[string]string{"checkpoint": "bafyOther"},
+		TreeSize: 9,
+		Root:     []byte{0x09},
+		IndexCID: "bafyOtherIndex",
+	})
+	require.Error(t, err)
+	index, err = store.GetCIDIndex(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, restored.Index, index)
+	size, root, err := store.GetTreeState(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), size)
+	assert.Equal(t, []byte{0x05}, root)
+}
+
+func TestLogStore_StaleHeads(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	// Four heads; the second has a superseded index CAR and the current one,
+	// which is still the last upload
+	for size := uint64(1); size <= 4; size++ {
+		cp := fmt.Sprintf("bafyCheckpoint%d", size)
+		require.NoError(t, store.RecordHead(ctx, logDID, size, []byte{byte(size)}, cp, 100+size))
+	}
+	require.NoError(t, store.SetCID(ctx, logDID, "checkpoint", "bafyCheckpoint4"))
+	cars := []storage.IndexCAR{
+		{RootCID: "bafyIndex1", ShardCID: "bafyShard1", Size: 1000, CheckpointCID: "bafyCheckpoint1"},
+		{RootCID: "bafyIndex2a", ShardCID: "bafyShard2a", Size: 2000, CheckpointCID: "bafyCheckpoint2"},
+		{RootCID: "bafyIndex2", ShardCID: "bafyShard2", Size: 2001, CheckpointCID: "bafyCheckpoint2"},
+	}
+	for _, car := range cars {
+		require.NoError(t, store.RecordIndexCAR(ctx, logDID, car))
+		require.NoError(t, store.SetHeadIndexCID(ctx, logDID, car.CheckpointCID, car.RootCID))
+	}
+	require.NoError(t, store.SetIndexPersistence(ctx, logDID, time.Now(), 2001, "bafyIndex2"))
+
+	_, err = store.GetStaleHeads(ctx, logDID, 0)
+	assert.Error(t, err)
+
+	stale, err := store.GetStaleHeads(ctx, logDID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, stale.Checkpoints)
+	assert.Empty(t, stale.IndexCARs)
+
+	// Retaining two heads leaves the checkpoints of sizes 1 and 2 and every
+	// index CAR but the current one
+	stale, err = store.GetStaleHeads(ctx, logDID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.HeadBlob{
+		{TreeSize: 1, CID: "bafyCheckpoint1", Size: 101},
+		{TreeSize: 2, CID: "bafyCheckpoint2", Size: 102},
+	}, stale.Checkpoints)
+	assert.ElementsMatch(t, []storage.HeadBlob{
+		{TreeSize: 1, CID: "bafyIndex1", ShardCID: "bafyShard1", Size: 1000},
+		{TreeSize: 2, CID: "bafyIndex2a", ShardCID: "bafyShard2a", Size: 2000},
+	}, stale.IndexCARs)
+
+	require.NoError(t, store.DeleteHeadBlobs(ctx, logDID,
+		[]string{"bafyCheckpoint1", "bafyCheckpoint2"}, []string{"bafyIndex1", "bafyIndex2a"}))
+
+	stale, err = store.GetStaleHeads(ctx, logDID, 2)
+	require.NoError(t, err)
+	assert.Empty(t, stale.Checkpoints)
+	assert.Empty(t, stale.IndexCARs)
+
+	// Collected heads keep their root
+	head, err := store.GetHeadAtSize(ctx, logDID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01}, head.Root)
+	assert.Empty(t, head.CheckpointCID)
+	assert.Empty(t, head.IndexCID)
+	head, err = store.GetHeadAtSize(ctx, logDID, 2)
+	require.NoError(t, err)
+	assert.Empty(t, head.CheckpointCID)
+	assert.Equal(t, "bafyIndex2", head.IndexCID)
+}
+
+func TestLogStore_StaleHeads_KeepsReusedShards(t *testing.T) {
+	store, err := sqlite.OpenLogStore(t.TempDir(), "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	// Each incremental index CAR reuses blocks of the first one, and the
+	// third also of the second one
+	for size := uint64(1); size <= 3; size++ {
+		require.NoError(t, store.RecordHead(ctx, logDID, size, []byte{byte(size)}, fmt.Sprintf("bafyCheckpoint%d", size), 0))
+	}
+	cars := []storage.IndexCAR{
+		{RootCID: "bafyIndex1", ShardCID: "bafyShard1", Size: 1000, CheckpointCID: "bafyCheckpoint1"},
+		{RootCID: "bafyIndex2", ShardCID: "bafyShard2", Size: 100, CheckpointCID: "bafyCheckpoint2",
+			Shards: []string{"bafyShard1"}},
+		{RootCID: "bafyIndex3", ShardCID: "bafyShard3", Size: 100, CheckpointCID: "bafyCheckpoint3",
+			Shards: []string{"bafyShard1", "bafyShard2"}},
+	}
+	for _, car := range cars {
+		require.NoError(t, store.RecordIndexCAR(ctx, logDID, car))
+		require.NoError(t, store.SetHeadIndexCID(ctx, logDID, car.CheckpointCID, car.RootCID))
+	}
+	require.NoError(t, store.SetIndexPersistence(ctx, logDID, time.Now(), 3, "bafyIndex3"))
+
+	stale, err := store.GetStaleHeads(ctx, logDID, 1)
+	require.NoError(t, err)
+	assert.Empty(t, stale.IndexCARs)
+
+	// Once a full CAR replaces them, the older CARs can go
+	require.NoError(t, store.RecordHead(ctx, logDID, 4, []byte{0x04}, "bafyCheckpoint4", 0))
+	full := storage.IndexCAR{RootCID: "bafyIndex4", ShardCID: "bafyShard4", Size: 1100, CheckpointCID: "bafyCheckpoint4"}
+	require.NoError(t, store.RecordIndexCAR(ctx, logDID, full))
+	require.NoError(t, store.SetHeadIndexCID(ctx, logDID, full.CheckpointCID, full.RootCID))
+	require.NoError(t, store.SetIndexPersistence(ctx, logDID, time.Now(), 4, "bafyIndex4"))
+
+	stale, err = store.GetStaleHeads(ctx, logDID, 1)
+	require.NoError(t, err)
+	var roots []string
+	for _, blob := range stale.IndexCARs {
+		roots = append(roots, blob.CID)
+	}
+	assert.Equal(t, []string{"bafyIndex1", "bafyIndex2", "bafyIndex3"}, roots)
+}
+
+func TestLogStore_Attribution(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store, err := sqlite.OpenLogStore(tmpDir, "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	receivedAt := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, store.AddAttributions(ctx, logDID, []sqlite.Attribution{
+		{Index: 0, InvocationCID: "bafyInv0", IssuerDID: "did:key:z6MkAlice", DelegationCID: "bafyDlgA", ReceivedAt: receivedAt},
+		{Index: 1, InvocationCID: "bafyInv1", IssuerDID: "did:key:z6MkBob", DelegationCID: "bafyDlgB", ReceivedAt: receivedAt},
+		{Index: 2, InvocationCID: "bafyInv1", IssuerDID: "did:key:z6MkAlice", DelegationCID: "bafyDlgA", ReceivedAt: receivedAt},
+	}))
+
+	a, err := store.GetAttribution(ctx, logDID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "bafyInv1", a.InvocationCID)
+	assert.Equal(t, "did:key:z6MkBob", a.IssuerDID)
+	assert.Equal(t, "bafyDlgB", a.DelegationCID)
+	assert.True(t, receivedAt.Equal(a.ReceivedAt))
+
+	_, err = store.GetAttribution(ctx, logDID, 3)
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	byAlice, err := store.GetAttributionsByIssuer(ctx, logDID, "did:key:z6MkAlice", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, byAlice, 2)
+	assert.Equal(t, uint64(0), byAlice[0].Index)
+	assert.Equal(t, uint64(2), byAlice[1].Index)
+
+	page, err := store.GetAttributionsByIssuer(ctx, logDID, "did:key:z6MkAlice", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, uint64(2), page[0].Index)
+}
+
+func TestLogStore_SetTreeStateWithAttributions(t *testing.T) {
+	store, err := sqlite.OpenLogStore(t.TempDir(), "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	receivedAt := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, store.SetTreeStateWithAttributions(ctx, logDID, 2, []byte("root"), []sqlite.Attribution{
+		{Index: 0, InvocationCID: "bafyInv0", IssuerDID: "did:key:z6MkAlice", DelegationCID: "bafyDlgA", ReceivedAt: receivedAt},
+		{Index: 1, InvocationCID: "bafyInv1", IssuerDID: "did:key:z6MkBob", DelegationCID: "bafyDlgB", ReceivedAt: receivedAt},
+	}))
+
+	size, root, err := store.GetTreeState(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), size)
+	assert.Equal(t, []byte("root"), root)
+
+	a, err := store.GetAttribution(ctx, logDID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "did:key:z6MkBob", a.IssuerDID)
+
+	// A log without a record rejects both writes together
+	err = store.SetTreeStateWithAttributions(ctx, "did:key:z6MkOther", 1, []byte("root"), []sqlite.Attribution{
+		{Index: 0, InvocationCID: "bafyInv2", IssuerDID: "did:key:z6MkAlice", DelegationCID: "bafyDlgA", ReceivedAt: receivedAt},
+	})
+	require.Error(t, err)
+	size, _, err = store.GetTreeState(ctx, "did:key:z6MkOther")
+	require.NoError(t, err)
+	assert.Zero(t, size)
+}
+
+func TestLogStore_WebhookOutbox(t *testing.T) {
+	store, err := sqlite.OpenLogStore(t.TempDir(), "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.AddWebhook(ctx, logDID, sqlite.Webhook{
+		ID: "wh1", URL: "https://example.com/a", Events: []string{"append", "gc"},
+		Signing: "hmac", Secret: "00ff", CreatedBy: "did:key:z6MkOwner", CreatedAt: now,
+	}))
+	require.NoError(t, store.AddWebhook(ctx, logDID, sqlite.Webhook{
+		ID: "wh2", URL: "https://example.com/b", Events: []string{"revocation"},
+		Signing: "ucan", CreatedBy: "did:key:z6MkOwner", CreatedAt: now,
+	}))
+
+	webhooks, err := store.ListWebhooks(ctx, logDID)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, []string{"append", "gc"}, webhooks[0].Events)
+	assert.Equal(t, now, webhooks[0].CreatedAt)
+
+	// Events are queued for subscribed webhooks only
+	n, err := store.EnqueueWebhookEvent(ctx, logDID, "ev1", "append", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = store.EnqueueWebhookEvent(ctx, logDID, "ev2", "revocation", []byte(`{"r":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	due, err := store.DueWebhookDeliveries(ctx, now.Add(time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "wh1", due[0].Webhook.ID)
+	assert.Equal(t, "00ff", due[0].Webhook.Secret)
+	assert.Equal(t, []byte(`{"a":1}`), due[0].Payload)
+
+	// A retried delivery is not due until its next attempt
+	require.NoError(t, store.RetryWebhookDelivery(ctx, due[0].ID, now.Add(time.Hour), "status 500"))
+	require.NoError(t, store.CompleteWebhookDelivery(ctx, due[1].ID))
+	due, err = store.DueWebhookDeliveries(ctx, now.Add(time.Second), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = store.DueWebhookDeliveries(ctx, now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "status 500", due[0].LastError)
+
+	// Postponing a webhook only moves its deliveries due earlier
+	n, err = store.EnqueueWebhookEvent(ctx, logDID, "ev3", "gc", []byte(`{"g":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	require.NoError(t, store.PostponeWebhookDeliveries(ctx, "wh1", now.Add(30*time.Minute)))
+	due, err = store.DueWebhookDeliveries(ctx, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = store.DueWebhookDeliveries(ctx, now.Add(30*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "ev3", due[0].EventID)
+	require.NoError(t, store.CompleteWebhookDelivery(ctx, due[0].ID))
+
+	// Removing a webhook drops its pending deliveries
+	count, err := store.CountWebhookDeliveries(ctx, "wh1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, store.RemoveWebhook(ctx, logDID, "wh1"))
+	count, err = store.CountWebhookDeliveries(ctx, "")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.ErrorIs(t, store.RemoveWebhook(ctx, logDID, "wh1"), sqlite.ErrNotFound)
+
+	_, err = store.GetWebhookCursor(ctx, logDID)
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+	require.NoError(t, store.SetWebhookCursor(ctx, logDID, 7))
+	require.NoError(t, store.SetWebhookCursor(ctx, logDID, 9))
+	cursor, err := store.GetWebhookCursor(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), cursor)
+}
+
+func TestLogStore_GCSchedule(t *testing.T) {
+	store, err := sqlite.OpenLogStore(t.TempDir(), "did:key:z6MkMain")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkMain"
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	_, err = store.GetGCSchedule(ctx, logDID)
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	expires := now.Add(24 * time.Hour)
+	require.NoError(t, store.SetGCSchedule(ctx, logDID, sqlite.GCSchedule{
+		Delegation: "dlg1", DelegationCID: "bafy1", ExpiresAt: &expires,
+		CreatedBy: "did:key:z6MkOwner", CreatedAt: now,
+	}))
+	sched, err := store.GetGCSchedule(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, "dlg1", sched.Delegation)
+	assert.Equal(t, "bafy1", sched.DelegationCID)
+	require.NotNil(t, sched.ExpiresAt)
+	assert.Equal(t, expires, *sched.ExpiresAt)
+	assert.Equal(t, now, sched.CreatedAt)
+	assert.Nil(t, sched.LastRunAt)
+
+	// Runs are recorded with their outcome
+	require.NoError(t, store.RecordGCScheduleRun(ctx, logDID, now, "remove failed"))
+	sched, err = store.GetGCSchedule(ctx, logDID)
+	require.NoError(t, err)
+	require.NotNil(t, sched.LastRunAt)
+	assert.Equal(t, now, *sched.LastRunAt)
+	assert.Equal(t, "remove failed", sched.LastError)
+
+	// Scheduling again replaces the delegation and resets the run history
+	require.NoError(t, store.SetGCSchedule(ctx, logDID, sqlite.GCSchedule{
+		Delegation: "dlg2", DelegationCID: "bafy2", CreatedBy: "did:key:z6MkOwner", CreatedAt: now,
+	}))
+	sched, err = store.GetGCSchedule(ctx, logDID)
+	require.NoError(t, err)
+	assert.Equal(t, "bafy2", sched.DelegationCID)
+	assert.Nil(t, sched.ExpiresAt)
+	assert.Nil(t, sched.LastRunAt)
+	assert.Empty(t, sched.LastError)
+
+	require.NoError(t, store.DeleteGCSchedule(ctx, logDID))
+	assert.ErrorIs(t, store.DeleteGCSchedule(ctx, logDID), sqlite.ErrNotFound)
+}
@@ -2,15 +2,40 @@ package storacha
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
-	"time"
 
+	"github.com/relves/ucanlog/internal/storage"
 	"github.com/transparency-dev/tessera"
 	"github.com/transparency-dev/tessera/api/layout"
 )
 
+// ErrShutDown is returned by AddBatch once the appender has been shut down.
+var ErrShutDown = errors.New("appender has been shut down")
+
+// NewAppender creates an appender for d with tessera.NewAppender. Batches
+// added with Storage.AddBatch bypass the appender's Add, so its shutdown
+// function also stops a Storage from queueing further batches.
+func NewAppender(ctx context.Context, d tessera.Driver, opts *tessera.AppendOptions) (*tessera.Appender, func(context.Context) error, tessera.LogReader, error) {
+	appender, shutdown, reader, err := tessera.NewAppender(ctx, d, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s, ok := d.(*Storage)
+	if !ok {
+		return appender, shutdown, reader, nil
+	}
+	s.mu.Lock()
+	q := s.queue
+	s.mu.Unlock()
+	return appender, func(ctx context.Context) error {
+		q.stop()
+		return shutdown(ctx)
+	}, reader, nil
+}
+
 func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*tessera.Appender, tessera.LogReader, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -45,11 +70,9 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 	if maxSize == 0 {
 		maxSize = 256
 	}
-	// !NOTE! Timer-based batching (maxAge) is disabled because it's incompatible with
-	// per-request delegations. The timer fires with context.Background() which
-	// lacks the delegation needed for write operations. Size-based batching works
-	// because it flushes with the caller's context (which has the delegation).
-	maxAge := time.Duration(0)
+	// Batches are flushed under a delegation carried by one of their entries,
+	// so timer flushes can write on behalf of concurrent requests.
+	maxAge := opts.BatchMaxAge()
 
 	flushFn := func(ctx context.Context, items []queueItem) error {
 		if len(items) == 0 {
@@ -68,11 +91,16 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 		}
 
 		entries := make([]SequencedEntry, len(items))
+		var attributions []storage.Attribution
 		for i, item := range items {
 			entries[i] = SequencedEntry{
 				BundleData: item.entry.MarshalBundleData(currentSize + uint64(i)),
 				LeafHash:   item.entry.LeafHash(),
 			}
+			if attr, ok := GetAttribution(item.ctx); ok {
+				attr.Index = currentSize + uint64(i)
+				attributions = append(attributions, attr)
+			}
 		}
 
 		newRoot, err := integrateEntries(ctx, currentSize, entries, lrs, s.logger)
@@ -82,7 +110,7 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 
 		newSize := currentSize + uint64(len(entries))
 
-		if err := coord.writeTreeState(ctx, newSize, newRoot); err != nil {
+		if err := coord.writeTreeState(ctx, newSize, newRoot, attributions); err != nil {
 			return fmt.Errorf("failed to write tree state: %w", err)
 		}
 
@@ -95,6 +123,12 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 			if err := lrs.setCheckpoint(ctx, cpRaw); err != nil {
 				return fmt.Errorf("failed to store checkpoint: %w", err)
 			}
+			if err := s.cfg.StateStore.RecordHead(ctx, s.cfg.LogDID, newSize, newRoot, objStore.GetCID("checkpoint"), uint64(len(cpRaw))); err != nil {
+				s.logger.Warn("failed to record head history", "size", newSize, "error", err)
+			}
+			if s.cfg.OnCheckpoint != nil {
+				s.cfg.OnCheckpoint(s.cfg.LogDID, newSize, cpRaw)
+			}
 		}
 
 		for i, item := range items {
@@ -105,6 +139,7 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 	}
 
 	q := newEntryQueue(ctx, maxAge, uint(maxSize), flushFn)
+	s.queue = q
 
 	appender := &storachaAppender{
 		lrs:    lrs,
@@ -118,6 +153,20 @@ func (s *Storage) Appender(ctx context.Context, opts *tessera.AppendOptions) (*t
 	}, reader, nil
 }
 
+// AddBatch queues entries to be integrated together in one batch, so they
+// receive contiguous indices and are either all appended or none are. A batch
+// may hold at most the appender's maximum batch size of entries. Returns
+// ErrShutDown once the appender's shutdown function has been called.
+func (s *Storage) AddBatch(ctx context.Context, entries []*tessera.Entry) ([]tessera.IndexFuture, error) {
+	s.mu.Lock()
+	q := s.queue
+	s.mu.Unlock()
+	if q == nil {
+		return nil, fmt.Errorf("appender has not been created")
+	}
+	return q.AddBatch(ctx, entries)
+}
+
 type storachaAppender struct {
 	lrs    *logResourceStore
 	coord  *coordinator
@@ -30,3 +30,13 @@ func MultihashFromCID(cidStr string) (mh.Multihash, error) {
 	}
 	return c.Hash(), nil
 }
+
+// gatewayPath returns the IPFS gateway path for a CID. dag-pb blocks (the
+// directories of an index CAR) are requested in raw block format, as gateways
+// would otherwise render them as UnixFS directory listings.
+func gatewayPath(cidStr string) string {
+	if c, err := cid.Decode(cidStr); err == nil && c.Type() == cid.DagProtobuf {
+		return "/ipfs/" + cidStr + "?format=raw"
+	}
+	return "/ipfs/" + cidStr
+}
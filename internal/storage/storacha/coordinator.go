@@ -39,9 +39,13 @@ func (c *coordinator) readTreeState(ctx context.Context) (uint64, []byte, error)
 	return c.stateStore.GetTreeState(ctx, c.logDID)
 }
 
-// writeTreeState persists the tree state to StateStore.
-func (c *coordinator) writeTreeState(ctx context.Context, size uint64, root []byte) error {
-	return c.stateStore.SetTreeState(ctx, c.logDID, size, root)
+// writeTreeState persists the tree state to StateStore, together with the
+// attributions of the entries it integrates.
+func (c *coordinator) writeTreeState(ctx context.Context, size uint64, root []byte, attributions []storage.Attribution) error {
+	if len(attributions) == 0 {
+		return c.stateStore.SetTreeState(ctx, c.logDID, size, root)
+	}
+	return c.stateStore.SetTreeStateWithAttributions(ctx, c.logDID, size, root, attributions)
 }
 
 // readNextIndex returns the next available sequence number.
@@ -22,7 +22,7 @@ func TestCoordinator_TreeState(t *testing.T) {
 
 	// Write state
 	newRoot := []byte("merkle root hash here")
-	err = coord.writeTreeState(ctx, 100, newRoot)
+	err = coord.writeTreeState(ctx, 100, newRoot, nil)
 	require.NoError(t, err)
 
 	// Read back
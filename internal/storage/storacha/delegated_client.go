@@ -62,6 +62,14 @@ type DelegatedClientConfig struct {
 	// Default: https://w3s.link
 	GatewayURL string
 
+	// ReceiptsURL is the receipts API endpoint polled for blob/accept receipts.
+	// Default: ReceiptsEndpoint
+	ReceiptsURL string
+
+	// PollInterval is the time to wait between receipt poll requests.
+	// Default: PollInterval
+	PollInterval time.Duration
+
 	// HTTPClient for outgoing requests.
 	// Default: client with 30s timeout
 	HTTPClient *http.Client
@@ -104,6 +112,12 @@ func (c *DelegatedClientConfig) ApplyDefaults() {
 	if c.GatewayURL == "" {
 		c.GatewayURL = "https://ipfs.w3s.link"
 	}
+	if c.ReceiptsURL == "" {
+		c.ReceiptsURL = ReceiptsEndpoint
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = PollInterval
+	}
 	if c.HTTPClient == nil {
 		c.HTTPClient = &http.Client{
 			Timeout: 10 * time.Second, // Reduced from 30s for faster failure on slow gateways
@@ -560,7 +574,7 @@ func extractReceiptFromResponse(body []byte, taskLink ipld.Link) (receipt.AnyRec
 
 // pollAcceptReceipt polls for blob acceptance and verifies the receipt indicates success.
 func (c *DelegatedClient) pollAcceptReceipt(ctx context.Context, taskLink ipld.Link) error {
-	endpoint := fmt.Sprintf("%s/%s", ReceiptsEndpoint, taskLink.String())
+	endpoint := fmt.Sprintf("%s/%s", c.cfg.ReceiptsURL, taskLink.String())
 	c.logger.Debug("polling accept receipt", "endpoint", endpoint)
 
 	for attempt := 0; attempt < PollRetries; attempt++ {
@@ -568,7 +582,7 @@ func (c *DelegatedClient) pollAcceptReceipt(ctx context.Context, taskLink ipld.L
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(PollInterval):
+			case <-time.After(c.cfg.PollInterval):
 			}
 		}
 
@@ -1108,7 +1122,7 @@ func (c *DelegatedClient) FetchBlobViaGateway(ctx context.Context, cidStr string
 		return nil, fmt.Errorf("invalid CID: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/ipfs/%s", c.cfg.GatewayURL, cidStr)
+	url := c.cfg.GatewayURL + gatewayPath(cidStr)
 	startTime := time.Now()
 	c.logger.Debug("FetchBlob starting", "cid", cidStr)
 
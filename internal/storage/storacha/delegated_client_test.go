@@ -2,9 +2,18 @@ package storacha
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
+	"github.com/ipfs/go-cid"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/multiformats/go-multicodec"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/storacha/go-ucanto/core/car"
 	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/storacha/go-ucanto/core/ipld"
+	"github.com/storacha/go-ucanto/core/ipld/block"
 	"github.com/storacha/go-ucanto/principal/ed25519/signer"
 	"github.com/storacha/go-ucanto/ucan"
 	"github.com/stretchr/testify/assert"
@@ -134,3 +143,164 @@ func TestDelegatedClientIntegration(t *testing.T) {
 	ctx := context.Background()
 	_ = ctx // Would use for actual upload operations
 }
+
+// newFakeServiceClient starts a fake Storacha service and returns a client
+// for a new space delegated to the client's signer.
+func newFakeServiceClient(t *testing.T) (*storachatest.Service, *DelegatedClient, string) {
+	t.Helper()
+
+	svc, err := storachatest.NewService()
+	require.NoError(t, err)
+	t.Cleanup(svc.Close)
+
+	serviceSigner, err := signer.Generate()
+	require.NoError(t, err)
+	space, dlg, err := storachatest.SpaceDelegation(serviceSigner.DID())
+	require.NoError(t, err)
+
+	client, err := NewDelegatedClient(DelegatedClientConfig{
+		ServiceSigner: serviceSigner,
+		Delegation:    dlg,
+		SpaceDID:      space.String(),
+		ServiceDID:    svc.DID(),
+		ServiceURL:    svc.URL(),
+		GatewayURL:    svc.GatewayURL(),
+		ReceiptsURL:   svc.ReceiptsURL(),
+		PollInterval:  time.Millisecond,
+		RetryDelay:    time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	return svc, client, space.String()
+}
+
+func TestDelegatedClient_FakeService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("blob round trip", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		data := []byte("tile data")
+
+		cidStr, err := client.UploadBlob(ctx, space, data, client.cfg.Delegation)
+		require.NoError(t, err)
+		expected, hash, err := ComputeCID(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, cidStr)
+
+		stored, ok := svc.Blob(hash)
+		require.True(t, ok)
+		require.Equal(t, data, stored)
+		require.Equal(t, 1, svc.Calls("ucan/conclude"), "http/put receipt should be concluded")
+
+		// Gateway fetch without a delegation
+		got, err := client.FetchBlob(ctx, cidStr)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		// Direct fetch with a delegation
+		got, err = client.FetchBlob(WithDelegation(ctx, client.cfg.Delegation), cidStr)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+		require.Equal(t, 1, svc.Calls(ContentRetrieveAbility))
+
+		require.NoError(t, client.RemoveBlob(ctx, space, hash, client.cfg.Delegation))
+		_, ok = svc.Blob(hash)
+		require.False(t, ok)
+		_, err = client.FetchBlob(ctx, cidStr)
+		require.Error(t, err)
+	})
+
+	t.Run("existing blob is not uploaded again", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		data := []byte("duplicate")
+
+		_, err := client.UploadBlob(ctx, space, data, client.cfg.Delegation)
+		require.NoError(t, err)
+		_, err = client.UploadBlob(ctx, space, data, client.cfg.Delegation)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, svc.Calls(BlobAddAbility))
+		require.Equal(t, 1, svc.Puts())
+	})
+
+	t.Run("upload CAR registers index and upload", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+
+		blockData := []byte("checkpoint")
+		_, hash, err := ComputeCID(blockData)
+		require.NoError(t, err)
+		root := cidlink.Link{Cid: cid.NewCidV1(cid.Raw, hash)}
+		blocks := func(yield func(ipld.Block, error) bool) {
+			yield(block.NewBlock(root, blockData), nil)
+		}
+		carData, err := io.ReadAll(car.Encode([]ipld.Link{root}, blocks))
+		require.NoError(t, err)
+
+		rootCID, err := client.UploadCAR(ctx, space, carData, client.cfg.Delegation)
+		require.NoError(t, err)
+		require.Equal(t, root.Cid.String(), rootCID)
+
+		_, carHash, err := ComputeCID(carData)
+		require.NoError(t, err)
+		shards, ok := svc.Upload(root.Cid)
+		require.True(t, ok)
+		require.Equal(t, []ipld.Link{cidlink.Link{Cid: cid.NewCidV1(uint64(multicodec.Car), carHash)}}, shards)
+		require.Len(t, svc.Indexes(), 1)
+		_, ok = svc.Blob(carHash)
+		require.True(t, ok)
+	})
+
+	t.Run("receipt polling waits for acceptance", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		svc.DelayReceipts(3)
+
+		_, err := client.UploadBlob(ctx, space, []byte("slow accept"), client.cfg.Delegation)
+		require.NoError(t, err)
+	})
+
+	t.Run("receipt polling gives up", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		svc.DelayReceipts(PollRetries)
+
+		_, err := client.UploadBlob(ctx, space, []byte("never accepted"), client.cfg.Delegation)
+		require.ErrorContains(t, err, "accept receipt not found")
+	})
+
+	t.Run("failed PUT fails the upload", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		svc.FailPuts(1)
+
+		data := []byte("put fails")
+		_, err := client.UploadBlob(ctx, space, data, client.cfg.Delegation)
+		require.ErrorContains(t, err, "status 503")
+
+		// The blob was not stored, so a retry uploads it
+		_, err = client.UploadBlob(ctx, space, data, client.cfg.Delegation)
+		require.NoError(t, err)
+		require.Equal(t, 1, svc.Puts())
+	})
+
+	t.Run("gateway fetch retries", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		cidStr, err := client.UploadBlob(ctx, space, []byte("flaky gateway"), client.cfg.Delegation)
+		require.NoError(t, err)
+
+		svc.FailGateway(client.cfg.RetryAttempts - 1)
+		_, err = client.FetchBlobViaGateway(ctx, cidStr)
+		require.NoError(t, err)
+
+		svc.FailGateway(client.cfg.RetryAttempts)
+		_, err = client.FetchBlobViaGateway(ctx, cidStr)
+		require.ErrorContains(t, err, "status 502")
+	})
+
+	t.Run("delegation for another space is rejected", func(t *testing.T) {
+		svc, client, space := newFakeServiceClient(t)
+		_, other, err := storachatest.SpaceDelegation(client.cfg.ServiceSigner.DID())
+		require.NoError(t, err)
+
+		_, err = client.UploadBlob(ctx, space, []byte("unauthorized"), other)
+		require.Error(t, err)
+		require.Zero(t, svc.Calls(BlobAddAbility))
+	})
+}
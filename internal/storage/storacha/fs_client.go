@@ -0,0 +1,174 @@
+package storacha
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/storacha/go-ucanto/core/car"
+	"github.com/storacha/go-ucanto/core/delegation"
+)
+
+// FSClient is a StorachaClient that stores blobs in a local directory instead
+// of a Storacha space, for development, CI and air-gapped deployments.
+//
+// Blobs are content addressed like in Storacha: each is stored in its space's
+// subdirectory under the raw CID of its SHA2-256 multihash, so any CID with
+// the same multihash (raw or CAR codec) fetches it. As with Storacha spaces,
+// removing a blob from one space leaves the copies in other spaces in place,
+// and fetches are not scoped to a space. Delegations are accepted but not
+// checked.
+type FSClient struct {
+	dir string
+}
+
+// NewFSClient creates a filesystem client storing blobs under dir.
+func NewFSClient(dir string) (*FSClient, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &FSClient{dir: dir}, nil
+}
+
+// UploadBlob stores data and returns its raw CID.
+func (c *FSClient) UploadBlob(ctx context.Context, spaceDID string, data []byte, dlg delegation.Delegation) (string, error) {
+	cidStr, hash, err := ComputeCID(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute CID: %w", err)
+	}
+	if err := c.write(spaceDID, hash, data); err != nil {
+		return "", err
+	}
+	return cidStr, nil
+}
+
+// UploadCAR stores the CAR as a blob together with each of its blocks, so the
+// DAG can be traversed block by block, and returns the CAR's root CID.
+func (c *FSClient) UploadCAR(ctx context.Context, spaceDID string, data []byte, dlg delegation.Delegation) (string, error) {
+	roots, blocks, err := car.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode CAR: %w", err)
+	}
+	if len(roots) == 0 {
+		return "", fmt.Errorf("CAR has no roots")
+	}
+	rootLink, ok := roots[0].(cidlink.Link)
+	if !ok {
+		return "", fmt.Errorf("unsupported CAR root link %s", roots[0])
+	}
+
+	for blk, err := range blocks {
+		if err != nil {
+			return "", fmt.Errorf("error reading CAR block: %w", err)
+		}
+		if err := c.write(spaceDID, blk.Link().(cidlink.Link).Cid.Hash(), blk.Bytes()); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := c.UploadBlob(ctx, spaceDID, data, dlg); err != nil {
+		return "", fmt.Errorf("failed to store CAR blob: %w", err)
+	}
+
+	return rootLink.Cid.String(), nil
+}
+
+// FetchBlob retrieves data by CID from whichever space holds it.
+func (c *FSClient) FetchBlob(ctx context.Context, cidStr string) ([]byte, error) {
+	parsed, err := cid.Decode(cidStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CID %s: %w", cidStr, err)
+	}
+	spaces, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blob directory: %w", err)
+	}
+	name := blobName(parsed.Hash())
+	for _, space := range spaces {
+		if !space.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.dir, space.Name(), name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blob %s: %w", cidStr, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("blob not found: %s", cidStr)
+}
+
+// RemoveBlob removes a blob from a space by its multihash. Removing a missing
+// blob succeeds.
+func (c *FSClient) RemoveBlob(ctx context.Context, spaceDID string, digest []byte, dlg delegation.Delegation) error {
+	if _, err := mh.Cast(digest); err != nil {
+		return fmt.Errorf("invalid multihash: %w", err)
+	}
+	dir, err := c.spaceDir(spaceDID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, blobName(digest))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove blob: %w", err)
+	}
+	return nil
+}
+
+// spaceDir returns the directory storing the blobs of a space.
+func (c *FSClient) spaceDir(spaceDID string) (string, error) {
+	if spaceDID == "" || spaceDID == "." || spaceDID == ".." || strings.ContainsAny(spaceDID, `/\`) {
+		return "", fmt.Errorf("invalid space DID %q", spaceDID)
+	}
+	return filepath.Join(c.dir, spaceDID), nil
+}
+
+// blobName returns the file name of the blob with the given multihash.
+func blobName(hash mh.Multihash) string {
+	return cid.NewCidV1(cid.Raw, hash).String()
+}
+
+// write stores a blob atomically, so a crash never leaves a partial blob
+// under a content address. Existing blobs are left untouched.
+func (c *FSClient) write(spaceDID string, hash mh.Multihash, data []byte) error {
+	dir, err := c.spaceDir(spaceDID)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, blobName(hash))
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create space directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+	return nil
+}
+
+// Ensure FSClient implements StorachaClient.
+var _ StorachaClient = (*FSClient)(nil)
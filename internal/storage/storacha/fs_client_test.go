@@ -0,0 +1,108 @@
+package storacha
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/multiformats/go-multicodec"
+	"github.com/storacha/go-ucanto/core/car"
+	"github.com/storacha/go-ucanto/core/ipld"
+	"github.com/storacha/go-ucanto/core/ipld/block"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSClient_Blobs(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	client, err := NewFSClient(dir)
+	require.NoError(t, err)
+
+	data := []byte("tile data")
+	cidStr, err := client.UploadBlob(ctx, "did:key:space", data, nil)
+	require.NoError(t, err)
+
+	expected, hash, err := ComputeCID(data)
+	require.NoError(t, err)
+	require.Equal(t, expected, cidStr)
+
+	// Blobs persist across clients and are addressed by multihash
+	reopened, err := NewFSClient(dir)
+	require.NoError(t, err)
+	got, err := reopened.FetchBlob(ctx, cidStr)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	got, err = reopened.FetchBlob(ctx, cid.NewCidV1(uint64(multicodec.Car), hash).String())
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	require.NoError(t, reopened.RemoveBlob(ctx, "did:key:space", hash, nil))
+	_, err = reopened.FetchBlob(ctx, cidStr)
+	require.Error(t, err)
+
+	// Removing again is a no-op
+	require.NoError(t, reopened.RemoveBlob(ctx, "did:key:space", hash, nil))
+}
+
+func TestFSClient_RemoveBlobIsScopedToSpace(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFSClient(t.TempDir())
+	require.NoError(t, err)
+
+	data := []byte("shared tile")
+	cidStr, err := client.UploadBlob(ctx, "did:key:spaceA", data, nil)
+	require.NoError(t, err)
+	_, err = client.UploadBlob(ctx, "did:key:spaceB", data, nil)
+	require.NoError(t, err)
+	_, hash, err := ComputeCID(data)
+	require.NoError(t, err)
+
+	// Collecting the blob in one space keeps the other space's copy
+	require.NoError(t, client.RemoveBlob(ctx, "did:key:spaceA", hash, nil))
+	got, err := client.FetchBlob(ctx, cidStr)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	require.NoError(t, client.RemoveBlob(ctx, "did:key:spaceB", hash, nil))
+	_, err = client.FetchBlob(ctx, cidStr)
+	require.Error(t, err)
+
+	_, err = client.UploadBlob(ctx, "../escape", data, nil)
+	require.Error(t, err)
+}
+
+func TestFSClient_UploadCAR(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFSClient(t.TempDir())
+	require.NoError(t, err)
+
+	blockData := []byte("checkpoint")
+	_, hash, err := ComputeCID(blockData)
+	require.NoError(t, err)
+	root := cidlink.Link{Cid: cid.NewCidV1(cid.Raw, hash)}
+
+	blocks := func(yield func(ipld.Block, error) bool) {
+		yield(block.NewBlock(root, blockData), nil)
+	}
+	carData, err := io.ReadAll(car.Encode([]ipld.Link{root}, blocks))
+	require.NoError(t, err)
+
+	rootCID, err := client.UploadCAR(ctx, "did:key:space", carData, nil)
+	require.NoError(t, err)
+	require.Equal(t, root.Cid.String(), rootCID)
+
+	got, err := client.FetchBlob(ctx, rootCID)
+	require.NoError(t, err)
+	require.Equal(t, blockData, got)
+
+	// The CAR itself is stored as a blob
+	carCID, _, err := ComputeCID(carData)
+	require.NoError(t, err)
+	got, err = client.FetchBlob(ctx, carCID)
+	require.NoError(t, err)
+	require.Equal(t, carData, got)
+}
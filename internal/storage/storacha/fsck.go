@@ -0,0 +1,294 @@
+package storacha
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api"
+	"github.com/transparency-dev/tessera/api/layout"
+)
+
+// Kinds of problems found by Fsck.
+const (
+	FsckMissing      = "missing"       // Path is not in the CID index
+	FsckFetchFailed  = "fetch_failed"  // Blob could not be retrieved
+	FsckCIDMismatch  = "cid_mismatch"  // Retrieved content does not hash to its CID
+	FsckMalformed    = "malformed"     // Blob does not parse as a tile, bundle or checkpoint
+	FsckHashMismatch = "hash_mismatch" // Tile hashes differ from those derived from the bundles
+	FsckRootMismatch = "root_mismatch" // Recomputed root differs from tree state or checkpoint
+	FsckCheckpoint   = "checkpoint"    // Checkpoint is invalid or ahead of the tree state
+)
+
+// FsckOptions configures an integrity check.
+type FsckOptions struct {
+	// Repair re-derives missing or corrupt hash tiles from the entry bundles
+	// and uploads them. Repairs are only made when the root recomputed from
+	// the bundles matches the tree state. The context must carry a
+	// delegation (see WithDelegation).
+	Repair bool
+
+	// OpenCheckpoint verifies a raw checkpoint note and parses it. If nil,
+	// the checkpoint body is parsed without checking signatures.
+	OpenCheckpoint func(raw []byte) (*log.Checkpoint, error)
+}
+
+// FsckProblem is an inconsistency found by Fsck.
+type FsckProblem struct {
+	Path   string `json:"path,omitempty"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// FsckReport is the result of an integrity check of a log.
+type FsckReport struct {
+	LogDID         string        `json:"log"`
+	TreeSize       uint64        `json:"tree_size"`                 // Size in tree_state
+	Root           []byte        `json:"root"`                      // Root in tree_state
+	CheckpointSize uint64        `json:"checkpoint_size"`           // Size of the latest checkpoint
+	CheckpointRoot []byte        `json:"checkpoint_root,omitempty"` // Root of the latest checkpoint
+	ComputedRoot   []byte        `json:"computed_root,omitempty"`   // Root recomputed from the entry bundles
+	BundlesChecked int           `json:"bundles_checked"`
+	TilesChecked   int           `json:"tiles_checked"`
+	Problems       []FsckProblem `json:"problems"`
+	Repaired       []string      `json:"repaired,omitempty"` // Hash tile paths re-uploaded by repair
+}
+
+// OK reports whether the check found no problems.
+func (r *FsckReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *FsckReport) addProblem(path, kind, format string, args ...any) {
+	r.Problems = append(r.Problems, FsckProblem{Path: path, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Fsck checks that the log's CID index describes a consistent tree. Every
+// entry bundle and hash tile of the tree in tree_state is fetched from
+// storage and checked against its CID. Leaf hashes and the Merkle root are
+// recomputed from the bundles and compared with the hash tiles, the root in
+// tree_state and the latest checkpoint.
+//
+// Problems are collected in the report; an error is only returned when the
+// check itself cannot run. The log should not be appended to meanwhile.
+func (s *Storage) Fsck(ctx context.Context, opts FsckOptions) (*FsckReport, error) {
+	treeSize, root, err := s.cfg.StateStore.GetTreeState(ctx, s.cfg.LogDID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree state: %w", err)
+	}
+	report := &FsckReport{LogDID: s.cfg.LogDID, TreeSize: treeSize, Root: root, Problems: []FsckProblem{}}
+
+	var cp *log.Checkpoint
+	if raw, ok := s.fsckFetch(ctx, report, layout.CheckpointPath); ok {
+		cp, err = openCheckpoint(raw, opts.OpenCheckpoint)
+		if err != nil {
+			report.addProblem(layout.CheckpointPath, FsckCheckpoint, "%v", err)
+			cp = nil
+		} else {
+			report.CheckpointSize, report.CheckpointRoot = cp.Size, cp.Hash
+			if cp.Size > treeSize {
+				report.addProblem(layout.CheckpointPath, FsckCheckpoint, "checkpoint size %d is ahead of tree size %d", cp.Size, treeSize)
+				cp = nil
+			}
+		}
+	}
+
+	// Recompute the tree from the bundles, keeping the bottom row of each
+	// tile level above 0 to check the upper hash tiles against.
+	rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	tree := rf.NewEmptyRange(0)
+	var rows [][][]byte
+	visit := func(id compact.NodeID, hash []byte) {
+		if id.Level == 0 || id.Level%layout.TileHeight != 0 {
+			return
+		}
+		level := int(id.Level / layout.TileHeight)
+		for len(rows) <= level {
+			rows = append(rows, nil)
+		}
+		if id.Index == uint64(len(rows[level])) {
+			rows[level] = append(rows[level], hash)
+		}
+	}
+	complete := true // All bundles were read, so derived hashes are trustworthy
+	var cpRoot []byte
+	if cp != nil && cp.Size == 0 {
+		cpRoot = rfc6962.DefaultHasher.EmptyRoot()
+	}
+	repairs := make(map[string][][]byte)
+
+	bundles := (treeSize + layout.EntryBundleWidth - 1) / layout.EntryBundleWidth
+	for i := uint64(0); i < bundles; i++ {
+		p := layout.PartialTileSize(0, i, treeSize)
+		want := int(p)
+		if p == 0 {
+			want = layout.EntryBundleWidth
+		}
+
+		var leaves [][]byte
+		path := layout.EntriesPath(i, p)
+		report.BundlesChecked++
+		if raw, ok := s.fsckFetch(ctx, report, path); ok {
+			var bundle api.EntryBundle
+			if err := bundle.UnmarshalText(raw); err != nil {
+				report.addProblem(path, FsckMalformed, "%v", err)
+			} else if len(bundle.Entries) < want {
+				report.addProblem(path, FsckMalformed, "bundle has %d entries, want %d", len(bundle.Entries), want)
+			} else {
+				for _, e := range bundle.Entries[:want] {
+					leaves = append(leaves, rfc6962.DefaultHasher.HashLeaf(e))
+				}
+			}
+		}
+		if leaves == nil {
+			complete = false
+		}
+		if complete {
+			for _, leaf := range leaves {
+				if err := tree.Append(leaf, visit); err != nil {
+					return nil, fmt.Errorf("failed to append leaf: %w", err)
+				}
+				if cp != nil && tree.End() == cp.Size {
+					if cpRoot, err = tree.GetRootHash(nil); err != nil {
+						return nil, fmt.Errorf("failed to compute root: %w", err)
+					}
+				}
+			}
+		}
+
+		report.TilesChecked++
+		s.fsckTile(ctx, report, layout.TilePath(0, i, p), leaves, repairs)
+	}
+
+	for level := uint64(1); treeSize>>(level*layout.TileHeight) > 0; level++ {
+		width := treeSize >> (level * layout.TileHeight)
+		var row [][]byte
+		if complete && int(level) < len(rows) {
+			row = rows[level]
+		}
+		for i := uint64(0); i*layout.TileWidth < width; i++ {
+			p := layout.PartialTileSize(level, i, treeSize)
+			var nodes [][]byte
+			if complete {
+				end := min((i+1)*layout.TileWidth, width)
+				if uint64(len(row)) < end {
+					return nil, fmt.Errorf("recomputed %d nodes at tile level %d, want %d", len(row), level, end)
+				}
+				nodes = row[i*layout.TileWidth : end]
+			}
+			report.TilesChecked++
+			s.fsckTile(ctx, report, layout.TilePath(level, i, p), nodes, repairs)
+		}
+	}
+
+	if !complete {
+		return report, nil
+	}
+	computed, err := tree.GetRootHash(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute root: %w", err)
+	}
+	report.ComputedRoot = computed
+	if !bytes.Equal(computed, root) {
+		report.addProblem("", FsckRootMismatch, "root recomputed from bundles %x does not match tree state root %x", computed, root)
+	}
+	if cp != nil && !bytes.Equal(cpRoot, cp.Hash) {
+		report.addProblem(layout.CheckpointPath, FsckRootMismatch, "root recomputed at size %d %x does not match checkpoint root %x", cp.Size, cpRoot, cp.Hash)
+	}
+
+	if opts.Repair && len(repairs) > 0 && bytes.Equal(computed, root) {
+		for path, nodes := range repairs {
+			data, err := api.HashTile{Nodes: nodes}.MarshalText()
+			if err != nil {
+				return report, fmt.Errorf("failed to marshal tile %s: %w", path, err)
+			}
+			if err := s.objStore.setObject(ctx, path, data); err != nil {
+				return report, fmt.Errorf("failed to repair tile %s: %w", path, err)
+			}
+			report.Repaired = append(report.Repaired, path)
+		}
+	}
+	return report, nil
+}
+
+// fsckFetch fetches the blob at path and checks it against its CID, recording
+// any problem in report.
+func (s *Storage) fsckFetch(ctx context.Context, report *FsckReport, path string) ([]byte, bool) {
+	if s.objStore.getCID(path) == "" {
+		report.addProblem(path, FsckMissing, "path not in CID index")
+		return nil, false
+	}
+	cidStr, data, err := s.objStore.fetchObject(ctx, path)
+	if err != nil {
+		report.addProblem(path, FsckFetchFailed, "%v", err)
+		return nil, false
+	}
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		report.addProblem(path, FsckCIDMismatch, "invalid CID %s: %v", cidStr, err)
+		return nil, false
+	}
+	sum, err := c.Prefix().Sum(data)
+	if err != nil || !sum.Equals(c) {
+		report.addProblem(path, FsckCIDMismatch, "content of %s does not match its CID", cidStr)
+		return nil, false
+	}
+	return data, true
+}
+
+// fsckTile checks the hash tile at path against nodes derived from the entry
+// bundles, or only against its CID when nodes is nil. Tiles that are missing
+// or differ are queued in repairs.
+func (s *Storage) fsckTile(ctx context.Context, report *FsckReport, path string, nodes [][]byte, repairs map[string][][]byte) {
+	raw, ok := s.fsckFetch(ctx, report, path)
+	if !ok {
+		if nodes != nil {
+			repairs[path] = nodes
+		}
+		return
+	}
+	var tile api.HashTile
+	if err := tile.UnmarshalText(raw); err != nil {
+		report.addProblem(path, FsckMalformed, "%v", err)
+		if nodes != nil {
+			repairs[path] = nodes
+		}
+		return
+	}
+	if nodes == nil {
+		return
+	}
+	if len(tile.Nodes) != len(nodes) {
+		report.addProblem(path, FsckHashMismatch, "tile has %d hashes, want %d", len(tile.Nodes), len(nodes))
+		repairs[path] = nodes
+		return
+	}
+	for i := range nodes {
+		if !bytes.Equal(tile.Nodes[i], nodes[i]) {
+			report.addProblem(path, FsckHashMismatch, "hash %d differs from the one derived from the entry bundles", i)
+			repairs[path] = nodes
+			return
+		}
+	}
+}
+
+// openCheckpoint parses a checkpoint with open, or parses its body without
+// verifying signatures when open is nil.
+func openCheckpoint(raw []byte, open func([]byte) (*log.Checkpoint, error)) (*log.Checkpoint, error) {
+	if open != nil {
+		return open(raw)
+	}
+	body, _, ok := bytes.Cut(raw, []byte("\n\n"))
+	if !ok {
+		return nil, fmt.Errorf("malformed checkpoint note")
+	}
+	cp := &log.Checkpoint{}
+	if _, err := cp.Unmarshal(append(body, '\n')); err != nil {
+		return nil, fmt.Errorf("malformed checkpoint: %w", err)
+	}
+	return cp, nil
+}
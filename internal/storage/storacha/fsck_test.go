@@ -0,0 +1,138 @@
+package storacha
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/tessera"
+	"github.com/transparency-dev/tessera/api/layout"
+)
+
+// tamperingClient returns forged content for selected CIDs.
+type tamperingClient struct {
+	StorachaClient
+	forged map[string][]byte
+}
+
+func (c *tamperingClient) FetchBlob(ctx context.Context, cid string) ([]byte, error) {
+	if data, ok := c.forged[cid]; ok {
+		return data, nil
+	}
+	return c.StorachaClient.FetchBlob(ctx, cid)
+}
+
+// newFsckTestStorage returns a storage holding a log of size entries with a
+// published checkpoint.
+func newFsckTestStorage(t *testing.T, ctx context.Context, size int) (*Storage, *tamperingClient) {
+	t.Helper()
+	fsClient, err := NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	client := &tamperingClient{StorachaClient: fsClient, forged: make(map[string][]byte)}
+
+	driver, err := New(ctx, Config{
+		SpaceDID:   "did:key:z6MkwDuRThQcyWjqNsK54yKAmzfsiH6BTkASyiucThMtHt1y",
+		StateStore: newMockStateStore(),
+		LogDID:     "did:key:test",
+		Client:     client,
+	})
+	require.NoError(t, err)
+	storage := driver.(*Storage)
+
+	opts := tessera.NewAppendOptions().
+		WithCheckpointSigner(&dummySigner{}).
+		WithCheckpointInterval(100*time.Millisecond).
+		WithBatching(uint(size), 0)
+	appender, reader, err := storage.Appender(ctx, opts)
+	require.NoError(t, err)
+
+	futures := make([]tessera.IndexFuture, size)
+	for i := range futures {
+		futures[i] = appender.Add(ctx, tessera.NewEntry([]byte(fmt.Sprintf("entry %d", i))))
+	}
+	for _, f := range futures {
+		_, err := f()
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		raw, err := reader.ReadCheckpoint(ctx)
+		if err != nil {
+			return false
+		}
+		cp, err := openCheckpoint(raw, nil)
+		return err == nil && cp.Size == uint64(size)
+	}, 5*time.Second, 50*time.Millisecond)
+	return storage, client
+}
+
+func TestStorage_Fsck(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+	storage, _ := newFsckTestStorage(t, ctx, 300)
+
+	report, err := storage.Fsck(ctx, FsckOptions{})
+	require.NoError(t, err)
+	require.True(t, report.OK(), "%+v", report.Problems)
+	require.Equal(t, uint64(300), report.TreeSize)
+	require.Equal(t, uint64(300), report.CheckpointSize)
+	require.Equal(t, report.Root, report.ComputedRoot)
+	require.Equal(t, 2, report.BundlesChecked)
+	require.Equal(t, 3, report.TilesChecked) // tile/0/000, tile/0/001.p/44, tile/1/000.p/1
+}
+
+func TestStorage_FsckDetectsAndRepairsTiles(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+	storage, client := newFsckTestStorage(t, ctx, 300)
+
+	// Lose one hash tile and serve forged content for another
+	missing := layout.TilePath(1, 0, 1)
+	storage.index.DeletePrefix(missing)
+	forged := layout.TilePath(0, 0, 0)
+	client.forged[storage.objStore.getCID(forged)] = make([]byte, 32*256)
+
+	report, err := storage.Fsck(ctx, FsckOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []FsckProblem{
+		{Path: forged, Kind: FsckCIDMismatch, Detail: fmt.Sprintf("content of %s does not match its CID", storage.objStore.getCID(forged))},
+		{Path: missing, Kind: FsckMissing, Detail: "path not in CID index"},
+	}, report.Problems)
+	require.Empty(t, report.Repaired)
+
+	report, err = storage.Fsck(ctx, FsckOptions{Repair: true})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{forged, missing}, report.Repaired)
+
+	// The re-derived tiles are stored under new CIDs
+	delete(client.forged, storage.objStore.getCID(forged))
+	report, err = storage.Fsck(ctx, FsckOptions{})
+	require.NoError(t, err)
+	require.True(t, report.OK(), "%+v", report.Problems)
+}
+
+func TestStorage_FsckDetectsForeignBundle(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+	storage, client := newFsckTestStorage(t, ctx, 10)
+
+	// Point the bundle at a well-formed blob holding other entries
+	bundle := layout.EntriesPath(0, 10)
+	var data []byte
+	for i := 0; i < 10; i++ {
+		data = append(data, marshalBundleEntry([]byte(fmt.Sprintf("other %d", i)))...)
+	}
+	cid, err := client.UploadBlob(ctx, storage.cfg.SpaceDID, data, nil)
+	require.NoError(t, err)
+	require.NoError(t, storage.index.Set(bundle, cid))
+
+	report, err := storage.Fsck(ctx, FsckOptions{Repair: true})
+	require.NoError(t, err)
+	kinds := make(map[string]int)
+	for _, p := range report.Problems {
+		kinds[p.Kind]++
+	}
+	require.Equal(t, map[string]int{FsckHashMismatch: 1, FsckRootMismatch: 2}, kinds)
+	// Tiles are not rewritten from bundles that contradict the tree state
+	require.Empty(t, report.Repaired)
+}
@@ -29,7 +29,7 @@ func NewGatewayClient(gatewayURL string) *GatewayClient {
 
 // FetchBlob retrieves data by CID via the IPFS gateway.
 func (c *GatewayClient) FetchBlob(ctx context.Context, cidStr string) ([]byte, error) {
-	url := fmt.Sprintf("%s/ipfs/%s", c.gatewayURL, cidStr)
+	url := c.gatewayURL + gatewayPath(cidStr)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -16,6 +16,13 @@ type Config struct {
 	// Default: 100 (same as GCP)
 	MaxBundles uint
 
+	// HeadRetention, when positive, is the number of most recent heads whose
+	// checkpoint and index CAR are kept; older checkpoints and superseded
+	// index CARs are removed. Every index root links to the previous one, so
+	// this truncates the history walked by tlog.VerifyIndexHistory.
+	// Default: 0, which keeps every head
+	HeadRetention int
+
 	// Logger for structured logging.
 	// Default: slog.Default()
 	Logger *slog.Logger
@@ -113,9 +113,9 @@ func TestGarbageCollect_CleansUpIndexPaths(t *testing.T) {
 	ctx := context.Background()
 	dlg := storachatest.MockDelegation()
 
-	newProgress, _, err := mgr.garbageCollect(ctx, 0, 256, dlg)
+	result, err := mgr.garbageCollect(ctx, 0, 256, dlg, false)
 	require.NoError(t, err)
-	require.Equal(t, uint64(256), newProgress)
+	require.Equal(t, uint64(256), result.NewFromSize)
 
 	// Verify: blobs were removed (3 partials)
 	require.Len(t, remover.removed, 3, "should have removed 3 partial blobs")
@@ -182,9 +182,9 @@ func TestGarbageCollect_MultipleBundles_IndexCleanup(t *testing.T) {
 	ctx := context.Background()
 	dlg := storachatest.MockDelegation()
 
-	newProgress, _, err := mgr.garbageCollect(ctx, 0, 768, dlg)
+	result, err := mgr.garbageCollect(ctx, 0, 768, dlg, false)
 	require.NoError(t, err)
-	require.Equal(t, uint64(768), newProgress)
+	require.Equal(t, uint64(768), result.NewFromSize)
 
 	// Verify: 6 partial blobs removed (2 per bundle × 3 bundles)
 	require.Len(t, remover.removed, 6)
@@ -192,3 +192,56 @@ func TestGarbageCollect_MultipleBundles_IndexCleanup(t *testing.T) {
 	// Verify: only 6 complete paths remain (2 per bundle × 3 bundles)
 	require.Equal(t, 6, len(pathStore.paths))
 }
+
+func TestRunGCSync_DryRunAndBytesFreed(t *testing.T) {
+	entryPartial := layout.EntriesPath(0, 0) + ".p/128"
+	tilePartial := layout.TilePath(0, 0, 0) + ".p/128"
+	unsizedPartial := layout.EntriesPath(0, 0) + ".p/255"
+	newPathStore := func() *mockPathStore {
+		return &mockPathStore{
+			paths: map[string]string{
+				layout.EntriesPath(0, 0): "bafkreichgieyp6netvnqaem3syhsi6uvm5z7k5kdtavyx7fw3jn3hl6z54",
+				entryPartial:             "bafkreif3gzzg23xfjtgvw45ggqvkpoq7fof3b6ag5f74y4afpnjcxfutre",
+				unsizedPartial:           "bafkreifl4sayvhqhqjgst32ebsqjuqbzdmnyky2k7igwgneiav7ni3r6ei",
+				tilePartial:              "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+			},
+			sizes: map[string]uint64{
+				layout.EntriesPath(0, 0): 4096,
+				entryPartial:             2048,
+				tilePartial:              4096,
+			},
+		}
+	}
+	ctx := context.Background()
+	cfg := Config{MaxBundles: 100}
+
+	// A dry run lists the partials without a delegation and keeps them
+	remover := &mockRemover{}
+	pathStore := newPathStore()
+	mgr := NewManager(cfg, func() BlobRemover { return remover }, pathStore, &mockTreeSizeProvider{size: 256}, "did:key:test")
+
+	plan, err := mgr.RunGCSync(ctx, 0, 256, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(256), plan.NewFromSize)
+	require.Equal(t, 1, plan.BundlesProcessed)
+	require.Zero(t, plan.BlobsRemoved)
+	require.Equal(t, uint64(6144), plan.BytesFreed)
+	require.Equal(t, 1, plan.UnknownSizes)
+	require.ElementsMatch(t, []Blob{
+		{Kind: KindPartial, Path: entryPartial, CID: pathStore.paths[entryPartial], Size: 2048, SizeKnown: true},
+		{Kind: KindPartial, Path: unsizedPartial, CID: pathStore.paths[unsizedPartial]},
+		{Kind: KindPartial, Path: tilePartial, CID: pathStore.paths[tilePartial], Size: 4096, SizeKnown: true},
+	}, plan.Blobs)
+	require.Empty(t, remover.removed)
+	require.Len(t, pathStore.paths, 4)
+
+	// The real run removes what the dry run listed
+	result, err := mgr.RunGCSync(ctx, 0, 256, storachatest.MockDelegation(), false)
+	require.NoError(t, err)
+	require.Equal(t, plan.Blobs, result.Blobs)
+	require.Equal(t, 3, result.BlobsRemoved)
+	require.Equal(t, uint64(6144), result.BytesFreed)
+	require.Equal(t, 1, result.UnknownSizes)
+	require.Len(t, remover.removed, 3)
+	require.Len(t, pathStore.paths, 1)
+}
@@ -23,11 +23,43 @@ type BlobRemover interface {
 type PathStore interface {
 	// GetCID returns the CID for a path, or empty string if not found.
 	GetCID(path string) string
+	// GetSize returns the size of the blob stored for a path, if known.
+	GetSize(path string) (uint64, bool)
 	// DeletePrefix removes all path mappings with the given prefix.
 	// Returns the number of deleted entries.
 	DeletePrefix(prefix string) int
 }
 
+// Kinds of blobs removed by garbage collection
+const (
+	KindPartial    = "partial"    // Partial entry bundle or tile
+	KindCheckpoint = "checkpoint" // Checkpoint of a past head
+	KindIndexCAR   = "index"      // Index CAR of a past head
+)
+
+// Blob is an obsolete blob found by garbage collection.
+type Blob struct {
+	Kind      string
+	Path      string // Tessera path of a partial, or the root CID of an index CAR
+	CID       string
+	Size      uint64 // Size in bytes, zero if unknown
+	SizeKnown bool   // False for blobs uploaded before sizes were tracked
+	TreeSize  uint64 // Tree size of the head a checkpoint or index CAR belongs to, zero for partials
+}
+
+// Result describes a garbage collection run.
+type Result struct {
+	NewFromSize      uint64 // GC position after the run
+	BundlesProcessed int    // Completed bundles whose partials were collected
+	BlobsRemoved     int    // Blobs removed (zero in a dry run)
+	BytesFreed       uint64 // Total size of the removed blobs, or of Blobs in a dry run
+	UnknownSizes     int    // Blobs whose size is unknown, not counted in BytesFreed
+
+	// Blobs lists the obsolete blobs found. In a real run, blobs that failed
+	// to be removed are listed but not counted.
+	Blobs []Blob
+}
+
 // TreeSizeProvider provides the current tree size.
 type TreeSizeProvider interface {
 	// GetTreeSize returns the current tree size.
@@ -70,13 +102,15 @@ func (m *Manager) SetStateStore(store storage.StateStore, logDID string) {
 	m.logDID = logDID
 }
 
-// RunGCSync runs garbage collection synchronously and returns the new position.
-// This is used by the explicit GC API endpoint.
-func (m *Manager) RunGCSync(ctx context.Context, fromSize, treeSize uint64, dlg delegation.Delegation) (uint64, int, error) {
+// RunGCSync runs garbage collection synchronously from fromSize.
+// This is used by the explicit GC API endpoint. With dryRun set, nothing is
+// removed and dlg may be nil: the result lists the blobs a real run would
+// remove and their total size.
+func (m *Manager) RunGCSync(ctx context.Context, fromSize, treeSize uint64, dlg delegation.Delegation, dryRun bool) (*Result, error) {
 	m.mu.Lock()
 	if m.gcInProgress {
 		m.mu.Unlock()
-		return fromSize, 0, fmt.Errorf("garbage collection already in progress")
+		return nil, fmt.Errorf("garbage collection already in progress")
 	}
 	m.gcInProgress = true
 	m.mu.Unlock()
@@ -87,24 +121,88 @@ func (m *Manager) RunGCSync(ctx context.Context, fromSize, treeSize uint64, dlg
 		m.mu.Unlock()
 	}()
 
-	if fromSize >= treeSize {
-		return fromSize, 0, nil // Nothing to GC
+	result := &Result{NewFromSize: fromSize}
+	if fromSize < treeSize {
+		var err error
+		result, err = m.garbageCollect(ctx, fromSize, treeSize, dlg, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("garbage collection failed: %w", err)
+		}
+	}
+
+	if err := m.collectHeads(ctx, dlg, dryRun, result); err != nil {
+		return nil, fmt.Errorf("garbage collection of past heads failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// collectHeads removes the checkpoints and index CARs of heads older than the
+// HeadRetention most recent ones, and forgets them in the head history. It
+// does nothing unless head retention is enabled.
+func (m *Manager) collectHeads(ctx context.Context, dlg delegation.Delegation, dryRun bool, result *Result) error {
+	m.mu.Lock()
+	store, logDID := m.stateStore, m.logDID
+	m.mu.Unlock()
+	if store == nil || m.cfg.HeadRetention <= 0 {
+		return nil
 	}
 
-	// Run GC
-	newFromSize, blobsRemoved, err := m.garbageCollect(ctx, fromSize, treeSize, dlg)
+	stale, err := store.GetStaleHeads(ctx, logDID, m.cfg.HeadRetention)
 	if err != nil {
-		return fromSize, 0, fmt.Errorf("garbage collection failed: %w", err)
+		return fmt.Errorf("failed to list past heads: %w", err)
+	}
+
+	var checkpoints, indexRoots []string
+	for _, head := range stale.Checkpoints {
+		blob := Blob{
+			Kind:      KindCheckpoint,
+			Path:      layout.CheckpointPath,
+			CID:       head.CID,
+			Size:      head.Size,
+			SizeKnown: head.Size > 0,
+			TreeSize:  head.TreeSize,
+		}
+		removed, err := m.removeBlob(ctx, blob, dlg, dryRun, result)
+		if err != nil {
+			return err
+		}
+		if removed {
+			checkpoints = append(checkpoints, head.CID)
+		}
+	}
+	for _, car := range stale.IndexCARs {
+		blob := Blob{
+			Kind:      KindIndexCAR,
+			Path:      car.CID,
+			CID:       car.ShardCID,
+			Size:      car.Size,
+			SizeKnown: true,
+			TreeSize:  car.TreeSize,
+		}
+		removed, err := m.removeBlob(ctx, blob, dlg, dryRun, result)
+		if err != nil {
+			return err
+		}
+		if removed {
+			indexRoots = append(indexRoots, car.CID)
+		}
 	}
 
-	return newFromSize, blobsRemoved, nil
+	if dryRun || (len(checkpoints) == 0 && len(indexRoots) == 0) {
+		return nil
+	}
+	if err := store.DeleteHeadBlobs(ctx, logDID, checkpoints, indexRoots); err != nil {
+		return fmt.Errorf("failed to forget removed head blobs: %w", err)
+	}
+	m.logger.Debug("collected past heads", "checkpoints", len(checkpoints), "indexCARs", len(indexRoots))
+	return nil
 }
 
-// garbageCollect removes obsolete partial bundles.
-// Returns the new fromSize (progress).
-func (m *Manager) garbageCollect(ctx context.Context, fromSize, treeSize uint64, dlg delegation.Delegation) (uint64, int, error) {
-	bundlesProcessed := uint(0)
-	blobsRemoved := 0
+// garbageCollect removes obsolete partial bundles, or only lists them when
+// dryRun is set. The result's NewFromSize is the new progress.
+func (m *Manager) garbageCollect(ctx context.Context, fromSize, treeSize uint64, dlg delegation.Delegation, dryRun bool) (*Result, error) {
+	result := &Result{NewFromSize: fromSize}
 
 	// Iterate over bundles using tessera's layout.Range
 	for ri := range layout.Range(fromSize, treeSize-fromSize, treeSize) {
@@ -114,50 +212,44 @@ func (m *Manager) garbageCollect(ctx context.Context, fromSize, treeSize uint64,
 		}
 
 		// Stop if we've reached our limit
-		if bundlesProcessed >= m.cfg.MaxBundles {
+		if uint(result.BundlesProcessed) >= m.cfg.MaxBundles {
 			break
 		}
 
 		// Delete partial versions of the entry bundle
 		entriesPrefix := layout.EntriesPath(ri.Index, 0) + ".p/"
-		removed, err := m.deleteWithPrefix(ctx, entriesPrefix, dlg)
-		if err != nil {
-			return fromSize, blobsRemoved, fmt.Errorf("failed to delete entries partials: %w", err)
+		if err := m.deleteWithPrefix(ctx, entriesPrefix, dlg, dryRun, result); err != nil {
+			return nil, fmt.Errorf("failed to delete entries partials: %w", err)
 		}
-		blobsRemoved += removed
 
 		// Delete partial versions of the tile at level 0
 		tilePrefix := layout.TilePath(0, ri.Index, 0) + ".p/"
-		removed, err = m.deleteWithPrefix(ctx, tilePrefix, dlg)
-		if err != nil {
-			return fromSize, blobsRemoved, fmt.Errorf("failed to delete tile partials: %w", err)
+		if err := m.deleteWithPrefix(ctx, tilePrefix, dlg, dryRun, result); err != nil {
+			return nil, fmt.Errorf("failed to delete tile partials: %w", err)
 		}
-		blobsRemoved += removed
 
-		fromSize += uint64(ri.N)
-		bundlesProcessed++
+		result.NewFromSize += uint64(ri.N)
+		result.BundlesProcessed++
 
 		// Walk up parent tiles when at right edge of subtree
 		pL, pIdx := uint64(0), ri.Index
 		for isLastLeafInParent(pIdx) {
 			pL, pIdx = pL+1, pIdx>>layout.TileHeight
 			parentPrefix := layout.TilePath(pL, pIdx, 0) + ".p/"
-			removed, err := m.deleteWithPrefix(ctx, parentPrefix, dlg)
-			blobsRemoved += removed
-			if err != nil {
+			if err := m.deleteWithPrefix(ctx, parentPrefix, dlg, dryRun, result); err != nil {
 				m.logger.Warn("failed to delete parent tile partials", "level", pL, "error", err)
 				// Continue - parent tile cleanup is best-effort
 			}
 		}
 	}
 
-	return fromSize, blobsRemoved, nil
+	return result, nil
 }
 
-// deleteWithPrefix removes all blobs whose paths start with the given prefix.
-// This is a logical delete - we look up CIDs by path and remove those blobs.
-func (m *Manager) deleteWithPrefix(ctx context.Context, prefix string, dlg delegation.Delegation) (int, error) {
-	removed := 0
+// deleteWithPrefix removes all blobs whose paths start with the given prefix
+// and adds them to result. This is a logical delete - we look up CIDs by path
+// and remove those blobs. In a dry run, blobs are only added to result.
+func (m *Manager) deleteWithPrefix(ctx context.Context, prefix string, dlg delegation.Delegation, dryRun bool, result *Result) error {
 	// For each partial size 1-255, check if the path exists and delete
 	for p := 1; p < 256; p++ {
 		path := fmt.Sprintf("%s%d", prefix, p)
@@ -166,29 +258,15 @@ func (m *Manager) deleteWithPrefix(ctx context.Context, prefix string, dlg deleg
 			continue // Path doesn't exist
 		}
 
-		// Parse CID to get multihash
-		digest, err := cidToMultihash(cidStr)
-		if err != nil {
-			m.logger.Warn("failed to parse CID", "cid", cidStr, "error", err)
-			continue
-		}
-
-		remover := m.removerGetter
-		if remover == nil {
-			return removed, fmt.Errorf("no blob remover configured")
-		}
-		client := remover()
-		if client == nil {
-			return removed, fmt.Errorf("no blob remover configured")
+		blob := Blob{Kind: KindPartial, Path: path, CID: cidStr}
+		blob.Size, blob.SizeKnown = m.pathStore.GetSize(path)
+		if _, err := m.removeBlob(ctx, blob, dlg, dryRun, result); err != nil {
+			return err
 		}
+	}
 
-		// Remove the blob
-		if err := client.RemoveBlob(ctx, m.spaceDID, digest, dlg); err != nil {
-			// Log but continue - partial cleanup is best-effort
-			m.logger.Warn("failed to remove blob", "cid", cidStr, "error", err)
-			continue
-		}
-		removed++
+	if dryRun {
+		return nil
 	}
 
 	// Clean up index mappings for this prefix
@@ -197,7 +275,53 @@ func (m *Manager) deleteWithPrefix(ctx context.Context, prefix string, dlg deleg
 		m.logger.Debug("cleaned up index entries", "count", deleted, "prefix", prefix)
 	}
 
-	return removed, nil
+	return nil
+}
+
+// removeBlob removes an obsolete blob and adds it to result, or only adds it
+// in a dry run. Failures to remove the blob are logged and reported as not
+// removed: cleanup is best-effort. An error is only returned when no blob
+// remover is configured.
+func (m *Manager) removeBlob(ctx context.Context, blob Blob, dlg delegation.Delegation, dryRun bool, result *Result) (bool, error) {
+	// Parse CID to get multihash
+	digest, err := cidToMultihash(blob.CID)
+	if err != nil {
+		m.logger.Warn("failed to parse CID", "cid", blob.CID, "error", err)
+		return false, nil
+	}
+
+	result.Blobs = append(result.Blobs, blob)
+	if dryRun {
+		result.addFreed(blob)
+		return false, nil
+	}
+
+	if m.removerGetter == nil {
+		return false, fmt.Errorf("no blob remover configured")
+	}
+	client := m.removerGetter()
+	if client == nil {
+		return false, fmt.Errorf("no blob remover configured")
+	}
+
+	// Remove the blob
+	if err := client.RemoveBlob(ctx, m.spaceDID, digest, dlg); err != nil {
+		// Log but continue - partial cleanup is best-effort
+		m.logger.Warn("failed to remove blob", "cid", blob.CID, "error", err)
+		return false, nil
+	}
+	result.BlobsRemoved++
+	result.addFreed(blob)
+	return true, nil
+}
+
+// addFreed counts the size of a removed blob.
+func (r *Result) addFreed(blob Blob) {
+	if blob.SizeKnown {
+		r.BytesFreed += blob.Size
+	} else {
+		r.UnknownSizes++
+	}
 }
 
 // isLastLeafInParent returns true if a tile with the provided index is the final child
@@ -20,12 +20,18 @@ func (m *mockRemover) RemoveBlob(ctx context.Context, spaceDID string, digest []
 
 type mockPathStore struct {
 	paths map[string]string
+	sizes map[string]uint64
 }
 
 func (m *mockPathStore) GetCID(path string) string {
 	return m.paths[path]
 }
 
+func (m *mockPathStore) GetSize(path string) (uint64, bool) {
+	size, ok := m.sizes[path]
+	return size, ok
+}
+
 func (m *mockPathStore) DeletePrefix(prefix string) int {
 	count := 0
 	for path := range m.paths {
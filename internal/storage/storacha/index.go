@@ -18,6 +18,7 @@ import (
 //   - "tile/L/NNN/NNN/..." - merkle tree tiles at level L
 type CIDIndex struct {
 	Paths      map[string]string `json:"paths"`
+	sizes      map[string]uint64 // Blob sizes in bytes, where known
 	mu         sync.RWMutex
 	stateStore storage.StateStore
 	logDID     string
@@ -28,6 +29,7 @@ type CIDIndex struct {
 func NewCIDIndex() *CIDIndex {
 	return &CIDIndex{
 		Paths:  make(map[string]string),
+		sizes:  make(map[string]uint64),
 		logger: slog.Default(),
 	}
 }
@@ -39,6 +41,7 @@ func NewCIDIndexFromMap(data map[string]string) *CIDIndex {
 	}
 	return &CIDIndex{
 		Paths:  data,
+		sizes:  make(map[string]uint64),
 		logger: slog.Default(),
 	}
 }
@@ -61,12 +64,22 @@ func (idx *CIDIndex) SetLogger(logger *slog.Logger) {
 	idx.logger = logger
 }
 
+// SetSizes records known blob sizes, e.g. as loaded from the StateStore.
+func (idx *CIDIndex) SetSizes(sizes map[string]uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for path, size := range sizes {
+		idx.sizes[path] = size
+	}
+}
+
 // Set stores a CID for a path and syncs to StateStore if configured.
 // Returns an error if the StateStore sync fails.
 func (idx *CIDIndex) Set(path, cid string) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 	idx.Paths[path] = cid
+	delete(idx.sizes, path)
 
 	// Sync to state store if configured
 	if idx.stateStore != nil && idx.logDID != "" {
@@ -77,6 +90,30 @@ func (idx *CIDIndex) Set(path, cid string) error {
 	return nil
 }
 
+// SetWithSize stores a CID for a path along with the size of the blob, and
+// syncs both to StateStore if configured.
+func (idx *CIDIndex) SetWithSize(path, cid string, size uint64) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.Paths[path] = cid
+	idx.sizes[path] = size
+
+	if idx.stateStore != nil && idx.logDID != "" {
+		if err := idx.stateStore.SetCIDWithSize(context.Background(), idx.logDID, path, cid, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSize returns the size of the blob stored for a path, if known.
+func (idx *CIDIndex) GetSize(path string) (uint64, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	size, ok := idx.sizes[path]
+	return size, ok
+}
+
 // Get retrieves the CID for a path.
 func (idx *CIDIndex) Get(path string) (string, bool) {
 	idx.mu.RLock()
@@ -90,6 +127,7 @@ func (idx *CIDIndex) Delete(path string) {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 	delete(idx.Paths, path)
+	delete(idx.sizes, path)
 }
 
 // DeletePrefix removes all paths with the given prefix.
@@ -102,6 +140,7 @@ func (idx *CIDIndex) DeletePrefix(prefix string) int {
 	for path := range idx.Paths {
 		if len(path) >= len(prefix) && path[:len(prefix)] == prefix {
 			delete(idx.Paths, path)
+			delete(idx.sizes, path)
 			count++
 		}
 	}
@@ -149,6 +188,7 @@ func (idx *CIDIndex) UnmarshalJSON(data []byte) error {
 	if idx.Paths == nil {
 		idx.Paths = make(map[string]string)
 	}
+	idx.sizes = make(map[string]uint64)
 	return nil
 }
 
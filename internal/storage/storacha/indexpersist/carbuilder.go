@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"sort"
 	"strings"
 
@@ -19,31 +20,128 @@ import (
 	blockstore "github.com/ipfs/go-ipfs-blockstore"
 	format "github.com/ipfs/go-ipld-format"
 	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/storacha/go-ucanto/core/car"
 	"github.com/storacha/go-ucanto/core/ipld"
 	"github.com/storacha/go-ucanto/core/ipld/block"
 )
 
+// PreviousLink is the name of the root directory entry linking an index CAR
+// to the root of the index CAR uploaded before it. Index paths never start
+// with a dot, so it cannot collide with a Tessera path. Together with the
+// "checkpoint" entry, the signed checkpoint the index covers, it chains the
+// index CARs of a log into a history of its heads.
+const PreviousLink = ".prev"
+
+// DefaultHAMTShardingSize is the estimated encoded size, in bytes, above
+// which a directory is built as a HAMT. It matches the Kubo default.
+const DefaultHAMTShardingSize = 256 * 1024
+
 // BuildIndexCAR builds a UnixFS CAR from a path->CID index map.
 // The CAR contains directory nodes that link to the existing blob CIDs.
 // Returns the CAR data and the root CID string.
 func BuildIndexCAR(ctx context.Context, index map[string]string) ([]byte, string, error) {
+	built, err := NewBuilder(0).Build(ctx, index, "")
+	if err != nil {
+		return nil, "", err
+	}
+	return built.Data, built.RootCID, nil
+}
+
+// Builder builds index CARs incrementally. It keeps the directory nodes of
+// the last committed build: only directories whose entries changed since are
+// rebuilt, and only blocks that were not already uploaded go into the CAR.
+// The other blocks of the new DAG stay in the CARs that uploaded them.
+//
+// A Builder starts empty, so the first CAR it builds holds the whole index.
+// It is not safe for concurrent use.
+type Builder struct {
+	shardingSize int
+
+	index  map[string]string        // Index of the last committed build
+	dirs   map[string]format.Node   // Directory nodes by path, "" for the root
+	blocks map[cid.Cid]uploadedNode // Blocks of the last committed DAG
+}
+
+// uploadedNode is a block of a committed DAG and the CAR holding it.
+type uploadedNode struct {
+	node  format.Node
+	shard string
+}
+
+// BuiltIndex is an index CAR built by a Builder.
+type BuiltIndex struct {
+	Data            []byte
+	RootCID         string
+	ShardCID        string   // CID of the CAR blob
+	CheckpointCID   string   // Signed checkpoint the index covers, empty if none
+	PreviousRootCID string   // Root linked as PreviousLink, empty for none
+	Shards          []string // Earlier CARs holding blocks the DAG reuses
+	Blocks          int      // Blocks in the CAR
+
+	index  map[string]string
+	dirs   map[string]format.Node
+	blocks map[cid.Cid]uploadedNode
+}
+
+// NewBuilder creates a Builder that shards directories whose estimated size
+// exceeds shardingSize bytes, or DefaultHAMTShardingSize if zero.
+func NewBuilder(shardingSize int) *Builder {
+	if shardingSize <= 0 {
+		shardingSize = DefaultHAMTShardingSize
+	}
+	return &Builder{
+		shardingSize: shardingSize,
+		index:        make(map[string]string),
+		dirs:         make(map[string]format.Node),
+		blocks:       make(map[cid.Cid]uploadedNode),
+	}
+}
+
+// Build builds the CAR of index on top of the last committed build. If
+// previousRoot is set, the root directory links to it as PreviousLink. The
+// Builder is unchanged until the CAR is uploaded and passed to Commit.
+func (b *Builder) Build(ctx context.Context, index map[string]string, previousRoot string) (*BuiltIndex, error) {
 	// Create in-memory blockstore
 	ds := dssync.MutexWrap(datastore.NewMapDatastore())
 	bs := blockstore.NewBlockstore(ds)
 	bserv := blockservice.New(bs, offline.Exchange(bs))
 	dagService := merkledag.NewDAGService(bserv)
 
-	// Build directory tree
-	rootNode, err := buildDirectoryTree(ctx, dagService, index)
+	var prev *cid.Cid
+	if previousRoot != "" {
+		c, err := cid.Decode(previousRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous root %s: %w", previousRoot, err)
+		}
+		prev = &c
+	}
+
+	// Build directory tree, reusing unchanged directories
+	st := &buildState{
+		builder:    b,
+		dagService: dagService,
+		dirty:      changedDirs(b.index, index),
+		dirs:       make(map[string]format.Node, len(b.dirs)),
+	}
+	for path, node := range b.dirs {
+		st.dirs[path] = node
+	}
+	rootNode, err := st.buildDirectoryTree(ctx, index, prev)
 	if err != nil {
-		return nil, "", fmt.Errorf("build tree: %w", err)
+		return nil, fmt.Errorf("build tree: %w", err)
+	}
+	// Directories that are gone are dirty but were not rebuilt
+	for path := range st.dirty {
+		if !st.built[path] {
+			delete(st.dirs, path)
+		}
 	}
 
-	// Collect all blocks for the CAR
-	nodes, err := collectBlocks(ctx, dagService, rootNode.Cid())
+	// Collect the new blocks for the CAR, and the CARs holding the others
+	nodes, reused, err := st.collectBlocks(ctx, rootNode.Cid())
 	if err != nil {
-		return nil, "", fmt.Errorf("collect blocks: %w", err)
+		return nil, fmt.Errorf("collect blocks: %w", err)
 	}
 
 	// Convert to go-ucanto types and encode using car.Encode
@@ -55,10 +153,72 @@ func BuildIndexCAR(ctx context.Context, index map[string]string) ([]byte, string
 
 	carData, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, "", fmt.Errorf("read CAR: %w", err)
+		return nil, fmt.Errorf("read CAR: %w", err)
+	}
+
+	// The CAR is stored as a blob addressed by its SHA2-256 digest
+	hash, err := mh.Sum(carData, mh.SHA2_256, -1)
+	if err != nil {
+		return nil, fmt.Errorf("hash CAR: %w", err)
+	}
+	built := &BuiltIndex{
+		Data:            carData,
+		RootCID:         rootNode.Cid().String(),
+		ShardCID:        cid.NewCidV1(cid.Raw, hash).String(),
+		CheckpointCID:   index["checkpoint"],
+		PreviousRootCID: previousRoot,
+		Blocks:          len(nodes),
+		index:           maps.Clone(index),
+		dirs:            st.dirs,
+		blocks:          make(map[cid.Cid]uploadedNode, len(nodes)+len(reused)),
+	}
+	shards := make(map[string]bool)
+	for c, blk := range reused {
+		built.blocks[c] = blk
+		shards[blk.shard] = true
+	}
+	for _, node := range nodes {
+		built.blocks[node.Cid()] = uploadedNode{node: node, shard: built.ShardCID}
+	}
+	for shard := range shards {
+		built.Shards = append(built.Shards, shard)
 	}
+	sort.Strings(built.Shards)
 
-	return carData, rootNode.Cid().String(), nil
+	return built, nil
+}
+
+// Commit makes an uploaded CAR the base of the next build.
+func (b *Builder) Commit(built *BuiltIndex) {
+	b.index = built.index
+	b.dirs = built.dirs
+	b.blocks = built.blocks
+}
+
+// changedDirs returns the paths of the directories whose entries differ
+// between two indexes: every ancestor of an added, changed or removed path.
+func changedDirs(prev, next map[string]string) map[string]bool {
+	dirty := map[string]bool{"": true}
+	mark := func(path string) {
+		for i := strings.LastIndexByte(path, '/'); i >= 0; i = strings.LastIndexByte(path, '/') {
+			path = path[:i]
+			if dirty[path] {
+				return
+			}
+			dirty[path] = true
+		}
+	}
+	for path, c := range next {
+		if prev[path] != c {
+			mark(path)
+		}
+	}
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			mark(path)
+		}
+	}
+	return dirty
 }
 
 // nodesToBlocks converts format.Node slice to iter.Seq2[ipld.Block, error]
@@ -89,8 +249,17 @@ type dirEntry struct {
 	children map[string]*dirEntry // if directory
 }
 
+// buildState holds the directories of a build in progress.
+type buildState struct {
+	builder    *Builder
+	dagService format.DAGService
+	dirty      map[string]bool        // Directories to rebuild
+	dirs       map[string]format.Node // Directory nodes of the new DAG
+	built      map[string]bool        // Directories rebuilt
+}
+
 // buildDirectoryTree creates a UnixFS directory DAG from path->CID mappings.
-func buildDirectoryTree(ctx context.Context, dagService format.DAGService, index map[string]string) (format.Node, error) {
+func (st *buildState) buildDirectoryTree(ctx context.Context, index map[string]string, prev *cid.Cid) (format.Node, error) {
 	// Sort paths for deterministic output
 	paths := make([]string, 0, len(index))
 	for p := range index {
@@ -125,16 +294,20 @@ func buildDirectoryTree(ctx context.Context, dagService format.DAGService, index
 			}
 		}
 	}
+	if prev != nil {
+		root.children[PreviousLink] = &dirEntry{blobCID: prev.String()}
+	}
 
 	// Recursively build the DAG
-	return buildDirNode(ctx, dagService, root)
+	st.built = make(map[string]bool)
+	return st.buildDirNode(ctx, "", root)
 }
 
-// buildDirNode recursively builds a UnixFS directory node.
-func buildDirNode(ctx context.Context, dagService format.DAGService, entry *dirEntry) (format.Node, error) {
-	dir, err := ufsio.NewDirectory(dagService)
-	if err != nil {
-		return nil, fmt.Errorf("create directory: %w", err)
+// buildDirNode recursively builds a UnixFS directory node, or reuses the
+// node of the last committed build if the directory did not change.
+func (st *buildState) buildDirNode(ctx context.Context, path string, entry *dirEntry) (format.Node, error) {
+	if node, ok := st.dirs[path]; ok && !st.dirty[path] {
+		return node, nil
 	}
 
 	// Sort children for deterministic output
@@ -144,12 +317,18 @@ func buildDirNode(ctx context.Context, dagService format.DAGService, entry *dirE
 	}
 	sort.Strings(childNames)
 
-	for _, name := range childNames {
+	childNodes := make([]format.Node, len(childNames))
+	estimatedSize := 0
+	for i, name := range childNames {
 		child := entry.children[name]
 
 		var childNode format.Node
 		if child.isDir {
-			node, err := buildDirNode(ctx, dagService, child)
+			childPath := name
+			if path != "" {
+				childPath = path + "/" + name
+			}
+			node, err := st.buildDirNode(ctx, childPath, child)
 			if err != nil {
 				return nil, fmt.Errorf("build subdir %s: %w", name, err)
 			}
@@ -166,13 +345,30 @@ func buildDirNode(ctx context.Context, dagService format.DAGService, entry *dirE
 		} else {
 			// Create a raw node with actual content (for testing)
 			node := merkledag.NewRawNode(child.content)
-			if err := dagService.Add(ctx, node); err != nil {
+			if err := st.dagService.Add(ctx, node); err != nil {
 				return nil, fmt.Errorf("add file %s: %w", name, err)
 			}
 			childNode = node
 		}
+		childNodes[i] = childNode
+		estimatedSize += len(name) + childNode.Cid().ByteLen()
+	}
+
+	// Very large directories are sharded so their blocks stay small enough
+	// for gateways to fetch
+	var dir ufsio.Directory
+	var err error
+	if estimatedSize > st.builder.shardingSize {
+		dir, err = ufsio.NewHAMTDirectory(st.dagService, 0)
+	} else {
+		dir, err = ufsio.NewBasicDirectory(st.dagService)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
 
-		if err := dir.AddChild(ctx, name, childNode); err != nil {
+	for i, name := range childNames {
+		if err := dir.AddChild(ctx, name, childNodes[i]); err != nil {
 			return nil, fmt.Errorf("add child %s: %w", name, err)
 		}
 	}
@@ -182,45 +378,63 @@ func buildDirNode(ctx context.Context, dagService format.DAGService, entry *dirE
 		return nil, fmt.Errorf("get directory node: %w", err)
 	}
 
-	if err := dagService.Add(ctx, node); err != nil {
+	if err := st.dagService.Add(ctx, node); err != nil {
 		return nil, fmt.Errorf("add directory: %w", err)
 	}
 
+	st.dirs[path] = node
+	st.built[path] = true
 	return node, nil
 }
 
-// collectBlocks traverses the DAG and collects all blocks.
-func collectBlocks(ctx context.Context, dagService format.DAGService, root cid.Cid) ([]format.Node, error) {
+// collectBlocks traverses the DAG and collects the blocks built for it.
+// Blocks of the last committed DAG are returned separately with the CAR
+// holding them. The PreviousLink of the root is not followed.
+func (st *buildState) collectBlocks(ctx context.Context, root cid.Cid) ([]format.Node, map[cid.Cid]uploadedNode, error) {
 	var blocks []format.Node
+	reused := make(map[cid.Cid]uploadedNode)
 	seen := make(map[cid.Cid]bool)
 
-	var collect func(c cid.Cid) error
-	collect = func(c cid.Cid) error {
-		if seen[c] {
+	var collect func(c cid.Cid, isRoot bool) error
+	collect = func(c cid.Cid, isRoot bool) error {
+		// Only directories and HAMT shards are dag-pb; leaves are the log's
+		// blobs, which HAMT directories store in the DAG service as empty
+		// proxy nodes
+		if seen[c] || c.Type() != cid.DagProtobuf {
 			return nil
 		}
 		seen[c] = true
 
-		node, err := dagService.Get(ctx, c)
-		if err != nil {
-			// Assume external CID, skip
-			return nil
+		var node format.Node
+		if uploaded, ok := st.builder.blocks[c]; ok {
+			reused[c] = uploaded
+			node = uploaded.node
+		} else {
+			var err error
+			node, err = st.dagService.Get(ctx, c)
+			if err != nil {
+				// Assume external CID, skip
+				return nil
+			}
+			blocks = append(blocks, node)
 		}
-		blocks = append(blocks, node)
 
 		for _, link := range node.Links() {
-			if err := collect(link.Cid); err != nil {
+			if isRoot && link.Name == PreviousLink {
+				continue
+			}
+			if err := collect(link.Cid, false); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
 
-	if err := collect(root); err != nil {
-		return nil, err
+	if err := collect(root, true); err != nil {
+		return nil, nil, err
 	}
 
-	return blocks, nil
+	return blocks, reused, nil
 }
 
 // cidProxyNode is a minimal node implementation that just holds a CID.
@@ -3,8 +3,12 @@ package indexpersist
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"testing"
 
+	"github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
 )
@@ -103,3 +107,134 @@ func TestBuildIndexCAR_AfterGCCleanup(t *testing.T) {
 	// The CAR after should be smaller (fewer directory entries)
 	require.Less(t, len(carAfter), len(carBefore), "CAR after GC should be smaller")
 }
+
+func TestBuilder_Incremental(t *testing.T) {
+	ctx := context.Background()
+
+	index := map[string]string{
+		"checkpoint":        rawCID(t, "checkpoint 1"),
+		"tile/0/000":        rawCID(t, "tile 0"),
+		"tile/entries/000":  rawCID(t, "bundle 0"),
+		"tile/entries/001":  rawCID(t, "bundle 1"),
+		"tile/entries/x001": rawCID(t, "bundle 1000"),
+	}
+	builder := NewBuilder(0)
+	first, err := builder.Build(ctx, index, "")
+	require.NoError(t, err)
+	require.Empty(t, first.Shards)
+	builder.Commit(first)
+
+	// The first CAR holds the same blocks as a full build
+	fullData, fullRoot, err := BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	require.Equal(t, fullRoot, first.RootCID)
+	require.Equal(t, fullData, first.Data)
+
+	// Only the checkpoint changes: the tile directories are reused
+	next := maps.Clone(index)
+	next["checkpoint"] = rawCID(t, "checkpoint 2")
+	second, err := builder.Build(ctx, next, first.RootCID)
+	require.NoError(t, err)
+	require.Equal(t, 1, second.Blocks, "only the root is new")
+	require.Equal(t, []string{first.ShardCID}, second.Shards)
+	require.Equal(t, first.RootCID, second.PreviousRootCID)
+	require.Less(t, len(second.Data), len(first.Data))
+	builder.Commit(second)
+
+	// A changed bundle rebuilds its directory and the ones above it
+	next = maps.Clone(next)
+	next["tile/entries/001"] = rawCID(t, "bundle 1 again")
+	third, err := builder.Build(ctx, next, second.RootCID)
+	require.NoError(t, err)
+	require.Equal(t, 3, third.Blocks, "root, tile and tile/entries")
+	require.Equal(t, []string{first.ShardCID}, third.Shards, "tile/0 is still in the first CAR")
+
+	// The blocks of all CARs together hold the whole index, and the root
+	// links to the previous one without it being part of the index
+	_, blocks := carFetcher(t, first.Data)
+	for _, built := range []*BuiltIndex{second, third} {
+		_, stored := carFetcher(t, built.Data)
+		maps.Copy(blocks, stored)
+	}
+	fetch := func(ctx context.Context, c string) ([]byte, error) {
+		data, ok := blocks[c]
+		if !ok {
+			return nil, fmt.Errorf("block not found: %s", c)
+		}
+		return data, nil
+	}
+	got, err := ReadIndex(ctx, third.RootCID, fetch)
+	require.NoError(t, err)
+	require.Equal(t, next, got)
+
+	prev, err := ResolvePath(ctx, third.RootCID, PreviousLink, fetch)
+	require.NoError(t, err)
+	require.Equal(t, second.RootCID, prev)
+	got, err = ReadIndex(ctx, prev, fetch)
+	require.NoError(t, err)
+	require.Equal(t, rawCID(t, "checkpoint 2"), got["checkpoint"])
+}
+
+func TestBuilder_UncommittedBuildIsNotReused(t *testing.T) {
+	ctx := context.Background()
+	builder := NewBuilder(0)
+
+	index := map[string]string{"tile/0/000": rawCID(t, "tile 0")}
+	lost, err := builder.Build(ctx, index, "")
+	require.NoError(t, err)
+
+	// The upload of the first CAR failed, so the next one holds every block
+	index["checkpoint"] = rawCID(t, "checkpoint")
+	built, err := builder.Build(ctx, index, "")
+	require.NoError(t, err)
+	require.Empty(t, built.Shards)
+	require.NotEqual(t, lost.ShardCID, built.ShardCID)
+
+	fetch, _ := carFetcher(t, built.Data)
+	got, err := ReadIndex(ctx, built.RootCID, fetch)
+	require.NoError(t, err)
+	require.Equal(t, index, got)
+}
+
+func TestBuilder_HAMTSharding(t *testing.T) {
+	ctx := context.Background()
+
+	index := map[string]string{"checkpoint": rawCID(t, "checkpoint")}
+	for i := 0; i < 100; i++ {
+		index[fmt.Sprintf("tile/entries/%03d", i)] = rawCID(t, fmt.Sprintf("bundle %d", i))
+	}
+
+	// 100 links of about 40 bytes exceed 1 KiB
+	builder := NewBuilder(1024)
+	first, err := builder.Build(ctx, index, "")
+	require.NoError(t, err)
+	builder.Commit(first)
+	fetch, blocks := carFetcher(t, first.Data)
+
+	entriesCID, err := ResolvePath(ctx, first.RootCID, "tile/entries", fetch)
+	require.NoError(t, err)
+	node, err := merkledag.DecodeProtobuf(blocks[entriesCID])
+	require.NoError(t, err)
+	fsNode, err := unixfs.FSNodeFromBytes(node.Data())
+	require.NoError(t, err)
+	require.Equal(t, unixfs.THAMTShard, fsNode.Type())
+
+	got, err := ReadIndex(ctx, first.RootCID, fetch)
+	require.NoError(t, err)
+	require.Equal(t, index, got)
+
+	// Changing one entry only rebuilds the shards along its path
+	index["tile/entries/042"] = rawCID(t, "bundle 42 again")
+	second, err := builder.Build(ctx, index, first.RootCID)
+	require.NoError(t, err)
+	require.Less(t, second.Blocks, first.Blocks)
+	_, stored := carFetcher(t, second.Data)
+	maps.Copy(blocks, stored)
+
+	resolved, err := ResolvePath(ctx, second.RootCID, "tile/entries/042", fetch)
+	require.NoError(t, err)
+	require.Equal(t, index["tile/entries/042"], resolved)
+	got, err = ReadIndex(ctx, second.RootCID, fetch)
+	require.NoError(t, err)
+	require.Equal(t, index, got)
+}
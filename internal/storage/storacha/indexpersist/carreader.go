@@ -0,0 +1,270 @@
+// storage/storacha/indexpersist/carreader.go
+package indexpersist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	"github.com/ipfs/boxo/ipld/unixfs/hamt"
+	ufsio "github.com/ipfs/boxo/ipld/unixfs/io"
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+)
+
+// BlockFetcher fetches the bytes of a block by CID.
+type BlockFetcher func(ctx context.Context, cid string) ([]byte, error)
+
+// ReadIndex walks the UnixFS directory tree of an index CAR built by
+// BuildIndexCAR and returns the path->CID index it encodes.
+// Only directory blocks are fetched; leaves link to the log's blobs and are
+// returned without being retrieved. Every fetched block is checked against
+// its CID. The PreviousLink of the root is not followed.
+func ReadIndex(ctx context.Context, rootCID string, fetch BlockFetcher) (map[string]string, error) {
+	root, err := cid.Decode(rootCID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root CID %s: %w", rootCID, err)
+	}
+	if root.Type() != cid.DagProtobuf {
+		return nil, fmt.Errorf("root %s is not a UnixFS directory", rootCID)
+	}
+
+	dag := &fetchingDAG{fetch: fetch}
+	index := make(map[string]string)
+	if err := readDir(ctx, dag, root, "", index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// ErrPathNotFound is returned by ResolvePath for paths missing from an index.
+var ErrPathNotFound = errors.New("path not found in index")
+
+// ResolvePath returns the CID linked at path, such as "tile/0/x001/234", in
+// the index CAR rooted at rootCID. Only the directory blocks along the path
+// are fetched, and each is checked against its CID.
+func ResolvePath(ctx context.Context, rootCID, path string, fetch BlockFetcher) (string, error) {
+	c, err := cid.Decode(rootCID)
+	if err != nil {
+		return "", fmt.Errorf("invalid root CID %s: %w", rootCID, err)
+	}
+
+	dag := &fetchingDAG{fetch: fetch}
+	for _, name := range strings.Split(path, "/") {
+		if c.Type() != cid.DagProtobuf {
+			return "", fmt.Errorf("%w: %s", ErrPathNotFound, path)
+		}
+		node, err := dag.Get(ctx, c)
+		if err != nil {
+			return "", fmt.Errorf("fetch directory of %q: %w", path, err)
+		}
+		link, err := findLink(ctx, dag, node, name)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrPathNotFound, path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("resolve %q: %w", path, err)
+		}
+		c = link.Cid
+	}
+	return c.String(), nil
+}
+
+// findLink looks up name in a UnixFS directory node, following HAMT shards.
+// Unlike Directory.Find it does not fetch the linked node.
+func findLink(ctx context.Context, dag format.DAGService, node format.Node, name string) (*format.Link, error) {
+	pn, ok := node.(*merkledag.ProtoNode)
+	if !ok {
+		return nil, fmt.Errorf("not a UnixFS directory")
+	}
+	fsNode, err := unixfs.FSNodeFromBytes(pn.Data())
+	if err != nil {
+		return nil, err
+	}
+	switch fsNode.Type() {
+	case unixfs.TDirectory:
+		link, err := pn.GetNodeLink(name)
+		if errors.Is(err, merkledag.ErrLinkNotFound) {
+			return nil, os.ErrNotExist
+		}
+		return link, err
+	case unixfs.THAMTShard:
+		shard, err := hamt.NewHamtFromDag(dag, node)
+		if err != nil {
+			return nil, err
+		}
+		return shard.Find(ctx, name)
+	default:
+		return nil, os.ErrNotExist
+	}
+}
+
+// readDir adds the entries of the directory at c to index, prefixing their
+// paths with prefix. Sub-directories are dag-pb nodes; blobs are raw leaves.
+func readDir(ctx context.Context, dag format.DAGService, c cid.Cid, prefix string, index map[string]string) error {
+	node, err := dag.Get(ctx, c)
+	if err != nil {
+		return fmt.Errorf("fetch directory %q: %w", prefix, err)
+	}
+	dir, err := ufsio.NewDirectoryFromNode(dag, node)
+	if err != nil {
+		return fmt.Errorf("read directory %q: %w", prefix, err)
+	}
+
+	return dir.ForEachLink(ctx, func(l *format.Link) error {
+		if prefix == "" && l.Name == PreviousLink {
+			return nil
+		}
+		path := prefix + l.Name
+		if l.Cid.Type() == cid.DagProtobuf {
+			return readDir(ctx, dag, l.Cid, path+"/", index)
+		}
+		index[path] = l.Cid.String()
+		return nil
+	})
+}
+
+// fetchingDAG is a read-only DAGService that decodes dag-pb blocks retrieved
+// through a BlockFetcher, so directories (including HAMT shards) can be
+// traversed without a local blockstore.
+type fetchingDAG struct {
+	fetch BlockFetcher
+}
+
+func (d *fetchingDAG) Get(ctx context.Context, c cid.Cid) (format.Node, error) {
+	if c.Type() != cid.DagProtobuf {
+		return nil, fmt.Errorf("unsupported codec for %s", c)
+	}
+	data, err := d.fetch(ctx, c.String())
+	if err != nil {
+		return nil, err
+	}
+	sum, err := c.Prefix().Sum(data)
+	if err != nil {
+		return nil, fmt.Errorf("hash block %s: %w", c, err)
+	}
+	if !sum.Equals(c) {
+		return nil, fmt.Errorf("block %s does not match its CID", c)
+	}
+
+	node, err := merkledag.DecodeProtobuf(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode block %s: %w", c, err)
+	}
+	node.SetCidBuilder(c.Prefix())
+	return node, nil
+}
+
+func (d *fetchingDAG) GetMany(ctx context.Context, cids []cid.Cid) <-chan *format.NodeOption {
+	out := make(chan *format.NodeOption, len(cids))
+	go func() {
+		defer close(out)
+		for _, c := range cids {
+			node, err := d.Get(ctx, c)
+			select {
+			case out <- &format.NodeOption{Node: node, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (d *fetchingDAG) Add(context.Context, format.Node) error {
+	return fmt.Errorf("index DAG is read-only")
+}
+
+func (d *fetchingDAG) AddMany(context.Context, []format.Node) error {
+	return fmt.Errorf("index DAG is read-only")
+}
+
+func (d *fetchingDAG) Remove(context.Context, cid.Cid) error {
+	return fmt.Errorf("index DAG is read-only")
+}
+
+func (d *fetchingDAG) RemoveMany(context.Context, []cid.Cid) error {
+	return fmt.Errorf("index DAG is read-only")
+}
+
+// ErrHistoryGap is returned by WalkHistory when an index root linked as
+// PreviousLink cannot be fetched.
+var ErrHistoryGap = errors.New("index history has a gap")
+
+// ErrStopWalk can be returned by a WalkHistory callback to stop the walk
+// without error.
+var ErrStopWalk = errors.New("stop walking index history")
+
+// IndexHead is the head an index CAR covers, read from its root directory.
+type IndexHead struct {
+	RootCID         string
+	CheckpointCID   string // Signed checkpoint the index covers, empty if none
+	PreviousRootCID string // Root linked as PreviousLink, empty for the first CAR
+}
+
+// ReadHead reads the checkpoint and previous root linked from the root
+// directory of an index CAR. Only the root block is fetched.
+func ReadHead(ctx context.Context, rootCID string, fetch BlockFetcher) (*IndexHead, error) {
+	root, err := cid.Decode(rootCID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root CID %s: %w", rootCID, err)
+	}
+	if root.Type() != cid.DagProtobuf {
+		return nil, fmt.Errorf("root %s is not a UnixFS directory", rootCID)
+	}
+
+	dag := &fetchingDAG{fetch: fetch}
+	node, err := dag.Get(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("fetch root %s: %w", rootCID, err)
+	}
+
+	head := &IndexHead{RootCID: rootCID}
+	for name, target := range map[string]*string{
+		"checkpoint": &head.CheckpointCID,
+		PreviousLink: &head.PreviousRootCID,
+	} {
+		link, err := findLink(ctx, dag, node, name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s of root %s: %w", name, rootCID, err)
+		}
+		*target = link.Cid.String()
+	}
+	return head, nil
+}
+
+// WalkHistory calls fn with the head of the index CAR rooted at rootCID and
+// then with each earlier head, following PreviousLink until a root without
+// one. A previous root that cannot be fetched ends the walk with
+// ErrHistoryGap.
+func WalkHistory(ctx context.Context, rootCID string, fetch BlockFetcher, fn func(*IndexHead) error) error {
+	seen := make(map[string]bool)
+	for c := rootCID; c != ""; {
+		if seen[c] {
+			return fmt.Errorf("index history loops back to %s", c)
+		}
+		seen[c] = true
+
+		head, err := ReadHead(ctx, c, fetch)
+		if err != nil {
+			if c == rootCID {
+				return err
+			}
+			return fmt.Errorf("%w: %v", ErrHistoryGap, err)
+		}
+		if err := fn(head); errors.Is(err, ErrStopWalk) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		c = head.PreviousRootCID
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// storage/storacha/indexpersist/carreader_test.go
+package indexpersist
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/storacha/go-ucanto/core/car"
+	"github.com/stretchr/testify/require"
+)
+
+// carFetcher serves the blocks of a CAR by CID.
+func carFetcher(t *testing.T, carData []byte) (BlockFetcher, map[string][]byte) {
+	t.Helper()
+	_, blocks, err := car.Decode(bytes.NewReader(carData))
+	require.NoError(t, err)
+
+	stored := make(map[string][]byte)
+	for blk, err := range blocks {
+		require.NoError(t, err)
+		stored[blk.Link().(cidlink.Link).Cid.String()] = blk.Bytes()
+	}
+	return func(ctx context.Context, c string) ([]byte, error) {
+		data, ok := stored[c]
+		if !ok {
+			return nil, fmt.Errorf("block not found: %s", c)
+		}
+		return data, nil
+	}, stored
+}
+
+func rawCID(t *testing.T, data string) string {
+	t.Helper()
+	hash, err := mh.Sum([]byte(data), mh.SHA2_256, -1)
+	require.NoError(t, err)
+	return cid.NewCidV1(cid.Raw, hash).String()
+}
+
+func TestReadIndex_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	index := map[string]string{
+		"checkpoint":                 rawCID(t, "checkpoint"),
+		"tile/0/000":                 rawCID(t, "tile 0"),
+		"tile/0/000.p/5":             rawCID(t, "partial tile 0"),
+		"tile/1/000.p/1":             rawCID(t, "tile 1"),
+		"tile/entries/000":           rawCID(t, "bundle 0"),
+		"tile/entries/x001/002.p/17": rawCID(t, "bundle 1002"),
+	}
+
+	carData, rootCID, err := BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	fetch, _ := carFetcher(t, carData)
+
+	got, err := ReadIndex(ctx, rootCID, fetch)
+	require.NoError(t, err)
+	require.Equal(t, index, got)
+}
+
+func TestReadIndex_RejectsTamperedBlock(t *testing.T) {
+	ctx := context.Background()
+
+	carData, rootCID, err := BuildIndexCAR(ctx, map[string]string{
+		"checkpoint": rawCID(t, "checkpoint"),
+	})
+	require.NoError(t, err)
+
+	_, stored := carFetcher(t, carData)
+	other, otherRoot, err := BuildIndexCAR(ctx, map[string]string{
+		"checkpoint": rawCID(t, "forged checkpoint"),
+	})
+	require.NoError(t, err)
+	_, otherStored := carFetcher(t, other)
+	stored[rootCID] = otherStored[otherRoot]
+
+	_, err = ReadIndex(ctx, rootCID, func(ctx context.Context, c string) ([]byte, error) {
+		return stored[c], nil
+	})
+	require.ErrorContains(t, err, "does not match its CID")
+}
+
+func TestResolvePath(t *testing.T) {
+	ctx := context.Background()
+
+	index := map[string]string{
+		"checkpoint":                 rawCID(t, "checkpoint"),
+		"tile/0/x001/002":            rawCID(t, "tile 1002"),
+		"tile/entries/x001/002.p/17": rawCID(t, "bundle 1002"),
+	}
+	carData, rootCID, err := BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	fetch, _ := carFetcher(t, carData)
+
+	for path, want := range index {
+		got, err := ResolvePath(ctx, rootCID, path, fetch)
+		require.NoError(t, err, path)
+		require.Equal(t, want, got, path)
+	}
+
+	for _, path := range []string{"tile/0/x001/003", "checkpoint/x", "tile/0", "../checkpoint"} {
+		_, err := ResolvePath(ctx, rootCID, path, fetch)
+		if path == "tile/0" {
+			require.NoError(t, err) // Directories resolve to their own CID
+			continue
+		}
+		require.ErrorIs(t, err, ErrPathNotFound, path)
+	}
+}
+
+func TestWalkHistory(t *testing.T) {
+	ctx := context.Background()
+	stored := make(map[string][]byte)
+	fetch := func(ctx context.Context, c string) ([]byte, error) {
+		data, ok := stored[c]
+		if !ok {
+			return nil, fmt.Errorf("block not found: %s", c)
+		}
+		return data, nil
+	}
+
+	b := NewBuilder(0)
+	var roots, checkpoints []string
+	previous := ""
+	for i := 0; i < 3; i++ {
+		checkpoints = append(checkpoints, rawCID(t, fmt.Sprintf("checkpoint %d", i)))
+		built, err := b.Build(ctx, map[string]string{
+			"checkpoint": checkpoints[i],
+			"tile/0/000": rawCID(t, "tile 0"),
+		}, previous)
+		require.NoError(t, err)
+		require.Equal(t, checkpoints[i], built.CheckpointCID)
+		_, blocks := carFetcher(t, built.Data)
+		maps.Copy(stored, blocks)
+		b.Commit(built)
+		previous = built.RootCID
+		roots = append(roots, previous)
+	}
+
+	var heads []*IndexHead
+	err := WalkHistory(ctx, roots[2], fetch, func(head *IndexHead) error {
+		heads = append(heads, head)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, heads, 3)
+	for i, head := range heads {
+		require.Equal(t, roots[2-i], head.RootCID)
+		require.Equal(t, checkpoints[2-i], head.CheckpointCID)
+	}
+	require.Equal(t, roots[1], heads[0].PreviousRootCID)
+	require.Empty(t, heads[2].PreviousRootCID)
+
+	// The previous link is not an index entry
+	index, err := ReadIndex(ctx, roots[2], fetch)
+	require.NoError(t, err)
+	require.NotContains(t, index, PreviousLink)
+
+	// Callbacks can stop the walk early
+	heads = nil
+	err = WalkHistory(ctx, roots[2], fetch, func(head *IndexHead) error {
+		heads = append(heads, head)
+		return ErrStopWalk
+	})
+	require.NoError(t, err)
+	require.Len(t, heads, 1)
+
+	// A missing earlier root is a gap
+	delete(stored, roots[0])
+	err = WalkHistory(ctx, roots[2], fetch, func(*IndexHead) error { return nil })
+	require.ErrorIs(t, err, ErrHistoryGap)
+	err = WalkHistory(ctx, roots[0], fetch, func(*IndexHead) error { return nil })
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrHistoryGap)
+}
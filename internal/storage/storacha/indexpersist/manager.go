@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ipfs/go-cid"
+
 	"github.com/relves/ucanlog/internal/storage"
 )
 
@@ -31,6 +33,11 @@ type Manager struct {
 	logDID        string
 	logger        *slog.Logger
 
+	// uploadMu serializes uploads, so each CAR is built on top of the last
+	// one and links to it
+	uploadMu sync.Mutex
+	builder  *Builder
+
 	mu                sync.Mutex
 	dirty             bool
 	lastHash          string
@@ -58,6 +65,7 @@ func NewManager(cfg Config, uploader Uploader, indexProvider IndexProvider) *Man
 		cfg:           cfg,
 		uploader:      uploader,
 		indexProvider: indexProvider,
+		builder:       NewBuilder(cfg.HAMTShardingSize),
 		dirty:         true, // Start dirty to trigger initial upload
 		logger:        cfg.Logger,
 	}
@@ -146,40 +154,32 @@ func (m *Manager) maybeUpload(ctx context.Context) error {
 	}
 	m.mu.Unlock()
 
-	// Build CAR
-	carData, rootCID, err := BuildIndexCAR(ctx, index)
+	// Build and upload CAR
+	built, meta, err := m.uploadIndex(ctx, index)
 	if err != nil {
-		return fmt.Errorf("failed to build CAR: %w", err)
-	}
-
-	// Upload
-	uploadedCID, err := m.uploader.UploadCAR(ctx, carData)
-	if err != nil {
-		return fmt.Errorf("failed to upload CAR: %w", err)
+		return err
 	}
 
 	// Update state
 	m.mu.Lock()
-	m.meta.Version++
-	m.meta.RootCID = uploadedCID
-	m.meta.LastUploaded = time.Now()
-	m.meta.EntryCount = len(index)
 	m.lastHash = hash
 	m.dirty = false
-	meta := m.meta
 	m.mu.Unlock()
 
 	// Persist metadata
 	if err := m.saveMeta(); err != nil {
 		m.logger.Warn("failed to save index meta", "error", err)
 	}
+	m.recordHeadIndexCID(index, meta.RootCID)
+	m.recordIndexCAR(built, meta)
 
 	// Log
-	m.logger.Info("index CAR uploaded", "cid", rootCID, "version", meta.Version, "entries", meta.EntryCount)
+	m.logger.Info("index CAR uploaded", "cid", meta.RootCID, "version", meta.Version, "entries", meta.EntryCount,
+		"blocks", built.Blocks, "bytes", len(built.Data))
 
 	// Callback
 	if m.cfg.OnUpload != nil {
-		m.cfg.OnUpload(uploadedCID, meta)
+		m.cfg.OnUpload(meta.RootCID, meta)
 	}
 
 	return nil
@@ -284,41 +284,71 @@ func (m *Manager) runPersist(ctx context.Context) {
 
 // doUpload handles the actual CAR build and upload.
 func (m *Manager) doUpload(ctx context.Context, index map[string]string, hash string) error {
-	carData, rootCID, err := BuildIndexCAR(ctx, index)
+	built, meta, err := m.uploadIndex(ctx, index)
 	if err != nil {
-		return fmt.Errorf("failed to build CAR: %w", err)
+		return err
 	}
 
-	uploadedCID, err := m.uploader.UploadCAR(ctx, carData)
-	if err != nil {
-		return fmt.Errorf("failed to upload CAR: %w", err)
-	}
-
-	// Update metadata
 	m.mu.Lock()
-	m.meta.Version++
-	m.meta.RootCID = uploadedCID
-	m.meta.LastUploaded = time.Now()
-	m.meta.EntryCount = len(index)
 	m.lastHash = hash
-	meta := m.meta
 	m.mu.Unlock()
 
 	// Persist metadata to StateStore
 	if err := m.saveMeta(); err != nil {
 		m.logger.Warn("failed to save index meta", "error", err)
 	}
+	m.recordHeadIndexCID(index, meta.RootCID)
+	m.recordIndexCAR(built, meta)
 
-	m.logger.Info("index CAR uploaded", "cid", rootCID, "version", meta.Version, "entries", meta.EntryCount)
+	m.logger.Info("index CAR uploaded", "cid", meta.RootCID, "version", meta.Version, "entries", meta.EntryCount,
+		"blocks", built.Blocks, "bytes", len(built.Data))
 
 	// Callback
 	if m.cfg.OnUpload != nil {
-		m.cfg.OnUpload(uploadedCID, meta)
+		m.cfg.OnUpload(meta.RootCID, meta)
 	}
 
 	return nil
 }
 
+// uploadIndex builds the CAR of index on top of the last uploaded one,
+// linking to its root, and uploads it. The CAR only holds the directory
+// blocks that changed since.
+func (m *Manager) uploadIndex(ctx context.Context, index map[string]string) (*BuiltIndex, IndexMeta, error) {
+	m.uploadMu.Lock()
+	defer m.uploadMu.Unlock()
+
+	m.mu.Lock()
+	previousRoot := m.meta.RootCID
+	m.mu.Unlock()
+	if _, err := cid.Decode(previousRoot); previousRoot != "" && err != nil {
+		// Don't let a bad stored CID hold back uploads
+		m.logger.Warn("not linking index CAR to invalid previous root", "cid", previousRoot, "error", err)
+		previousRoot = ""
+	}
+
+	built, err := m.builder.Build(ctx, index, previousRoot)
+	if err != nil {
+		return nil, IndexMeta{}, fmt.Errorf("failed to build CAR: %w", err)
+	}
+
+	uploadedCID, err := m.uploader.UploadCAR(ctx, built.Data)
+	if err != nil {
+		return nil, IndexMeta{}, fmt.Errorf("failed to upload CAR: %w", err)
+	}
+	m.builder.Commit(built)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meta.Version++
+	m.meta.RootCID = uploadedCID
+	m.meta.PreviousRootCID = previousRoot
+	m.meta.CheckpointCID = built.CheckpointCID
+	m.meta.LastUploaded = time.Now()
+	m.meta.EntryCount = len(index)
+	return built, m.meta, nil
+}
+
 func (m *Manager) loadMeta() {
 	if m.stateStore == nil || m.logDID == "" {
 		return
@@ -356,6 +386,44 @@ func (m *Manager) saveMeta() error {
 	return nil
 }
 
+// recordHeadIndexCID links an uploaded index CAR to the head history entry
+// of the checkpoint it contains.
+func (m *Manager) recordHeadIndexCID(index map[string]string, uploadedCID string) {
+	if m.stateStore == nil || m.logDID == "" {
+		return
+	}
+	checkpointCID, ok := index["checkpoint"]
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	if err := m.stateStore.SetHeadIndexCID(ctx, m.logDID, checkpointCID, uploadedCID); err != nil {
+		m.logger.Warn("failed to record index CID in head history", "error", err)
+	}
+}
+
+// recordIndexCAR records an uploaded index CAR, so that garbage collection
+// can remove its CAR blob once the head it covers is superseded.
+func (m *Manager) recordIndexCAR(built *BuiltIndex, meta IndexMeta) {
+	if m.stateStore == nil || m.logDID == "" {
+		return
+	}
+
+	ctx := context.Background()
+	car := storage.IndexCAR{
+		RootCID:       meta.RootCID,
+		ShardCID:      built.ShardCID,
+		Size:          uint64(len(built.Data)),
+		CheckpointCID: built.CheckpointCID,
+		Shards:        built.Shards,
+		UploadedAt:    meta.LastUploaded,
+	}
+	if err := m.stateStore.RecordIndexCAR(ctx, m.logDID, car); err != nil {
+		m.logger.Warn("failed to record index CAR", "error", err)
+	}
+}
+
 // computeIndexHash creates a simple hash of the index for change detection.
 func computeIndexHash(index map[string]string) string {
 	// Simple approach: serialize to JSON and hash
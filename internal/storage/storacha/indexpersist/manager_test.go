@@ -2,13 +2,18 @@
 package indexpersist
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/relves/ucanlog/internal/storage"
+	"github.com/storacha/go-ucanto/core/car"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +22,7 @@ import (
 type mockStateStore struct {
 	mu        sync.Mutex
 	indexMeta map[string]*storage.IndexPersistenceMeta
+	indexCARs []storage.IndexCAR
 }
 
 func newMockStateStore() *mockStateStore {
@@ -48,6 +54,19 @@ func (m *mockStateStore) getIndexMeta(logDID string) *storage.IndexPersistenceMe
 	return m.indexMeta[logDID]
 }
 
+func (m *mockStateStore) RecordIndexCAR(ctx context.Context, logDID string, car storage.IndexCAR) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indexCARs = append(m.indexCARs, car)
+	return nil
+}
+
+func (m *mockStateStore) getIndexCARs() []storage.IndexCAR {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]storage.IndexCAR(nil), m.indexCARs...)
+}
+
 // Stub implementations for unused StateStore methods
 func (m *mockStateStore) GetHead(ctx context.Context, logDID string) (string, uint64, error) {
 	return "", 0, nil
@@ -56,6 +75,12 @@ func (m *mockStateStore) GetCIDIndex(ctx context.Context, logDID string) (map[st
 	return nil, nil
 }
 func (m *mockStateStore) SetCID(ctx context.Context, logDID, path, cid string) error { return nil }
+func (m *mockStateStore) SetCIDWithSize(ctx context.Context, logDID, path, cid string, size uint64) error {
+	return nil
+}
+func (m *mockStateStore) GetCIDSizes(ctx context.Context, logDID string) (map[string]uint64, error) {
+	return nil, nil
+}
 func (m *mockStateStore) SetCIDs(ctx context.Context, logDID string, mappings map[string]string) error {
 	return nil
 }
@@ -65,6 +90,21 @@ func (m *mockStateStore) GetTreeState(ctx context.Context, logDID string) (uint6
 func (m *mockStateStore) SetTreeState(ctx context.Context, logDID string, size uint64, root []byte) error {
 	return nil
 }
+func (m *mockStateStore) SetTreeStateWithAttributions(ctx context.Context, logDID string, size uint64, root []byte, attributions []storage.Attribution) error {
+	return nil
+}
+func (m *mockStateStore) RecordHead(ctx context.Context, logDID string, treeSize uint64, root []byte, checkpointCID string, checkpointSize uint64) error {
+	return nil
+}
+func (m *mockStateStore) GetStaleHeads(ctx context.Context, logDID string, retain int) (*storage.StaleHeads, error) {
+	return &storage.StaleHeads{}, nil
+}
+func (m *mockStateStore) DeleteHeadBlobs(ctx context.Context, logDID string, checkpointCIDs, indexRootCIDs []string) error {
+	return nil
+}
+func (m *mockStateStore) SetHeadIndexCID(ctx context.Context, logDID, checkpointCID, indexCID string) error {
+	return nil
+}
 func (m *mockStateStore) AddRevocation(ctx context.Context, delegationCID string) error { return nil }
 func (m *mockStateStore) IsRevoked(ctx context.Context, delegationCID string) (bool, error) {
 	return false, nil
@@ -308,6 +348,72 @@ func TestManager_PersistsMetadataToStateStore(t *testing.T) {
 	require.NotNil(t, meta)
 	require.NotEmpty(t, meta.LastUploadedCID)
 	require.Equal(t, uint64(1), meta.LastUploadedSize)
+
+	// The uploaded CAR is recorded for garbage collection
+	cars := stateStore.getIndexCARs()
+	require.Len(t, cars, 1)
+	require.Equal(t, meta.LastUploadedCID, cars[0].RootCID)
+	require.Equal(t, "bafkreichgieyp6netvnqaem3syhsi6uvm5z7k5kdtavyx7fw3jn3hl6z54", cars[0].CheckpointCID)
+	require.Equal(t, uint64(len(uploader.uploads[0])), cars[0].Size)
+	hash, err := mh.Sum(uploader.uploads[0], mh.SHA2_256, -1)
+	require.NoError(t, err)
+	require.Equal(t, cid.NewCidV1(cid.Raw, hash).String(), cars[0].ShardCID)
+}
+
+// rootUploader returns the real root CID of the uploaded CARs.
+type rootUploader struct {
+	mockUploader
+}
+
+func (u *rootUploader) UploadCAR(ctx context.Context, data []byte) (string, error) {
+	if _, err := u.mockUploader.UploadCAR(ctx, data); err != nil {
+		return "", err
+	}
+	roots, _, err := car.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	return roots[0].String(), nil
+}
+
+func TestManager_IncrementalUploads(t *testing.T) {
+	ctx := context.Background()
+	stateStore := newMockStateStore()
+	uploader := &rootUploader{}
+	index := map[string]string{
+		"checkpoint":       rawCID(t, "checkpoint 1"),
+		"tile/0/000":       rawCID(t, "tile 0"),
+		"tile/entries/000": rawCID(t, "bundle 0"),
+	}
+	indexProvider := &mockIndexProvider{index: index}
+
+	mgr := NewManager(Config{MinInterval: 0}, uploader, indexProvider)
+	mgr.SetStateStore(stateStore, "did:key:test")
+
+	require.NoError(t, mgr.ForceUpload(ctx))
+	first := mgr.GetMeta()
+	require.Empty(t, first.PreviousRootCID)
+
+	next := maps.Clone(index)
+	next["checkpoint"] = rawCID(t, "checkpoint 2")
+	indexProvider.SetIndex(next)
+	require.NoError(t, mgr.ForceUpload(ctx))
+	second := mgr.GetMeta()
+	require.Equal(t, first.RootCID, second.PreviousRootCID)
+
+	// The second CAR only holds the new root, linked to the first one
+	require.Len(t, uploader.uploads, 2)
+	require.Less(t, len(uploader.uploads[1]), len(uploader.uploads[0]))
+	fetch, _ := carFetcher(t, uploader.uploads[1])
+	prev, err := ResolvePath(ctx, second.RootCID, PreviousLink, fetch)
+	require.NoError(t, err)
+	require.Equal(t, first.RootCID, prev)
+
+	// and depends on the first CAR's blob for the tile directories
+	cars := stateStore.getIndexCARs()
+	require.Len(t, cars, 2)
+	require.Empty(t, cars[0].Shards)
+	require.Equal(t, []string{cars[0].ShardCID}, cars[1].Shards)
 }
 
 func TestTriggerPersistAsync_BasicTrigger(t *testing.T) {
@@ -11,6 +11,14 @@ type IndexMeta struct {
 	// RootCID is the CID of the uploaded UnixFS directory root.
 	RootCID string `json:"root_cid"`
 
+	// PreviousRootCID is the root of the index CAR uploaded before, which
+	// the root directory links to as PreviousLink. Empty for the first one.
+	PreviousRootCID string `json:"previous_root_cid,omitempty"`
+
+	// CheckpointCID is the signed checkpoint the index covers. Following
+	// PreviousRootCID from root to root yields the log's head history.
+	CheckpointCID string `json:"checkpoint_cid,omitempty"`
+
 	// Version is a monotonically increasing counter for each upload.
 	Version uint64 `json:"version"`
 
@@ -39,6 +47,11 @@ type Config struct {
 	// Default: "index/"
 	PathPrefix string
 
+	// HAMTShardingSize is the estimated encoded size, in bytes, above which
+	// an index directory is built as a HAMT.
+	// Default: DefaultHAMTShardingSize (256 KiB)
+	HAMTShardingSize int
+
 	// OnUpload is called after a successful upload with the new root CID.
 	// Optional.
 	OnUpload func(rootCID string, meta IndexMeta)
@@ -81,7 +81,7 @@ func integrateImpl(ctx context.Context, getTiles func(ctx context.Context, tileI
 		return 0, nil, nil, err
 	}
 
-	if err := baseRange.AppendRange(newRange, nil); err != nil {
+	if err := baseRange.AppendRange(newRange, visitor); err != nil {
 		return 0, nil, nil, fmt.Errorf("failed to merge new range onto existing log: %w", err)
 	}
 
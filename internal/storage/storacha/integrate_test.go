@@ -131,3 +131,36 @@ func TestIntegrate_NoEntries(t *testing.T) {
 
 	require.Equal(t, rfc6962.DefaultHasher.EmptyRoot(), root)
 }
+
+func TestIntegrate_CompleteTileAcrossIntegrations(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+	newStore := func() (*logResourceStore, *CIDIndex) {
+		index := NewCIDIndex()
+		objStore := newObjStore(newClientRef(NewMockClient()), index, "did:key:test", "https://w3s.link", slog.Default())
+		return newLogResourceStore(objStore, layout.EntriesPath), index
+	}
+
+	leafHashes := make([][]byte, 266)
+	for i := range leafHashes {
+		leafHashes[i] = rfc6962.DefaultHasher.HashLeaf([]byte{byte(i), byte(i >> 8)})
+	}
+
+	// The parent of the first tile is only formed when the second integration
+	// merges onto the existing range, and must still be stored
+	lrs, index := newStore()
+	_, err := integrate(ctx, 0, leafHashes[:200], lrs, slog.Default())
+	require.NoError(t, err)
+	_, err = integrate(ctx, 200, leafHashes[200:256], lrs, slog.Default())
+	require.NoError(t, err)
+	tPath := layout.TilePath(1, 0, 1)
+	_, ok := index.Get(tPath)
+	require.True(t, ok, "tile should be stored at %s", tPath)
+
+	root, err := integrate(ctx, 256, leafHashes[256:], lrs, slog.Default())
+	require.NoError(t, err)
+
+	want, _ := newStore()
+	wantRoot, err := integrate(ctx, 0, leafHashes, want, slog.Default())
+	require.NoError(t, err)
+	require.Equal(t, wantRoot, root)
+}
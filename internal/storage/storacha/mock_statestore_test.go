@@ -14,10 +14,20 @@ type mockStateStore struct {
 
 	heads       map[string]headState
 	cidIndexes  map[string]map[string]string
+	cidSizes    map[string]map[string]uint64
 	treeStates  map[string]treeState
 	revocations map[string]bool
 	indexMeta   map[string]*storage.IndexPersistenceMeta
 	gcProgress  map[string]uint64
+	headHistory map[string][]headRecord
+	attribution map[string][]storage.Attribution
+}
+
+type headRecord struct {
+	treeSize      uint64
+	root          []byte
+	checkpointCID string
+	indexCID      string
 }
 
 type headState struct {
@@ -34,10 +44,13 @@ func newMockStateStore() *mockStateStore {
 	return &mockStateStore{
 		heads:       make(map[string]headState),
 		cidIndexes:  make(map[string]map[string]string),
+		cidSizes:    make(map[string]map[string]uint64),
 		treeStates:  make(map[string]treeState),
 		revocations: make(map[string]bool),
 		indexMeta:   make(map[string]*storage.IndexPersistenceMeta),
 		gcProgress:  make(map[string]uint64),
+		headHistory: make(map[string][]headRecord),
+		attribution: make(map[string][]storage.Attribution),
 	}
 }
 
@@ -76,9 +89,34 @@ func (m *mockStateStore) SetCID(ctx context.Context, logDID, path, cid string) e
 		m.cidIndexes[logDID] = make(map[string]string)
 	}
 	m.cidIndexes[logDID][path] = cid
+	delete(m.cidSizes[logDID], path)
+	return nil
+}
+
+func (m *mockStateStore) SetCIDWithSize(ctx context.Context, logDID, path, cid string, size uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cidIndexes[logDID] == nil {
+		m.cidIndexes[logDID] = make(map[string]string)
+	}
+	if m.cidSizes[logDID] == nil {
+		m.cidSizes[logDID] = make(map[string]uint64)
+	}
+	m.cidIndexes[logDID][path] = cid
+	m.cidSizes[logDID][path] = size
 	return nil
 }
 
+func (m *mockStateStore) GetCIDSizes(ctx context.Context, logDID string) (map[string]uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string]uint64, len(m.cidSizes[logDID]))
+	for k, v := range m.cidSizes[logDID] {
+		result[k] = v
+	}
+	return result, nil
+}
+
 func (m *mockStateStore) SetCIDs(ctx context.Context, logDID string, mappings map[string]string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -105,6 +143,14 @@ func (m *mockStateStore) SetTreeState(ctx context.Context, logDID string, size u
 	return nil
 }
 
+func (m *mockStateStore) SetTreeStateWithAttributions(ctx context.Context, logDID string, size uint64, root []byte, attributions []storage.Attribution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.treeStates[logDID] = treeState{size: size, root: root}
+	m.attribution[logDID] = append(m.attribution[logDID], attributions...)
+	return nil
+}
+
 func (m *mockStateStore) AddRevocation(ctx context.Context, delegationCID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -155,6 +201,7 @@ func (m *mockStateStore) DeleteCIDsWithPrefix(ctx context.Context, logDID, prefi
 	for path := range idx {
 		if len(path) >= len(prefix) && path[:len(prefix)] == prefix {
 			delete(idx, path)
+			delete(m.cidSizes[logDID], path)
 		}
 	}
 	return nil
@@ -172,3 +219,37 @@ func (m *mockStateStore) SetGCProgress(ctx context.Context, logDID string, fromS
 	m.gcProgress[logDID] = fromSize
 	return nil
 }
+
+func (m *mockStateStore) RecordHead(ctx context.Context, logDID string, treeSize uint64, root []byte, checkpointCID string, checkpointSize uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.headHistory[logDID] = append(m.headHistory[logDID], headRecord{
+		treeSize:      treeSize,
+		root:          root,
+		checkpointCID: checkpointCID,
+	})
+	return nil
+}
+
+func (m *mockStateStore) RecordIndexCAR(ctx context.Context, logDID string, car storage.IndexCAR) error {
+	return nil
+}
+
+func (m *mockStateStore) GetStaleHeads(ctx context.Context, logDID string, retain int) (*storage.StaleHeads, error) {
+	return &storage.StaleHeads{}, nil
+}
+
+func (m *mockStateStore) DeleteHeadBlobs(ctx context.Context, logDID string, checkpointCIDs, indexRootCIDs []string) error {
+	return nil
+}
+
+func (m *mockStateStore) SetHeadIndexCID(ctx context.Context, logDID, checkpointCID, indexCID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.headHistory[logDID] {
+		if m.headHistory[logDID][i].checkpointCID == checkpointCID {
+			m.headHistory[logDID][i].indexCID = indexCID
+		}
+	}
+	return nil
+}
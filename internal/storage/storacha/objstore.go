@@ -7,6 +7,8 @@ import (
 	"sync"
 
 	"github.com/hashicorp/golang-lru/v2"
+	"github.com/relves/ucanlog/internal/storage"
+	"github.com/relves/ucanlog/internal/storage/blobcache"
 	"github.com/storacha/go-ucanto/core/delegation"
 )
 
@@ -30,6 +32,21 @@ func GetDelegation(ctx context.Context) delegation.Delegation {
 	return nil
 }
 
+// attributionContextKey is used to store an entry's attribution in context.
+type attributionContextKey struct{}
+
+// WithAttribution adds the attribution of the entries appended under ctx, so
+// it is recorded in the same transaction that integrates them.
+func WithAttribution(ctx context.Context, attr storage.Attribution) context.Context {
+	return context.WithValue(ctx, attributionContextKey{}, attr)
+}
+
+// GetAttribution retrieves the attribution from context, if present.
+func GetAttribution(ctx context.Context) (storage.Attribution, bool) {
+	attr, ok := ctx.Value(attributionContextKey{}).(storage.Attribution)
+	return attr, ok
+}
+
 // objStore provides object storage operations backed by Storacha.
 // It implements the same interface pattern as AWS/GCP drivers.
 type objStore struct {
@@ -44,6 +61,10 @@ type objStore struct {
 	// blobCache caches fetched blobs by CID to avoid slow gateway re-fetches.
 	// Uses LRU eviction to bound memory usage. Thread-safe. No TTL.
 	blobCache *lru.Cache[string, []byte]
+
+	// diskCache is an optional on-disk tier below blobCache that survives
+	// restarts and is shared between logs.
+	diskCache *blobcache.Cache
 }
 
 // newObjStore creates a new object store.
@@ -98,13 +119,14 @@ func (s *objStore) setObject(ctx context.Context, path string, data []byte) erro
 	s.blobCache.Add(cid, data)
 
 	// Store mapping (syncs to StateStore via CIDIndex)
-	indexErr := s.index.Set(path, cid)
+	indexErr := s.index.SetWithSize(path, cid, uint64(len(data)))
 	onDirty := s.onDirty
 	s.mu.Unlock()
 
 	if indexErr != nil {
 		return fmt.Errorf("failed to sync CID to state store: %w", indexErr)
 	}
+	s.cacheOnDisk(cid, data)
 	s.logger.Debug("setObject", "path", path, "cid", cid, "indexSize", s.index.Size())
 
 	if onDirty != nil {
@@ -127,6 +149,13 @@ func (s *objStore) getObject(ctx context.Context, path string) ([]byte, error) {
 		return data, nil
 	}
 
+	if s.diskCache != nil {
+		if data, ok := s.diskCache.Get(cid); ok {
+			s.blobCache.Add(cid, data)
+			return data, nil
+		}
+	}
+
 	// Cache miss - fetch from gateway
 	s.logger.Debug("blob cache miss", "cid", cid, "path", path)
 	if s.clientRef == nil {
@@ -143,10 +172,44 @@ func (s *objStore) getObject(ctx context.Context, path string) ([]byte, error) {
 
 	// Cache for future reads (LRU handles eviction)
 	s.blobCache.Add(cid, data)
+	s.cacheOnDisk(cid, data)
 
 	return data, nil
 }
 
+// cacheOnDisk stores a blob in the disk cache, if configured. Blobs that do
+// not match their CID are not cached.
+func (s *objStore) cacheOnDisk(cid string, data []byte) {
+	if s.diskCache == nil {
+		return
+	}
+	if err := s.diskCache.Put(cid, data); err != nil {
+		s.logger.Warn("failed to cache blob on disk", "cid", cid, "error", err)
+	}
+}
+
+// fetchObject retrieves the object at path from Storacha, bypassing the blob
+// cache, and returns it with the CID it is indexed under. Used by integrity
+// checks, which must see what storage holds rather than what was uploaded.
+func (s *objStore) fetchObject(ctx context.Context, path string) (string, []byte, error) {
+	cid, ok := s.index.Get(path)
+	if !ok {
+		return "", nil, fmt.Errorf("path not found in index: %s", path)
+	}
+	if s.clientRef == nil {
+		return cid, nil, fmt.Errorf("no Storacha client configured: provide Config.Client")
+	}
+	client := s.clientRef.Get()
+	if client == nil {
+		return cid, nil, fmt.Errorf("no Storacha client configured: provide Config.Client")
+	}
+	data, err := client.FetchBlob(ctx, cid)
+	if err != nil {
+		return cid, nil, fmt.Errorf("failed to fetch from Storacha: %w", err)
+	}
+	return cid, data, nil
+}
+
 // setObjectIfNoneMatch uploads only if the path doesn't already exist.
 // Returns (true, nil) if written, (false, nil) if already exists.
 // UploadBlob fails if accept receipt polling fails, ensuring we only store CIDs for confirmed blobs.
@@ -188,13 +251,14 @@ func (s *objStore) setObjectIfNoneMatch(ctx context.Context, path string, data [
 	s.blobCache.Add(cid, data)
 
 	// Store mapping (syncs to StateStore via CIDIndex)
-	indexErr := s.index.Set(path, cid)
+	indexErr := s.index.SetWithSize(path, cid, uint64(len(data)))
 	onDirty := s.onDirty
 	s.mu.Unlock()
 
 	if indexErr != nil {
 		return false, fmt.Errorf("failed to sync CID to state store: %w", indexErr)
 	}
+	s.cacheOnDisk(cid, data)
 	s.logger.Debug("setObjectIfNoneMatch", "path", path, "cid", cid, "indexSize", s.index.Size())
 
 	if onDirty != nil {
@@ -234,6 +298,12 @@ func (s *objStore) GetCID(path string) string {
 	return cid
 }
 
+// GetSize returns the size of the blob stored for a path, if known.
+// Implements gc.PathStore interface.
+func (s *objStore) GetSize(path string) (uint64, bool) {
+	return s.index.GetSize(path)
+}
+
 // DeletePrefix removes all path mappings with the given prefix.
 // Implements gc.PathStore interface.
 func (s *objStore) DeletePrefix(prefix string) int {
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/relves/ucanlog/internal/storage/blobcache"
 	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
 	"github.com/storacha/go-ucanto/core/delegation"
 	"github.com/stretchr/testify/require"
@@ -216,3 +218,27 @@ func TestObjStore_MarksDirtyOnWrite(t *testing.T) {
 
 	require.Equal(t, 1, dirtyCount)
 }
+
+func TestObjStore_DiskCacheSurvivesRestart(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+	client, err := NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	cache, err := blobcache.New(filepath.Join(t.TempDir(), "cache"), 1<<20, slog.Default())
+	require.NoError(t, err)
+	index := NewCIDIndex()
+
+	store := newObjStore(newClientRef(client), index, "did:key:test", "https://w3s.link", slog.Default())
+	store.diskCache = cache
+	path := "tile/0/000"
+	data := []byte("hash tile")
+	require.NoError(t, store.setObject(ctx, path, data))
+
+	// A fresh store with an empty memory cache reads from disk, not the client
+	restarted := newObjStore(newClientRef(&tamperingClient{StorachaClient: client, forged: map[string][]byte{
+		store.getCID(path): []byte("forged"),
+	}}), index, "did:key:test", "https://w3s.link", slog.Default())
+	restarted.diskCache = cache
+	got, err := restarted.getObject(ctx, path)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
@@ -2,26 +2,45 @@ package storacha
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/storacha/go-ucanto/core/delegation"
 	"github.com/transparency-dev/tessera"
 )
 
+// entryQueue batches entries added by concurrent callers so that they share
+// one integration and one set of tile uploads. Each item keeps its caller's
+// context: writes need a delegation, which arrives with each request, so a
+// batch is flushed under the delegation of one of its own items instead of
+// the context of whichever caller happened to trigger the flush.
 type entryQueue struct {
 	maxSize uint
 	maxAge  time.Duration
 	flushFn func(context.Context, []queueItem) error
 
-	mu       sync.Mutex
-	items    []queueItem
-	timer    *time.Timer
-	flushing bool
+	mu    sync.Mutex
+	items []queueItem
+	timer *time.Timer
+
+	// flushMu serializes flushes so batches are sequenced in the order their
+	// entries were added.
+	flushMu sync.Mutex
+
+	// stopped is set once the appender is shut down. Add is refused by the
+	// appender itself, but AddBatch bypasses it and checks this instead.
+	stopped bool
 }
 
 type queueItem struct {
+	ctx    context.Context // caller's context, carrying its delegation and deadline
 	entry  *tessera.Entry
 	result chan queueResult
+
+	// group is the number of items, starting with this one, added together
+	// by AddBatch. They are always flushed in the same batch.
+	group int
 }
 
 type queueResult struct {
@@ -29,7 +48,13 @@ type queueResult struct {
 	err   error
 }
 
+// newEntryQueue creates a queue that flushes when maxSize entries are queued
+// or the oldest queued entry is maxAge old. A maxAge of 0 flushes on every
+// add, still coalescing entries added while a flush is running.
 func newEntryQueue(ctx context.Context, maxAge time.Duration, maxSize uint, flushFn func(context.Context, []queueItem) error) *entryQueue {
+	if maxSize == 0 {
+		maxSize = 1
+	}
 	q := &entryQueue{
 		maxSize: maxSize,
 		maxAge:  maxAge,
@@ -46,29 +71,21 @@ func (q *entryQueue) Add(ctx context.Context, entry *tessera.Entry) tessera.Inde
 	q.mu.Lock()
 
 	q.items = append(q.items, queueItem{
+		ctx:    ctx,
 		entry:  entry,
 		result: resultCh,
 	})
 
-	shouldFlush := len(q.items) >= int(q.maxSize)
+	shouldFlush := len(q.items) >= int(q.maxSize) || q.maxAge <= 0
 
 	if len(q.items) == 1 && q.maxAge > 0 {
-		q.timer = time.AfterFunc(q.maxAge, func() {
-			q.flush(context.Background())
-		})
+		q.timer = time.AfterFunc(q.maxAge, q.flush)
 	}
 
-	if shouldFlush {
-		if q.timer != nil {
-			q.timer.Stop()
-		}
-		items := q.items
-		q.items = make([]queueItem, 0, q.maxSize)
-		q.mu.Unlock()
+	q.mu.Unlock()
 
-		go q.doFlush(ctx, items)
-	} else {
-		q.mu.Unlock()
+	if shouldFlush {
+		go q.flush()
 	}
 
 	return func() (tessera.Index, error) {
@@ -77,39 +94,202 @@ func (q *entryQueue) Add(ctx context.Context, entry *tessera.Entry) tessera.Inde
 	}
 }
 
-func (q *entryQueue) flush(ctx context.Context) {
+// AddBatch queues entries to be flushed together in one batch, so they are
+// integrated at once and receive contiguous indices. It flushes without
+// waiting for the timer, as the batch is already complete.
+func (q *entryQueue) AddBatch(ctx context.Context, entries []*tessera.Entry) ([]tessera.IndexFuture, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if len(entries) > int(q.maxSize) {
+		return nil, fmt.Errorf("batch of %d entries exceeds maximum batch size %d", len(entries), q.maxSize)
+	}
+
+	futures := make([]tessera.IndexFuture, len(entries))
+
 	q.mu.Lock()
-	if len(q.items) == 0 {
+	if q.stopped {
 		q.mu.Unlock()
-		return
+		return nil, ErrShutDown
+	}
+	for i, entry := range entries {
+		resultCh := make(chan queueResult, 1)
+		item := queueItem{
+			ctx:    ctx,
+			entry:  entry,
+			result: resultCh,
+		}
+		if i == 0 {
+			item.group = len(entries)
+		}
+		q.items = append(q.items, item)
+		futures[i] = func() (tessera.Index, error) {
+			result := <-resultCh
+			return result.index, result.err
+		}
 	}
-
-	items := q.items
-	q.items = make([]queueItem, 0, q.maxSize)
 	q.mu.Unlock()
 
-	q.doFlush(ctx, items)
+	go q.flush()
+
+	return futures, nil
+}
+
+// batchLen returns how many queued items the next flush takes: at most
+// maxSize, without splitting items added together by AddBatch.
+// It must be called with q.mu held.
+func (q *entryQueue) batchLen() int {
+	n := 0
+	for n < len(q.items) {
+		size := max(q.items[n].group, 1)
+		if n+size > int(q.maxSize) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// flush integrates the queued entries, at most maxSize at a time, until fewer
+// than a full batch remain. Remaining entries wait for the next timer.
+// Entries added together by AddBatch are never split across flushes.
+func (q *entryQueue) flush() {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	for {
+		q.mu.Lock()
+		n := q.batchLen()
+		if n == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		items := q.items[:n:n]
+		q.items = append(make([]queueItem, 0, q.maxSize), q.items[n:]...)
+		if q.timer != nil {
+			q.timer.Stop()
+			q.timer = nil
+		}
+		if len(q.items) > 0 && q.maxAge > 0 {
+			q.timer = time.AfterFunc(q.maxAge, q.flush)
+		}
+		q.mu.Unlock()
+
+		q.doFlush(items)
+
+		// A batch from AddBatch left behind by a full flush is already
+		// complete, so it does not wait for the timer either
+		q.mu.Lock()
+		more := len(q.items) >= int(q.maxSize) || (q.maxAge <= 0 && len(q.items) > 0) ||
+			(len(q.items) > 0 && q.items[0].group > 1)
+		q.mu.Unlock()
+		if !more {
+			return
+		}
+	}
 }
 
-func (q *entryQueue) doFlush(ctx context.Context, items []queueItem) {
-	if len(items) == 0 {
+func (q *entryQueue) doFlush(items []queueItem) {
+	// Callers that gave up while queued are answered now and left out of the
+	// batch, so their entries are not sequenced behind their back.
+	live := make([]queueItem, 0, len(items))
+	for _, item := range items {
+		if err := item.ctx.Err(); err != nil {
+			item.result <- queueResult{err: err}
+			continue
+		}
+		live = append(live, item)
+	}
+	if len(live) == 0 {
 		return
 	}
 
-	err := q.flushFn(ctx, items)
+	ctx, cancel, err := batchContext(live)
+	if err == nil {
+		defer cancel()
+		err = q.flushFn(ctx, live)
+	}
 
 	// Only send error results here - on success, flushFn already sent results
 	// with the correct indices via the result channels
 	if err != nil {
-		q.mu.Lock()
-		defer q.mu.Unlock()
-
-		for _, item := range items {
+		for _, item := range live {
 			item.result <- queueResult{err: err}
 		}
 	}
 }
 
+// batchContext returns the context a batch is flushed with. It carries the
+// values of the item with the latest-expiring unexpired delegation, is not
+// cancelled when that item's caller returns, and ends at the latest of the
+// items' deadlines. Items without a delegation are only flushed without one
+// if no item in the batch has one.
+func batchContext(items []queueItem) (context.Context, context.CancelFunc, error) {
+	now := time.Now().Unix()
+
+	var base context.Context
+	var baseDlg delegation.Delegation
+	expired := 0
+	for _, item := range items {
+		dlg := GetDelegation(item.ctx)
+		if dlg == nil {
+			continue
+		}
+		exp := dlg.Expiration()
+		if exp != nil && int64(*exp) <= now {
+			expired++
+			continue
+		}
+		if baseDlg == nil || expiresAfter(dlg, baseDlg) {
+			base, baseDlg = item.ctx, dlg
+		}
+	}
+	if baseDlg == nil {
+		if expired > 0 {
+			return nil, nil, fmt.Errorf("no unexpired delegation in batch of %d entries", len(items))
+		}
+		base = items[0].ctx
+	}
+	base = context.WithoutCancel(base)
+
+	var latest time.Time
+	for _, item := range items {
+		deadline, ok := item.ctx.Deadline()
+		if !ok {
+			ctx, cancel := context.WithCancel(base)
+			return ctx, cancel, nil
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+	ctx, cancel := context.WithDeadline(base, latest)
+	return ctx, cancel, nil
+}
+
+// expiresAfter reports whether a expires later than b. A delegation without
+// expiration expires after any other.
+func expiresAfter(a, b delegation.Delegation) bool {
+	expA, expB := a.Expiration(), b.Expiration()
+	switch {
+	case expA == nil:
+		return expB != nil
+	case expB == nil:
+		return false
+	default:
+		return *expA > *expB
+	}
+}
+
+// stop refuses batches added after the appender is shut down. Entries
+// already queued are still flushed.
+func (q *entryQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+}
+
 func (q *entryQueue) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
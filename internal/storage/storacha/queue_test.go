@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/relves/ucanlog/internal/storage"
 	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/storacha/go-ucanto/core/delegation"
 	"github.com/stretchr/testify/require"
 	"github.com/transparency-dev/tessera"
 )
@@ -63,10 +65,6 @@ func TestQueue_BatchedAdd(t *testing.T) {
 }
 
 func TestQueue_FlushOnMaxAge(t *testing.T) {
-	// Skip: Timer-based batching is disabled in Appender (maxAge forced to 0)
-	// because it's incompatible with per-request delegations.
-	t.Skip("timer-based batching disabled in Appender")
-
 	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
 
 	mockClient := NewMockClient()
@@ -156,3 +154,188 @@ func TestQueue_MaxSizeFlush(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint64(5), size)
 }
+
+func TestQueue_TimerFlushSharesBatch(t *testing.T) {
+	dlgA := storachatest.MockDelegation()
+	dlgB := storachatest.MockDelegation()
+
+	var mu sync.Mutex
+	var batches [][]queueItem
+	var flushDlg delegation.Delegation
+	q := newEntryQueue(context.Background(), 50*time.Millisecond, 100, func(ctx context.Context, items []queueItem) error {
+		mu.Lock()
+		batches = append(batches, items)
+		flushDlg = GetDelegation(ctx)
+		mu.Unlock()
+		for i, item := range items {
+			item.result <- queueResult{index: tessera.Index{Index: uint64(i)}}
+		}
+		return nil
+	})
+	defer q.Close()
+
+	// Concurrent callers with their own delegations, one of which returns
+	// before the timer fires
+	ctxA, cancelA := context.WithCancel(WithDelegation(context.Background(), dlgA))
+	futureA := q.Add(ctxA, tessera.NewEntry([]byte("a")))
+	futureB := q.Add(WithDelegation(context.Background(), dlgB), tessera.NewEntry([]byte("b")))
+	futureC := q.Add(WithDelegation(context.Background(), dlgA), tessera.NewEntry([]byte("c")))
+	cancelA()
+
+	_, err := futureA()
+	require.ErrorIs(t, err, context.Canceled)
+	idx, err := futureB()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), idx.Index)
+	idx, err = futureC()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx.Index)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 1, "timer flush should integrate both live entries at once")
+	require.Len(t, batches[0], 2)
+	require.NotNil(t, flushDlg, "timer flush must carry a delegation from the batch")
+}
+
+func TestQueue_BatchContext(t *testing.T) {
+	dlg := storachatest.MockDelegation()
+	expired := storachatest.MockDelegation(delegation.WithExpiration(int(time.Now().Add(-time.Hour).Unix())))
+
+	t.Run("skips expired delegations", func(t *testing.T) {
+		ctx, cancel, err := batchContext([]queueItem{
+			{ctx: WithDelegation(context.Background(), expired)},
+			{ctx: WithDelegation(context.Background(), dlg)},
+		})
+		require.NoError(t, err)
+		defer cancel()
+		require.Equal(t, dlg.Link(), GetDelegation(ctx).Link())
+	})
+
+	t.Run("fails when all delegations expired", func(t *testing.T) {
+		_, _, err := batchContext([]queueItem{
+			{ctx: WithDelegation(context.Background(), expired)},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("ends at latest deadline", func(t *testing.T) {
+		soon, cancelSoon := context.WithTimeout(WithDelegation(context.Background(), dlg), time.Minute)
+		defer cancelSoon()
+		later, cancelLater := context.WithTimeout(context.Background(), time.Hour)
+		defer cancelLater()
+
+		ctx, cancel, err := batchContext([]queueItem{{ctx: soon}, {ctx: later}})
+		require.NoError(t, err)
+		defer cancel()
+		laterDeadline, _ := later.Deadline()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.Equal(t, laterDeadline, deadline)
+
+		// Flushes outlive the caller whose delegation they use
+		cancelSoon()
+		require.NoError(t, ctx.Err())
+	})
+}
+
+func TestQueue_AddBatchFlushedTogether(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+
+	var mu sync.Mutex
+	var batches []int
+	next := uint64(0)
+	q := newEntryQueue(context.Background(), time.Hour, 4, func(ctx context.Context, items []queueItem) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, len(items))
+		for _, item := range items {
+			item.result <- queueResult{index: tessera.Index{Index: next}}
+			next++
+		}
+		return nil
+	})
+	defer q.Close()
+
+	_, err := q.AddBatch(ctx, make([]*tessera.Entry, 5))
+	require.Error(t, err, "a batch larger than the maximum batch size must be rejected")
+
+	// Three single entries wait for the timer; a batch of three behind them
+	// would overflow a full flush and must not be split across two
+	singles := make([]tessera.IndexFuture, 3)
+	for i := range singles {
+		singles[i] = q.Add(ctx, tessera.NewEntry([]byte{byte(i)}))
+	}
+	entries := make([]*tessera.Entry, 3)
+	for i := range entries {
+		entries[i] = tessera.NewEntry([]byte{byte(10 + i)})
+	}
+	futures, err := q.AddBatch(ctx, entries)
+	require.NoError(t, err)
+
+	// Neither flush may wait for the hour-long timer
+	for i, future := range append(singles, futures...) {
+		idx, err := future()
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), idx.Index)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{3, 3}, batches)
+}
+
+func TestQueue_AddBatchAfterStop(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+
+	q := newEntryQueue(context.Background(), 0, 4, func(ctx context.Context, items []queueItem) error {
+		for i, item := range items {
+			item.result <- queueResult{index: tessera.Index{Index: uint64(i)}}
+		}
+		return nil
+	})
+	defer q.Close()
+
+	q.stop()
+	_, err := q.AddBatch(ctx, []*tessera.Entry{tessera.NewEntry([]byte("late"))})
+	require.ErrorIs(t, err, ErrShutDown)
+}
+
+func TestQueue_RecordsAttributionWithTreeState(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+
+	stateStore := newMockStateStore()
+	driver, err := New(ctx, Config{
+		SpaceDID:   "did:key:z6MkwDuRThQcyWjqNsK54yKAmzfsiH6BTkASyiucThMtHt1y",
+		StateStore: stateStore,
+		LogDID:     "did:key:test",
+		Client:     NewMockClient(),
+	})
+	require.NoError(t, err)
+
+	opts := tessera.NewAppendOptions().
+		WithCheckpointSigner(&dummySigner{}).
+		WithBatching(3, 10*time.Second)
+	appender, _, err := driver.(*Storage).Appender(ctx, opts)
+	require.NoError(t, err)
+
+	// Entries without an attribution, such as revocation log entries, are
+	// integrated alongside attributed ones
+	alice := storage.Attribution{InvocationCID: "bafyInvA", IssuerDID: "did:key:z6MkAlice"}
+	bob := storage.Attribution{InvocationCID: "bafyInvB", IssuerDID: "did:key:z6MkBob"}
+	futures := []tessera.IndexFuture{
+		appender.Add(WithAttribution(ctx, alice), tessera.NewEntry([]byte("a"))),
+		appender.Add(ctx, tessera.NewEntry([]byte("b"))),
+		appender.Add(WithAttribution(ctx, bob), tessera.NewEntry([]byte("c"))),
+	}
+	for _, future := range futures {
+		_, err := future()
+		require.NoError(t, err)
+	}
+
+	alice.Index, bob.Index = 0, 2
+	stateStore.mu.Lock()
+	defer stateStore.mu.Unlock()
+	require.Equal(t, []storage.Attribution{alice, bob}, stateStore.attribution["did:key:test"])
+	require.Equal(t, uint64(3), stateStore.treeStates["did:key:test"].size)
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/relves/ucanlog/internal/storage"
+	"github.com/relves/ucanlog/internal/storage/blobcache"
 	"github.com/relves/ucanlog/internal/storage/storacha/gc"
 	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
 	"github.com/storacha/go-ucanto/core/delegation"
@@ -55,6 +56,14 @@ type Config struct {
 	// If nil, GC is disabled.
 	GC *gc.Config
 
+	// BlobCache is an optional on-disk cache of blobs by CID, below the
+	// in-memory cache. It can be shared between logs.
+	BlobCache *blobcache.Cache
+
+	// OnCheckpoint is called with each checkpoint published after entries
+	// are integrated, once it is stored. It must not block.
+	OnCheckpoint func(logDID string, size uint64, checkpoint []byte)
+
 	// Logger for structured logging.
 	// Default: slog.Default()
 	Logger *slog.Logger
@@ -69,6 +78,7 @@ type Storage struct {
 	objStore        *objStore
 	indexPersistMgr *indexpersist.Manager
 	gcMgr           *gc.Manager
+	queue           *entryQueue
 	logger          *slog.Logger
 }
 
@@ -105,9 +115,14 @@ func New(ctx context.Context, cfg Config) (tessera.Driver, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load CID index: %w", err)
 	}
+	cidSizes, err := cfg.StateStore.GetCIDSizes(ctx, cfg.LogDID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CID index sizes: %w", err)
+	}
 
 	// Create index wrapper with StateStore sync
 	index := NewCIDIndexFromMap(cidIndex)
+	index.SetSizes(cidSizes)
 	index.SetStateStore(cfg.StateStore, cfg.LogDID)
 	index.SetLogger(cfg.Logger)
 
@@ -120,6 +135,7 @@ func New(ctx context.Context, cfg Config) (tessera.Driver, error) {
 
 	// Create objStore (no longer needs stateDir)
 	objStore := newObjStore(ref, index, cfg.SpaceDID, cfg.GatewayURL, cfg.Logger)
+	objStore.diskCache = cfg.BlobCache
 
 	// Set up index persistence if configured
 	var indexPersistMgr *indexpersist.Manager
@@ -241,16 +257,29 @@ func (s *Storage) TriggerIndexPersistence(ctx context.Context) {
 
 // GCResult contains the results of a garbage collection run.
 type GCResult struct {
-	BundlesProcessed int    // Number of bundles processed
-	BlobsRemoved     int    // Number of blobs removed
-	BytesFreed       uint64 // Bytes freed (estimated)
-	NewGCPosition    uint64 // New GC checkpoint position
+	BundlesProcessed int       // Number of bundles processed
+	BlobsRemoved     int       // Number of blobs removed
+	BytesFreed       uint64    // Total size of the removed blobs with a known size
+	UnknownSizes     int       // Removed blobs uploaded before sizes were tracked
+	NewGCPosition    uint64    // New GC checkpoint position
+	Blobs            []gc.Blob // Obsolete blobs found by the run
+	DryRun           bool      // Nothing was removed, see PlanGC
 }
 
 // RunGC runs garbage collection synchronously with the provided delegation.
 // The delegation must include space/blob/remove capability.
 // Returns the number of bundles processed and any errors.
 func (s *Storage) RunGC(ctx context.Context, dlg delegation.Delegation) (*GCResult, error) {
+	return s.runGC(ctx, dlg, false)
+}
+
+// PlanGC is a dry run of RunGC: it lists the blobs the next run would remove
+// and their total size, without removing them or advancing GC progress.
+func (s *Storage) PlanGC(ctx context.Context) (*GCResult, error) {
+	return s.runGC(ctx, nil, true)
+}
+
+func (s *Storage) runGC(ctx context.Context, dlg delegation.Delegation, dryRun bool) (*GCResult, error) {
 	s.mu.Lock()
 	mgr := s.gcMgr
 	s.mu.Unlock()
@@ -264,9 +293,6 @@ func (s *Storage) RunGC(ctx context.Context, dlg delegation.Delegation) (*GCResu
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tree state: %w", err)
 	}
-	if treeSize == 0 {
-		return &GCResult{}, nil // Nothing to collect
-	}
 
 	// Get GC progress from state store
 	fromSize, err := s.cfg.StateStore.GetGCProgress(ctx, s.cfg.LogDID)
@@ -274,29 +300,28 @@ func (s *Storage) RunGC(ctx context.Context, dlg delegation.Delegation) (*GCResu
 		return nil, fmt.Errorf("failed to get GC progress: %w", err)
 	}
 
-	if fromSize >= treeSize {
-		return &GCResult{NewGCPosition: fromSize}, nil // Already up to date
+	// Run GC using the manager's RunGCSync method. Partials are collected up
+	// to the tree size, past heads regardless of the GC position.
+	run, err := mgr.RunGCSync(ctx, fromSize, treeSize, dlg, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("garbage collection failed: %w", err)
 	}
 
-	// Run GC using the manager's RunGCSync method
 	result := &GCResult{
-		BlobsRemoved: 0,
-		BytesFreed:   0,
+		BundlesProcessed: run.BundlesProcessed,
+		BlobsRemoved:     run.BlobsRemoved,
+		BytesFreed:       run.BytesFreed,
+		UnknownSizes:     run.UnknownSizes,
+		NewGCPosition:    run.NewFromSize,
+		Blobs:            run.Blobs,
+		DryRun:           dryRun,
 	}
-
-	newFromSize, blobsRemoved, err := mgr.RunGCSync(ctx, fromSize, treeSize, dlg)
-	if err != nil {
-		return nil, fmt.Errorf("garbage collection failed: %w", err)
+	if dryRun || run.NewFromSize == fromSize {
+		return result, nil
 	}
 
-	// Calculate bundles processed (256 entries per bundle)
-	bundlesProcessed := int((newFromSize - fromSize) / 256)
-	result.BundlesProcessed = bundlesProcessed
-	result.BlobsRemoved = blobsRemoved
-	result.NewGCPosition = newFromSize
-
 	// Update progress in state store
-	if err := s.cfg.StateStore.SetGCProgress(ctx, s.cfg.LogDID, newFromSize); err != nil {
+	if err := s.cfg.StateStore.SetGCProgress(ctx, s.cfg.LogDID, run.NewFromSize); err != nil {
 		return nil, fmt.Errorf("failed to save GC progress: %w", err)
 	}
 
@@ -143,6 +143,44 @@ func TestStorage_Appender(t *testing.T) {
 	require.NotNil(t, reader)
 }
 
+func TestStorage_AppenderRecordsHeadHistory(t *testing.T) {
+	ctx := WithDelegation(context.Background(), storachatest.MockDelegation())
+
+	stateStore := newMockStateStore()
+	driver, err := New(ctx, Config{
+		SpaceDID:   "did:key:z6MkwDuRThQcyWjqNsK54yKAmzfsiH6BTkASyiucThMtHt1y",
+		StateStore: stateStore,
+		LogDID:     "did:key:test",
+		Client:     NewMockClient(),
+	})
+	require.NoError(t, err)
+
+	storage := driver.(*Storage)
+	opts := tessera.NewAppendOptions().
+		WithCheckpointSigner(&dummySigner{}).
+		WithBatching(1, time.Second)
+
+	appender, _, err := storage.Appender(ctx, opts)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := appender.Add(ctx, tessera.NewEntry([]byte{byte(i)}))()
+		require.NoError(t, err)
+	}
+
+	stateStore.mu.Lock()
+	history := stateStore.headHistory["did:key:test"]
+	stateStore.mu.Unlock()
+
+	require.Len(t, history, 3)
+	for i, head := range history {
+		require.Equal(t, uint64(i+1), head.treeSize)
+		require.NotEmpty(t, head.root)
+		require.NotEmpty(t, head.checkpointCID)
+	}
+	require.Equal(t, storage.objStore.GetCID("checkpoint"), history[2].checkpointCID)
+}
+
 type dummySigner struct{}
 
 func (d *dummySigner) Name() string                { return "test" }
@@ -9,7 +9,8 @@ import (
 
 // MockDelegation creates a mock delegation for testing purposes.
 // This is useful for tests that need a valid delegation but don't care about its content.
-func MockDelegation() delegation.Delegation {
+// Options such as delegation.WithExpiration are passed to delegation.Delegate.
+func MockDelegation(opts ...delegation.Option) delegation.Delegation {
 	// Create a test signer
 	signer, err := signer.Generate()
 	if err != nil {
@@ -23,6 +24,7 @@ func MockDelegation() delegation.Delegation {
 		[]ucan.Capability[ucan.NoCaveats]{
 			ucan.NewCapability("space/blob/add", signer.DID().String(), ucan.NoCaveats{}),
 		},
+		opts...,
 	)
 	if err != nil {
 		panic(err)
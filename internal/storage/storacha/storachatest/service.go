@@ -0,0 +1,591 @@
+package storachatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	basicnode "github.com/ipld/go-ipld-prime/node/basic"
+	mh "github.com/multiformats/go-multihash"
+	blobcap "github.com/storacha/go-libstoracha/capabilities/blob"
+	httpcap "github.com/storacha/go-libstoracha/capabilities/http"
+	spaceblobcap "github.com/storacha/go-libstoracha/capabilities/space/blob"
+	contentcap "github.com/storacha/go-libstoracha/capabilities/space/content"
+	spaceindexcap "github.com/storacha/go-libstoracha/capabilities/space/index"
+	"github.com/storacha/go-libstoracha/capabilities/types"
+	ucancap "github.com/storacha/go-libstoracha/capabilities/ucan"
+	uploadcap "github.com/storacha/go-libstoracha/capabilities/upload"
+	"github.com/storacha/go-ucanto/core/dag/blockstore"
+	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/storacha/go-ucanto/core/invocation"
+	"github.com/storacha/go-ucanto/core/ipld"
+	"github.com/storacha/go-ucanto/core/receipt"
+	"github.com/storacha/go-ucanto/core/receipt/fx"
+	"github.com/storacha/go-ucanto/core/receipt/ran"
+	"github.com/storacha/go-ucanto/core/result"
+	"github.com/storacha/go-ucanto/core/result/failure"
+	"github.com/storacha/go-ucanto/did"
+	"github.com/storacha/go-ucanto/principal"
+	"github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/storacha/go-ucanto/server"
+	thttp "github.com/storacha/go-ucanto/transport/http"
+	"github.com/storacha/go-ucanto/ucan"
+)
+
+// Service is an in-process fake of the Storacha upload service, receipts API
+// and IPFS gateway, for end-to-end tests of the delegated client.
+//
+// Invocations are validated like the hosted service does, so clients need a
+// delegation from the space (see SpaceDelegation). space/blob/add answers with
+// the same effects as the hosted service: a blob/allocate receipt carrying a
+// PUT address, an http/put task the client concludes, and a blob/accept task
+// whose receipt is published on the receipts endpoint once the blob was PUT
+// and the put concluded.
+//
+// All spaces share one blob store, and blobs are served by any raw or CAR CID
+// of their multihash at /ipfs/{cid}.
+type Service struct {
+	server *httptest.Server
+	signer principal.Signer
+
+	mu      sync.Mutex
+	blobs   map[string][]byte       // multihash bytes -> blob
+	puts    map[string]*pendingBlob // http/put task -> pending blob
+	accepts map[string]*pendingBlob // blob/accept task -> pending blob
+	indexes []ipld.Link             // registered index CARs
+	uploads map[string][]ipld.Link  // upload root -> shards
+	calls   map[string]int          // ability -> handled invocations
+	failPut int                     // PUTs left to fail
+	failGet int                     // gateway requests left to fail
+	delay   int                     // polls answered "no receipt" per task
+	putN    int                     // accepted PUTs
+}
+
+// pendingBlob tracks a blob/add from allocation to acceptance.
+type pendingBlob struct {
+	blob      types.Blob
+	putSigner did.DID
+	accept    invocation.Invocation
+	concluded bool
+	polls     int
+	receipt   []byte // archived blob/accept receipt, once issued
+}
+
+// NewService starts a fake Storacha service. Close it when done.
+func NewService() (*Service, error) {
+	id, err := signer.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate service signer: %w", err)
+	}
+
+	s := &Service{
+		signer:  id,
+		blobs:   make(map[string][]byte),
+		puts:    make(map[string]*pendingBlob),
+		accepts: make(map[string]*pendingBlob),
+		uploads: make(map[string][]ipld.Link),
+		calls:   make(map[string]int),
+	}
+
+	ucantoServer, err := server.NewServer(
+		id,
+		server.WithServiceMethod(spaceblobcap.AddAbility, server.Provide(spaceblobcap.Add, s.blobAdd)),
+		server.WithServiceMethod(spaceblobcap.RemoveAbility, server.Provide(spaceblobcap.Remove, s.blobRemove)),
+		server.WithServiceMethod(ucancap.ConcludeAbility, server.Provide(ucancap.Conclude, s.conclude)),
+		server.WithServiceMethod(spaceindexcap.AddAbility, server.Provide(spaceindexcap.Add, s.indexAdd)),
+		server.WithServiceMethod(uploadcap.AddAbility, server.Provide(uploadcap.Add, s.uploadAdd)),
+		server.WithServiceMethod(contentcap.RetrieveAbility, server.Provide(contentcap.Retrieve, s.retrieve)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ucanto server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		res, err := ucantoServer.Request(r.Context(), thttp.NewRequest(r.Body, r.Header))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for name, values := range res.Headers() {
+			for _, value := range values {
+				w.Header().Add(name, value)
+			}
+		}
+		if res.Status() != 0 {
+			w.WriteHeader(res.Status())
+		}
+		body := res.Body()
+		io.Copy(w, body)
+		body.Close()
+	})
+	mux.HandleFunc("PUT /blob/{cid}", s.handlePut)
+	mux.HandleFunc("GET /receipt/{task}", s.handleReceipt)
+	mux.HandleFunc("GET /ipfs/{cid}", s.handleGateway)
+
+	s.server = httptest.NewServer(mux)
+	return s, nil
+}
+
+// Close shuts the service down.
+func (s *Service) Close() {
+	s.server.Close()
+}
+
+// DID returns the service DID clients invoke.
+func (s *Service) DID() string {
+	return s.signer.DID().String()
+}
+
+// URL returns the ucanto endpoint URL.
+func (s *Service) URL() string {
+	return s.server.URL
+}
+
+// ReceiptsURL returns the receipts API endpoint.
+func (s *Service) ReceiptsURL() string {
+	return s.server.URL + "/receipt"
+}
+
+// GatewayURL returns the IPFS gateway URL.
+func (s *Service) GatewayURL() string {
+	return s.server.URL
+}
+
+// FailPuts makes the next n blob PUTs fail with 503.
+func (s *Service) FailPuts(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failPut = n
+}
+
+// FailGateway makes the next n gateway requests fail with 502.
+func (s *Service) FailGateway(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failGet = n
+}
+
+// DelayReceipts makes the receipts endpoint answer "no receipt" to the first
+// n polls for each blob/accept task, as the hosted service does while a blob
+// is being accepted.
+func (s *Service) DelayReceipts(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = n
+}
+
+// Blob returns a stored blob by multihash.
+func (s *Service) Blob(digest mh.Multihash) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[string(digest)]
+	return data, ok
+}
+
+// Upload returns the shards registered for an upload root.
+func (s *Service) Upload(root cid.Cid) ([]ipld.Link, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shards, ok := s.uploads[root.String()]
+	return shards, ok
+}
+
+// Indexes returns the index CARs registered with space/index/add.
+func (s *Service) Indexes() []ipld.Link {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ipld.Link(nil), s.indexes...)
+}
+
+// Calls returns the number of invocations of an ability that passed
+// validation.
+func (s *Service) Calls(ability string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[ability]
+}
+
+// Puts returns the number of blob PUTs that were stored.
+func (s *Service) Puts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.putN
+}
+
+// SpaceDelegation creates a new space and delegates the abilities the
+// delegated client invokes on it to agent.
+func SpaceDelegation(agent ucan.Principal, opts ...delegation.Option) (did.DID, delegation.Delegation, error) {
+	space, err := signer.Generate()
+	if err != nil {
+		return did.DID{}, nil, err
+	}
+
+	var caps []ucan.Capability[ucan.NoCaveats]
+	for _, ability := range []string{
+		spaceblobcap.AddAbility,
+		spaceblobcap.RemoveAbility,
+		spaceindexcap.AddAbility,
+		uploadcap.AddAbility,
+		contentcap.RetrieveAbility,
+	} {
+		caps = append(caps, ucan.NewCapability(ability, space.DID().String(), ucan.NoCaveats{}))
+	}
+
+	dlg, err := delegation.Delegate(space, agent, caps, opts...)
+	if err != nil {
+		return did.DID{}, nil, err
+	}
+	return space.DID(), dlg, nil
+}
+
+func (s *Service) called(ability string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[ability]++
+}
+
+func (s *Service) blobAdd(
+	ctx context.Context,
+	cap ucan.Capability[spaceblobcap.AddCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[spaceblobcap.AddOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(spaceblobcap.AddAbility)
+
+	space, err := did.Parse(cap.With())
+	if err != nil {
+		return nil, nil, err
+	}
+	blob := cap.Nb().Blob
+
+	s.mu.Lock()
+	_, stored := s.blobs[string(blob.Digest)]
+	s.mu.Unlock()
+
+	// blob/allocate, concluded in place: stored blobs get no address, so the
+	// client skips the PUT
+	allocate, err := blobcap.Allocate.Invoke(s.signer, s.signer, s.DID(), blobcap.AllocateCaveats{
+		Space: space,
+		Blob:  blob,
+		Cause: inv.Link(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	allocateOk := blobcap.AllocateOk{}
+	if !stored {
+		putURL, err := url.Parse(fmt.Sprintf("%s/blob/%s", s.server.URL, cid.NewCidV1(cid.Raw, blob.Digest)))
+		if err != nil {
+			return nil, nil, err
+		}
+		allocateOk.Size = blob.Size
+		allocateOk.Address = &blobcap.Address{
+			URL:     *putURL,
+			Headers: http.Header{},
+			Expires: uint64(time.Now().Add(time.Hour).Unix()),
+		}
+	}
+	allocateRcpt, err := receipt.Issue(s.signer, result.Ok[blobcap.AllocateOk, failure.IPLDBuilderFailure](allocateOk), ran.FromInvocation(allocate))
+	if err != nil {
+		return nil, nil, err
+	}
+	conclude, err := ucancap.Conclude.Invoke(s.signer, s.signer, s.DID(), ucancap.ConcludeCaveats{
+		Receipt: allocateRcpt.Root().Link(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for blk, err := range allocateRcpt.Blocks() {
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := conclude.Attach(blk); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// http/put, signed by a key handed to the client so it can issue the
+	// receipt for the PUT it performs
+	putSigner, err := signer.Generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	put, err := httpcap.Put.Invoke(putSigner, putSigner, putSigner.DID().String(), httpcap.PutCaveats{
+		URL:     types.Promise{UcanAwait: types.Await{Selector: ".out.ok.address.url", Link: allocate.Link()}},
+		Headers: types.Promise{UcanAwait: types.Await{Selector: ".out.ok.address.headers", Link: allocate.Link()}},
+		Body:    httpcap.Body{Digest: blob.Digest, Size: blob.Size},
+	}, delegation.WithFacts([]ucan.FactBuilder{keysFact{putSigner}}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accept, err := blobcap.Accept.Invoke(s.signer, s.signer, s.DID(), blobcap.AcceptCaveats{
+		Space: space,
+		Blob:  blob,
+		Put:   blobcap.Promise{UcanAwait: blobcap.Await{Selector: ".out.ok", Link: put.Link()}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending := &pendingBlob{
+		blob:      blob,
+		putSigner: putSigner.DID(),
+		accept:    accept,
+		concluded: stored,
+	}
+	s.mu.Lock()
+	s.puts[put.Link().String()] = pending
+	s.accepts[accept.Link().String()] = pending
+	s.mu.Unlock()
+
+	ok := spaceblobcap.AddOk{
+		Site: types.Promise{UcanAwait: types.Await{Selector: ".out.ok.site", Link: accept.Link()}},
+	}
+	effects := fx.NewEffects(fx.WithFork(
+		fx.FromInvocation(allocate),
+		fx.FromInvocation(conclude),
+		fx.FromInvocation(put),
+		fx.FromInvocation(accept),
+	))
+	return result.Ok[spaceblobcap.AddOk, failure.IPLDBuilderFailure](ok), effects, nil
+}
+
+// conclude accepts the client's http/put receipt, which makes the blob's
+// blob/accept receipt available once the blob was stored.
+func (s *Service) conclude(
+	ctx context.Context,
+	cap ucan.Capability[ucancap.ConcludeCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[ucancap.ConcludeOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(ucancap.ConcludeAbility)
+
+	bs, err := blockstore.NewBlockStore(blockstore.WithBlocksIterator(inv.Blocks()))
+	if err != nil {
+		return nil, nil, err
+	}
+	rcpt, err := receipt.NewAnyReceipt(cap.Nb().Receipt, bs)
+	if err != nil {
+		return result.Error[ucancap.ConcludeOk](failure.FromError(fmt.Errorf("reading concluded receipt: %w", err))), nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.puts[rcpt.Ran().Link().String()]
+	if !ok {
+		return result.Error[ucancap.ConcludeOk](failure.FromError(fmt.Errorf("unknown task %s", rcpt.Ran().Link()))), nil, nil
+	}
+	if rcpt.Issuer() == nil || rcpt.Issuer().DID() != pending.putSigner {
+		return result.Error[ucancap.ConcludeOk](failure.FromError(fmt.Errorf("http/put receipt not issued by the task's key"))), nil, nil
+	}
+	if _, xerr := result.Unwrap(rcpt.Out()); xerr == nil {
+		pending.concluded = true
+	}
+
+	return result.Ok[ucancap.ConcludeOk, failure.IPLDBuilderFailure](ucancap.ConcludeOk{Time: time.Now()}), nil, nil
+}
+
+func (s *Service) blobRemove(
+	ctx context.Context,
+	cap ucan.Capability[spaceblobcap.RemoveCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[spaceblobcap.RemoveOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(spaceblobcap.RemoveAbility)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := string(cap.Nb().Digest)
+	size := uint64(len(s.blobs[key]))
+	delete(s.blobs, key)
+
+	return result.Ok[spaceblobcap.RemoveOk, failure.IPLDBuilderFailure](spaceblobcap.RemoveOk{Size: size}), nil, nil
+}
+
+// indexAdd registers an index CAR, which must have been stored as a blob.
+func (s *Service) indexAdd(
+	ctx context.Context,
+	cap ucan.Capability[spaceindexcap.AddCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[spaceindexcap.AddOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(spaceindexcap.AddAbility)
+
+	index, ok := cap.Nb().Index.(cidlink.Link)
+	if !ok {
+		return result.Error[spaceindexcap.AddOk](failure.FromError(fmt.Errorf("unsupported index link %s", cap.Nb().Index))), nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, stored := s.blobs[string(index.Cid.Hash())]; !stored {
+		return result.Error[spaceindexcap.AddOk](failure.FromError(fmt.Errorf("index blob %s not found in space", index))), nil, nil
+	}
+	s.indexes = append(s.indexes, index)
+
+	return result.Ok[spaceindexcap.AddOk, failure.IPLDBuilderFailure](spaceindexcap.AddOk{}), nil, nil
+}
+
+func (s *Service) uploadAdd(
+	ctx context.Context,
+	cap ucan.Capability[uploadcap.AddCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[uploadcap.AddOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(uploadcap.AddAbility)
+
+	nb := cap.Nb()
+	s.mu.Lock()
+	s.uploads[nb.Root.String()] = nb.Shards
+	s.mu.Unlock()
+
+	return result.Ok[uploadcap.AddOk, failure.IPLDBuilderFailure](uploadcap.AddOk{Root: nb.Root, Shards: nb.Shards}), nil, nil
+}
+
+// retrieve answers space/content/retrieve with the gateway URL of the blob,
+// which is what the delegated client expects.
+func (s *Service) retrieve(
+	ctx context.Context,
+	cap ucan.Capability[contentcap.RetrieveCaveats],
+	inv invocation.Invocation,
+	ictx server.InvocationContext,
+) (result.Result[retrieveOk, failure.IPLDBuilderFailure], fx.Effects, error) {
+	s.called(contentcap.RetrieveAbility)
+
+	digest := cap.Nb().Blob.Digest
+	if _, ok := s.Blob(digest); !ok {
+		return result.Error[retrieveOk](failure.FromError(fmt.Errorf("blob not found"))), nil, nil
+	}
+
+	ok := retrieveOk{url: fmt.Sprintf("%s/ipfs/%s", s.server.URL, cid.NewCidV1(cid.Raw, digest))}
+	return result.Ok[retrieveOk, failure.IPLDBuilderFailure](ok), nil, nil
+}
+
+// handlePut stores a blob PUT to an allocated address.
+func (s *Service) handlePut(w http.ResponseWriter, r *http.Request) {
+	parsed, err := cid.Decode(r.PathValue("cid"))
+	if err != nil {
+		http.Error(w, "invalid CID", http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failPut > 0 {
+		s.failPut--
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	digest, err := mh.Sum(data, mh.SHA2_256, -1)
+	if err != nil || !bytes.Equal(digest, parsed.Hash()) {
+		http.Error(w, "body does not match digest", http.StatusBadRequest)
+		return
+	}
+	s.blobs[string(digest)] = data
+	s.putN++
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleReceipt serves blob/accept receipts as receipt archives. Until the
+// blob is accepted it answers with a base64 encoded "No receipt" message, like
+// the hosted receipts API.
+func (s *Service) handleReceipt(w http.ResponseWriter, r *http.Request) {
+	task := r.PathValue("task")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.accepts[task]
+	if !ok {
+		http.Error(w, "unknown task", http.StatusNotFound)
+		return
+	}
+
+	pending.polls++
+	_, stored := s.blobs[string(pending.blob.Digest)]
+	if !stored || !pending.concluded || pending.polls <= s.delay {
+		io.WriteString(w, base64.StdEncoding.EncodeToString([]byte("No receipt found for "+task)))
+		return
+	}
+
+	if pending.receipt == nil {
+		site := cidlink.Link{Cid: cid.NewCidV1(cid.Raw, pending.blob.Digest)}
+		rcpt, err := receipt.Issue(s.signer, result.Ok[blobcap.AcceptOk, failure.IPLDBuilderFailure](blobcap.AcceptOk{Site: site}), ran.FromInvocation(pending.accept))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		archive, err := io.ReadAll(rcpt.Archive())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		pending.receipt = archive
+	}
+	w.Write(pending.receipt)
+}
+
+// handleGateway serves stored blobs by CID.
+func (s *Service) handleGateway(w http.ResponseWriter, r *http.Request) {
+	parsed, err := cid.Decode(r.PathValue("cid"))
+	if err != nil {
+		http.Error(w, "invalid CID", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	if s.failGet > 0 {
+		s.failGet--
+		s.mu.Unlock()
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+	data, ok := s.blobs[string(parsed.Hash())]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+// keysFact carries the http/put signer in the task's facts.
+type keysFact struct {
+	signer principal.Signer
+}
+
+func (k keysFact) ToIPLD() (map[string]datamodel.Node, error) {
+	return map[string]datamodel.Node{"keys": basicnode.NewBytes(k.signer.Encode())}, nil
+}
+
+// retrieveOk is the space/content/retrieve result the delegated client reads.
+type retrieveOk struct {
+	url string
+}
+
+func (r retrieveOk) ToIPLD() (datamodel.Node, error) {
+	return qp.BuildMap(basicnode.Prototype.Map, 1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "url", qp.String(r.url))
+	})
+}
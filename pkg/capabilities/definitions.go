@@ -14,9 +14,17 @@ import (
 func (c CreateCaveats) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
 	nb := np.NewBuilder()
-	ma, _ := nb.BeginMap(1)
+	fieldCount := 1 // delegation is required
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
 	ma.AssembleKey().AssignString("delegation")
 	ma.AssembleValue().AssignString(c.Delegation)
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -25,6 +33,7 @@ func createCaveatsType() ipldschema.Type {
 	ts, err := ipldprime.LoadSchemaBytes([]byte(`
 		type CreateCaveats struct {
 			delegation String
+			name optional String
 		}
 	`))
 	if err != nil {
@@ -68,6 +77,12 @@ func (c AppendCaveats) ToIPLD() (ipld.Node, error) {
 	if c.IndexCID != nil && *c.IndexCID != "" {
 		fieldCount++
 	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	if c.Envelope != nil {
+		fieldCount++
+	}
 	ma, _ := nb.BeginMap(int64(fieldCount))
 	ma.AssembleKey().AssignString("data")
 	ma.AssembleValue().AssignString(c.Data)
@@ -77,6 +92,14 @@ func (c AppendCaveats) ToIPLD() (ipld.Node, error) {
 	}
 	ma.AssembleKey().AssignString("delegation")
 	ma.AssembleValue().AssignString(c.Delegation)
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	if c.Envelope != nil {
+		ma.AssembleKey().AssignString("envelope")
+		ma.AssembleValue().AssignBool(*c.Envelope)
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -87,6 +110,8 @@ func appendCaveatsType() ipldschema.Type {
 			data String
 			index_cid optional String
 			delegation String
+			name optional String
+			envelope optional Bool
 		}
 	`))
 	if err != nil {
@@ -99,13 +124,23 @@ func appendCaveatsType() ipldschema.Type {
 func (s AppendSuccess) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
 	nb := np.NewBuilder()
-	ma, _ := nb.BeginMap(3)
+	fieldCount := 4
+	if s.ReceiptError != "" {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
 	ma.AssembleKey().AssignString("index")
 	ma.AssembleValue().AssignInt(s.Index)
 	ma.AssembleKey().AssignString("new_index_cid")
 	ma.AssembleValue().AssignString(s.NewIndexCID)
 	ma.AssembleKey().AssignString("tree_size")
 	ma.AssembleValue().AssignInt(int64(s.TreeSize))
+	ma.AssembleKey().AssignString("receipt")
+	ma.AssembleValue().AssignString(s.Receipt)
+	if s.ReceiptError != "" {
+		ma.AssembleKey().AssignString("receipt_error")
+		ma.AssembleValue().AssignString(s.ReceiptError)
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -122,6 +157,104 @@ func (f AppendFailure) ToIPLD() (ipld.Node, error) {
 	return nb.Build(), nil
 }
 
+// ToIPLD converts AppendBatchCaveats to an IPLD node
+func (c AppendBatchCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 2 // entries and delegation are required
+	if c.IndexCID != nil && *c.IndexCID != "" {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	if c.Envelope != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("entries")
+	la, _ := ma.AssembleValue().BeginList(int64(len(c.Entries)))
+	for _, entry := range c.Entries {
+		la.AssembleValue().AssignString(entry)
+	}
+	la.Finish()
+	if c.IndexCID != nil && *c.IndexCID != "" {
+		ma.AssembleKey().AssignString("index_cid")
+		ma.AssembleValue().AssignString(*c.IndexCID)
+	}
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	if c.Envelope != nil {
+		ma.AssembleKey().AssignString("envelope")
+		ma.AssembleValue().AssignBool(*c.Envelope)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func appendBatchCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type AppendBatchCaveats struct {
+			entries [String]
+			indexCID optional String (rename "index_cid")
+			delegation String
+			name optional String
+			envelope optional Bool
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("AppendBatchCaveats")
+}
+
+// ToIPLD converts AppendBatchSuccess to an IPLD node
+func (s AppendBatchSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 5
+	if s.ReceiptError != "" {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("first_index")
+	ma.AssembleValue().AssignInt(s.FirstIndex)
+	ma.AssembleKey().AssignString("last_index")
+	ma.AssembleValue().AssignInt(s.LastIndex)
+	ma.AssembleKey().AssignString("new_index_cid")
+	ma.AssembleValue().AssignString(s.NewIndexCID)
+	ma.AssembleKey().AssignString("tree_size")
+	ma.AssembleValue().AssignInt(int64(s.TreeSize))
+	ma.AssembleKey().AssignString("receipts")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Receipts)))
+	for _, receipt := range s.Receipts {
+		la.AssembleValue().AssignString(receipt)
+	}
+	la.Finish()
+	if s.ReceiptError != "" {
+		ma.AssembleKey().AssignString("receipt_error")
+		ma.AssembleValue().AssignString(s.ReceiptError)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f AppendBatchFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
 // ToIPLD converts ReadCaveats to an IPLD node
 func (c ReadCaveats) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
@@ -133,6 +266,15 @@ func (c ReadCaveats) ToIPLD() (ipld.Node, error) {
 	if c.Limit != nil {
 		fieldCount++
 	}
+	if c.AtSize != nil {
+		fieldCount++
+	}
+	if c.AtCID != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
 	ma, _ := nb.BeginMap(int64(fieldCount))
 	if c.Offset != nil {
 		ma.AssembleKey().AssignString("offset")
@@ -142,6 +284,18 @@ func (c ReadCaveats) ToIPLD() (ipld.Node, error) {
 		ma.AssembleKey().AssignString("limit")
 		ma.AssembleValue().AssignInt(*c.Limit)
 	}
+	if c.AtSize != nil {
+		ma.AssembleKey().AssignString("at_size")
+		ma.AssembleValue().AssignInt(*c.AtSize)
+	}
+	if c.AtCID != nil {
+		ma.AssembleKey().AssignString("at_cid")
+		ma.AssembleValue().AssignString(*c.AtCID)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -151,6 +305,9 @@ func readCaveatsType() ipldschema.Type {
 		type ReadCaveats struct {
 			offset optional Int
 			limit optional Int
+			atSize optional Int (rename "at_size")
+			atCID optional String (rename "at_cid")
+			name optional String
 		}
 	`))
 	if err != nil {
@@ -191,13 +348,203 @@ func (f ReadFailure) ToIPLD() (ipld.Node, error) {
 	return nb.Build(), nil
 }
 
+// ToIPLD converts ProveCaveats to an IPLD node
+func (c ProveCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 1 // index is required
+	if c.Size != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("index")
+	ma.AssembleValue().AssignInt(c.Index)
+	if c.Size != nil {
+		ma.AssembleKey().AssignString("size")
+		ma.AssembleValue().AssignInt(*c.Size)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func proveCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type ProveCaveats struct {
+			index Int
+			size optional Int
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("ProveCaveats")
+}
+
+// ToIPLD converts ProveSuccess to an IPLD node
+func (s ProveSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 6
+	if len(s.CheckpointProof) > 0 {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("index")
+	ma.AssembleValue().AssignInt(s.Index)
+	ma.AssembleKey().AssignString("entry")
+	ma.AssembleValue().AssignString(s.Entry)
+
+	ma.AssembleKey().AssignString("proof")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Proof)))
+	for _, hash := range s.Proof {
+		la.AssembleValue().AssignString(hash)
+	}
+	la.Finish()
+
+	ma.AssembleKey().AssignString("tree_size")
+	ma.AssembleValue().AssignInt(int64(s.TreeSize))
+	ma.AssembleKey().AssignString("root_hash")
+	ma.AssembleValue().AssignString(s.RootHash)
+	ma.AssembleKey().AssignString("checkpoint")
+	ma.AssembleValue().AssignString(s.Checkpoint)
+	if len(s.CheckpointProof) > 0 {
+		ma.AssembleKey().AssignString("checkpoint_proof")
+		la, _ := ma.AssembleValue().BeginList(int64(len(s.CheckpointProof)))
+		for _, hash := range s.CheckpointProof {
+			la.AssembleValue().AssignString(hash)
+		}
+		la.Finish()
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f ProveFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts ConsistencyCaveats to an IPLD node
+func (c ConsistencyCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 1 // from is required
+	if c.To != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("from")
+	ma.AssembleValue().AssignInt(c.From)
+	if c.To != nil {
+		ma.AssembleKey().AssignString("to")
+		ma.AssembleValue().AssignInt(*c.To)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func consistencyCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type ConsistencyCaveats struct {
+			from Int
+			to optional Int
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("ConsistencyCaveats")
+}
+
+// ToIPLD converts ConsistencySuccess to an IPLD node
+func (s ConsistencySuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(6)
+	ma.AssembleKey().AssignString("from")
+	ma.AssembleValue().AssignInt(int64(s.From))
+	ma.AssembleKey().AssignString("to")
+	ma.AssembleValue().AssignInt(int64(s.To))
+
+	ma.AssembleKey().AssignString("proof")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Proof)))
+	for _, hash := range s.Proof {
+		la.AssembleValue().AssignString(hash)
+	}
+	la.Finish()
+
+	ma.AssembleKey().AssignString("from_root")
+	ma.AssembleValue().AssignString(s.FromRoot)
+	ma.AssembleKey().AssignString("to_root")
+	ma.AssembleValue().AssignString(s.ToRoot)
+	ma.AssembleKey().AssignString("checkpoint")
+	ma.AssembleValue().AssignString(s.Checkpoint)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f ConsistencyFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
 // ToIPLD converts RevokeCaveats to an IPLD node
 func (c RevokeCaveats) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
 	nb := np.NewBuilder()
-	ma, _ := nb.BeginMap(2)
-	ma.AssembleKey().AssignString("cid")
-	ma.AssembleValue().AssignString(c.Cid)
+	fieldCount := 1
+	if c.Cid != nil {
+		fieldCount++
+	}
+	if c.Account != nil {
+		fieldCount++
+	}
+	if c.Ability != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	if c.Cid != nil {
+		ma.AssembleKey().AssignString("cid")
+		ma.AssembleValue().AssignString(*c.Cid)
+	}
+	if c.Account != nil {
+		ma.AssembleKey().AssignString("account")
+		ma.AssembleValue().AssignString(*c.Account)
+	}
+	if c.Ability != nil {
+		ma.AssembleKey().AssignString("ability")
+		ma.AssembleValue().AssignString(*c.Ability)
+	}
 	ma.AssembleKey().AssignString("delegation")
 	ma.AssembleValue().AssignString(c.Delegation)
 	ma.Finish()
@@ -207,7 +554,9 @@ func (c RevokeCaveats) ToIPLD() (ipld.Node, error) {
 func revokeCaveatsType() ipldschema.Type {
 	ts, err := ipldprime.LoadSchemaBytes([]byte(`
 		type RevokeCaveats struct {
-			cid String
+			cid optional String
+			account optional String
+			ability optional String
 			delegation String
 		}
 	`))
@@ -240,15 +589,67 @@ func (f RevokeFailure) ToIPLD() (ipld.Node, error) {
 	return nb.Build(), nil
 }
 
+// ToIPLD converts RebuildRevocationsCaveats to an IPLD node
+func (c RebuildRevocationsCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(0)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func rebuildRevocationsCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type RebuildRevocationsCaveats struct {}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("RebuildRevocationsCaveats")
+}
+
+// ToIPLD converts RebuildRevocationsSuccess to an IPLD node
+func (s RebuildRevocationsSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("scanned")
+	ma.AssembleValue().AssignInt(int64(s.Scanned))
+	ma.AssembleKey().AssignString("added")
+	ma.AssembleValue().AssignInt(int64(s.Added))
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f RebuildRevocationsFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
 // ToIPLD converts GarbageCaveats to an IPLD node
 func (c GarbageCaveats) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
 	nb := np.NewBuilder()
-	ma, _ := nb.BeginMap(2)
+	fieldCount := 2 // logId and delegation are required
+	if c.DryRun != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
 	ma.AssembleKey().AssignString("logId")
 	ma.AssembleValue().AssignString(c.LogID)
 	ma.AssembleKey().AssignString("delegation")
 	ma.AssembleValue().AssignString(c.Delegation)
+	if c.DryRun != nil {
+		ma.AssembleKey().AssignString("dryRun")
+		ma.AssembleValue().AssignBool(*c.DryRun)
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -256,8 +657,9 @@ func (c GarbageCaveats) ToIPLD() (ipld.Node, error) {
 func garbageCaveatsType() ipldschema.Type {
 	ts, err := ipldprime.LoadSchemaBytes([]byte(`
 		type GarbageCaveats struct {
-			logId String
+			logID String (rename "logId")
 			delegation String
+			dryRun optional Bool
 		}
 	`))
 	if err != nil {
@@ -270,15 +672,53 @@ func garbageCaveatsType() ipldschema.Type {
 func (s GarbageSuccess) ToIPLD() (ipld.Node, error) {
 	np := basicnode.Prototype.Any
 	nb := np.NewBuilder()
-	ma, _ := nb.BeginMap(4)
+	fieldCount := 6
+	if len(s.Blobs) > 0 {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
 	ma.AssembleKey().AssignString("bundlesProcessed")
 	ma.AssembleValue().AssignInt(int64(s.BundlesProcessed))
 	ma.AssembleKey().AssignString("blobsRemoved")
 	ma.AssembleValue().AssignInt(int64(s.BlobsRemoved))
 	ma.AssembleKey().AssignString("bytesFreed")
 	ma.AssembleValue().AssignInt(int64(s.BytesFreed))
+	ma.AssembleKey().AssignString("unknownSizes")
+	ma.AssembleValue().AssignInt(int64(s.UnknownSizes))
 	ma.AssembleKey().AssignString("newGCPosition")
 	ma.AssembleValue().AssignInt(int64(s.NewGCPosition))
+	ma.AssembleKey().AssignString("dryRun")
+	ma.AssembleValue().AssignBool(s.DryRun)
+	if len(s.Blobs) > 0 {
+		ma.AssembleKey().AssignString("blobs")
+		la, _ := ma.AssembleValue().BeginList(int64(len(s.Blobs)))
+		for _, b := range s.Blobs {
+			blobFields := 3
+			if b.Size != nil {
+				blobFields++
+			}
+			if b.TreeSize != nil {
+				blobFields++
+			}
+			bm, _ := la.AssembleValue().BeginMap(int64(blobFields))
+			bm.AssembleKey().AssignString("kind")
+			bm.AssembleValue().AssignString(b.Kind)
+			bm.AssembleKey().AssignString("path")
+			bm.AssembleValue().AssignString(b.Path)
+			bm.AssembleKey().AssignString("cid")
+			bm.AssembleValue().AssignString(b.CID)
+			if b.Size != nil {
+				bm.AssembleKey().AssignString("size")
+				bm.AssembleValue().AssignInt(int64(*b.Size))
+			}
+			if b.TreeSize != nil {
+				bm.AssembleKey().AssignString("treeSize")
+				bm.AssembleValue().AssignInt(int64(*b.TreeSize))
+			}
+			bm.Finish()
+		}
+		la.Finish()
+	}
 	ma.Finish()
 	return nb.Build(), nil
 }
@@ -295,29 +735,661 @@ func (f GarbageFailure) ToIPLD() (ipld.Node, error) {
 	return nb.Build(), nil
 }
 
-// Capability parsers
-var (
-	// TlogCreate is the capability parser for tlog/create
-	TlogCreate = validator.NewCapability(
-		AbilityCreate,
-		schema.DIDString(),
-		schema.Struct[CreateCaveats](createCaveatsType(), nil),
-		nil,
-	)
-
-	// TlogAppend is the capability parser for tlog/append
-	TlogAppend = validator.NewCapability(
-		AbilityAppend,
-		schema.DIDString(),
-		schema.Struct[AppendCaveats](appendCaveatsType(), nil),
-		nil,
-	)
-
-	// TlogRead is the capability parser for tlog/read
-	TlogRead = validator.NewCapability(
-		AbilityRead,
-		schema.DIDString(),
-		schema.Struct[ReadCaveats](readCaveatsType(), nil),
+// ToIPLD converts RestoreCaveats to an IPLD node
+func (c RestoreCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 2 // delegation and index_cid are required
+	if c.RevocationIndexCID != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.AssembleKey().AssignString("index_cid")
+	ma.AssembleValue().AssignString(c.IndexCID)
+	if c.RevocationIndexCID != nil {
+		ma.AssembleKey().AssignString("revocation_index_cid")
+		ma.AssembleValue().AssignString(*c.RevocationIndexCID)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func restoreCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type RestoreCaveats struct {
+			delegation String
+			indexCID String (rename "index_cid")
+			revocationIndexCID optional String (rename "revocation_index_cid")
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("RestoreCaveats")
+}
+
+// ToIPLD converts RestoreSuccess to an IPLD node
+func (s RestoreSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 4
+	if s.RevocationIndexCID != "" {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(s.LogID)
+	ma.AssembleKey().AssignString("index_cid")
+	ma.AssembleValue().AssignString(s.IndexCID)
+	ma.AssembleKey().AssignString("tree_size")
+	ma.AssembleValue().AssignInt(int64(s.TreeSize))
+	ma.AssembleKey().AssignString("root_hash")
+	ma.AssembleValue().AssignString(s.RootHash)
+	if s.RevocationIndexCID != "" {
+		ma.AssembleKey().AssignString("revocation_index_cid")
+		ma.AssembleValue().AssignString(s.RevocationIndexCID)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f RestoreFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts MigrateCaveats to an IPLD node
+func (c MigrateCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 3 // delegation, previous_key and index_cid are required
+	if c.RevocationIndexCID != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.AssembleKey().AssignString("previous_key")
+	ma.AssembleValue().AssignString(c.PreviousKey)
+	ma.AssembleKey().AssignString("index_cid")
+	ma.AssembleValue().AssignString(c.IndexCID)
+	if c.RevocationIndexCID != nil {
+		ma.AssembleKey().AssignString("revocation_index_cid")
+		ma.AssembleValue().AssignString(*c.RevocationIndexCID)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func migrateCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type MigrateCaveats struct {
+			delegation String
+			previousKey String (rename "previous_key")
+			indexCID String (rename "index_cid")
+			revocationIndexCID optional String (rename "revocation_index_cid")
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("MigrateCaveats")
+}
+
+// ToIPLD converts MigrateSuccess to an IPLD node
+func (s MigrateSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 9
+	if s.RevocationIndexCID != "" {
+		fieldCount += 2
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(s.LogID)
+	ma.AssembleKey().AssignString("index_cid")
+	ma.AssembleValue().AssignString(s.IndexCID)
+	ma.AssembleKey().AssignString("tree_size")
+	ma.AssembleValue().AssignInt(int64(s.TreeSize))
+	ma.AssembleKey().AssignString("root_hash")
+	ma.AssembleValue().AssignString(s.RootHash)
+	ma.AssembleKey().AssignString("previous_origin")
+	ma.AssembleValue().AssignString(s.PreviousOrigin)
+	ma.AssembleKey().AssignString("previous_checkpoint")
+	ma.AssembleValue().AssignString(s.PreviousCheckpoint)
+	ma.AssembleKey().AssignString("checkpoint_cid")
+	ma.AssembleValue().AssignString(s.CheckpointCID)
+	ma.AssembleKey().AssignString("migration_cid")
+	ma.AssembleValue().AssignString(s.MigrationCID)
+	ma.AssembleKey().AssignString("migration")
+	ma.AssembleValue().AssignString(s.Migration)
+	if s.RevocationIndexCID != "" {
+		ma.AssembleKey().AssignString("revocation_index_cid")
+		ma.AssembleValue().AssignString(s.RevocationIndexCID)
+		ma.AssembleKey().AssignString("revocation_migration_cid")
+		ma.AssembleValue().AssignString(s.RevocationMigrationCID)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f MigrateFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts AttributionCaveats to an IPLD node
+func (c AttributionCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 0
+	if c.Index != nil {
+		fieldCount++
+	}
+	if c.Issuer != nil {
+		fieldCount++
+	}
+	if c.Offset != nil {
+		fieldCount++
+	}
+	if c.Limit != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	if c.Index != nil {
+		ma.AssembleKey().AssignString("index")
+		ma.AssembleValue().AssignInt(*c.Index)
+	}
+	if c.Issuer != nil {
+		ma.AssembleKey().AssignString("issuer")
+		ma.AssembleValue().AssignString(*c.Issuer)
+	}
+	if c.Offset != nil {
+		ma.AssembleKey().AssignString("offset")
+		ma.AssembleValue().AssignInt(*c.Offset)
+	}
+	if c.Limit != nil {
+		ma.AssembleKey().AssignString("limit")
+		ma.AssembleValue().AssignInt(*c.Limit)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func attributionCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type AttributionCaveats struct {
+			index optional Int
+			issuer optional String
+			offset optional Int
+			limit optional Int
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("AttributionCaveats")
+}
+
+// ToIPLD converts AttributionSuccess to an IPLD node
+func (s AttributionSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(1)
+	ma.AssembleKey().AssignString("attributions")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Attributions)))
+	for _, a := range s.Attributions {
+		ra, _ := la.AssembleValue().BeginMap(5)
+		ra.AssembleKey().AssignString("index")
+		ra.AssembleValue().AssignInt(a.Index)
+		ra.AssembleKey().AssignString("invocation_cid")
+		ra.AssembleValue().AssignString(a.InvocationCID)
+		ra.AssembleKey().AssignString("issuer_did")
+		ra.AssembleValue().AssignString(a.IssuerDID)
+		ra.AssembleKey().AssignString("delegation_cid")
+		ra.AssembleValue().AssignString(a.DelegationCID)
+		ra.AssembleKey().AssignString("received_at")
+		ra.AssembleValue().AssignString(a.ReceivedAt)
+		ra.Finish()
+	}
+	la.Finish()
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f AttributionFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts WebhookAddCaveats to an IPLD node
+func (c WebhookAddCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 2 // delegation and url are required
+	if c.Events != nil {
+		fieldCount++
+	}
+	if c.Signing != nil {
+		fieldCount++
+	}
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.AssembleKey().AssignString("url")
+	ma.AssembleValue().AssignString(c.URL)
+	if c.Events != nil {
+		ma.AssembleKey().AssignString("events")
+		la, _ := ma.AssembleValue().BeginList(int64(len(c.Events)))
+		for _, event := range c.Events {
+			la.AssembleValue().AssignString(event)
+		}
+		la.Finish()
+	}
+	if c.Signing != nil {
+		ma.AssembleKey().AssignString("signing")
+		ma.AssembleValue().AssignString(*c.Signing)
+	}
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func webhookAddCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type WebhookAddCaveats struct {
+			delegation String
+			URL String (rename "url")
+			events optional [String]
+			signing optional String
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("WebhookAddCaveats")
+}
+
+// ToIPLD converts WebhookAddSuccess to an IPLD node
+func (s WebhookAddSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(5)
+	ma.AssembleKey().AssignString("id")
+	ma.AssembleValue().AssignString(s.ID)
+	ma.AssembleKey().AssignString("events")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Events)))
+	for _, event := range s.Events {
+		la.AssembleValue().AssignString(event)
+	}
+	la.Finish()
+	ma.AssembleKey().AssignString("signing")
+	ma.AssembleValue().AssignString(s.Signing)
+	ma.AssembleKey().AssignString("secret")
+	ma.AssembleValue().AssignString(s.Secret)
+	ma.AssembleKey().AssignString("signer")
+	ma.AssembleValue().AssignString(s.Signer)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f WebhookAddFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts WebhookRemoveCaveats to an IPLD node
+func (c WebhookRemoveCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 2 // delegation and id are required
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.AssembleKey().AssignString("id")
+	ma.AssembleValue().AssignString(c.ID)
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func webhookRemoveCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type WebhookRemoveCaveats struct {
+			delegation String
+			ID String (rename "id")
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("WebhookRemoveCaveats")
+}
+
+// ToIPLD converts WebhookRemoveSuccess to an IPLD node
+func (s WebhookRemoveSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(1)
+	ma.AssembleKey().AssignString("id")
+	ma.AssembleValue().AssignString(s.ID)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f WebhookRemoveFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts WebhookListCaveats to an IPLD node
+func (c WebhookListCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 1 // delegation is required
+	if c.Name != nil {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	if c.Name != nil {
+		ma.AssembleKey().AssignString("name")
+		ma.AssembleValue().AssignString(*c.Name)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func webhookListCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type WebhookListCaveats struct {
+			delegation String
+			name optional String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("WebhookListCaveats")
+}
+
+// ToIPLD converts WebhookListSuccess to an IPLD node
+func (s WebhookListSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(1)
+	ma.AssembleKey().AssignString("webhooks")
+	la, _ := ma.AssembleValue().BeginList(int64(len(s.Webhooks)))
+	for _, w := range s.Webhooks {
+		ra, _ := la.AssembleValue().BeginMap(7)
+		ra.AssembleKey().AssignString("id")
+		ra.AssembleValue().AssignString(w.ID)
+		ra.AssembleKey().AssignString("url")
+		ra.AssembleValue().AssignString(w.URL)
+		ra.AssembleKey().AssignString("events")
+		ea, _ := ra.AssembleValue().BeginList(int64(len(w.Events)))
+		for _, event := range w.Events {
+			ea.AssembleValue().AssignString(event)
+		}
+		ea.Finish()
+		ra.AssembleKey().AssignString("signing")
+		ra.AssembleValue().AssignString(w.Signing)
+		ra.AssembleKey().AssignString("created_by")
+		ra.AssembleValue().AssignString(w.CreatedBy)
+		ra.AssembleKey().AssignString("created_at")
+		ra.AssembleValue().AssignString(w.CreatedAt)
+		ra.AssembleKey().AssignString("pending")
+		ra.AssembleValue().AssignInt(w.Pending)
+		ra.Finish()
+	}
+	la.Finish()
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f WebhookListFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts GCScheduleCaveats to an IPLD node
+func (c GCScheduleCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(c.LogID)
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func gcScheduleCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type GCScheduleCaveats struct {
+			LogID String (rename "logId")
+			delegation String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("GCScheduleCaveats")
+}
+
+// ToIPLD converts GCScheduleSuccess to an IPLD node
+func (s GCScheduleSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	fieldCount := 2 // logId and delegationCid are always set
+	if s.ExpiresAt != "" {
+		fieldCount++
+	}
+	ma, _ := nb.BeginMap(int64(fieldCount))
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(s.LogID)
+	ma.AssembleKey().AssignString("delegationCid")
+	ma.AssembleValue().AssignString(s.DelegationCID)
+	if s.ExpiresAt != "" {
+		ma.AssembleKey().AssignString("expiresAt")
+		ma.AssembleValue().AssignString(s.ExpiresAt)
+	}
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f GCScheduleFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// ToIPLD converts GCUnscheduleCaveats to an IPLD node
+func (c GCUnscheduleCaveats) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(c.LogID)
+	ma.AssembleKey().AssignString("delegation")
+	ma.AssembleValue().AssignString(c.Delegation)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func gcUnscheduleCaveatsType() ipldschema.Type {
+	ts, err := ipldprime.LoadSchemaBytes([]byte(`
+		type GCUnscheduleCaveats struct {
+			LogID String (rename "logId")
+			delegation String
+		}
+	`))
+	if err != nil {
+		panic(err)
+	}
+	return ts.TypeByName("GCUnscheduleCaveats")
+}
+
+// ToIPLD converts GCUnscheduleSuccess to an IPLD node
+func (s GCUnscheduleSuccess) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(1)
+	ma.AssembleKey().AssignString("logId")
+	ma.AssembleValue().AssignString(s.LogID)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+func (f GCUnscheduleFailure) ToIPLD() (ipld.Node, error) {
+	np := basicnode.Prototype.Any
+	nb := np.NewBuilder()
+	ma, _ := nb.BeginMap(2)
+	ma.AssembleKey().AssignString("name")
+	ma.AssembleValue().AssignString(f.name)
+	ma.AssembleKey().AssignString("message")
+	ma.AssembleValue().AssignString(f.message)
+	ma.Finish()
+	return nb.Build(), nil
+}
+
+// Capability parsers
+var (
+	// TlogCreate is the capability parser for tlog/create
+	TlogCreate = validator.NewCapability(
+		AbilityCreate,
+		schema.DIDString(),
+		schema.Struct[CreateCaveats](createCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogAppend is the capability parser for tlog/append
+	TlogAppend = validator.NewCapability(
+		AbilityAppend,
+		schema.DIDString(),
+		schema.Struct[AppendCaveats](appendCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogAppendBatch is the capability parser for tlog/append-batch
+	TlogAppendBatch = validator.NewCapability(
+		AbilityAppendBatch,
+		schema.DIDString(),
+		schema.Struct[AppendBatchCaveats](appendBatchCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogRead is the capability parser for tlog/read
+	TlogRead = validator.NewCapability(
+		AbilityRead,
+		schema.DIDString(),
+		schema.Struct[ReadCaveats](readCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogProve is the capability parser for tlog/prove
+	TlogProve = validator.NewCapability(
+		AbilityProve,
+		schema.DIDString(),
+		schema.Struct[ProveCaveats](proveCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogConsistency is the capability parser for tlog/consistency
+	TlogConsistency = validator.NewCapability(
+		AbilityConsistency,
+		schema.DIDString(),
+		schema.Struct[ConsistencyCaveats](consistencyCaveatsType(), nil),
 		nil,
 	)
 
@@ -336,4 +1408,76 @@ var (
 		schema.Struct[GarbageCaveats](garbageCaveatsType(), nil),
 		nil,
 	)
+
+	// TlogRebuildRevocations is the capability parser for tlog/revocations/rebuild
+	TlogRebuildRevocations = validator.NewCapability(
+		AbilityRebuildRevocations,
+		schema.DIDString(),
+		schema.Struct[RebuildRevocationsCaveats](rebuildRevocationsCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogAttribution is the capability parser for tlog/attribution
+	TlogAttribution = validator.NewCapability(
+		AbilityAttribution,
+		schema.DIDString(),
+		schema.Struct[AttributionCaveats](attributionCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogRestore is the capability parser for tlog/restore
+	TlogRestore = validator.NewCapability(
+		AbilityRestore,
+		schema.DIDString(),
+		schema.Struct[RestoreCaveats](restoreCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogMigrate is the capability parser for tlog/migrate
+	TlogMigrate = validator.NewCapability(
+		AbilityMigrate,
+		schema.DIDString(),
+		schema.Struct[MigrateCaveats](migrateCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogWebhookAdd is the capability parser for tlog/webhook/add
+	TlogWebhookAdd = validator.NewCapability(
+		AbilityWebhookAdd,
+		schema.DIDString(),
+		schema.Struct[WebhookAddCaveats](webhookAddCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogWebhookRemove is the capability parser for tlog/webhook/remove
+	TlogWebhookRemove = validator.NewCapability(
+		AbilityWebhookRemove,
+		schema.DIDString(),
+		schema.Struct[WebhookRemoveCaveats](webhookRemoveCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogWebhookList is the capability parser for tlog/webhook/list
+	TlogWebhookList = validator.NewCapability(
+		AbilityWebhookList,
+		schema.DIDString(),
+		schema.Struct[WebhookListCaveats](webhookListCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogGCSchedule is the capability parser for tlog/gc/schedule
+	TlogGCSchedule = validator.NewCapability(
+		AbilityGCSchedule,
+		schema.DIDString(),
+		schema.Struct[GCScheduleCaveats](gcScheduleCaveatsType(), nil),
+		nil,
+	)
+
+	// TlogGCUnschedule is the capability parser for tlog/gc/unschedule
+	TlogGCUnschedule = validator.NewCapability(
+		AbilityGCUnschedule,
+		schema.DIDString(),
+		schema.Struct[GCUnscheduleCaveats](gcUnscheduleCaveatsType(), nil),
+		nil,
+	)
 )
@@ -3,17 +3,35 @@ package capabilities
 
 // Capability ability constants
 const (
-	AbilityCreate  = "tlog/create"
-	AbilityAppend  = "tlog/append"
-	AbilityRead    = "tlog/read"
-	AbilityRevoke  = "tlog/revoke"  // Changed from tlog/admin/revoke
-	AbilityGarbage = "tlog/gc" // For GC with remove delegation
+	AbilityCreate      = "tlog/create"
+	AbilityAppend      = "tlog/append"
+	AbilityAppendBatch = "tlog/append-batch"
+	AbilityRead        = "tlog/read"
+	AbilityProve       = "tlog/prove"
+	AbilityConsistency = "tlog/consistency"
+	AbilityRevoke      = "tlog/revoke" // Changed from tlog/admin/revoke
+	AbilityGarbage     = "tlog/gc"     // For GC with remove delegation
+
+	AbilityRebuildRevocations = "tlog/revocations/rebuild"
+	AbilityAttribution        = "tlog/attribution"
+	AbilityRestore            = "tlog/restore"
+	AbilityMigrate            = "tlog/migrate"
+	AbilityWebhookAdd         = "tlog/webhook/add"
+	AbilityWebhookRemove      = "tlog/webhook/remove"
+	AbilityWebhookList        = "tlog/webhook/list"
+	AbilityGCSchedule         = "tlog/gc/schedule"
+	AbilityGCUnschedule       = "tlog/gc/unschedule"
 )
 
 // CreateCaveats represents the caveats for tlog/create capability
 type CreateCaveats struct {
 	// Delegation is the base64-encoded UCAN delegation granting write access to the space
 	Delegation string `json:"delegation"`
+
+	// Name selects a named log in the space (optional). Named logs are
+	// identified as "{spaceDID}/{name}"; without a name the space's default
+	// log is used.
+	Name *string `json:"name,omitempty"`
 }
 
 // CreateSuccess is the success result for tlog/create
@@ -52,6 +70,13 @@ type AppendCaveats struct {
 
 	// Delegation is the base64-encoded UCAN delegation (required)
 	Delegation string `json:"delegation"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+
+	// Envelope commits the entry's attribution into the log by appending a
+	// types.AttributedEntry wrapping the data instead of the bare data (optional)
+	Envelope *bool `json:"envelope,omitempty"`
 }
 
 // AppendSuccess is the success result for tlog/append
@@ -59,6 +84,11 @@ type AppendSuccess struct {
 	Index       int64  `json:"index"`
 	NewIndexCID string `json:"new_index_cid"` // New head CID after append
 	TreeSize    uint64 `json:"tree_size"`     // New tree size
+	Receipt     string `json:"receipt"`       // Signed promise to include the entry at Index
+
+	// ReceiptError reports why Receipt is empty when the entry was appended
+	// but its receipt could not be signed (optional)
+	ReceiptError string `json:"receipt_error,omitempty"`
 }
 
 // AppendFailure is the failure result for tlog/append
@@ -80,12 +110,69 @@ func NewAppendFailure(name, message string) AppendFailure {
 	return AppendFailure{name: name, message: message}
 }
 
+// AppendBatchCaveats represents the caveats for tlog/append-batch capability
+type AppendBatchCaveats struct {
+	// Entries are the base64-encoded entries to append, in order. A batch
+	// holds at most 256 entries (tlog.MaxBatchEntries).
+	Entries []string `json:"entries"`
+
+	// IndexCID is the expected current head CID for optimistic concurrency (optional)
+	IndexCID *string `json:"index_cid,omitempty"`
+
+	// Delegation is the base64-encoded UCAN delegation (required)
+	Delegation string `json:"delegation"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+
+	// Envelope wraps each entry in a types.AttributedEntry, as for tlog/append (optional)
+	Envelope *bool `json:"envelope,omitempty"`
+}
+
+// AppendBatchSuccess is the success result for tlog/append-batch
+type AppendBatchSuccess struct {
+	FirstIndex  int64    `json:"first_index"`   // Index assigned to the first entry
+	LastIndex   int64    `json:"last_index"`    // Index assigned to the last entry
+	NewIndexCID string   `json:"new_index_cid"` // New head CID after append
+	TreeSize    uint64   `json:"tree_size"`     // New tree size
+	Receipts    []string `json:"receipts"`      // Signed inclusion promises, one per entry in order
+
+	// ReceiptError reports why Receipts is empty when the entries were
+	// appended but their receipts could not be signed (optional)
+	ReceiptError string `json:"receipt_error,omitempty"`
+}
+
+// AppendBatchFailure is the failure result for tlog/append-batch
+type AppendBatchFailure struct {
+	name    string
+	message string
+}
+
+func (f AppendBatchFailure) Name() string {
+	return f.name
+}
+
+func (f AppendBatchFailure) Error() string {
+	return f.message
+}
+
+// NewAppendBatchFailure creates a new AppendBatchFailure
+func NewAppendBatchFailure(name, message string) AppendBatchFailure {
+	return AppendBatchFailure{name: name, message: message}
+}
+
 // ReadCaveats represents the caveats for tlog/read capability
 type ReadCaveats struct {
 	// Offset is the starting index (optional)
 	Offset *int64
 	// Limit is the maximum number of entries to return (optional)
 	Limit *int64
+	// AtSize reads the log as it was at this tree size (optional)
+	AtSize *int64
+	// AtCID reads the log as it was at the head with this checkpoint or index CID (optional)
+	AtCID *string
+	// Name selects a named log in the space (optional)
+	Name *string
 }
 
 // ReadSuccess is the success result for tlog/read
@@ -113,11 +200,106 @@ func NewReadFailure(name, message string) ReadFailure {
 	return ReadFailure{name: name, message: message}
 }
 
+// ProveCaveats represents the caveats for tlog/prove capability
+type ProveCaveats struct {
+	// Index is the index of the entry to prove
+	Index int64
+	// Size is the tree size to prove against (optional, default: latest checkpoint)
+	Size *int64
+	// Name selects a named log in the space (optional)
+	Name *string
+}
+
+// ProveSuccess is the success result for tlog/prove
+type ProveSuccess struct {
+	Index      int64    `json:"index"`
+	Entry      string   `json:"entry"`      // Base64-encoded entry data
+	Proof      []string `json:"proof"`      // Base64-encoded inclusion proof hashes
+	TreeSize   uint64   `json:"tree_size"`  // Tree size the proof is for
+	RootHash   string   `json:"root_hash"`  // Base64-encoded root hash at tree_size
+	Checkpoint string   `json:"checkpoint"` // Latest signed checkpoint note
+	// CheckpointProof is the base64-encoded consistency proof from tree_size
+	// to the checkpoint size, set when tree_size is smaller
+	CheckpointProof []string `json:"checkpoint_proof,omitempty"`
+}
+
+// ProveFailure is the failure result for tlog/prove
+type ProveFailure struct {
+	name    string
+	message string
+}
+
+func (f ProveFailure) Name() string {
+	return f.name
+}
+
+func (f ProveFailure) Error() string {
+	return f.message
+}
+
+// NewProveFailure creates a new ProveFailure
+func NewProveFailure(name, message string) ProveFailure {
+	return ProveFailure{name: name, message: message}
+}
+
+// ConsistencyCaveats represents the caveats for tlog/consistency capability
+type ConsistencyCaveats struct {
+	// From is the smaller tree size
+	From int64
+	// To is the larger tree size (optional, default: latest checkpoint)
+	To *int64
+	// Name selects a named log in the space (optional)
+	Name *string
+}
+
+// ConsistencySuccess is the success result for tlog/consistency
+type ConsistencySuccess struct {
+	From       uint64   `json:"from"`
+	To         uint64   `json:"to"`
+	Proof      []string `json:"proof"`      // Base64-encoded consistency proof hashes
+	FromRoot   string   `json:"from_root"`  // Base64-encoded root hash at from
+	ToRoot     string   `json:"to_root"`    // Base64-encoded root hash at to
+	Checkpoint string   `json:"checkpoint"` // Latest signed checkpoint note
+}
+
+// ConsistencyFailure is the failure result for tlog/consistency
+type ConsistencyFailure struct {
+	name    string
+	message string
+}
+
+func (f ConsistencyFailure) Name() string {
+	return f.name
+}
+
+func (f ConsistencyFailure) Error() string {
+	return f.message
+}
+
+// NewConsistencyFailure creates a new ConsistencyFailure
+func NewConsistencyFailure(name, message string) ConsistencyFailure {
+	return ConsistencyFailure{name: name, message: message}
+}
+
 // RevokeCaveats represents the caveats for tlog/revoke capability
 type RevokeCaveats struct {
+	// Exactly one of Cid, Account or Ability selects what is revoked.
+
 	// Cid is the CID of the delegation to revoke.
 	// The delegation must be stored in the space (uploaded by the client).
-	Cid string `json:"cid"`
+	Cid *string `json:"cid,omitempty"`
+
+	// Account is a DID whose delegations are all revoked, whether it
+	// issued them or received them.
+	Account *string `json:"account,omitempty"`
+
+	// Ability revokes every delegation granting this ability or an ability
+	// nested under it (e.g. "tlog/append"). Grants of a broader ability or
+	// wildcard, such as "tlog/*" or "*", are not revoked by it; revoke that
+	// ability or the delegation itself. Revoking a broad ability revokes
+	// every grant under it, including the tlog/revoke grants needed to undo
+	// the revocation.
+	Ability *string `json:"ability,omitempty"`
 
 	// Delegation grants access to the space for fetching the delegation
 	// and writing to the revocation log (base64-encoded)
@@ -148,22 +330,69 @@ func NewRevokeFailure(name, message string) RevokeFailure {
 	return RevokeFailure{name: name, message: message}
 }
 
+// RebuildRevocationsCaveats represents the caveats for tlog/revocations/rebuild capability.
+// The log is identified by the capability's with field (space DID).
+type RebuildRevocationsCaveats struct{}
+
+// RebuildRevocationsSuccess is the success result for tlog/revocations/rebuild
+type RebuildRevocationsSuccess struct {
+	Scanned int `json:"scanned"` // Revocation log entries read
+	Added   int `json:"added"`   // Revocations restored to the revocations table
+}
+
+// RebuildRevocationsFailure is the failure result for tlog/revocations/rebuild
+type RebuildRevocationsFailure struct {
+	name    string
+	message string
+}
+
+func (f RebuildRevocationsFailure) Name() string {
+	return f.name
+}
+
+func (f RebuildRevocationsFailure) Error() string {
+	return f.message
+}
+
+// NewRebuildRevocationsFailure creates a new RebuildRevocationsFailure
+func NewRebuildRevocationsFailure(name, message string) RebuildRevocationsFailure {
+	return RebuildRevocationsFailure{name: name, message: message}
+}
+
 // GarbageCaveats represents the caveats for tlog/gc capability
 type GarbageCaveats struct {
-	// LogID is the space DID (log identifier)
+	// LogID is the log identifier: the space DID, or "{spaceDID}/{name}"
+	// for a named log
 	LogID string `json:"logId"`
 
 	// Delegation grants space/blob/remove capability (base64-encoded)
-	// Must be a direct delegation from space owner to service
+	// Must be a direct delegation from space owner to service. For a dry
+	// run, a delegation granting access to the space is enough.
 	Delegation string `json:"delegation"`
+
+	// DryRun lists the blobs the run would remove without removing them
+	// (optional)
+	DryRun *bool `json:"dryRun,omitempty"`
 }
 
 // GarbageSuccess is the success result for tlog/gc
 type GarbageSuccess struct {
-	BundlesProcessed int    `json:"bundlesProcessed"` // Number of bundles processed
-	BlobsRemoved     int    `json:"blobsRemoved"`     // Number of blobs removed
-	BytesFreed       uint64 `json:"bytesFreed"`       // Bytes freed (estimated)
-	NewGCPosition    uint64 `json:"newGCPosition"`    // New GC checkpoint position
+	BundlesProcessed int      `json:"bundlesProcessed"` // Number of bundles processed
+	BlobsRemoved     int      `json:"blobsRemoved"`     // Number of blobs removed
+	BytesFreed       uint64   `json:"bytesFreed"`       // Bytes freed, or that a dry run would free
+	UnknownSizes     int      `json:"unknownSizes"`     // Blobs of unknown size, not counted in bytesFreed
+	NewGCPosition    uint64   `json:"newGCPosition"`    // New GC checkpoint position
+	DryRun           bool     `json:"dryRun"`           // Nothing was removed
+	Blobs            []GCBlob `json:"blobs,omitempty"`  // Blobs a dry run would remove
+}
+
+// GCBlob is an obsolete blob listed by a tlog/gc dry run
+type GCBlob struct {
+	Kind     string  `json:"kind"`               // "partial", "checkpoint" or "index"
+	Path     string  `json:"path"`               // Tessera path of the blob, or root CID of an index CAR
+	CID      string  `json:"cid"`                // Blob CID
+	Size     *uint64 `json:"size,omitempty"`     // Size in bytes, unset if unknown
+	TreeSize *uint64 `json:"treeSize,omitempty"` // Head of a checkpoint or index CAR
 }
 
 // GarbageFailure is the failure result for tlog/gc
@@ -184,3 +413,354 @@ func (f GarbageFailure) Error() string {
 func NewGarbageFailure(name, message string) GarbageFailure {
 	return GarbageFailure{name: name, message: message}
 }
+
+// RestoreCaveats represents the caveats for tlog/restore capability
+type RestoreCaveats struct {
+	// Delegation is the base64-encoded UCAN delegation granting access to the space
+	Delegation string `json:"delegation"`
+
+	// IndexCID is the root CID of the index CAR to restore from
+	IndexCID string `json:"index_cid"`
+
+	// RevocationIndexCID is the root CID of the index CAR to restore the
+	// space's revocation log from. Required unless the revocation log exists
+	// on the service.
+	RevocationIndexCID *string `json:"revocation_index_cid,omitempty"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+}
+
+// RestoreSuccess is the success result for tlog/restore
+type RestoreSuccess struct {
+	LogID              string `json:"logId"`
+	IndexCID           string `json:"index_cid"`                      // Index CAR the log was restored from
+	TreeSize           uint64 `json:"tree_size"`                      // Tree size of the restored checkpoint
+	RootHash           string `json:"root_hash"`                      // Base64-encoded root hash of the restored checkpoint
+	RevocationIndexCID string `json:"revocation_index_cid,omitempty"` // Index CAR the revocation log was restored from, if it was
+}
+
+// RestoreFailure is the failure result for tlog/restore
+type RestoreFailure struct {
+	name    string
+	message string
+}
+
+func (f RestoreFailure) Name() string {
+	return f.name
+}
+
+func (f RestoreFailure) Error() string {
+	return f.message
+}
+
+// NewRestoreFailure creates a new RestoreFailure
+func NewRestoreFailure(name, message string) RestoreFailure {
+	return RestoreFailure{name: name, message: message}
+}
+
+// MigrateCaveats represents the caveats for tlog/migrate capability
+type MigrateCaveats struct {
+	// Delegation is the base64-encoded UCAN delegation granting access to the space
+	Delegation string `json:"delegation"`
+
+	// PreviousKey is the signed-note verifier key of the service the log is
+	// migrated from
+	PreviousKey string `json:"previous_key"`
+
+	// IndexCID is the root CID of the index CAR to migrate from
+	IndexCID string `json:"index_cid"`
+
+	// RevocationIndexCID is the root CID of the index CAR to migrate the
+	// space's revocation log from. Required unless the revocation log exists
+	// on the service.
+	RevocationIndexCID *string `json:"revocation_index_cid,omitempty"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+}
+
+// MigrateSuccess is the success result for tlog/migrate
+type MigrateSuccess struct {
+	LogID              string `json:"logId"`
+	IndexCID           string `json:"index_cid"`           // Index CAR written after the migration
+	TreeSize           uint64 `json:"tree_size"`           // Tree size at the handoff
+	RootHash           string `json:"root_hash"`           // Base64-encoded root hash at the handoff
+	PreviousOrigin     string `json:"previous_origin"`     // Origin of the log at the previous service
+	PreviousCheckpoint string `json:"previous_checkpoint"` // CID of the previous service's final checkpoint
+	CheckpointCID      string `json:"checkpoint_cid"`      // CID of this service's first checkpoint
+	MigrationCID       string `json:"migration_cid"`       // CID of the signed migration record
+	Migration          string `json:"migration"`           // Signed migration record note
+
+	// RevocationIndexCID and RevocationMigrationCID describe the handoff of
+	// the space's revocation log, empty if it already existed on the service
+	RevocationIndexCID     string `json:"revocation_index_cid,omitempty"`
+	RevocationMigrationCID string `json:"revocation_migration_cid,omitempty"`
+}
+
+// MigrateFailure is the failure result for tlog/migrate
+type MigrateFailure struct {
+	name    string
+	message string
+}
+
+func (f MigrateFailure) Name() string {
+	return f.name
+}
+
+func (f MigrateFailure) Error() string {
+	return f.message
+}
+
+// NewMigrateFailure creates a new MigrateFailure
+func NewMigrateFailure(name, message string) MigrateFailure {
+	return MigrateFailure{name: name, message: message}
+}
+
+// AttributionCaveats represents the caveats for tlog/attribution capability.
+// Exactly one of Index or Issuer selects the attributions returned.
+type AttributionCaveats struct {
+	// Index looks up the attribution of a single entry
+	Index *int64
+	// Issuer lists the attributions of entries appended by this DID
+	Issuer *string
+	// Offset skips this many matches when listing by issuer (optional)
+	Offset *int64
+	// Limit is the maximum number of matches returned by issuer (optional)
+	Limit *int64
+	// Name selects a named log in the space (optional)
+	Name *string
+}
+
+// AttributionRecord describes who appended an entry and under which authority
+type AttributionRecord struct {
+	Index         int64  `json:"index"`
+	InvocationCID string `json:"invocation_cid"` // CID of the append invocation
+	IssuerDID     string `json:"issuer_did"`     // DID that issued the invocation
+	DelegationCID string `json:"delegation_cid"` // CID of the storage delegation used
+	ReceivedAt    string `json:"received_at"`    // RFC3339 time the append was received
+}
+
+// AttributionSuccess is the success result for tlog/attribution
+type AttributionSuccess struct {
+	Attributions []AttributionRecord `json:"attributions"`
+}
+
+// AttributionFailure is the failure result for tlog/attribution
+type AttributionFailure struct {
+	name    string
+	message string
+}
+
+func (f AttributionFailure) Name() string {
+	return f.name
+}
+
+func (f AttributionFailure) Error() string {
+	return f.message
+}
+
+// NewAttributionFailure creates a new AttributionFailure
+func NewAttributionFailure(name, message string) AttributionFailure {
+	return AttributionFailure{name: name, message: message}
+}
+
+// WebhookAddCaveats represents the caveats for tlog/webhook/add capability
+type WebhookAddCaveats struct {
+	// Delegation is the base64-encoded UCAN delegation granting access to the space
+	Delegation string `json:"delegation"`
+
+	// URL is the http or https endpoint notifications are POSTed to
+	URL string `json:"url"`
+
+	// Events are the event types delivered: "append", "revocation" and
+	// "gc" (optional). Without events every event type is delivered.
+	Events []string `json:"events,omitempty"`
+
+	// Signing is the payload signing scheme, "hmac" (default) or "ucan" (optional)
+	Signing *string `json:"signing,omitempty"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+}
+
+// WebhookAddSuccess is the success result for tlog/webhook/add
+type WebhookAddSuccess struct {
+	ID      string   `json:"id"`
+	Events  []string `json:"events"`  // Event types delivered
+	Signing string   `json:"signing"` // Payload signing scheme
+	Secret  string   `json:"secret"`  // Hex HMAC key for "hmac" signing, only returned here
+	Signer  string   `json:"signer"`  // DID signing payloads for "ucan" signing
+}
+
+// WebhookAddFailure is the failure result for tlog/webhook/add
+type WebhookAddFailure struct {
+	name    string
+	message string
+}
+
+func (f WebhookAddFailure) Name() string {
+	return f.name
+}
+
+func (f WebhookAddFailure) Error() string {
+	return f.message
+}
+
+// NewWebhookAddFailure creates a new WebhookAddFailure
+func NewWebhookAddFailure(name, message string) WebhookAddFailure {
+	return WebhookAddFailure{name: name, message: message}
+}
+
+// WebhookRemoveCaveats represents the caveats for tlog/webhook/remove capability
+type WebhookRemoveCaveats struct {
+	// Delegation is the base64-encoded UCAN delegation granting access to the space
+	Delegation string `json:"delegation"`
+
+	// ID is the webhook to remove
+	ID string `json:"id"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+}
+
+// WebhookRemoveSuccess is the success result for tlog/webhook/remove
+type WebhookRemoveSuccess struct {
+	ID string `json:"id"`
+}
+
+// WebhookRemoveFailure is the failure result for tlog/webhook/remove
+type WebhookRemoveFailure struct {
+	name    string
+	message string
+}
+
+func (f WebhookRemoveFailure) Name() string {
+	return f.name
+}
+
+func (f WebhookRemoveFailure) Error() string {
+	return f.message
+}
+
+// NewWebhookRemoveFailure creates a new WebhookRemoveFailure
+func NewWebhookRemoveFailure(name, message string) WebhookRemoveFailure {
+	return WebhookRemoveFailure{name: name, message: message}
+}
+
+// WebhookListCaveats represents the caveats for tlog/webhook/list capability
+type WebhookListCaveats struct {
+	// Delegation is the base64-encoded UCAN delegation granting access to the space
+	Delegation string `json:"delegation"`
+
+	// Name selects a named log in the space (optional)
+	Name *string `json:"name,omitempty"`
+}
+
+// WebhookRecord describes a webhook registered on a log
+type WebhookRecord struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Signing   string   `json:"signing"`
+	CreatedBy string   `json:"created_by"` // DID that registered the webhook
+	CreatedAt string   `json:"created_at"` // RFC3339 registration time
+	Pending   int64    `json:"pending"`    // Notifications awaiting delivery
+}
+
+// WebhookListSuccess is the success result for tlog/webhook/list
+type WebhookListSuccess struct {
+	Webhooks []WebhookRecord `json:"webhooks"`
+}
+
+// WebhookListFailure is the failure result for tlog/webhook/list
+type WebhookListFailure struct {
+	name    string
+	message string
+}
+
+func (f WebhookListFailure) Name() string {
+	return f.name
+}
+
+func (f WebhookListFailure) Error() string {
+	return f.message
+}
+
+// NewWebhookListFailure creates a new WebhookListFailure
+func NewWebhookListFailure(name, message string) WebhookListFailure {
+	return WebhookListFailure{name: name, message: message}
+}
+
+// GCScheduleCaveats represents the caveats for tlog/gc/schedule capability
+type GCScheduleCaveats struct {
+	// LogID is the log identifier: the space DID, or "{spaceDID}/{name}"
+	// for a named log
+	LogID string `json:"logId"`
+
+	// Delegation grants space/blob/remove capability (base64-encoded).
+	// Must be a direct delegation from space owner to service; it is stored
+	// and used for background GC until it expires or is revoked.
+	Delegation string `json:"delegation"`
+}
+
+// GCScheduleSuccess is the success result for tlog/gc/schedule
+type GCScheduleSuccess struct {
+	LogID         string `json:"logId"`
+	DelegationCID string `json:"delegationCid"`       // CID of the stored delegation
+	ExpiresAt     string `json:"expiresAt,omitempty"` // RFC3339 expiry, empty if none
+}
+
+// GCScheduleFailure is the failure result for tlog/gc/schedule
+type GCScheduleFailure struct {
+	name    string
+	message string
+}
+
+func (f GCScheduleFailure) Name() string {
+	return f.name
+}
+
+func (f GCScheduleFailure) Error() string {
+	return f.message
+}
+
+// NewGCScheduleFailure creates a new GCScheduleFailure
+func NewGCScheduleFailure(name, message string) GCScheduleFailure {
+	return GCScheduleFailure{name: name, message: message}
+}
+
+// GCUnscheduleCaveats represents the caveats for tlog/gc/unschedule capability
+type GCUnscheduleCaveats struct {
+	// LogID is the log identifier: the space DID, or "{spaceDID}/{name}"
+	// for a named log
+	LogID string `json:"logId"`
+
+	// Delegation is a direct space/blob/remove delegation from the space
+	// owner to the service (base64-encoded), as for tlog/gc
+	Delegation string `json:"delegation"`
+}
+
+// GCUnscheduleSuccess is the success result for tlog/gc/unschedule
+type GCUnscheduleSuccess struct {
+	LogID string `json:"logId"`
+}
+
+// GCUnscheduleFailure is the failure result for tlog/gc/unschedule
+type GCUnscheduleFailure struct {
+	name    string
+	message string
+}
+
+func (f GCUnscheduleFailure) Name() string {
+	return f.name
+}
+
+func (f GCUnscheduleFailure) Error() string {
+	return f.message
+}
+
+// NewGCUnscheduleFailure creates a new GCUnscheduleFailure
+func NewGCUnscheduleFailure(name, message string) GCUnscheduleFailure {
+	return GCUnscheduleFailure{name: name, message: message}
+}
@@ -4,6 +4,8 @@ package log
 import (
 	"context"
 	"crypto/ed25519"
+
+	"github.com/relves/ucanlog/pkg/types"
 )
 
 // Service defines the interface for log operations.
@@ -26,6 +28,35 @@ type ReadResult struct {
 	Total   int64
 }
 
+// InclusionProofResult contains an entry with its inclusion proof and the
+// signed checkpoint the proof was computed against.
+type InclusionProofResult struct {
+	types.LogEntryWithProof
+
+	// Checkpoint is the latest signed checkpoint note. When the proof's
+	// TreeSize is smaller than the checkpoint size, RootHash is the root
+	// recomputed at TreeSize and is not signed itself: CheckpointProof links
+	// it to the checkpoint.
+	Checkpoint []byte
+
+	// CheckpointProof is the consistency proof from TreeSize to the
+	// checkpoint size. It is empty when TreeSize is the checkpoint size.
+	CheckpointProof [][]byte
+}
+
+// ConsistencyProofResult contains a consistency proof between two tree sizes
+// and the signed checkpoint the larger tree size was checked against.
+type ConsistencyProofResult struct {
+	From     uint64
+	To       uint64
+	Proof    [][]byte
+	FromRoot []byte
+	ToRoot   []byte
+
+	// Checkpoint is the latest signed checkpoint note.
+	Checkpoint []byte
+}
+
 // Revocation represents a revocation entry.
 type Revocation struct {
 	Target string
@@ -1,17 +1,56 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"crypto/ed25519"
 
 	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/gc"
 	"github.com/relves/ucanlog/pkg/tlog"
 	"github.com/relves/ucanlog/pkg/types"
 	ucanPkg "github.com/relves/ucanlog/pkg/ucan"
+	"github.com/relves/ucanlog/pkg/webhook"
 	"github.com/storacha/go-ucanto/core/delegation"
+	formatslog "github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+var (
+	// ErrInvalidProofRequest is returned when a proof is requested for an index or
+	// tree size the log cannot prove.
+	ErrInvalidProofRequest = errors.New("invalid proof request")
+
+	// ErrCheckpointUnavailable is returned when a log has no readable checkpoint,
+	// either because it does not exist or nothing has been integrated yet.
+	ErrCheckpointUnavailable = errors.New("checkpoint unavailable")
+
+	// ErrHeadNotFound is returned when a historical head is requested that was
+	// never recorded in the log's head history.
+	ErrHeadNotFound = errors.New("head not found")
+
+	// ErrAttributionNotFound is returned when no attribution was recorded for
+	// an entry.
+	ErrAttributionNotFound = errors.New("attribution not found")
+
+	// ErrWebhooksDisabled is returned when webhooks are managed on a service
+	// without a webhook dispatcher.
+	ErrWebhooksDisabled = errors.New("webhooks not configured")
+
+	// ErrInvalidWebhook is returned when a webhook's URL, events or signing
+	// scheme are invalid.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrWebhookNotFound is returned when removing a webhook the log does not have.
+	ErrWebhookNotFound = errors.New("webhook not found")
 )
 
 type LogService struct {
@@ -20,6 +59,7 @@ type LogService struct {
 	logMetaStore *tlog.LogMetaStore
 	serviceDID   string // Service DID for delegation validation
 	storeManager *sqlite.StoreManager
+	webhooks     *webhook.Dispatcher
 }
 
 // LogServiceConfig holds configuration for creating a LogService.
@@ -29,6 +69,7 @@ type LogServiceConfig struct {
 	LogMetaStore *tlog.LogMetaStore
 	ServiceDID   string
 	StoreManager *sqlite.StoreManager
+	Webhooks     *webhook.Dispatcher // Optional; notifies log webhooks of events
 }
 
 func NewLogService(tlogMgr *tlog.Manager, issuer *ucanPkg.Issuer) *LogService {
@@ -46,12 +87,16 @@ func NewLogServiceWithConfig(cfg LogServiceConfig) *LogService {
 		logMetaStore: cfg.LogMetaStore,
 		serviceDID:   cfg.ServiceDID,
 		storeManager: cfg.StoreManager,
+		webhooks:     cfg.Webhooks,
 	}
 }
 
 // CreateLogParams contains parameters for creating a log with customer-delegated storage.
 type CreateLogParams struct {
-	SpaceDID   string
+	SpaceDID string
+	// Name selects a named log in the space (optional). An empty name
+	// creates the space's default log, identified by the space DID.
+	Name       string
 	Delegation delegation.Delegation
 }
 
@@ -80,30 +125,40 @@ func (s *LogService) CreateLog(ctx context.Context, logIdKey, accountIdKey ed255
 }
 
 // CreateLogWithDelegation creates a log with customer-provided Storacha space delegation.
+// Named logs are identified as "{spaceDID}/{name}" and share the revocation
+// log of their space, which is created with the first log in the space.
 func (s *LogService) CreateLogWithDelegation(ctx context.Context, params CreateLogParams) (*LogResult, error) {
 	spaceDID := params.SpaceDID
+	if params.Name != "" {
+		if err := tlog.ValidateLogName(params.Name); err != nil {
+			return nil, err
+		}
+	}
+	logID := tlog.LogID(spaceDID, params.Name)
 
-	// Create log using space DID as identity
-	if err := s.tlogManager.CreateLogWithDelegation(ctx, spaceDID, spaceDID, params.Delegation); err != nil {
+	if err := s.tlogManager.CreateLogWithDelegation(ctx, logID, spaceDID, params.Delegation); err != nil {
 		return nil, fmt.Errorf("failed to create log: %w", err)
 	}
 
-	// Create revocation log for this space
-	revocationLogID := spaceDID + "-revocations"
-	if err := s.tlogManager.CreateLogWithDelegation(ctx, revocationLogID, spaceDID, params.Delegation); err != nil {
-		return nil, fmt.Errorf("failed to create revocation log: %w", err)
+	// Create revocation log for this space unless an earlier log in the
+	// space already did
+	revocationLogID := tlog.RevocationLogID(logID)
+	if _, err := s.tlogManager.GetLogInstance(ctx, revocationLogID); err != nil {
+		if err := s.tlogManager.CreateLogWithDelegation(ctx, revocationLogID, spaceDID, params.Delegation); err != nil {
+			return nil, fmt.Errorf("failed to create revocation log: %w", err)
+		}
 	}
 
 	// Store metadata (without delegation - it's passed fresh on each request)
 	if s.logMetaStore != nil {
-		_, err := s.logMetaStore.Create(spaceDID, spaceDID, spaceDID)
+		_, err := s.logMetaStore.Create(logID, logID, spaceDID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to store log metadata: %w", err)
 		}
 	}
 
 	return &LogResult{
-		LogID: spaceDID,
+		LogID: logID,
 	}, nil
 }
 
@@ -113,8 +168,185 @@ func (s *LogService) Append(ctx context.Context, logID string, data []byte, dlg
 	return s.tlogManager.AddEntryWithDelegation(ctx, logID, data, dlg)
 }
 
-// Read retrieves entries from a log with pagination
-func (s *LogService) Read(ctx context.Context, logID string, offset, limit int64) (*ReadResult, error) {
+// AppendBatch adds multiple entries to a log in order and returns the
+// contiguous range of assigned indices (first and last inclusive).
+func (s *LogService) AppendBatch(ctx context.Context, logID string, entries [][]byte, dlg delegation.Delegation) (uint64, uint64, error) {
+	indices, err := s.tlogManager.AddEntriesWithDelegation(ctx, logID, entries, dlg)
+	if err != nil {
+		return 0, 0, err
+	}
+	return indices[0], indices[len(indices)-1], nil
+}
+
+// ReceiptIssuer returns the issuer of signed receipts promising that
+// appended entries will be included in the log. Receipts are signed with the
+// log's checkpoint key and verify offline with tlog.VerifyReceipt.
+func (s *LogService) ReceiptIssuer(logID string) (*tlog.ReceiptIssuer, error) {
+	return s.tlogManager.ReceiptIssuer(logID)
+}
+
+// ProveInclusion returns the entry at index together with a Merkle inclusion
+// proof for the tree of the given size. A treeSize of 0 selects the size of
+// the latest checkpoint. For a smaller tree, a consistency proof to the
+// checkpoint is returned as well, so the proof verifies against the signed
+// checkpoint. Both proofs are verified before they are returned.
+func (s *LogService) ProveInclusion(ctx context.Context, logID string, index, treeSize uint64) (*InclusionProofResult, error) {
+	cpRaw, cp, err := s.latestCheckpoint(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	if treeSize == 0 {
+		treeSize = cp.Size
+	}
+	if treeSize > cp.Size {
+		return nil, fmt.Errorf("%w: tree size %d exceeds checkpoint size %d", ErrInvalidProofRequest, treeSize, cp.Size)
+	}
+	if index >= treeSize {
+		return nil, fmt.Errorf("%w: index %d is outside tree of size %d", ErrInvalidProofRequest, index, treeSize)
+	}
+
+	rootHash := cp.Hash
+	var checkpointProof [][]byte
+	if treeSize != cp.Size {
+		rootHash, err = s.tlogManager.RootHashAt(ctx, logID, treeSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute root hash at size %d: %w", treeSize, err)
+		}
+		checkpointProof, err = s.tlogManager.ConsistencyProof(ctx, logID, treeSize, cp.Size)
+		if err != nil {
+			return nil, err
+		}
+		if err := proof.VerifyConsistency(rfc6962.DefaultHasher, treeSize, cp.Size, checkpointProof, rootHash, cp.Hash); err != nil {
+			return nil, fmt.Errorf("computed consistency proof to checkpoint does not verify: %w", err)
+		}
+	}
+
+	hashes, err := s.tlogManager.InclusionProof(ctx, logID, index, treeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.tlogManager.ReadEntry(ctx, logID, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry: %w", err)
+	}
+
+	leafHash := rfc6962.DefaultHasher.HashLeaf(data)
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, index, treeSize, leafHash, hashes, rootHash); err != nil {
+		return nil, fmt.Errorf("computed inclusion proof does not verify: %w", err)
+	}
+
+	return &InclusionProofResult{
+		LogEntryWithProof: types.LogEntryWithProof{
+			Entry: types.LogEntry{
+				Index: index,
+				Data:  data,
+			},
+			InclusionProof: bytes.Join(hashes, nil),
+			TreeSize:       treeSize,
+			RootHash:       rootHash,
+		},
+		Checkpoint:      cpRaw,
+		CheckpointProof: checkpointProof,
+	}, nil
+}
+
+// ProveConsistency returns a Merkle consistency proof showing that the tree of
+// size from is a prefix of the tree of size to. A to of 0 selects the size of
+// the latest checkpoint. The proof is verified before it is returned.
+func (s *LogService) ProveConsistency(ctx context.Context, logID string, from, to uint64) (*ConsistencyProofResult, error) {
+	cpRaw, cp, err := s.latestCheckpoint(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to = cp.Size
+	}
+	if to > cp.Size {
+		return nil, fmt.Errorf("%w: tree size %d exceeds checkpoint size %d", ErrInvalidProofRequest, to, cp.Size)
+	}
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("%w: from size %d must be between 1 and %d", ErrInvalidProofRequest, from, to)
+	}
+
+	fromRoot, err := s.tlogManager.RootHashAt(ctx, logID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute root hash at size %d: %w", from, err)
+	}
+	toRoot := cp.Hash
+	if to != cp.Size {
+		toRoot, err = s.tlogManager.RootHashAt(ctx, logID, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute root hash at size %d: %w", to, err)
+		}
+	}
+
+	hashes, err := s.tlogManager.ConsistencyProof(ctx, logID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, from, to, hashes, fromRoot, toRoot); err != nil {
+		return nil, fmt.Errorf("computed consistency proof does not verify: %w", err)
+	}
+
+	return &ConsistencyProofResult{
+		From:       from,
+		To:         to,
+		Proof:      hashes,
+		FromRoot:   fromRoot,
+		ToRoot:     toRoot,
+		Checkpoint: cpRaw,
+	}, nil
+}
+
+// latestCheckpoint reads and parses the latest signed checkpoint for a log.
+func (s *LogService) latestCheckpoint(ctx context.Context, logID string) ([]byte, *formatslog.Checkpoint, error) {
+	cpRaw, err := s.tlogManager.ReadCheckpoint(ctx, logID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCheckpointUnavailable, err)
+	}
+	cp, err := tlog.ParseCheckpoint(cpRaw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cpRaw, cp, nil
+}
+
+// ReadOption configures a Read.
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	atSize *uint64
+	atCID  string
+}
+
+// ReadAtSize reads the log as it was when it contained size entries.
+func ReadAtSize(size uint64) ReadOption {
+	return func(o *readOptions) {
+		o.atSize = &size
+	}
+}
+
+// ReadAtCID reads the log as it was at a past head, identified by its
+// checkpoint CID or index CAR CID.
+func ReadAtCID(cid string) ReadOption {
+	return func(o *readOptions) {
+		o.atCID = cid
+	}
+}
+
+// Read retrieves entries from a log with pagination.
+// By default the latest state is read; ReadAtSize and ReadAtCID bound the
+// read to a past head, in which case Total is the tree size of that head.
+func (s *LogService) Read(ctx context.Context, logID string, offset, limit int64, opts ...ReadOption) (*ReadResult, error) {
+	var o readOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Get total entry count first
 	reader, err := s.tlogManager.GetReader(ctx, logID)
 	if err != nil {
@@ -126,6 +358,21 @@ func (s *LogService) Read(ctx context.Context, logID string, offset, limit int64
 		return nil, fmt.Errorf("failed to get log size: %w", err)
 	}
 
+	// Bound the read to a historical head if requested
+	if o.atCID != "" {
+		head, err := s.GetHeadByCID(ctx, logID, o.atCID)
+		if err != nil {
+			return nil, err
+		}
+		o.atSize = &head.TreeSize
+	}
+	if o.atSize != nil {
+		if *o.atSize > total {
+			return nil, fmt.Errorf("%w: size %d exceeds log size %d", ErrHeadNotFound, *o.atSize, total)
+		}
+		total = *o.atSize
+	}
+
 	// Apply defaults
 	if offset < 0 {
 		offset = 0
@@ -140,7 +387,6 @@ func (s *LogService) Read(ctx context.Context, logID string, offset, limit int64
 	if endIdx > total {
 		endIdx = total
 	}
-
 	entries, err := s.tlogManager.ReadRange(ctx, logID, startIdx, endIdx)
 	if err != nil {
 		return nil, err
@@ -152,15 +398,110 @@ func (s *LogService) Read(ctx context.Context, logID string, offset, limit int64
 	}, nil
 }
 
+// GetHeadAtSize returns the head recorded when the log reached the given tree size.
+func (s *LogService) GetHeadAtSize(ctx context.Context, logID string, treeSize uint64) (*sqlite.HeadRecord, error) {
+	store, err := s.headStore(logID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := store.GetHeadAtSize(ctx, logID, treeSize)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return nil, fmt.Errorf("%w: no head recorded at size %d", ErrHeadNotFound, treeSize)
+	}
+	return head, err
+}
+
+// GetHeadByCID returns the head whose checkpoint CID or index CAR CID matches cid.
+func (s *LogService) GetHeadByCID(ctx context.Context, logID, cid string) (*sqlite.HeadRecord, error) {
+	store, err := s.headStore(logID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := store.GetHeadByCID(ctx, logID, cid)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return nil, fmt.Errorf("%w: no head recorded for CID %s", ErrHeadNotFound, cid)
+	}
+	return head, err
+}
+
+func (s *LogService) headStore(logID string) (*sqlite.LogStore, error) {
+	if s.storeManager == nil {
+		return nil, fmt.Errorf("%w: head history not configured", ErrHeadNotFound)
+	}
+	store, err := s.storeManager.GetStore(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store: %w", err)
+	}
+	return store, nil
+}
+
+// WithAttribution attributes the entries appended under ctx to attr. The
+// attribution is recorded in the same transaction that integrates the
+// entries, so every entry in the log has one.
+func WithAttribution(ctx context.Context, attr sqlite.Attribution) context.Context {
+	return storacha.WithAttribution(ctx, attr)
+}
+
+// GetAttribution returns the attribution of the entry at index.
+func (s *LogService) GetAttribution(ctx context.Context, logID string, index uint64) (*sqlite.Attribution, error) {
+	store, err := s.attributionStore(logID)
+	if err != nil {
+		return nil, err
+	}
+	attr, err := store.GetAttribution(ctx, logID, index)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return nil, fmt.Errorf("%w: no attribution for index %d", ErrAttributionNotFound, index)
+	}
+	return attr, err
+}
+
+// GetAttributionsByIssuer returns the attributions of entries appended by
+// issuerDID, in index order and paginated by offset and limit.
+func (s *LogService) GetAttributionsByIssuer(ctx context.Context, logID, issuerDID string, offset, limit int64) ([]sqlite.Attribution, error) {
+	store, err := s.attributionStore(logID)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetAttributionsByIssuer(ctx, logID, issuerDID, offset, limit)
+}
+
+func (s *LogService) attributionStore(logID string) (*sqlite.LogStore, error) {
+	if s.storeManager == nil {
+		return nil, fmt.Errorf("%w: attribution not configured", ErrAttributionNotFound)
+	}
+	store, err := s.storeManager.GetStore(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store: %w", err)
+	}
+	return store, nil
+}
+
 // Revoke adds a delegation CID to the revocation log and SQLite.
-// Note: Only NEW revocations are written to SQLite. Existing Tessera
-// revocations are not automatically migrated.
+// The revocation log is the source of truth; RebuildRevocations restores
+// SQLite from it. Revocations apply to every log in the log's space.
 func (s *LogService) Revoke(ctx context.Context, logID, delegationCID string, dlg delegation.Delegation) error {
-	revocationLogID := logID + "-revocations"
+	return s.revoke(ctx, logID, types.RevokeUCAN, delegationCID, dlg)
+}
+
+// RevokeAccount revokes every delegation issued by or to accountDID.
+func (s *LogService) RevokeAccount(ctx context.Context, logID, accountDID string, dlg delegation.Delegation) error {
+	return s.revoke(ctx, logID, types.RevokeAccount, accountDID, dlg)
+}
+
+// RevokeCapability revokes every delegation granting ability or an ability
+// nested under it. Grants of a broader ability or wildcard that covers it,
+// such as "tlog/*" or "*", are not revoked.
+func (s *LogService) RevokeCapability(ctx context.Context, logID, ability string, dlg delegation.Delegation) error {
+	return s.revoke(ctx, logID, types.RevokeCapability, ability, dlg)
+}
+
+// revoke writes a revocation entry to the revocation log and SQLite.
+func (s *LogService) revoke(ctx context.Context, logID string, revType types.RevocationType, target string, dlg delegation.Delegation) error {
+	revocationLogID := tlog.RevocationLogID(logID)
 
 	entry := types.RevocationEntry{
-		Type:      types.RevokeUCAN,
-		Target:    []byte(delegationCID),
+		Type:      revType,
+		Target:    []byte(target),
 		Timestamp: time.Now(),
 	}
 
@@ -169,35 +510,71 @@ func (s *LogService) Revoke(ctx context.Context, logID, delegationCID string, dl
 		return fmt.Errorf("failed to serialize revocation entry: %w", err)
 	}
 
-	_, err = s.tlogManager.AddEntryWithDelegation(ctx, revocationLogID, data, dlg)
+	index, err := s.tlogManager.AddEntryWithDelegation(ctx, revocationLogID, data, dlg)
 	if err != nil {
 		return fmt.Errorf("failed to add revocation entry to tessera: %w", err)
 	}
 
 	// Write to SQLite for fast lookups
 	if s.storeManager != nil {
-		store, err := s.storeManager.GetStateStore(logID)
+		store, err := s.storeManager.GetStore(tlog.SpaceDIDForLog(logID))
 		if err != nil {
 			return fmt.Errorf("failed to get store for revocation: %w", err)
 		}
 
-		if err := store.AddRevocation(ctx, delegationCID); err != nil {
+		if revType == types.RevokeUCAN {
+			err = store.AddRevocation(ctx, target)
+		} else {
+			err = store.AddScopedRevocation(ctx, string(revType), target, entry.Timestamp)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to add revocation to sqlite: %w", err)
 		}
 	}
 
+	s.emitRevocation(ctx, tlog.SpaceDIDForLog(logID), webhook.RevocationData{
+		Index:     index,
+		Type:      string(revType),
+		Target:    target,
+		RevokedAt: entry.Timestamp.UTC(),
+	})
+
 	return nil
 }
 
+// emitRevocation notifies the webhooks of every log in a space of a
+// revocation. The revocation is already written, so failures are only logged.
+func (s *LogService) emitRevocation(ctx context.Context, spaceDID string, data webhook.RevocationData) {
+	if s.webhooks == nil {
+		return
+	}
+	logDIDs, err := s.storeManager.LogDIDs()
+	if err != nil {
+		slog.Warn("failed to list logs for revocation webhooks", "space", spaceDID, "error", err)
+		return
+	}
+	for _, logDID := range logDIDs {
+		if logDID != spaceDID && !strings.HasPrefix(logDID, spaceDID+"/") {
+			continue
+		}
+		if err := s.webhooks.Emit(ctx, logDID, webhook.EventRevocation, data); err != nil {
+			slog.Warn("failed to queue revocation webhook event", "log", logDID, "error", err)
+		}
+	}
+}
+
 // GetRevocations reads all revoked delegation CIDs from SQLite ONLY.
-// Does NOT read from Tessera log - historical revocations from before
-// this change will not be returned unless loaded via future admin endpoint.
+// Does NOT read from Tessera log - historical revocations missing from SQLite
+// are loaded by the first revocation check for the space (see checkRevocations).
 func (s *LogService) GetRevocations(ctx context.Context, logID string) ([]types.RevocationEntry, error) {
 	if s.storeManager == nil {
 		return nil, nil // No store manager, return empty
 	}
+	if err := s.checkRevocations(ctx, logID); err != nil {
+		return nil, err
+	}
 
-	store, err := s.storeManager.GetStateStore(logID)
+	store, err := s.storeManager.GetStore(tlog.SpaceDIDForLog(logID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get store: %w", err)
 	}
@@ -207,7 +584,12 @@ func (s *LogService) GetRevocations(ctx context.Context, logID string) ([]types.
 		return nil, fmt.Errorf("failed to get revocations from sqlite: %w", err)
 	}
 
-	entries := make([]types.RevocationEntry, 0, len(cids))
+	scoped, err := store.GetScopedRevocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scoped revocations from sqlite: %w", err)
+	}
+
+	entries := make([]types.RevocationEntry, 0, len(cids)+len(scoped))
 	for i, cid := range cids {
 		entries = append(entries, types.RevocationEntry{
 			Index:  uint64(i),
@@ -215,18 +597,62 @@ func (s *LogService) GetRevocations(ctx context.Context, logID string) ([]types.
 			Target: []byte(cid),
 		})
 	}
+	for _, rev := range scoped {
+		entries = append(entries, types.RevocationEntry{
+			Index:     uint64(len(entries)),
+			Type:      types.RevocationType(rev.Type),
+			Target:    []byte(rev.Target),
+			Timestamp: rev.RevokedAt,
+		})
+	}
 
 	return entries, nil
 }
 
+// RebuildRevocations repopulates the SQLite revocations table of a log from
+// its Tessera revocation log. Revocations already in SQLite are kept.
+func (s *LogService) RebuildRevocations(ctx context.Context, logID string) (*tlog.RebuildRevocationsResult, error) {
+	return s.tlogManager.RebuildRevocations(ctx, logID)
+}
+
+// RestoreLog rebuilds the local state of a log from an index CAR in its
+// space. Unless the space's revocation log exists locally, it is restored
+// first from the index CAR at revocationIndexCID. authorize, if not nil, is
+// called once the space's revocations are loaded, before the log is written.
+func (s *LogService) RestoreLog(ctx context.Context, logID, indexCID, revocationIndexCID string, dlg delegation.Delegation, authorize tlog.AuthorizeFunc) (*tlog.RestoreLogResult, error) {
+	return s.tlogManager.RestoreLog(ctx, logID, indexCID, revocationIndexCID, dlg, authorize)
+}
+
+// MigrateLog takes over a log from the service whose checkpoints verify with
+// previousKey, re-signing it under this service's origin. Unless the space's
+// revocation log exists locally, it is migrated first from the index CAR at
+// revocationIndexCID. authorize, if not nil, is called once the space's
+// revocations are loaded, before the log is handed off.
+func (s *LogService) MigrateLog(ctx context.Context, logID, indexCID, revocationIndexCID, previousKey string, dlg delegation.Delegation, authorize tlog.AuthorizeFunc) (*tlog.MigrateLogResult, error) {
+	return s.tlogManager.MigrateLog(ctx, logID, indexCID, revocationIndexCID, previousKey, dlg, authorize)
+}
+
+// checkRevocations makes sure the revocations of a log's space are loaded
+// into SQLite. It fails when they cannot be, so that delegations are rejected
+// rather than checked against an incomplete table.
+func (s *LogService) checkRevocations(ctx context.Context, logID string) error {
+	if s.tlogManager == nil {
+		return nil
+	}
+	return s.tlogManager.CheckRevocations(ctx, logID)
+}
+
 // IsRevoked checks if a specific delegation CID is revoked.
 // Queries SQLite only - not Tessera.
 func (s *LogService) IsRevoked(ctx context.Context, logID, delegationCID string) (bool, error) {
 	if s.storeManager == nil {
 		return false, nil // No store manager, assume not revoked
 	}
+	if err := s.checkRevocations(ctx, logID); err != nil {
+		return false, err
+	}
 
-	store, err := s.storeManager.GetStateStore(logID)
+	store, err := s.storeManager.GetStateStore(tlog.SpaceDIDForLog(logID))
 	if err != nil {
 		return false, fmt.Errorf("failed to get store: %w", err)
 	}
@@ -234,6 +660,39 @@ func (s *LogService) IsRevoked(ctx context.Context, logID, delegationCID string)
 	return store.IsRevoked(ctx, delegationCID)
 }
 
+// IsScopeRevoked checks if a delegation is covered by an account-wide or
+// capability-scoped revocation: its issuer or audience is a revoked account,
+// or it grants a revoked ability or an ability nested under one. A grant of a
+// broader ability or wildcard, such as "tlog/*", is not revoked by revoking
+// an ability it covers. Queries SQLite only - not Tessera.
+func (s *LogService) IsScopeRevoked(ctx context.Context, logID string, dlg delegation.Delegation) (bool, error) {
+	if s.storeManager == nil {
+		return false, nil // No store manager, assume not revoked
+	}
+	if err := s.checkRevocations(ctx, logID); err != nil {
+		return false, err
+	}
+
+	store, err := s.storeManager.GetStore(tlog.SpaceDIDForLog(logID))
+	if err != nil {
+		return false, fmt.Errorf("failed to get store: %w", err)
+	}
+
+	accounts := []string{dlg.Issuer().DID().String(), dlg.Audience().DID().String()}
+	revoked, err := store.HasScopedRevocation(ctx, string(types.RevokeAccount), accounts)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	// A revoked ability matches the grants it covers, so look up every
+	// ability covering each granted one
+	var abilities []string
+	for _, c := range dlg.Capabilities() {
+		abilities = append(abilities, ucanPkg.CoveringAbilities(c.Can())...)
+	}
+	return store.HasScopedRevocation(ctx, string(types.RevokeCapability), abilities)
+}
+
 // GetLogMeta retrieves metadata for a log.
 func (s *LogService) GetLogMeta(ctx context.Context, logID string) (*tlog.LogMeta, error) {
 	if s.logMetaStore == nil {
@@ -247,6 +706,12 @@ func (s *LogService) ReadCheckpoint(ctx context.Context, logID string) ([]byte,
 	return s.tlogManager.ReadCheckpoint(ctx, logID)
 }
 
+// SubscribeCheckpoints subscribes to the checkpoints published by the given
+// logs, or by every log when none are given (for checkpoint streams)
+func (s *LogService) SubscribeCheckpoints(logIDs ...string) (<-chan tlog.CheckpointEvent, func()) {
+	return s.tlogManager.SubscribeCheckpoints(logIDs...)
+}
+
 // ReadTile reads a Merkle tree tile from a log (for tlog-tiles API)
 func (s *LogService) ReadTile(ctx context.Context, logID string, level, index uint64, partialWidth uint8) ([]byte, error) {
 	return s.tlogManager.ReadTile(ctx, logID, level, index, partialWidth)
@@ -263,12 +728,22 @@ func (s *LogService) GetBlobFetcher(ctx context.Context, spaceDID string, dlg de
 	return s.tlogManager.GetBlobFetcher(ctx, spaceDID, spaceDID, dlg)
 }
 
+// Fsck checks that a log's CID index, entry bundles and hash tiles describe
+// the tree in its tree state and latest checkpoint. With repair set, missing
+// or corrupt hash tiles are re-derived from the entry bundles.
+func (s *LogService) Fsck(ctx context.Context, logID string, repair bool, dlg delegation.Delegation) (*tlog.FsckReport, error) {
+	return s.tlogManager.Fsck(ctx, logID, repair, dlg)
+}
+
 // GCResult contains the results of a garbage collection run.
 type GCResult struct {
-	BundlesProcessed int    // Number of bundles processed
-	BlobsRemoved     int    // Number of blobs removed
-	BytesFreed       uint64 // Bytes freed (estimated)
-	NewGCPosition    uint64 // New GC checkpoint position
+	BundlesProcessed int       // Number of bundles processed
+	BlobsRemoved     int       // Number of blobs removed
+	BytesFreed       uint64    // Bytes freed, or that a dry run would free
+	UnknownSizes     int       // Blobs whose size is unknown, not counted in BytesFreed
+	NewGCPosition    uint64    // New GC checkpoint position
+	Blobs            []gc.Blob // Obsolete blobs removed, or that a dry run would remove
+	DryRun           bool      // Nothing was removed
 }
 
 // RunGC runs garbage collection for a log using the provided delegation.
@@ -281,11 +756,217 @@ func (s *LogService) RunGC(ctx context.Context, logID string, dlg delegation.Del
 		return nil, err
 	}
 
-	// Convert from storacha.GCResult to log.GCResult
+	s.emitGC(ctx, logID, result)
+
+	return newGCResult(result), nil
+}
+
+// PlanGC is a dry run of RunGC: it reports what the next run would remove
+// without removing anything, so it needs no remove delegation.
+func (s *LogService) PlanGC(ctx context.Context, logID string) (*GCResult, error) {
+	result, err := s.tlogManager.PlanGC(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	return newGCResult(result), nil
+}
+
+// newGCResult converts from storacha.GCResult to log.GCResult.
+func newGCResult(result *storacha.GCResult) *GCResult {
 	return &GCResult{
 		BundlesProcessed: result.BundlesProcessed,
 		BlobsRemoved:     result.BlobsRemoved,
 		BytesFreed:       result.BytesFreed,
+		UnknownSizes:     result.UnknownSizes,
 		NewGCPosition:    result.NewGCPosition,
-	}, nil
+		Blobs:            result.Blobs,
+		DryRun:           result.DryRun,
+	}
+}
+
+// emitGC notifies the log's webhooks of a completed garbage collection run.
+func (s *LogService) emitGC(ctx context.Context, logID string, result *storacha.GCResult) {
+	if s.webhooks == nil {
+		return
+	}
+	err := s.webhooks.Emit(ctx, logID, webhook.EventGC, webhook.GCData{
+		BundlesProcessed: result.BundlesProcessed,
+		BlobsRemoved:     result.BlobsRemoved,
+		BytesFreed:       result.BytesFreed,
+		GCPosition:       result.NewGCPosition,
+	})
+	if err != nil {
+		slog.Warn("failed to queue gc webhook event", "log", logID, "error", err)
+	}
+}
+
+// ScheduleGC stores a space/blob/remove delegation with which the log is
+// garbage collected in the background. The delegation must satisfy
+// ucan.ValidateGCDelegation; it is dropped once it expires or is revoked.
+func (s *LogService) ScheduleGC(ctx context.Context, logID string, dlg delegation.Delegation, createdBy string) (*sqlite.GCSchedule, error) {
+	return s.tlogManager.ScheduleGC(ctx, logID, dlg, createdBy)
+}
+
+// UnscheduleGC drops the stored GC delegation of a log.
+func (s *LogService) UnscheduleGC(ctx context.Context, logID string) error {
+	return s.tlogManager.UnscheduleGC(ctx, logID)
+}
+
+// StartGCScheduler starts background garbage collection of the logs with a
+// stored GC delegation, collecting each one every interval (zero for the
+// default). Scheduled runs notify webhooks like tlog/gc invocations.
+func (s *LogService) StartGCScheduler(ctx context.Context, interval time.Duration) error {
+	return s.tlogManager.StartGCScheduler(ctx, tlog.GCSchedulerConfig{
+		Interval:    interval,
+		IsRevoked:   s.isDelegationRevoked,
+		OnCollected: s.emitGC,
+	})
+}
+
+// isDelegationRevoked reports whether a delegation without proofs, such as
+// a GC delegation, is revoked by CID or by an account or capability scope.
+func (s *LogService) isDelegationRevoked(ctx context.Context, logID string, dlg delegation.Delegation) (bool, error) {
+	revoked, err := s.IsRevoked(ctx, logID, dlg.Link().String())
+	if err != nil || revoked {
+		return revoked, err
+	}
+	return s.IsScopeRevoked(ctx, logID, dlg)
+}
+
+// AddWebhookParams describes a webhook to register on a log.
+type AddWebhookParams struct {
+	URL string
+	// Events are the event types delivered; empty subscribes to all of them
+	Events []string
+	// Signing is webhook.SigningHMAC (the default) or webhook.SigningUCAN
+	Signing   string
+	CreatedBy string // DID registering the webhook
+}
+
+// WebhookInfo describes a registered webhook and its pending deliveries.
+type WebhookInfo struct {
+	sqlite.Webhook
+	Pending int64 // Notifications awaiting delivery
+}
+
+// AddWebhook registers a webhook on a log. For HMAC signing the returned
+// webhook holds the generated secret, which is not returned again.
+func (s *LogService) AddWebhook(ctx context.Context, logID string, params AddWebhookParams) (*sqlite.Webhook, error) {
+	if s.webhooks == nil || s.storeManager == nil {
+		return nil, ErrWebhooksDisabled
+	}
+	if err := webhook.ValidateURL(params.URL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	events, err := webhook.ValidateEvents(params.Events)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	w := sqlite.Webhook{
+		URL:       params.URL,
+		Events:    events,
+		Signing:   params.Signing,
+		CreatedBy: params.CreatedBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	switch w.Signing {
+	case "", webhook.SigningHMAC:
+		w.Signing = webhook.SigningHMAC
+		if w.Secret, err = webhook.NewSecret(); err != nil {
+			return nil, err
+		}
+	case webhook.SigningUCAN:
+		if s.webhooks.SignerDID() == "" {
+			return nil, fmt.Errorf("%w: UCAN signing is not configured", ErrInvalidWebhook)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown signing scheme %q (expected %s or %s)",
+			ErrInvalidWebhook, w.Signing, webhook.SigningHMAC, webhook.SigningUCAN)
+	}
+	if w.ID, err = webhook.NewID(); err != nil {
+		return nil, err
+	}
+
+	store, err := s.webhookStore(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	// Append events start with the entries integrated after the first webhook
+	// is added, not with the log's existing entries
+	if _, err := store.GetWebhookCursor(ctx, logID); errors.Is(err, sqlite.ErrNotFound) {
+		size, _, err := store.GetTreeState(ctx, logID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree state: %w", err)
+		}
+		if err := store.SetWebhookCursor(ctx, logID, size); err != nil {
+			return nil, fmt.Errorf("failed to set webhook cursor: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read webhook cursor: %w", err)
+	}
+	if err := store.AddWebhook(ctx, logID, w); err != nil {
+		return nil, fmt.Errorf("failed to add webhook: %w", err)
+	}
+	return &w, nil
+}
+
+// RemoveWebhook removes a webhook from a log, dropping its pending deliveries.
+func (s *LogService) RemoveWebhook(ctx context.Context, logID, id string) error {
+	store, err := s.webhookStore(ctx, logID)
+	if err != nil {
+		return err
+	}
+	err = store.RemoveWebhook(ctx, logID, id)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	return err
+}
+
+// ListWebhooks returns the webhooks registered on a log. Secrets are omitted.
+func (s *LogService) ListWebhooks(ctx context.Context, logID string) ([]WebhookInfo, error) {
+	store, err := s.webhookStore(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := store.ListWebhooks(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]WebhookInfo, len(webhooks))
+	for i, w := range webhooks {
+		w.Secret = ""
+		pending, err := store.CountWebhookDeliveries(ctx, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = WebhookInfo{Webhook: w, Pending: pending}
+	}
+	return infos, nil
+}
+
+// webhookStore returns the store holding the webhooks of an existing log.
+func (s *LogService) webhookStore(ctx context.Context, logID string) (*sqlite.LogStore, error) {
+	if s.webhooks == nil || s.storeManager == nil {
+		return nil, ErrWebhooksDisabled
+	}
+	if _, err := s.tlogManager.GetLogInstance(ctx, logID); err != nil {
+		return nil, fmt.Errorf("log %s not found: %w", logID, err)
+	}
+	store, err := s.storeManager.GetStore(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store: %w", err)
+	}
+	return store, nil
+}
+
+// WebhookSigner returns the DID that UCAN-signed webhook payloads verify
+// against, or an empty string if webhooks are not configured.
+func (s *LogService) WebhookSigner() string {
+	if s.webhooks == nil {
+		return ""
+	}
+	return s.webhooks.SignerDID()
 }
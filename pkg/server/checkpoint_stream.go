@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+// maxStreamLogs bounds the logs one multi-log checkpoint stream may follow.
+const maxStreamLogs = 1000
+
+// streamKeepAlive is how often an idle checkpoint stream sends a comment, so
+// proxies do not close the connection.
+const streamKeepAlive = 30 * time.Second
+
+// CheckpointSource provides the checkpoints streamed to clients.
+type CheckpointSource interface {
+	ReadCheckpoint(ctx context.Context, logID string) ([]byte, error)
+	SubscribeCheckpoints(logIDs ...string) (<-chan tlog.CheckpointEvent, func())
+}
+
+// CheckpointStreamHandler streams checkpoints over Server-Sent Events, so
+// monitors learn of new checkpoints without polling.
+type CheckpointStreamHandler struct {
+	source CheckpointSource
+}
+
+// NewCheckpointStreamHandler creates a new checkpoint stream handler.
+func NewCheckpointStreamHandler(source CheckpointSource) *CheckpointStreamHandler {
+	return &CheckpointStreamHandler{
+		source: source,
+	}
+}
+
+// CheckpointStreamEvent is the data of a "checkpoint" event.
+type CheckpointStreamEvent struct {
+	LogID      string `json:"log_id"`
+	TreeSize   uint64 `json:"tree_size"`
+	Checkpoint string `json:"checkpoint"` // Signed checkpoint note
+}
+
+// HandleLogStream handles GET /logs/{logID}/checkpoints/stream.
+// Streams the log's latest checkpoint, then each new one as it is published.
+func (h *CheckpointStreamHandler) HandleLogStream(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	if logID == "" || !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+	h.stream(w, r, []string{logID})
+}
+
+// HandleStream handles GET /checkpoints/stream?log={logID}&log={logID}...
+// Streams the latest checkpoint of each log, then each new one as it is
+// published.
+func (h *CheckpointStreamHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	logIDs := r.URL.Query()["log"]
+	if len(logIDs) == 0 {
+		http.Error(w, "at least one log parameter is required", http.StatusBadRequest)
+		return
+	}
+	if len(logIDs) > maxStreamLogs {
+		http.Error(w, fmt.Sprintf("at most %d logs per stream", maxStreamLogs), http.StatusBadRequest)
+		return
+	}
+	for _, logID := range logIDs {
+		if !isValidLogID(logID) {
+			http.Error(w, "invalid logID", http.StatusBadRequest)
+			return
+		}
+	}
+	h.stream(w, r, logIDs)
+}
+
+// stream writes checkpoint events for logIDs until the client disconnects.
+// Checkpoints no larger than one already sent for the log are skipped, so the
+// initial checkpoints and those published meanwhile are not sent twice.
+func (h *CheckpointStreamHandler) stream(w http.ResponseWriter, r *http.Request, logIDs []string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ctx := r.Context()
+
+	// Subscribe before reading the latest checkpoints so none are missed
+	events, cancel := h.source.SubscribeCheckpoints(logIDs...)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sent := make(map[string]uint64)
+	send := func(ev tlog.CheckpointEvent) error {
+		if last, ok := sent[ev.LogID]; ok && ev.TreeSize <= last {
+			return nil
+		}
+		sent[ev.LogID] = ev.TreeSize
+		data, err := json.Marshal(CheckpointStreamEvent{
+			LogID:      ev.LogID,
+			TreeSize:   ev.TreeSize,
+			Checkpoint: string(ev.Checkpoint),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: checkpoint\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	for _, logID := range logIDs {
+		raw, err := h.source.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			continue // Not published yet, or not held by this service
+		}
+		cp, err := tlog.ParseCheckpoint(raw)
+		if err != nil {
+			continue
+		}
+		if err := send(tlog.CheckpointEvent{LogID: logID, TreeSize: cp.Size, Checkpoint: raw}); err != nil {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
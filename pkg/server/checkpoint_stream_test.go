@@ -0,0 +1,121 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/pkg/server"
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+// fakeCheckpointSource serves fixed latest checkpoints and forwards published
+// ones to its subscriber.
+type fakeCheckpointSource struct {
+	latest     map[string][]byte
+	events     chan tlog.CheckpointEvent
+	subscribed chan []string
+}
+
+func (s *fakeCheckpointSource) ReadCheckpoint(ctx context.Context, logID string) ([]byte, error) {
+	if cp, ok := s.latest[logID]; ok {
+		return cp, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (s *fakeCheckpointSource) SubscribeCheckpoints(logIDs ...string) (<-chan tlog.CheckpointEvent, func()) {
+	s.subscribed <- logIDs
+	return s.events, func() {}
+}
+
+func testCheckpoint(logID string, size uint64) []byte {
+	return []byte(fmt.Sprintf("test/logs/%s\n%d\nAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n\n— test sig\n", logID, size))
+}
+
+func TestCheckpointStreamHandler(t *testing.T) {
+	logA, logB := "did:key:z6MkStreamA", "did:key:z6MkStreamB"
+	source := &fakeCheckpointSource{
+		latest:     map[string][]byte{logA: testCheckpoint(logA, 5)},
+		events:     make(chan tlog.CheckpointEvent, 4),
+		subscribed: make(chan []string, 1),
+	}
+	handler := server.NewCheckpointStreamHandler(source)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /logs/{logID}/checkpoints/stream", handler.HandleLogStream)
+	mux.HandleFunc("GET /checkpoints/stream", handler.HandleStream)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"/checkpoints/stream?log="+logA+"&log="+logB, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []string{logA, logB}, <-source.subscribed)
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() server.CheckpointStreamEvent {
+		t.Helper()
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" && data != "" {
+				break
+			}
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				event = v
+			}
+			if v, ok := strings.CutPrefix(line, "data: "); ok {
+				data = v
+			}
+		}
+		require.Equal(t, "checkpoint", event)
+		var ev server.CheckpointStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(data), &ev))
+		return ev
+	}
+
+	// The latest checkpoint is sent on connect
+	ev := next()
+	assert.Equal(t, logA, ev.LogID)
+	assert.Equal(t, uint64(5), ev.TreeSize)
+	assert.Equal(t, string(testCheckpoint(logA, 5)), ev.Checkpoint)
+
+	// Published checkpoints follow, skipping any already sent
+	source.events <- tlog.CheckpointEvent{LogID: logA, TreeSize: 5, Checkpoint: testCheckpoint(logA, 5)}
+	source.events <- tlog.CheckpointEvent{LogID: logB, TreeSize: 1, Checkpoint: testCheckpoint(logB, 1)}
+	source.events <- tlog.CheckpointEvent{LogID: logA, TreeSize: 6, Checkpoint: testCheckpoint(logA, 6)}
+	ev = next()
+	assert.Equal(t, logB, ev.LogID)
+	assert.Equal(t, uint64(1), ev.TreeSize)
+	ev = next()
+	assert.Equal(t, logA, ev.LogID)
+	assert.Equal(t, uint64(6), ev.TreeSize)
+}
+
+func TestCheckpointStreamHandler_BadRequest(t *testing.T) {
+	handler := server.NewCheckpointStreamHandler(&fakeCheckpointSource{})
+
+	for _, target := range []string{"/checkpoints/stream", "/checkpoints/stream?log=did:key:a/../b"} {
+		rec := httptest.NewRecorder()
+		handler.HandleStream(rec, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
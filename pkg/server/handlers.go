@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/storacha/go-ucanto/core/dag/blockstore"
 	"github.com/storacha/go-ucanto/core/delegation"
@@ -13,12 +15,16 @@ import (
 	"github.com/storacha/go-ucanto/core/invocation"
 	"github.com/storacha/go-ucanto/core/receipt/fx"
 	"github.com/storacha/go-ucanto/core/result"
+	"github.com/storacha/go-ucanto/did"
 	"github.com/storacha/go-ucanto/server"
 	"github.com/storacha/go-ucanto/ucan"
 
 	"github.com/relves/ucanlog/pkg/capabilities"
 	logSvc "github.com/relves/ucanlog/pkg/log"
+	"github.com/relves/ucanlog/pkg/tlog"
+	"github.com/relves/ucanlog/pkg/types"
 	ucanPkg "github.com/relves/ucanlog/pkg/ucan"
+	"github.com/relves/ucanlog/pkg/webhook"
 )
 
 // createHandler returns a handler function for tlog/create capability
@@ -86,16 +92,28 @@ func createHandler(serviceDID string, logService *logSvc.LogService, storeManage
 		// The delegation must trace back to the space owner
 		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
 			return result.Error[capabilities.CreateSuccess](capabilities.NewCreateFailure(
-				ucanPkg.ErrCodeDelegationNoAuthority,
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
 				err.Error(),
 			)), nil, nil
 		}
 
-		// Create log using space DID as identity
+		var name string
+		if cap.Nb().Name != nil {
+			name = *cap.Nb().Name
+		}
+
+		// Create the space's default log, or a named log in the space
 		logResult, err := logService.CreateLogWithDelegation(ctx, logSvc.CreateLogParams{
 			SpaceDID:   spaceDID,
+			Name:       name,
 			Delegation: dlg,
 		})
+		if errors.Is(err, tlog.ErrInvalidLogName) {
+			return result.Error[capabilities.CreateSuccess](capabilities.NewCreateFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
 		if err != nil {
 			return result.Error[capabilities.CreateSuccess](capabilities.NewCreateFailure(
 				"LogCreationFailed",
@@ -130,177 +148,326 @@ func appendHandler(serviceDID string, logService *logSvc.LogService, storeManage
 		inv invocation.Invocation,
 		ictx server.InvocationContext,
 	) (result.Result[capabilities.AppendSuccess, capabilities.AppendFailure], fx.Effects, error) {
-		// Validate request if validator is configured
-		if validator != nil {
-			if err := validator.ValidateRequest(ctx, inv); err != nil {
-				var vErr *ValidationError
-				if errors.As(err, &vErr) {
-					return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-						vErr.Code,
-						vErr.Message,
-					)), nil, nil
-				}
-				return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-					"VALIDATION_ERROR",
-					err.Error(),
-				)), nil, nil
-			}
+		fail := func(f *ValidationError) (result.Result[capabilities.AppendSuccess, capabilities.AppendFailure], fx.Effects, error) {
+			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(f.Code, f.Message)), nil, nil
 		}
 
-		// Delegation is now required
-		if cap.Nb().Delegation == "" {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"MissingDelegation",
-				"delegation is required",
-			)), nil, nil
+		req, vErr := authorizeAppend(ctx, serviceDID, logService, storeManager, validator, inv, appendTarget{
+			Delegation: cap.Nb().Delegation,
+			IndexCID:   cap.Nb().IndexCID,
+			Name:       cap.Nb().Name,
+		})
+		if vErr != nil {
+			return fail(vErr)
 		}
 
-		// Parse delegation
-		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
-		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"InvalidDelegation",
-				fmt.Sprintf("failed to parse delegation: %v", err),
-			)), nil, nil
+		entries, vErr := decodeEntries([]string{cap.Nb().Data}, cap.Nb().Envelope, req.attr)
+		if vErr != nil {
+			return fail(vErr)
 		}
 
-		// Extract space DID (this is the log identity)
-		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		// Append to the log using the validated delegation
+		index, err := logService.Append(logSvc.WithAttribution(ctx, req.attr), req.logID, entries[0], req.dlg)
 		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"InvalidSpaceDID",
-				fmt.Sprintf("failed to extract space DID: %v", err),
-			)), nil, nil
+			return fail(NewValidationError(
+				"AppendFailed",
+				fmt.Sprintf("failed to append to log: %v", err),
+			))
 		}
 
-		// Validate delegation
-		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"InvalidDelegation",
-				err.Error(),
-			)), nil, nil
+		newIndexCID, treeSize := appendedHead(ctx, storeManager, req.logID)
+		success := capabilities.AppendSuccess{
+			Index:       int64(index),
+			NewIndexCID: newIndexCID,
+			TreeSize:    treeSize,
 		}
 
-		// Validate invocation authority
-		invocationIssuerDID := inv.Issuer().DID().String()
-		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				ucanPkg.ErrCodeInvocationNotAuthorized,
-				err.Error(),
-			)), nil, nil
+		// The entry is in the log, so a receipt that cannot be signed is
+		// reported alongside the index rather than as a failure
+		if receipts, err := issueReceipts(req, index, entries); err != nil {
+			success.ReceiptError = fmt.Sprintf("receipt could not be signed: %v", err)
+		} else {
+			success.Receipt = receipts[0]
 		}
 
-		// Validate proof chain
-		// The delegation must trace back to the space owner
-		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				ucanPkg.ErrCodeDelegationNoAuthority,
-				err.Error(),
-			)), nil, nil
+		return result.Ok[capabilities.AppendSuccess, capabilities.AppendFailure](success), nil, nil
+	}
+}
+
+// appendBatchHandler returns a handler function for tlog/append-batch capability
+func appendBatchHandler(serviceDID string, logService *logSvc.LogService, storeManager interface{}, validator RequestValidator) server.HandlerFunc[capabilities.AppendBatchCaveats, capabilities.AppendBatchSuccess, capabilities.AppendBatchFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.AppendBatchCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.AppendBatchSuccess, capabilities.AppendBatchFailure], fx.Effects, error) {
+		fail := func(f *ValidationError) (result.Result[capabilities.AppendBatchSuccess, capabilities.AppendBatchFailure], fx.Effects, error) {
+			return result.Error[capabilities.AppendBatchSuccess](capabilities.NewAppendBatchFailure(f.Code, f.Message)), nil, nil
 		}
 
-		// Check if the delegation (from caveat) or any in its proof chain is revoked
-		revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
-		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"RevocationCheckFailed",
-				fmt.Sprintf("failed to check delegation revocations: %v", err),
-			)), nil, nil
+		req, vErr := authorizeAppend(ctx, serviceDID, logService, storeManager, validator, inv, appendTarget{
+			Delegation: cap.Nb().Delegation,
+			IndexCID:   cap.Nb().IndexCID,
+			Name:       cap.Nb().Name,
+		})
+		if vErr != nil {
+			return fail(vErr)
 		}
-		if revokedCID != "" {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"DelegationRevoked",
-				fmt.Sprintf("delegation %s has been revoked", revokedCID),
-			)), nil, nil
+
+		// Decode all entries up front so a malformed entry rejects the whole batch
+		if len(cap.Nb().Entries) == 0 {
+			return fail(NewValidationError(
+				"InvalidData",
+				"at least one entry is required",
+			))
+		}
+		if len(cap.Nb().Entries) > tlog.MaxBatchEntries {
+			return fail(NewValidationError(
+				"BatchTooLarge",
+				fmt.Sprintf("batch of %d entries exceeds the maximum of %d", len(cap.Nb().Entries), tlog.MaxBatchEntries),
+			))
+		}
+		entries, vErr := decodeEntries(cap.Nb().Entries, cap.Nb().Envelope, req.attr)
+		if vErr != nil {
+			return fail(vErr)
 		}
 
-		// Also check revocations for any proofs attached to the invocation itself
-		revokedCID, err = checkRevocations(ctx, inv, spaceDID, logService)
+		// Append all entries using the validated delegation
+		firstIndex, lastIndex, err := logService.AppendBatch(logSvc.WithAttribution(ctx, req.attr), req.logID, entries, req.dlg)
 		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"RevocationCheckFailed",
-				fmt.Sprintf("failed to check revocations: %v", err),
-			)), nil, nil
+			return fail(NewValidationError(
+				"AppendFailed",
+				fmt.Sprintf("failed to append batch to log: %v", err),
+			))
 		}
-		if revokedCID != "" {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"DelegationRevoked",
-				fmt.Sprintf("delegation %s has been revoked", revokedCID),
-			)), nil, nil
+
+		newIndexCID, treeSize := appendedHead(ctx, storeManager, req.logID)
+		success := capabilities.AppendBatchSuccess{
+			FirstIndex:  int64(firstIndex),
+			LastIndex:   int64(lastIndex),
+			NewIndexCID: newIndexCID,
+			TreeSize:    treeSize,
 		}
 
-		// Validate optimistic concurrency (IndexCID from caveats must match current head)
-		// Only validate if IndexCID is provided (optional field)
-		if storeManager != nil && cap.Nb().IndexCID != nil {
-			sm, ok := storeManager.(*sqlite.StoreManager)
-			if !ok {
-				return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-					"InternalError",
-					"store manager type mismatch",
-				)), nil, nil
-			}
+		// As for tlog/append, receipts that cannot be signed are reported
+		// alongside the committed range rather than as a failure
+		if receipts, err := issueReceipts(req, firstIndex, entries); err != nil {
+			success.ReceiptError = fmt.Sprintf("receipts could not be signed: %v", err)
+		} else {
+			success.Receipts = receipts
+		}
 
-			store, err := sm.GetStore(spaceDID)
-			if err != nil {
-				return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-					"StoreAccessFailed",
-					fmt.Sprintf("failed to get store: %v", err),
-				)), nil, nil
-			}
+		return result.Ok[capabilities.AppendBatchSuccess, capabilities.AppendBatchFailure](success), nil, nil
+	}
+}
 
-			expectedIndexCID := *cap.Nb().IndexCID
-			currentIndexCID, treeSize, err := store.GetHead(ctx, spaceDID)
-			if err != nil {
-				return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-					"HeadAccessFailed",
-					fmt.Sprintf("failed to get current head: %v", err),
-				)), nil, nil
-			}
-			if currentIndexCID != expectedIndexCID {
-				// Head mismatch - concurrent modification detected
-				return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-					"HeadMismatch",
-					fmt.Sprintf("expected head %s but current head is %s (tree size: %d)",
-						expectedIndexCID, currentIndexCID, treeSize),
-				)), nil, nil
+// appendTarget holds the caveats tlog/append and tlog/append-batch share.
+type appendTarget struct {
+	Delegation string
+	IndexCID   *string
+	Name       *string
+}
+
+// authorizedAppend is an append that passed the checks run before anything
+// is written to the log.
+type authorizedAppend struct {
+	logID    string
+	dlg      delegation.Delegation
+	attr     sqlite.Attribution
+	receipts *tlog.ReceiptIssuer
+}
+
+// authorizeAppend runs the checks tlog/append and tlog/append-batch share
+// before writing: request validation, the delegation and its proof chain,
+// revocations, the log name, the expected head if the caveats name one, and
+// that receipts can be signed for the appended entries.
+// A failed check is returned as the failure name and message.
+func authorizeAppend(
+	ctx context.Context,
+	serviceDID string,
+	logService *logSvc.LogService,
+	storeManager interface{},
+	validator RequestValidator,
+	inv invocation.Invocation,
+	target appendTarget,
+) (*authorizedAppend, *ValidationError) {
+	// Validate request if validator is configured
+	if validator != nil {
+		if err := validator.ValidateRequest(ctx, inv); err != nil {
+			var vErr *ValidationError
+			if errors.As(err, &vErr) {
+				return nil, vErr
 			}
+			return nil, NewValidationError("VALIDATION_ERROR", err.Error())
+		}
+	}
+
+	// Delegation is now required
+	if target.Delegation == "" {
+		return nil, NewValidationError("MissingDelegation", "delegation is required")
+	}
+
+	// Parse delegation
+	dlg, err := ucanPkg.ParseDelegation(target.Delegation)
+	if err != nil {
+		return nil, NewValidationError(
+			"InvalidDelegation",
+			fmt.Sprintf("failed to parse delegation: %v", err),
+		)
+	}
+
+	// Extract space DID (this is the log identity)
+	spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+	if err != nil {
+		return nil, NewValidationError(
+			"InvalidSpaceDID",
+			fmt.Sprintf("failed to extract space DID: %v", err),
+		)
+	}
+
+	// Validate delegation
+	if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+		return nil, NewValidationError("InvalidDelegation", err.Error())
+	}
+
+	// Validate invocation authority
+	invocationIssuerDID := inv.Issuer().DID().String()
+	if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+		return nil, NewValidationError(ucanPkg.ErrCodeInvocationNotAuthorized, err.Error())
+	}
+
+	// Validate proof chain
+	// The delegation must trace back to the space owner
+	if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+		return nil, NewValidationError(
+			delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+			err.Error(),
+		)
+	}
+
+	if vErr := checkRequestRevoked(ctx, inv, dlg, spaceDID, logService); vErr != nil {
+		return nil, vErr
+	}
+
+	logID, err := resolveLogID(spaceDID, target.Name)
+	if err != nil {
+		return nil, NewValidationError("InvalidLogName", err.Error())
+	}
+
+	// Validate optimistic concurrency (IndexCID from caveats must match current head)
+	// Only validate if IndexCID is provided (optional field)
+	if storeManager != nil && target.IndexCID != nil {
+		sm, ok := storeManager.(*sqlite.StoreManager)
+		if !ok {
+			return nil, NewValidationError("InternalError", "store manager type mismatch")
 		}
 
-		// Decode base64 data from caveats
-		data, err := base64.StdEncoding.DecodeString(cap.Nb().Data)
+		store, err := sm.GetStore(logID)
 		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"InvalidData",
-				fmt.Sprintf("failed to decode base64 data: %v", err),
-			)), nil, nil
+			return nil, NewValidationError(
+				"StoreAccessFailed",
+				fmt.Sprintf("failed to get store: %v", err),
+			)
 		}
 
-		// Append to the log using spaceDID and the validated delegation
-		index, err := logService.Append(ctx, spaceDID, data, dlg)
+		expectedIndexCID := *target.IndexCID
+		currentIndexCID, treeSize, err := store.GetHead(ctx, logID)
 		if err != nil {
-			return result.Error[capabilities.AppendSuccess](capabilities.NewAppendFailure(
-				"AppendFailed",
-				fmt.Sprintf("failed to append to log: %v", err),
-			)), nil, nil
+			return nil, NewValidationError(
+				"HeadAccessFailed",
+				fmt.Sprintf("failed to get current head: %v", err),
+			)
+		}
+		if currentIndexCID != expectedIndexCID {
+			// Head mismatch - concurrent modification detected
+			return nil, NewValidationError(
+				"HeadMismatch",
+				fmt.Sprintf("expected head %s but current head is %s (tree size: %d)",
+					expectedIndexCID, currentIndexCID, treeSize),
+			)
 		}
+	}
 
-		// Get updated head and tree size after append
-		var newIndexCID string
-		var treeSize uint64
-		if storeManager != nil {
-			if sm, ok := storeManager.(*sqlite.StoreManager); ok {
-				if store, err := sm.GetStore(spaceDID); err == nil {
-					newIndexCID, treeSize, _ = store.GetHead(ctx, spaceDID)
+	// Receipts are signed after the entries are committed, so an append
+	// that could not get them is refused before anything is written
+	receipts, err := logService.ReceiptIssuer(logID)
+	if err != nil {
+		return nil, NewValidationError(
+			"ReceiptUnavailable",
+			fmt.Sprintf("cannot issue append receipts: %v", err),
+		)
+	}
+
+	return &authorizedAppend{
+		logID:    logID,
+		dlg:      dlg,
+		attr:     newAttribution(inv, dlg),
+		receipts: receipts,
+	}, nil
+}
+
+// decodeEntries decodes base64 entries from an append's caveats and, if
+// envelope is set, wraps each in a types.AttributedEntry.
+func decodeEntries(encoded []string, envelope *bool, attr sqlite.Attribution) ([][]byte, *ValidationError) {
+	entries := make([][]byte, len(encoded))
+	for i, e := range encoded {
+		data, err := base64.StdEncoding.DecodeString(e)
+		if err != nil {
+			return nil, NewValidationError(
+				"InvalidData",
+				fmt.Sprintf("failed to decode base64 data%s: %v", entryLabel(i, len(encoded)), err),
+			)
+		}
+		if envelope != nil && *envelope {
+			if data, err = envelopeEntry(data, attr); err != nil {
+				msg := err.Error()
+				if len(encoded) > 1 {
+					msg = fmt.Sprintf("entry %d: %v", i, err)
 				}
+				return nil, NewValidationError("InvalidData", msg)
 			}
 		}
+		entries[i] = data
+	}
+	return entries, nil
+}
 
-		return result.Ok[capabilities.AppendSuccess, capabilities.AppendFailure](capabilities.AppendSuccess{
-			Index:       int64(index),
-			NewIndexCID: newIndexCID,
-			TreeSize:    treeSize,
-		}), nil, nil
+// entryLabel names entry i in messages about an append of n entries.
+func entryLabel(i, n int) string {
+	if n == 1 {
+		return ""
+	}
+	return fmt.Sprintf(" for entry %d", i)
+}
+
+// issueReceipts signs a receipt for each of the entries appended from
+// firstIndex on, returning the receipts in entry order.
+func issueReceipts(req *authorizedAppend, firstIndex uint64, entries [][]byte) ([]string, error) {
+	receipts := make([]string, len(entries))
+	for i, data := range entries {
+		receipt, err := req.receipts.Issue(firstIndex+uint64(i), data)
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = string(receipt)
+	}
+	return receipts, nil
+}
+
+// appendedHead returns the head CID and tree size of a log after an append,
+// or zero values if they cannot be read.
+func appendedHead(ctx context.Context, storeManager interface{}, logID string) (string, uint64) {
+	var indexCID string
+	var treeSize uint64
+	if storeManager != nil {
+		if sm, ok := storeManager.(*sqlite.StoreManager); ok {
+			if store, err := sm.GetStore(logID); err == nil {
+				indexCID, treeSize, _ = store.GetHead(ctx, logID)
+			}
+		}
 	}
+	return indexCID, treeSize
 }
 
 // readHandler returns a handler function for tlog/read capability
@@ -328,11 +495,18 @@ func readHandler(logService *logSvc.LogService, validator RequestValidator) serv
 			}
 		}
 
-		// Extract logID from the "with" field - this is the space DID
-		logID := cap.With()
+		// The "with" field is the space DID; the name caveat selects a named log in it
+		spaceDID := cap.With()
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.ReadSuccess](capabilities.NewReadFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
 
 		// Check for revoked delegations
-		revokedCID, err := checkRevocations(ctx, inv, logID, logService)
+		revokedCID, err := checkRevocations(ctx, inv, spaceDID, logService)
 		if err != nil {
 			return result.Error[capabilities.ReadSuccess](capabilities.NewReadFailure(
 				"RevocationCheckFailed",
@@ -355,8 +529,29 @@ func readHandler(logService *logSvc.LogService, validator RequestValidator) serv
 			limit = *cap.Nb().Limit
 		}
 
+		// Optionally read the log as it was at a past head
+		var opts []logSvc.ReadOption
+		if atSize := cap.Nb().AtSize; atSize != nil {
+			if *atSize < 0 {
+				return result.Error[capabilities.ReadSuccess](capabilities.NewReadFailure(
+					"InvalidReadRequest",
+					"at_size must not be negative",
+				)), nil, nil
+			}
+			opts = append(opts, logSvc.ReadAtSize(uint64(*atSize)))
+		}
+		if atCID := cap.Nb().AtCID; atCID != nil {
+			opts = append(opts, logSvc.ReadAtCID(*atCID))
+		}
+
 		// Read from the log
-		readResult, err := logService.Read(ctx, logID, offset, limit)
+		readResult, err := logService.Read(ctx, logID, offset, limit, opts...)
+		if errors.Is(err, logSvc.ErrHeadNotFound) {
+			return result.Error[capabilities.ReadSuccess](capabilities.NewReadFailure(
+				"HeadNotFound",
+				err.Error(),
+			)), nil, nil
+		}
 		if err != nil {
 			return result.Error[capabilities.ReadSuccess](capabilities.NewReadFailure(
 				"ReadFailed",
@@ -377,112 +572,329 @@ func readHandler(logService *logSvc.LogService, validator RequestValidator) serv
 	}
 }
 
-// revokeHandler returns a handler function for tlog/admin/revoke capability
-func revokeHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.RevokeCaveats, capabilities.RevokeSuccess, capabilities.RevokeFailure] {
+// proveHandler returns a handler function for tlog/prove capability
+func proveHandler(logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.ProveCaveats, capabilities.ProveSuccess, capabilities.ProveFailure] {
 	return func(
 		ctx context.Context,
-		cap ucan.Capability[capabilities.RevokeCaveats],
+		cap ucan.Capability[capabilities.ProveCaveats],
 		inv invocation.Invocation,
 		ictx server.InvocationContext,
-	) (result.Result[capabilities.RevokeSuccess, capabilities.RevokeFailure], fx.Effects, error) {
+	) (result.Result[capabilities.ProveSuccess, capabilities.ProveFailure], fx.Effects, error) {
 		// Validate request if validator is configured
 		if validator != nil {
 			if err := validator.ValidateRequest(ctx, inv); err != nil {
 				var vErr *ValidationError
 				if errors.As(err, &vErr) {
-					return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+					return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
 						vErr.Code,
 						vErr.Message,
 					)), nil, nil
 				}
-				return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
 					"VALIDATION_ERROR",
 					err.Error(),
 				)), nil, nil
 			}
 		}
 
-		// 1. Validate CID is provided
-		cidToRevoke := cap.Nb().Cid
-		if cidToRevoke == "" {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"MissingCID",
-				"cid is required",
-			)), nil, nil
-		}
-
-		// 2. Parse the storage delegation (for fetching and writing)
-		storageDlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		// The "with" field is the space DID; the name caveat selects a named log in it
+		spaceDID := cap.With()
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
 		if err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"InvalidDelegation",
-				fmt.Sprintf("failed to parse storage delegation: %v", err),
+			return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
+				"InvalidLogName",
+				err.Error(),
 			)), nil, nil
 		}
 
-		// 3. Extract space DID from storage delegation
-		spaceDID, err := ucanPkg.ExtractSpaceDID(storageDlg)
+		// Check for revoked delegations
+		revokedCID, err := checkRevocations(ctx, inv, spaceDID, logService)
 		if err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"InvalidSpaceDID",
-				fmt.Sprintf("failed to extract space DID: %v", err),
+			return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check revocations: %v", err),
 			)), nil, nil
 		}
-
-		// 4. Validate storage delegation grants access
-		if err := ucanPkg.ValidateDelegation(storageDlg, serviceDID, spaceDID); err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"InvalidDelegation",
-				fmt.Sprintf("storage delegation invalid: %v", err),
+		if revokedCID != "" {
+			return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
 			)), nil, nil
 		}
 
-		// Validate invocation authority for storage delegation
-		invocationIssuerDID := inv.Issuer().DID().String()
-		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, storageDlg); err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				ucanPkg.ErrCodeInvocationNotAuthorized,
-				fmt.Sprintf("not authorized to use storage delegation: %v", err),
+		if cap.Nb().Index < 0 || (cap.Nb().Size != nil && *cap.Nb().Size < 0) {
+			return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
+				"InvalidProofRequest",
+				"index and size must not be negative",
 			)), nil, nil
 		}
-
-		// Validate proof chain for storage delegation
-		if err := ucanPkg.ValidateProofChain(storageDlg, spaceDID); err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				ucanPkg.ErrCodeDelegationNoAuthority,
-				fmt.Sprintf("storage delegation has no authority: %v", err),
-			)), nil, nil
+		var treeSize uint64
+		if cap.Nb().Size != nil {
+			treeSize = uint64(*cap.Nb().Size)
 		}
 
-		// 5. Get a blob fetcher for the space
-		fetcher, err := logService.GetBlobFetcher(ctx, spaceDID, storageDlg)
+		proofResult, err := logService.ProveInclusion(ctx, logID, uint64(cap.Nb().Index), treeSize)
 		if err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"FetcherError",
-				fmt.Sprintf("failed to get blob fetcher: %v", err),
+			name := "ProofFailed"
+			if errors.Is(err, logSvc.ErrInvalidProofRequest) {
+				name = "InvalidProofRequest"
+			}
+			return result.Error[capabilities.ProveSuccess](capabilities.NewProveFailure(
+				name,
+				fmt.Sprintf("failed to compute inclusion proof: %v", err),
 			)), nil, nil
 		}
 
-		// 6. Fetch the delegation to revoke from storage
-		dlgToRevoke, err := ucanPkg.FetchDelegation(ctx, fetcher, cidToRevoke)
-		if err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"DelegationNotFound",
-				fmt.Sprintf("failed to fetch delegation %s: %v (delegation must be stored in the space before revocation)", cidToRevoke, err),
-			)), nil, nil
-		}
+		return result.Ok[capabilities.ProveSuccess, capabilities.ProveFailure](newProveSuccess(proofResult)), nil, nil
+	}
+}
 
-		// 7. Validate revocation authority
-		// The revoker is the issuer of the invocation
-		revokerDID := inv.Issuer().DID().String()
-		if err := ucanPkg.ValidateRevocationAuthority(revokerDID, dlgToRevoke); err != nil {
-			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
-				"NotAuthorized",
-				err.Error(),
-			)), nil, nil
-		}
+// newProveSuccess converts an inclusion proof result to its wire representation.
+func newProveSuccess(res *logSvc.InclusionProofResult) capabilities.ProveSuccess {
+	proof := make([]string, 0, len(res.InclusionProof)/sha256.Size)
+	for i := 0; i+sha256.Size <= len(res.InclusionProof); i += sha256.Size {
+		proof = append(proof, base64.StdEncoding.EncodeToString(res.InclusionProof[i:i+sha256.Size]))
+	}
+	var checkpointProof []string
+	for _, hash := range res.CheckpointProof {
+		checkpointProof = append(checkpointProof, base64.StdEncoding.EncodeToString(hash))
+	}
+	return capabilities.ProveSuccess{
+		Index:           int64(res.Entry.Index),
+		Entry:           base64.StdEncoding.EncodeToString(res.Entry.Data),
+		Proof:           proof,
+		TreeSize:        res.TreeSize,
+		RootHash:        base64.StdEncoding.EncodeToString(res.RootHash),
+		Checkpoint:      string(res.Checkpoint),
+		CheckpointProof: checkpointProof,
+	}
+}
 
-		// 8. Get the delegation's internal CID (this is what we check against during authorization)
+// consistencyHandler returns a handler function for tlog/consistency capability
+func consistencyHandler(logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.ConsistencyCaveats, capabilities.ConsistencySuccess, capabilities.ConsistencyFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.ConsistencyCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.ConsistencySuccess, capabilities.ConsistencyFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		// The "with" field is the space DID; the name caveat selects a named log in it
+		spaceDID := cap.With()
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		// Check for revoked delegations
+		revokedCID, err := checkRevocations(ctx, inv, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		if cap.Nb().From < 0 || (cap.Nb().To != nil && *cap.Nb().To < 0) {
+			return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+				"InvalidProofRequest",
+				"from and to must not be negative",
+			)), nil, nil
+		}
+		var to uint64
+		if cap.Nb().To != nil {
+			to = uint64(*cap.Nb().To)
+		}
+
+		proofResult, err := logService.ProveConsistency(ctx, logID, uint64(cap.Nb().From), to)
+		if err != nil {
+			name := "ProofFailed"
+			if errors.Is(err, logSvc.ErrInvalidProofRequest) {
+				name = "InvalidProofRequest"
+			}
+			return result.Error[capabilities.ConsistencySuccess](capabilities.NewConsistencyFailure(
+				name,
+				fmt.Sprintf("failed to compute consistency proof: %v", err),
+			)), nil, nil
+		}
+
+		return result.Ok[capabilities.ConsistencySuccess, capabilities.ConsistencyFailure](newConsistencySuccess(proofResult)), nil, nil
+	}
+}
+
+// newConsistencySuccess converts a consistency proof result to its wire representation.
+func newConsistencySuccess(res *logSvc.ConsistencyProofResult) capabilities.ConsistencySuccess {
+	proof := make([]string, len(res.Proof))
+	for i, hash := range res.Proof {
+		proof[i] = base64.StdEncoding.EncodeToString(hash)
+	}
+	return capabilities.ConsistencySuccess{
+		From:       res.From,
+		To:         res.To,
+		Proof:      proof,
+		FromRoot:   base64.StdEncoding.EncodeToString(res.FromRoot),
+		ToRoot:     base64.StdEncoding.EncodeToString(res.ToRoot),
+		Checkpoint: string(res.Checkpoint),
+	}
+}
+
+// revokeHandler returns a handler function for tlog/admin/revoke capability
+func revokeHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.RevokeCaveats, capabilities.RevokeSuccess, capabilities.RevokeFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.RevokeCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.RevokeSuccess, capabilities.RevokeFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		// 1. Validate exactly one revocation target is provided
+		nb := cap.Nb()
+		targets := 0
+		for _, target := range []*string{nb.Cid, nb.Account, nb.Ability} {
+			if target != nil {
+				if *target == "" {
+					return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+						"InvalidRevocation",
+						"cid, account and ability must not be empty",
+					)), nil, nil
+				}
+				targets++
+			}
+		}
+		if targets == 0 {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"MissingCID",
+				"one of cid, account or ability is required",
+			)), nil, nil
+		}
+		if targets > 1 {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidRevocation",
+				"only one of cid, account or ability may be provided",
+			)), nil, nil
+		}
+
+		// 2. Parse the storage delegation (for fetching and writing)
+		storageDlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse storage delegation: %v", err),
+			)), nil, nil
+		}
+
+		// 3. Extract space DID from storage delegation
+		spaceDID, err := ucanPkg.ExtractSpaceDID(storageDlg)
+		if err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		// 4. Validate storage delegation grants access
+		if err := ucanPkg.ValidateDelegation(storageDlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("storage delegation invalid: %v", err),
+			)), nil, nil
+		}
+
+		// Validate invocation authority for storage delegation
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, storageDlg); err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				fmt.Sprintf("not authorized to use storage delegation: %v", err),
+			)), nil, nil
+		}
+
+		// Validate proof chain for storage delegation
+		if err := ucanPkg.ValidateProofChain(storageDlg, spaceDID); err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				fmt.Sprintf("storage delegation has no authority: %v", err),
+			)), nil, nil
+		}
+
+		// The revoker is the issuer of the invocation
+		revokerDID := inv.Issuer().DID().String()
+
+		// Account and ability revocations affect delegations from any issuer,
+		// so they need authority over the whole space
+		if nb.Cid == nil {
+			return revokeScope(ctx, logService, serviceDID, spaceDID, revokerDID, nb, storageDlg)
+		}
+		cidToRevoke := *nb.Cid
+
+		// 5. Get a blob fetcher for the space
+		fetcher, err := logService.GetBlobFetcher(ctx, spaceDID, storageDlg)
+		if err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"FetcherError",
+				fmt.Sprintf("failed to get blob fetcher: %v", err),
+			)), nil, nil
+		}
+
+		// 6. Fetch the delegation to revoke from storage
+		dlgToRevoke, err := ucanPkg.FetchDelegation(ctx, fetcher, cidToRevoke)
+		if err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"DelegationNotFound",
+				fmt.Sprintf("failed to fetch delegation %s: %v (delegation must be stored in the space before revocation)", cidToRevoke, err),
+			)), nil, nil
+		}
+
+		// 7. Validate revocation authority
+		if err := ucanPkg.ValidateRevocationAuthority(revokerDID, dlgToRevoke); err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"NotAuthorized",
+				err.Error(),
+			)), nil, nil
+		}
+
+		// 8. Get the delegation's internal CID (this is what we check against during authorization)
 		delegationCID := dlgToRevoke.Link().String()
 
 		// 9. Add to revocation log using the delegation's internal CID and storage delegation
@@ -499,6 +911,64 @@ func revokeHandler(serviceDID string, logService *logSvc.LogService, validator R
 	}
 }
 
+// revokeScope handles account-wide and ability-scoped revocations for revokeHandler.
+func revokeScope(
+	ctx context.Context,
+	logService *logSvc.LogService,
+	serviceDID, spaceDID, revokerDID string,
+	nb capabilities.RevokeCaveats,
+	storageDlg delegation.Delegation,
+) (result.Result[capabilities.RevokeSuccess, capabilities.RevokeFailure], fx.Effects, error) {
+	if err := ucanPkg.ValidateScopedRevocationAuthority(revokerDID, spaceDID, storageDlg); err != nil {
+		return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+			"NotAuthorized",
+			err.Error(),
+		)), nil, nil
+	}
+
+	var err error
+	if nb.Account != nil {
+		account := *nb.Account
+		if _, err := did.Parse(account); err != nil {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidRevocation",
+				fmt.Sprintf("invalid account DID %q: %v", account, err),
+			)), nil, nil
+		}
+		// Revoking any of these would cut off every delegation in the space,
+		// including the one needed to undo the mistake
+		if account == spaceDID || account == serviceDID || account == revokerDID {
+			return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+				"InvalidRevocation",
+				fmt.Sprintf("account %s cannot be revoked", account),
+			)), nil, nil
+		}
+		err = logService.RevokeAccount(ctx, spaceDID, account, storageDlg)
+	} else {
+		// The storage delegation itself grants these, so revoking them
+		// would lock every client out of the space
+		for _, required := range ucanPkg.RequiredStorachaCapabilities() {
+			if ucanPkg.AbilityCovers(*nb.Ability, required) {
+				return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+					"InvalidRevocation",
+					fmt.Sprintf("ability %s covers %s, which storage delegations require", *nb.Ability, required),
+				)), nil, nil
+			}
+		}
+		err = logService.RevokeCapability(ctx, spaceDID, *nb.Ability, storageDlg)
+	}
+	if err != nil {
+		return result.Error[capabilities.RevokeSuccess](capabilities.NewRevokeFailure(
+			"RevokeFailed",
+			fmt.Sprintf("failed to revoke: %v", err),
+		)), nil, nil
+	}
+
+	return result.Ok[capabilities.RevokeSuccess, capabilities.RevokeFailure](capabilities.RevokeSuccess{
+		Revoked: true,
+	}), nil, nil
+}
+
 // func didToEd25519PublicKey(did string) (ed25519.PublicKey, error) {
 // 	// Use go-ucanto's verifier to parse the DID
 // 	v, err := verifier.Parse(did)
@@ -521,6 +991,56 @@ func revokeHandler(serviceDID string, logService *logSvc.LogService, validator R
 // 	return ed25519.PublicKey(rawKey), nil
 // }
 
+// delegationErrorCode returns the code of a *ucanPkg.DelegationError, so clients
+// can tell e.g. a bad signature from a missing proof, or fallback otherwise.
+func delegationErrorCode(err error, fallback string) string {
+	var dlgErr *ucanPkg.DelegationError
+	if errors.As(err, &dlgErr) {
+		return dlgErr.Code
+	}
+	return fallback
+}
+
+// resolveLogID returns the ID of the log addressed in a space: the space's
+// default log, or the named log "{spaceDID}/{name}" when a name is given.
+func resolveLogID(spaceDID string, name *string) (string, error) {
+	if name == nil || *name == "" {
+		return spaceDID, nil
+	}
+	if err := tlog.ValidateLogName(*name); err != nil {
+		return "", err
+	}
+	return tlog.LogID(spaceDID, *name), nil
+}
+
+// newAttribution records who appended entries under an invocation, and under
+// which delegation, at the time the invocation is received.
+func newAttribution(inv invocation.Invocation, dlg delegation.Delegation) sqlite.Attribution {
+	return sqlite.Attribution{
+		InvocationCID: inv.Link().String(),
+		IssuerDID:     inv.Issuer().DID().String(),
+		DelegationCID: dlg.Link().String(),
+		ReceivedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// envelopeEntry wraps entry data in a types.AttributedEntry, so the entry's
+// attribution is committed to the log along with the data.
+func envelopeEntry(data []byte, attr sqlite.Attribution) ([]byte, error) {
+	entry := types.AttributedEntry{
+		Data:          data,
+		InvocationCID: attr.InvocationCID,
+		IssuerDID:     attr.IssuerDID,
+		DelegationCID: attr.DelegationCID,
+		ReceivedAt:    attr.ReceivedAt,
+	}
+	encoded, err := entry.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attributed entry: %w", err)
+	}
+	return encoded, nil
+}
+
 // checkDelegationRevokedSQLite recursively checks if a delegation or any in its proof chain is revoked using SQLite
 func checkDelegationRevokedSQLite(
 	ctx context.Context,
@@ -539,6 +1059,15 @@ func checkDelegationRevokedSQLite(
 		return delegationCID, nil
 	}
 
+	// Check account-wide and ability-scoped revocations
+	isRevoked, err = logService.IsScopeRevoked(ctx, logID, dlg)
+	if err != nil {
+		return "", fmt.Errorf("failed to check scoped revocations: %w", err)
+	}
+	if isRevoked {
+		return delegationCID, nil
+	}
+
 	// Recursively check all proofs in the delegation's proof chain
 	proofLinks := dlg.Proofs()
 	proofs := delegation.NewProofsView(proofLinks, bs)
@@ -555,6 +1084,57 @@ func checkDelegationRevokedSQLite(
 	return "", nil
 }
 
+// checkRequestRevoked rejects a request if its delegation (passed in caveat),
+// any delegation in that delegation's proof chain, or any proof attached to
+// the invocation itself is revoked.
+func checkRequestRevoked(
+	ctx context.Context,
+	inv invocation.Invocation,
+	dlg delegation.Delegation,
+	spaceDID string,
+	logService *logSvc.LogService,
+) *ValidationError {
+	revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+	if err != nil {
+		return NewValidationError(
+			"RevocationCheckFailed",
+			fmt.Sprintf("failed to check delegation revocations: %v", err),
+		)
+	}
+	if revokedCID != "" {
+		return NewValidationError(
+			"DelegationRevoked",
+			fmt.Sprintf("delegation %s has been revoked", revokedCID),
+		)
+	}
+
+	revokedCID, err = checkRevocations(ctx, inv, spaceDID, logService)
+	if err != nil {
+		return NewValidationError(
+			"RevocationCheckFailed",
+			fmt.Sprintf("failed to check revocations: %v", err),
+		)
+	}
+	if revokedCID != "" {
+		return NewValidationError(
+			"DelegationRevoked",
+			fmt.Sprintf("delegation %s has been revoked", revokedCID),
+		)
+	}
+	return nil
+}
+
+// authorizeRestore returns the check RestoreLog and MigrateLog run once the
+// space's revocations are loaded, which may only happen during the restore.
+func authorizeRestore(inv invocation.Invocation, dlg delegation.Delegation, spaceDID string, logService *logSvc.LogService) tlog.AuthorizeFunc {
+	return func(ctx context.Context) error {
+		if vErr := checkRequestRevoked(ctx, inv, dlg, spaceDID, logService); vErr != nil {
+			return vErr
+		}
+		return nil
+	}
+}
+
 // checkDelegationChainRevoked checks if a delegation (passed in caveat) or any in its proof chain is revoked.
 // This is used to check delegations that are passed as base64 strings in request caveats.
 func checkDelegationChainRevoked(
@@ -655,41 +1235,101 @@ func garbageHandler(serviceDID string, logService *logSvc.LogService, validator
 			)), nil, nil
 		}
 
-		// CRITICAL: Validate delegation is DIRECT from space owner to service
-		// Issuer must be the space DID (no intermediaries allowed)
-		issuerDID := dlg.Issuer().DID().String()
-		if issuerDID != logID {
-			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
-				ucanPkg.ErrCodeGCDelegationNotDirect,
-				fmt.Sprintf("GC delegation must be issued by space owner %s, but was issued by %s", logID, issuerDID),
-			)), nil, nil
+		// Named logs ("{spaceDID}/{name}") are collected with their space's delegation
+		spaceDID, name := tlog.SplitLogID(logID)
+		if name != "" {
+			if err := tlog.ValidateLogName(name); err != nil {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					"InvalidLogName",
+					err.Error(),
+				)), nil, nil
+			}
 		}
 
-		// Validate GC delegation (checks for space/blob/remove capability and direct delegation)
-		if err := ucanPkg.ValidateGCDelegation(dlg, serviceDID, logID); err != nil {
-			var dlgErr *ucanPkg.DelegationError
-			if errors.As(err, &dlgErr) {
+		// A dry run removes nothing, so regular access to the space is enough
+		if dryRun := cap.Nb().DryRun; dryRun != nil && *dryRun {
+			if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
 				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
-					dlgErr.Code,
-					dlgErr.Message,
+					"INVALID_DELEGATION",
+					err.Error(),
 				)), nil, nil
 			}
-			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
-				"INVALID_DELEGATION",
-				err.Error(),
-			)), nil, nil
-		}
 
-		// Validate invocation authorization (issuer must match delegation issuer)
-		invocationIssuerDID := inv.Issuer().DID().String()
-		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
-			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
-				ucanPkg.ErrCodeInvocationNotAuthorized,
-				err.Error(),
-			)), nil, nil
-		}
+			invocationIssuerDID := inv.Issuer().DID().String()
+			if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					ucanPkg.ErrCodeInvocationNotAuthorized,
+					err.Error(),
+				)), nil, nil
+			}
 
-		// Run garbage collection with this delegation
+			if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+					err.Error(),
+				)), nil, nil
+			}
+
+			revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+			if err != nil {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					"RevocationCheckFailed",
+					fmt.Sprintf("failed to check delegation revocations: %v", err),
+				)), nil, nil
+			}
+			if revokedCID != "" {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					"DelegationRevoked",
+					fmt.Sprintf("delegation %s has been revoked", revokedCID),
+				)), nil, nil
+			}
+
+			gcResult, err := logService.PlanGC(ctx, logID)
+			if err != nil {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					ucanPkg.ErrCodeGCFailed,
+					fmt.Sprintf("garbage collection dry run failed: %v", err),
+				)), nil, nil
+			}
+
+			return result.Ok[capabilities.GarbageSuccess, capabilities.GarbageFailure](newGarbageSuccess(gcResult)), nil, nil
+		}
+
+		// CRITICAL: Validate delegation is DIRECT from space owner to service
+		// Issuer must be the space DID (no intermediaries allowed)
+		issuerDID := dlg.Issuer().DID().String()
+		if issuerDID != spaceDID {
+			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+				ucanPkg.ErrCodeGCDelegationNotDirect,
+				fmt.Sprintf("GC delegation must be issued by space owner %s, but was issued by %s", spaceDID, issuerDID),
+			)), nil, nil
+		}
+
+		// Validate GC delegation (checks for space/blob/remove capability and direct delegation)
+		if err := ucanPkg.ValidateGCDelegation(dlg, serviceDID, spaceDID); err != nil {
+			var dlgErr *ucanPkg.DelegationError
+			if errors.As(err, &dlgErr) {
+				return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+					dlgErr.Code,
+					dlgErr.Message,
+				)), nil, nil
+			}
+			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+				"INVALID_DELEGATION",
+				err.Error(),
+			)), nil, nil
+		}
+
+		// Validate invocation authorization (issuer must match delegation issuer)
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		// Run garbage collection with this delegation
 		gcResult, err := logService.RunGC(ctx, logID, dlg)
 		if err != nil {
 			return result.Error[capabilities.GarbageSuccess](capabilities.NewGarbageFailure(
@@ -698,11 +1338,1034 @@ func garbageHandler(serviceDID string, logService *logSvc.LogService, validator
 			)), nil, nil
 		}
 
-		return result.Ok[capabilities.GarbageSuccess, capabilities.GarbageFailure](capabilities.GarbageSuccess{
-			BundlesProcessed: gcResult.BundlesProcessed,
-			BlobsRemoved:     gcResult.BlobsRemoved,
-			BytesFreed:       gcResult.BytesFreed,
-			NewGCPosition:    gcResult.NewGCPosition,
+		return result.Ok[capabilities.GarbageSuccess, capabilities.GarbageFailure](newGarbageSuccess(gcResult)), nil, nil
+	}
+}
+
+// newGarbageSuccess converts a GC result to a tlog/gc response. Blobs are
+// only listed for dry runs.
+func newGarbageSuccess(res *logSvc.GCResult) capabilities.GarbageSuccess {
+	success := capabilities.GarbageSuccess{
+		BundlesProcessed: res.BundlesProcessed,
+		BlobsRemoved:     res.BlobsRemoved,
+		BytesFreed:       res.BytesFreed,
+		UnknownSizes:     res.UnknownSizes,
+		NewGCPosition:    res.NewGCPosition,
+		DryRun:           res.DryRun,
+	}
+	if res.DryRun {
+		success.Blobs = make([]capabilities.GCBlob, len(res.Blobs))
+		for i, b := range res.Blobs {
+			success.Blobs[i] = capabilities.GCBlob{Kind: b.Kind, Path: b.Path, CID: b.CID}
+			if b.SizeKnown {
+				size := b.Size
+				success.Blobs[i].Size = &size
+			}
+			if b.TreeSize > 0 {
+				treeSize := b.TreeSize
+				success.Blobs[i].TreeSize = &treeSize
+			}
+		}
+	}
+	return success
+}
+
+// gcScheduleHandler returns a handler function for tlog/gc/schedule capability.
+// The delegation must pass the same checks as for tlog/gc. It is stored and
+// used by the GC scheduler until it expires or is revoked.
+func gcScheduleHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.GCScheduleCaveats, capabilities.GCScheduleSuccess, capabilities.GCScheduleFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.GCScheduleCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.GCScheduleSuccess, capabilities.GCScheduleFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		logID := cap.Nb().LogID
+		if logID == "" {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				"MISSING_LOG_ID",
+				"logId is required",
+			)), nil, nil
+		}
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				"MISSING_DELEGATION",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				ucanPkg.ErrCodeDelegationParseError,
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, name := tlog.SplitLogID(logID)
+		if name != "" {
+			if err := tlog.ValidateLogName(name); err != nil {
+				return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+					"InvalidLogName",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		// Direct space/blob/remove delegation from the space owner to the service
+		if err := ucanPkg.ValidateGCDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				delegationErrorCode(err, "INVALID_DELEGATION"),
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		// A revoked delegation would only be dropped by the scheduler later
+		revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check delegation revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		sched, err := logService.ScheduleGC(ctx, logID, dlg, invocationIssuerDID)
+		if err != nil {
+			return result.Error[capabilities.GCScheduleSuccess](capabilities.NewGCScheduleFailure(
+				"GCScheduleFailed",
+				err.Error(),
+			)), nil, nil
+		}
+
+		success := capabilities.GCScheduleSuccess{
+			LogID:         logID,
+			DelegationCID: sched.DelegationCID,
+		}
+		if sched.ExpiresAt != nil {
+			success.ExpiresAt = sched.ExpiresAt.Format(time.RFC3339)
+		}
+		return result.Ok[capabilities.GCScheduleSuccess, capabilities.GCScheduleFailure](success), nil, nil
+	}
+}
+
+// gcUnscheduleHandler returns a handler function for tlog/gc/unschedule
+// capability. The delegation must pass the same checks as for tlog/gc.
+func gcUnscheduleHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.GCUnscheduleCaveats, capabilities.GCUnscheduleSuccess, capabilities.GCUnscheduleFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.GCUnscheduleCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.GCUnscheduleSuccess, capabilities.GCUnscheduleFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		logID := cap.Nb().LogID
+		if logID == "" {
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				"MISSING_LOG_ID",
+				"logId is required",
+			)), nil, nil
+		}
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				"MISSING_DELEGATION",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				ucanPkg.ErrCodeDelegationParseError,
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, name := tlog.SplitLogID(logID)
+		if name != "" {
+			if err := tlog.ValidateLogName(name); err != nil {
+				return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+					"InvalidLogName",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if err := ucanPkg.ValidateGCDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				delegationErrorCode(err, "INVALID_DELEGATION"),
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := logService.UnscheduleGC(ctx, logID); err != nil {
+			errName := "GCUnscheduleFailed"
+			if errors.Is(err, tlog.ErrGCNotScheduled) {
+				errName = "GCNotScheduled"
+			}
+			return result.Error[capabilities.GCUnscheduleSuccess](capabilities.NewGCUnscheduleFailure(
+				errName,
+				err.Error(),
+			)), nil, nil
+		}
+
+		return result.Ok[capabilities.GCUnscheduleSuccess, capabilities.GCUnscheduleFailure](capabilities.GCUnscheduleSuccess{
+			LogID: logID,
+		}), nil, nil
+	}
+}
+
+// rebuildRevocationsHandler returns a handler function for tlog/revocations/rebuild.
+// Only the space owner or the service operator may trigger a rebuild.
+func rebuildRevocationsHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.RebuildRevocationsCaveats, capabilities.RebuildRevocationsSuccess, capabilities.RebuildRevocationsFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.RebuildRevocationsCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.RebuildRevocationsSuccess, capabilities.RebuildRevocationsFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.RebuildRevocationsSuccess](capabilities.NewRebuildRevocationsFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.RebuildRevocationsSuccess](capabilities.NewRebuildRevocationsFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		// Extract logID from the "with" field - this is the space DID
+		logID := cap.With()
+
+		issuerDID := inv.Issuer().DID().String()
+		if issuerDID != logID && issuerDID != serviceDID {
+			return result.Error[capabilities.RebuildRevocationsSuccess](capabilities.NewRebuildRevocationsFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				fmt.Sprintf("revocations can only be rebuilt by space owner %s or the service, not %s", logID, issuerDID),
+			)), nil, nil
+		}
+
+		res, err := logService.RebuildRevocations(ctx, logID)
+		if err != nil {
+			return result.Error[capabilities.RebuildRevocationsSuccess](capabilities.NewRebuildRevocationsFailure(
+				"RebuildFailed",
+				fmt.Sprintf("failed to rebuild revocations: %v", err),
+			)), nil, nil
+		}
+
+		return result.Ok[capabilities.RebuildRevocationsSuccess, capabilities.RebuildRevocationsFailure](capabilities.RebuildRevocationsSuccess{
+			Scanned: res.Scanned,
+			Added:   res.Added,
 		}), nil, nil
 	}
 }
+
+// attributionHandler returns a handler function for tlog/attribution capability
+func attributionHandler(logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.AttributionCaveats, capabilities.AttributionSuccess, capabilities.AttributionFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.AttributionCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.AttributionSuccess, capabilities.AttributionFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		// The "with" field is the space DID; the name caveat selects a named log in it
+		spaceDID := cap.With()
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		// Check for revoked delegations
+		revokedCID, err := checkRevocations(ctx, inv, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		var attrs []sqlite.Attribution
+		switch {
+		case cap.Nb().Index != nil && cap.Nb().Issuer != nil:
+			return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+				"InvalidAttributionRequest",
+				"only one of index or issuer may be given",
+			)), nil, nil
+		case cap.Nb().Index != nil:
+			if *cap.Nb().Index < 0 {
+				return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+					"InvalidAttributionRequest",
+					"index must not be negative",
+				)), nil, nil
+			}
+			attr, err := logService.GetAttribution(ctx, logID, uint64(*cap.Nb().Index))
+			if errors.Is(err, logSvc.ErrAttributionNotFound) {
+				return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+					"AttributionNotFound",
+					err.Error(),
+				)), nil, nil
+			}
+			if err != nil {
+				return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+					"AttributionLookupFailed",
+					fmt.Sprintf("failed to look up attribution: %v", err),
+				)), nil, nil
+			}
+			attrs = []sqlite.Attribution{*attr}
+		case cap.Nb().Issuer != nil:
+			var offset, limit int64 = 0, 100
+			if cap.Nb().Offset != nil {
+				offset = *cap.Nb().Offset
+			}
+			if cap.Nb().Limit != nil {
+				limit = *cap.Nb().Limit
+			}
+			attrs, err = logService.GetAttributionsByIssuer(ctx, logID, *cap.Nb().Issuer, offset, limit)
+			if err != nil {
+				return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+					"AttributionLookupFailed",
+					fmt.Sprintf("failed to look up attributions: %v", err),
+				)), nil, nil
+			}
+		default:
+			return result.Error[capabilities.AttributionSuccess](capabilities.NewAttributionFailure(
+				"InvalidAttributionRequest",
+				"one of index or issuer is required",
+			)), nil, nil
+		}
+
+		records := make([]capabilities.AttributionRecord, len(attrs))
+		for i, attr := range attrs {
+			records[i] = capabilities.AttributionRecord{
+				Index:         int64(attr.Index),
+				InvocationCID: attr.InvocationCID,
+				IssuerDID:     attr.IssuerDID,
+				DelegationCID: attr.DelegationCID,
+				ReceivedAt:    attr.ReceivedAt.UTC().Format(time.RFC3339),
+			}
+		}
+
+		return result.Ok[capabilities.AttributionSuccess, capabilities.AttributionFailure](capabilities.AttributionSuccess{
+			Attributions: records,
+		}), nil, nil
+	}
+}
+
+// restoreHandler returns a handler function for tlog/restore capability.
+// The delegation authorizes reading the space the log is restored from, and
+// must satisfy the same checks as for tlog/create.
+func restoreHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.RestoreCaveats, capabilities.RestoreSuccess, capabilities.RestoreFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.RestoreCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.RestoreSuccess, capabilities.RestoreFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"MissingDelegation",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		if err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"InvalidDelegation",
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				err.Error(),
+			)), nil, nil
+		}
+
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		if cap.Nb().IndexCID == "" {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"MissingIndexCID",
+				"index_cid is required",
+			)), nil, nil
+		}
+		var revocationIndexCID string
+		if cap.Nb().RevocationIndexCID != nil {
+			revocationIndexCID = *cap.Nb().RevocationIndexCID
+		}
+
+		// The space's revocations may only be restored with the log, so the
+		// request is checked against them during the restore
+		res, err := logService.RestoreLog(ctx, logID, cap.Nb().IndexCID, revocationIndexCID, dlg,
+			authorizeRestore(inv, dlg, spaceDID, logService))
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				vErr.Code,
+				vErr.Message,
+			)), nil, nil
+		}
+		if errors.Is(err, tlog.ErrLogExists) {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"LogExists",
+				err.Error(),
+			)), nil, nil
+		}
+		if err != nil {
+			return result.Error[capabilities.RestoreSuccess](capabilities.NewRestoreFailure(
+				"RestoreFailed",
+				fmt.Sprintf("failed to restore log: %v", err),
+			)), nil, nil
+		}
+
+		return result.Ok[capabilities.RestoreSuccess, capabilities.RestoreFailure](capabilities.RestoreSuccess{
+			LogID:              res.LogID,
+			IndexCID:           res.IndexCID,
+			TreeSize:           res.TreeSize,
+			RootHash:           base64.StdEncoding.EncodeToString(res.Root),
+			RevocationIndexCID: res.RevocationIndexCID,
+		}), nil, nil
+	}
+}
+
+// migrateHandler returns a handler function for tlog/migrate capability.
+// The delegation authorizes reading and writing the space the log is migrated
+// in, and must satisfy the same checks as for tlog/create.
+func migrateHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.MigrateCaveats, capabilities.MigrateSuccess, capabilities.MigrateFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.MigrateCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.MigrateSuccess, capabilities.MigrateFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"MissingDelegation",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		if cap.Nb().PreviousKey == "" {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"MissingPreviousKey",
+				"previous_key is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		if err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"InvalidDelegation",
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				err.Error(),
+			)), nil, nil
+		}
+
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		if cap.Nb().IndexCID == "" {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"MissingIndexCID",
+				"index_cid is required",
+			)), nil, nil
+		}
+		var revocationIndexCID string
+		if cap.Nb().RevocationIndexCID != nil {
+			revocationIndexCID = *cap.Nb().RevocationIndexCID
+		}
+
+		// The space's revocations may only be migrated with the log, so the
+		// request is checked against them during the migration
+		res, err := logService.MigrateLog(ctx, logID, cap.Nb().IndexCID, revocationIndexCID, cap.Nb().PreviousKey, dlg,
+			authorizeRestore(inv, dlg, spaceDID, logService))
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				vErr.Code,
+				vErr.Message,
+			)), nil, nil
+		}
+		if errors.Is(err, tlog.ErrLogExists) {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"LogExists",
+				err.Error(),
+			)), nil, nil
+		}
+		if err != nil {
+			return result.Error[capabilities.MigrateSuccess](capabilities.NewMigrateFailure(
+				"MigrateFailed",
+				fmt.Sprintf("failed to migrate log: %v", err),
+			)), nil, nil
+		}
+
+		success := capabilities.MigrateSuccess{
+			LogID:              res.LogID,
+			IndexCID:           res.IndexCID,
+			TreeSize:           res.TreeSize,
+			RootHash:           base64.StdEncoding.EncodeToString(res.Root),
+			PreviousOrigin:     res.PreviousOrigin,
+			PreviousCheckpoint: res.PreviousCheckpoint,
+			CheckpointCID:      res.Checkpoint,
+			MigrationCID:       res.MigrationCID,
+			Migration:          string(res.Migration),
+		}
+		if res.RevocationLog != nil {
+			success.RevocationIndexCID = res.RevocationLog.IndexCID
+			success.RevocationMigrationCID = res.RevocationLog.MigrationCID
+		}
+		return result.Ok[capabilities.MigrateSuccess, capabilities.MigrateFailure](success), nil, nil
+	}
+}
+
+// webhookAddHandler returns a handler function for tlog/webhook/add capability.
+// Anyone holding a delegation with authority over the space, checked as for
+// tlog/create, may register webhooks on its logs.
+func webhookAddHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.WebhookAddCaveats, capabilities.WebhookAddSuccess, capabilities.WebhookAddFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.WebhookAddCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.WebhookAddSuccess, capabilities.WebhookAddFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"MissingDelegation",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		if err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"InvalidDelegation",
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				err.Error(),
+			)), nil, nil
+		}
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check delegation revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		var signing string
+		if cap.Nb().Signing != nil {
+			signing = *cap.Nb().Signing
+		}
+
+		w, err := logService.AddWebhook(ctx, logID, logSvc.AddWebhookParams{
+			URL:       cap.Nb().URL,
+			Events:    cap.Nb().Events,
+			Signing:   signing,
+			CreatedBy: invocationIssuerDID,
+		})
+		if err != nil {
+			return result.Error[capabilities.WebhookAddSuccess](capabilities.NewWebhookAddFailure(
+				webhookErrorName(err, "WebhookAddFailed"),
+				err.Error(),
+			)), nil, nil
+		}
+
+		res := capabilities.WebhookAddSuccess{
+			ID:      w.ID,
+			Events:  w.Events,
+			Signing: w.Signing,
+			Secret:  w.Secret,
+		}
+		if w.Signing == webhook.SigningUCAN {
+			res.Signer = logService.WebhookSigner()
+		}
+		return result.Ok[capabilities.WebhookAddSuccess, capabilities.WebhookAddFailure](res), nil, nil
+	}
+}
+
+// webhookRemoveHandler returns a handler function for tlog/webhook/remove capability.
+// The delegation must satisfy the same checks as for tlog/webhook/add.
+func webhookRemoveHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.WebhookRemoveCaveats, capabilities.WebhookRemoveSuccess, capabilities.WebhookRemoveFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.WebhookRemoveCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.WebhookRemoveSuccess, capabilities.WebhookRemoveFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"MissingDelegation",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		if err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"InvalidDelegation",
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				err.Error(),
+			)), nil, nil
+		}
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check delegation revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := logService.RemoveWebhook(ctx, logID, cap.Nb().ID); err != nil {
+			return result.Error[capabilities.WebhookRemoveSuccess](capabilities.NewWebhookRemoveFailure(
+				webhookErrorName(err, "WebhookRemoveFailed"),
+				err.Error(),
+			)), nil, nil
+		}
+
+		return result.Ok[capabilities.WebhookRemoveSuccess, capabilities.WebhookRemoveFailure](capabilities.WebhookRemoveSuccess{
+			ID: cap.Nb().ID,
+		}), nil, nil
+	}
+}
+
+// webhookListHandler returns a handler function for tlog/webhook/list capability.
+// The delegation must satisfy the same checks as for tlog/webhook/add.
+// HMAC secrets are never listed.
+func webhookListHandler(serviceDID string, logService *logSvc.LogService, validator RequestValidator) server.HandlerFunc[capabilities.WebhookListCaveats, capabilities.WebhookListSuccess, capabilities.WebhookListFailure] {
+	return func(
+		ctx context.Context,
+		cap ucan.Capability[capabilities.WebhookListCaveats],
+		inv invocation.Invocation,
+		ictx server.InvocationContext,
+	) (result.Result[capabilities.WebhookListSuccess, capabilities.WebhookListFailure], fx.Effects, error) {
+		// Validate request if validator is configured
+		if validator != nil {
+			if err := validator.ValidateRequest(ctx, inv); err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+						vErr.Code,
+						vErr.Message,
+					)), nil, nil
+				}
+				return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+					"VALIDATION_ERROR",
+					err.Error(),
+				)), nil, nil
+			}
+		}
+
+		if cap.Nb().Delegation == "" {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"MissingDelegation",
+				"delegation is required",
+			)), nil, nil
+		}
+
+		dlg, err := ucanPkg.ParseDelegation(cap.Nb().Delegation)
+		if err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"InvalidDelegation",
+				fmt.Sprintf("failed to parse delegation: %v", err),
+			)), nil, nil
+		}
+
+		spaceDID, err := ucanPkg.ExtractSpaceDID(dlg)
+		if err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"InvalidSpaceDID",
+				fmt.Sprintf("failed to extract space DID: %v", err),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateDelegation(dlg, serviceDID, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"InvalidDelegation",
+				err.Error(),
+			)), nil, nil
+		}
+
+		invocationIssuerDID := inv.Issuer().DID().String()
+		if err := ucanPkg.ValidateInvocationAuthority(invocationIssuerDID, dlg); err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				ucanPkg.ErrCodeInvocationNotAuthorized,
+				err.Error(),
+			)), nil, nil
+		}
+
+		if err := ucanPkg.ValidateProofChain(dlg, spaceDID); err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				delegationErrorCode(err, ucanPkg.ErrCodeDelegationNoAuthority),
+				err.Error(),
+			)), nil, nil
+		}
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, dlg, spaceDID, logService)
+		if err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"RevocationCheckFailed",
+				fmt.Sprintf("failed to check delegation revocations: %v", err),
+			)), nil, nil
+		}
+		if revokedCID != "" {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"DelegationRevoked",
+				fmt.Sprintf("delegation %s has been revoked", revokedCID),
+			)), nil, nil
+		}
+
+		logID, err := resolveLogID(spaceDID, cap.Nb().Name)
+		if err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				"InvalidLogName",
+				err.Error(),
+			)), nil, nil
+		}
+
+		webhooks, err := logService.ListWebhooks(ctx, logID)
+		if err != nil {
+			return result.Error[capabilities.WebhookListSuccess](capabilities.NewWebhookListFailure(
+				webhookErrorName(err, "WebhookListFailed"),
+				err.Error(),
+			)), nil, nil
+		}
+
+		records := make([]capabilities.WebhookRecord, len(webhooks))
+		for i, w := range webhooks {
+			records[i] = capabilities.WebhookRecord{
+				ID:        w.ID,
+				URL:       w.URL,
+				Events:    w.Events,
+				Signing:   w.Signing,
+				CreatedBy: w.CreatedBy,
+				CreatedAt: w.CreatedAt.UTC().Format(time.RFC3339),
+				Pending:   w.Pending,
+			}
+		}
+
+		return result.Ok[capabilities.WebhookListSuccess, capabilities.WebhookListFailure](capabilities.WebhookListSuccess{
+			Webhooks: records,
+		}), nil, nil
+	}
+}
+
+// webhookErrorName returns the failure name for an error managing webhooks.
+func webhookErrorName(err error, fallback string) string {
+	switch {
+	case errors.Is(err, logSvc.ErrWebhooksDisabled):
+		return "WebhooksDisabled"
+	case errors.Is(err, logSvc.ErrInvalidWebhook):
+		return "InvalidWebhook"
+	case errors.Is(err, logSvc.ErrWebhookNotFound):
+		return "WebhookNotFound"
+	default:
+		return fallback
+	}
+}
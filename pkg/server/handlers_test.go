@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/storacha/go-ucanto/core/delegation"
 	"github.com/storacha/go-ucanto/principal/ed25519/signer"
@@ -9,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	logSvc "github.com/relves/ucanlog/pkg/log"
 	ucanPkg "github.com/relves/ucanlog/pkg/ucan"
 )
 
@@ -432,3 +436,120 @@ func TestProofChainValidation(t *testing.T) {
 		assert.Equal(t, ucanPkg.ErrCodeDelegationNoAuthority, dlgErr.Code)
 	})
 }
+
+// TestCheckDelegationChainRevoked_Scoped verifies that account and ability
+// revocations reject any delegation in the proof chain that matches them.
+func TestCheckDelegationChainRevoked_Scoped(t *testing.T) {
+	spaceOwner, err := signer.Generate()
+	require.NoError(t, err)
+	userB, err := signer.Generate()
+	require.NoError(t, err)
+	userC, err := signer.Generate()
+	require.NoError(t, err)
+
+	spaceDID := spaceOwner.DID().String()
+
+	// SpaceOwner -> UserB -> UserC
+	ownerToB, err := delegation.Delegate(
+		spaceOwner,
+		userB.DID(),
+		[]ucan.Capability[ucan.NoCaveats]{
+			ucan.NewCapability("tlog/append", spaceDID, ucan.NoCaveats{}),
+		},
+	)
+	require.NoError(t, err)
+	bToC, err := delegation.Delegate(
+		userB,
+		userC.DID(),
+		[]ucan.Capability[ucan.NoCaveats]{
+			ucan.NewCapability("tlog/append", spaceDID, ucan.NoCaveats{}),
+		},
+		delegation.WithProof(delegation.FromDelegation(ownerToB)),
+	)
+	require.NoError(t, err)
+
+	newService := func(t *testing.T) (*logSvc.LogService, *sqlite.LogStore) {
+		storeManager := sqlite.NewStoreManager(t.TempDir())
+		t.Cleanup(func() { storeManager.CloseAll() })
+		store, err := storeManager.GetStore(spaceDID)
+		require.NoError(t, err)
+		return logSvc.NewLogServiceWithConfig(logSvc.LogServiceConfig{StoreManager: storeManager}), store
+	}
+
+	ctx := context.Background()
+
+	t.Run("nothing revoked", func(t *testing.T) {
+		logService, _ := newService(t)
+		revokedCID, err := checkDelegationChainRevoked(ctx, bToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Empty(t, revokedCID)
+	})
+
+	t.Run("account revocation matches upstream audience", func(t *testing.T) {
+		logService, store := newService(t)
+		require.NoError(t, store.AddScopedRevocation(ctx, "account", userB.DID().String(), time.Now()))
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, bToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Equal(t, bToC.Link().String(), revokedCID)
+	})
+
+	t.Run("ability revocation matches granted ability", func(t *testing.T) {
+		logService, store := newService(t)
+		require.NoError(t, store.AddScopedRevocation(ctx, "capability", "tlog/append", time.Now()))
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, bToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Equal(t, bToC.Link().String(), revokedCID)
+	})
+
+	t.Run("ability revocation matches nested grant", func(t *testing.T) {
+		logService, store := newService(t)
+		require.NoError(t, store.AddScopedRevocation(ctx, "capability", "tlog/*", time.Now()))
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, bToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Equal(t, bToC.Link().String(), revokedCID)
+	})
+
+	t.Run("ability revocation does not match broader grant", func(t *testing.T) {
+		logService, store := newService(t)
+		require.NoError(t, store.AddScopedRevocation(ctx, "capability", "tlog/append", time.Now()))
+
+		ownerToC, err := delegation.Delegate(
+			spaceOwner,
+			userC.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("tlog/*", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, ownerToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Empty(t, revokedCID)
+
+		// Neither does a wildcard grant of every ability
+		ownerToCAll, err := delegation.Delegate(
+			spaceOwner,
+			userC.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("*", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+
+		revokedCID, err = checkDelegationChainRevoked(ctx, ownerToCAll, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Empty(t, revokedCID)
+	})
+
+	t.Run("unrelated ability does not match", func(t *testing.T) {
+		logService, store := newService(t)
+		require.NoError(t, store.AddScopedRevocation(ctx, "capability", "tlog/read", time.Now()))
+
+		revokedCID, err := checkDelegationChainRevoked(ctx, bToC, spaceDID, logService)
+		require.NoError(t, err)
+		assert.Empty(t, revokedCID)
+	})
+}
@@ -1,10 +1,13 @@
 package server
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/relves/ucanlog/internal/storage/sqlite"
 )
@@ -26,16 +29,24 @@ type HeadResponse struct {
 	IndexCID      string `json:"index_cid"`
 	TreeSize      uint64 `json:"tree_size"`
 	CheckpointCID string `json:"checkpoint_cid,omitempty"`
+	RootHash      string `json:"root_hash,omitempty"`   // Base64-encoded root hash (historical heads only)
+	RecordedAt    string `json:"recorded_at,omitempty"` // RFC3339 time the head was recorded (historical heads only)
 }
 
 // HandleGetHead handles GET /logs/{logID}/head.
 // Returns the current head CID, tree size, and optional checkpoint CID.
+// With ?at_size=N or ?at_cid=CID, returns the past head recorded at that
+// tree size or with that checkpoint/index CID instead.
 func (h *HTTPHandler) HandleGetHead(w http.ResponseWriter, r *http.Request) {
 	logID := r.PathValue("logID")
 	if logID == "" {
 		http.Error(w, "logID required", http.StatusBadRequest)
 		return
 	}
+	if !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
 
 	store, err := h.storeManager.GetStore(logID)
 	if err != nil {
@@ -58,6 +69,12 @@ func (h *HTTPHandler) HandleGetHead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
+	if query.Has("at_size") || query.Has("at_cid") {
+		h.writeHistoricalHead(w, r, store, logID)
+		return
+	}
+
 	// Get head info from tree_state and index_persistence tables
 	indexCID, treeSize, err := store.GetHead(ctx, logID)
 	if err != nil {
@@ -82,3 +99,47 @@ func (h *HTTPHandler) HandleGetHead(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// writeHistoricalHead serves a head from the head history table.
+func (h *HTTPHandler) writeHistoricalHead(w http.ResponseWriter, r *http.Request, store *sqlite.LogStore, logID string) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var head *sqlite.HeadRecord
+	var err error
+	if query.Has("at_size") {
+		size, parseErr := strconv.ParseUint(query.Get("at_size"), 10, 64)
+		if parseErr != nil {
+			http.Error(w, "invalid at_size parameter", http.StatusBadRequest)
+			return
+		}
+		head, err = store.GetHeadAtSize(ctx, logID, size)
+	} else {
+		cid := query.Get("at_cid")
+		if cid == "" {
+			http.Error(w, "invalid at_cid parameter", http.StatusBadRequest)
+			return
+		}
+		head, err = store.GetHeadByCID(ctx, logID, cid)
+	}
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			http.Error(w, "head not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("failed to get head history", "logID", logID, "error", err)
+		http.Error(w, "failed to get head history", http.StatusInternalServerError)
+		return
+	}
+
+	resp := HeadResponse{
+		IndexCID:      head.IndexCID,
+		TreeSize:      head.TreeSize,
+		CheckpointCID: head.CheckpointCID,
+		RootHash:      base64.StdEncoding.EncodeToString(head.Root),
+		RecordedAt:    head.RecordedAt.UTC().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -75,3 +75,106 @@ func TestHandleGetHead_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestHandleGetHead_AtSize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "http-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	manager := sqlite.NewStoreManager(tmpDir)
+	defer manager.CloseAll()
+
+	ctx := context.Background()
+	logDID := "did:key:z6MkTestLog"
+
+	store, err := manager.GetStore(logDID)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+
+	require.NoError(t, store.RecordHead(ctx, logDID, 1, []byte{0x01}, "bafyCheckpoint1", 0))
+	require.NoError(t, store.RecordHead(ctx, logDID, 2, []byte{0x02}, "bafyCheckpoint2", 0))
+	require.NoError(t, store.SetHeadIndexCID(ctx, logDID, "bafyCheckpoint1", "bafyIndex1"))
+	require.NoError(t, store.SetTreeState(ctx, logDID, 2, []byte{0x02}))
+
+	handler := server.NewHTTPHandler(manager)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/logs/"+logDID+"/head?"+query, nil)
+		req.SetPathValue("logID", logDID)
+		w := httptest.NewRecorder()
+		handler.HandleGetHead(w, req)
+		return w
+	}
+
+	t.Run("at_size", func(t *testing.T) {
+		w := get("at_size=1")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp server.HeadResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, uint64(1), resp.TreeSize)
+		assert.Equal(t, "bafyIndex1", resp.IndexCID)
+		assert.Equal(t, "bafyCheckpoint1", resp.CheckpointCID)
+		assert.Equal(t, "AQ==", resp.RootHash)
+		assert.NotEmpty(t, resp.RecordedAt)
+	})
+
+	t.Run("at_cid", func(t *testing.T) {
+		w := get("at_cid=bafyCheckpoint2")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp server.HeadResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, uint64(2), resp.TreeSize)
+		assert.Empty(t, resp.IndexCID)
+	})
+
+	t.Run("unknown head", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("at_size=7").Code)
+		assert.Equal(t, http.StatusNotFound, get("at_cid=bafyUnknown").Code)
+	})
+
+	t.Run("malformed parameter", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("at_size=abc").Code)
+		assert.Equal(t, http.StatusBadRequest, get("at_cid=").Code)
+	})
+}
+
+func TestHandleGetHead_NamedLog(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "http-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	manager := sqlite.NewStoreManager(tmpDir)
+	defer manager.CloseAll()
+
+	ctx := context.Background()
+	spaceDID := "did:key:z6MkTestSpace"
+	namedLogID := spaceDID + "/evidence"
+
+	// Default log and named log in the same space keep separate state
+	for logID, size := range map[string]uint64{spaceDID: 1, namedLogID: 7} {
+		store, err := manager.GetStore(logID)
+		require.NoError(t, err)
+		require.NoError(t, store.CreateLogRecord(ctx, logID))
+		require.NoError(t, store.SetTreeState(ctx, logID, size, []byte{0x01}))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /logs/{logID}/head", server.NewHTTPHandler(manager).HandleGetHead)
+
+	// The slash in a named log ID is percent-encoded in the URL
+	req := httptest.NewRequest("GET", "/logs/"+spaceDID+"%2Fevidence/head", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(7), resp["tree_size"])
+
+	req = httptest.NewRequest("GET", "/logs/"+spaceDID+"%2F..%2Fother/head", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
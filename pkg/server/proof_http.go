@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	logSvc "github.com/relves/ucanlog/pkg/log"
+)
+
+// ProofHandler serves Merkle proofs over public HTTP endpoints.
+type ProofHandler struct {
+	logService *logSvc.LogService
+}
+
+// NewProofHandler creates a new proof handler.
+func NewProofHandler(logService *logSvc.LogService) *ProofHandler {
+	return &ProofHandler{
+		logService: logService,
+	}
+}
+
+// HandleInclusionProof handles GET /logs/{logID}/proof/inclusion?index=N&size=M.
+// The size parameter is optional and defaults to the latest checkpoint size.
+func (h *ProofHandler) HandleInclusionProof(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	if logID == "" || !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+
+	index, err := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid index parameter", http.StatusBadRequest)
+		return
+	}
+
+	var size uint64
+	if s := r.URL.Query().Get("size"); s != "" {
+		size, err = strconv.ParseUint(s, 10, 64)
+		if err != nil || size == 0 {
+			http.Error(w, "invalid size parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.logService.ProveInclusion(r.Context(), logID, index, size)
+	if err != nil {
+		writeProofError(w, err, "failed to compute inclusion proof", "logID", logID, "index", index)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newProveSuccess(res))
+}
+
+// HandleConsistencyProof handles GET /logs/{logID}/proof/consistency?from=N&to=M.
+// The to parameter is optional and defaults to the latest checkpoint size.
+func (h *ProofHandler) HandleConsistencyProof(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	if logID == "" || !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+
+	from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+	if err != nil || from == 0 {
+		http.Error(w, "invalid from parameter", http.StatusBadRequest)
+		return
+	}
+
+	var to uint64
+	if s := r.URL.Query().Get("to"); s != "" {
+		to, err = strconv.ParseUint(s, 10, 64)
+		if err != nil || to == 0 {
+			http.Error(w, "invalid to parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.logService.ProveConsistency(r.Context(), logID, from, to)
+	if err != nil {
+		writeProofError(w, err, "failed to compute consistency proof", "logID", logID, "from", from, "to", to)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newConsistencySuccess(res))
+}
+
+// writeProofError maps proof errors to HTTP status codes.
+func writeProofError(w http.ResponseWriter, err error, msg string, attrs ...any) {
+	switch {
+	case errors.Is(err, logSvc.ErrInvalidProofRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, logSvc.ErrCheckpointUnavailable):
+		http.Error(w, "checkpoint not found", http.StatusNotFound)
+	default:
+		slog.Error(msg, append(attrs, "error", err)...)
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,179 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ed25519signer "github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/relves/ucanlog/pkg/capabilities"
+	logSvc "github.com/relves/ucanlog/pkg/log"
+	"github.com/relves/ucanlog/pkg/server"
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+func TestHandleInclusionProof_BadRequest(t *testing.T) {
+	handler := server.NewProofHandler(nil)
+	logID := "did:key:z6MkTestLog"
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing index", ""},
+		{"non-numeric index", "?index=abc"},
+		{"negative index", "?index=-1"},
+		{"zero size", "?index=0&size=0"},
+		{"non-numeric size", "?index=0&size=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/logs/"+logID+"/proof/inclusion"+tt.query, nil)
+			req.SetPathValue("logID", logID)
+			w := httptest.NewRecorder()
+
+			handler.HandleInclusionProof(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleConsistencyProof_BadRequest(t *testing.T) {
+	handler := server.NewProofHandler(nil)
+	logID := "did:key:z6MkTestLog"
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing from", ""},
+		{"zero from", "?from=0"},
+		{"non-numeric from", "?from=abc"},
+		{"zero to", "?from=1&to=0"},
+		{"non-numeric to", "?from=1&to=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/logs/"+logID+"/proof/consistency"+tt.query, nil)
+			req.SetPathValue("logID", logID)
+			w := httptest.NewRecorder()
+
+			handler.HandleConsistencyProof(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleInclusionProof_HistoricalSize(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkProofHistory"
+	dlg := storachatest.MockDelegation()
+
+	dataDir := t.TempDir()
+	storeManager := sqlite.NewStoreManager(dataDir)
+	t.Cleanup(func() { storeManager.CloseAll() })
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signer, err := tlog.NewEd25519Signer(privKey, "test")
+	require.NoError(t, err)
+	serviceSigner, err := ed25519signer.Generate()
+	require.NoError(t, err)
+	mgr, err := tlog.NewDelegatedManager(tlog.DelegatedManagerConfig{
+		BasePath:      dataDir,
+		Signer:        signer,
+		PrivateKey:    privKey,
+		OriginPrefix:  "test",
+		ServiceSigner: serviceSigner,
+		CIDStore:      tlog.NewStateStoreCIDStore(storeManager.GetStateStore),
+		StoreManager:  storeManager,
+		StorageClient: blobs,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	entries := make([][]byte, 7)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d", i))
+	}
+	_, err = mgr.AddEntriesWithDelegation(ctx, logID, entries, dlg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		cpRaw, err := mgr.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			return false
+		}
+		cp, err := tlog.ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == 7
+	}, 5*time.Second, 50*time.Millisecond)
+
+	handler := server.NewProofHandler(logSvc.NewLogService(mgr, nil))
+	req := httptest.NewRequest("GET", "/logs/"+logID+"/proof/inclusion?index=2&size=3", nil)
+	req.SetPathValue("logID", logID)
+	w := httptest.NewRecorder()
+	handler.HandleInclusionProof(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res capabilities.ProveSuccess
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	decode := func(hashes []string) [][]byte {
+		out := make([][]byte, len(hashes))
+		for i, h := range hashes {
+			out[i], err = base64.StdEncoding.DecodeString(h)
+			require.NoError(t, err)
+		}
+		return out
+	}
+
+	// The proof verifies against the signed checkpoint it is returned with
+	vkey, err := mgr.LogVerifierKey(logID)
+	require.NoError(t, err)
+	verifier, err := note.NewVerifier(vkey)
+	require.NoError(t, err)
+	cp, _, _, err := log.ParseCheckpoint([]byte(res.Checkpoint), mgr.LogOrigin(logID), verifier)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), cp.Size)
+	require.Equal(t, uint64(3), res.TreeSize)
+	require.NotEmpty(t, res.CheckpointProof)
+
+	entry, err := base64.StdEncoding.DecodeString(res.Entry)
+	require.NoError(t, err)
+	assert.Equal(t, "entry 2", string(entry))
+	rootHash, err := base64.StdEncoding.DecodeString(res.RootHash)
+	require.NoError(t, err)
+	require.NoError(t, proof.VerifyInclusion(rfc6962.DefaultHasher, 2, res.TreeSize, rfc6962.DefaultHasher.HashLeaf(entry), decode(res.Proof), rootHash))
+	require.NoError(t, proof.VerifyConsistency(rfc6962.DefaultHasher, res.TreeSize, cp.Size, decode(res.CheckpointProof), rootHash, cp.Hash))
+
+	// At the checkpoint size no extra proof is needed
+	req = httptest.NewRequest("GET", "/logs/"+logID+"/proof/inclusion?index=2", nil)
+	req.SetPathValue("logID", logID)
+	w = httptest.NewRecorder()
+	handler.HandleInclusionProof(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	res = capabilities.ProveSuccess{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	assert.Empty(t, res.CheckpointProof)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(cp.Hash), res.RootHash)
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/storacha/go-ucanto/core/invocation"
+	"github.com/storacha/go-ucanto/core/result"
+	"github.com/storacha/go-ucanto/principal"
+	"github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/storacha/go-ucanto/ucan"
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+	"github.com/relves/ucanlog/pkg/capabilities"
+	logSvc "github.com/relves/ucanlog/pkg/log"
+	"github.com/relves/ucanlog/pkg/tlog"
+	"github.com/relves/ucanlog/pkg/types"
+)
+
+// revokedRestoreFixture is a log whose index CARs are in its space, written
+// by a service that revoked the delegation a friend of the space owner uses
+// to restore or migrate it.
+type revokedRestoreFixture struct {
+	blobs              storacha.StorachaClient
+	service            principal.Signer
+	friend             principal.Signer
+	spaceDID           string
+	dlg                delegation.Delegation // Friend -> service, proved by the revoked owner -> friend
+	indexCID           string
+	revocationIndexCID string
+	verifierKey        string // Signed-note verifier key of the writing service
+}
+
+// newTestTlogManager creates a manager with its own data directory that
+// stores blobs in blobs, standing in for the space.
+func newTestTlogManager(t *testing.T, privKey ed25519.PrivateKey, originPrefix string, service principal.Signer, blobs storacha.StorachaClient) (*tlog.Manager, *sqlite.StoreManager) {
+	t.Helper()
+	dataDir := t.TempDir()
+	storeManager := sqlite.NewStoreManager(dataDir)
+	t.Cleanup(func() { storeManager.CloseAll() })
+
+	logSigner, err := tlog.NewEd25519Signer(privKey, originPrefix)
+	require.NoError(t, err)
+	mgr, err := tlog.NewDelegatedManager(tlog.DelegatedManagerConfig{
+		BasePath:      dataDir,
+		Signer:        logSigner,
+		PrivateKey:    privKey,
+		OriginPrefix:  originPrefix,
+		ServiceSigner: service,
+		CIDStore:      tlog.NewStateStoreCIDStore(storeManager.GetStateStore),
+		StoreManager:  storeManager,
+		StorageClient: blobs,
+	})
+	require.NoError(t, err)
+	return mgr, storeManager
+}
+
+// uploadIndexedLog waits until a log's checkpoint of the given size is
+// indexed and uploads the log's index as an index CAR.
+func uploadIndexedLog(t *testing.T, ctx context.Context, store *sqlite.LogStore, blobs storacha.StorachaClient, logID string, size uint64) string {
+	t.Helper()
+	var index map[string]string
+	require.Eventually(t, func() bool {
+		var err error
+		index, err = store.GetCIDIndex(ctx, logID)
+		if err != nil || index["checkpoint"] == "" {
+			return false
+		}
+		cpRaw, err := blobs.FetchBlob(ctx, index["checkpoint"])
+		if err != nil {
+			return false
+		}
+		cp, err := tlog.ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == size
+	}, 5*time.Second, 50*time.Millisecond)
+
+	carData, _, err := indexpersist.BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	rootCID, err := blobs.UploadCAR(ctx, tlog.SpaceDIDForLog(logID), carData, nil)
+	require.NoError(t, err)
+	return rootCID
+}
+
+func newRevokedRestoreFixture(t *testing.T, privKey ed25519.PrivateKey, originPrefix string) *revokedRestoreFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	spaceOwner, err := signer.Generate()
+	require.NoError(t, err)
+	friend, err := signer.Generate()
+	require.NoError(t, err)
+	service, err := signer.Generate()
+	require.NoError(t, err)
+	spaceDID := spaceOwner.DID().String()
+
+	storageCaps := []ucan.Capability[ucan.NoCaveats]{
+		ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+		ucan.NewCapability("space/index/add", spaceDID, ucan.NoCaveats{}),
+		ucan.NewCapability("upload/add", spaceDID, ucan.NoCaveats{}),
+	}
+	ownerToFriend, err := delegation.Delegate(spaceOwner, friend.DID(), storageCaps)
+	require.NoError(t, err)
+	friendToService, err := delegation.Delegate(friend, service.DID(), storageCaps,
+		delegation.WithProof(delegation.FromDelegation(ownerToFriend)))
+	require.NoError(t, err)
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, stores := newTestTlogManager(t, privKey, originPrefix, service, blobs)
+	store, err := stores.GetStore(spaceDID)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, spaceDID, spaceDID, friendToService))
+	_, err = mgr.AddEntryWithDelegation(ctx, spaceDID, []byte("entry"), friendToService)
+	require.NoError(t, err)
+	indexCID := uploadIndexedLog(t, ctx, store, blobs, spaceDID, 1)
+
+	// The space owner revoked the friend's delegation
+	revocationLogID := tlog.RevocationLogID(spaceDID)
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, revocationLogID, spaceDID, friendToService))
+	revocation, err := (&types.RevocationEntry{
+		Type:      types.RevokeUCAN,
+		Target:    []byte(ownerToFriend.Link().String()),
+		Timestamp: time.Now(),
+	}).Serialize()
+	require.NoError(t, err)
+	_, err = mgr.AddEntryWithDelegation(ctx, revocationLogID, revocation, friendToService)
+	require.NoError(t, err)
+	revocationIndexCID := uploadIndexedLog(t, ctx, store, blobs, revocationLogID, 1)
+
+	verifierKey, err := mgr.LogVerifierKey(spaceDID)
+	require.NoError(t, err)
+	return &revokedRestoreFixture{
+		blobs:              blobs,
+		service:            service,
+		friend:             friend,
+		spaceDID:           spaceDID,
+		dlg:                friendToService,
+		indexCID:           indexCID,
+		revocationIndexCID: revocationIndexCID,
+		verifierKey:        verifierKey,
+	}
+}
+
+// invoke returns an invocation of ability by the friend.
+func (f *revokedRestoreFixture) invoke(t *testing.T, ability string) invocation.Invocation {
+	t.Helper()
+	inv, err := invocation.Invoke(f.friend, f.service,
+		ucan.NewCapability(ability, f.spaceDID, ucan.NoCaveats{}),
+		delegation.WithProof(delegation.FromDelegation(f.dlg)))
+	require.NoError(t, err)
+	return inv
+}
+
+// requireNotWritten checks that the log was not written by the target service.
+func (f *revokedRestoreFixture) requireNotWritten(t *testing.T, stores *sqlite.StoreManager) {
+	t.Helper()
+	store, err := stores.GetStore(f.spaceDID)
+	require.NoError(t, err)
+	_, err = store.GetLogRecord(context.Background(), f.spaceDID)
+	require.ErrorIs(t, err, sqlite.ErrNotFound)
+}
+
+// TestRestoreHandler_RejectsRevokedDelegation verifies that a delegation
+// revoked in the space's revocation log cannot restore the log, although
+// the revocation log is only restored with it.
+func TestRestoreHandler_RejectsRevokedDelegation(t *testing.T) {
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	f := newRevokedRestoreFixture(t, privKey, "test")
+
+	mgr, stores := newTestTlogManager(t, privKey, "test", f.service, f.blobs)
+	logService := logSvc.NewLogServiceWithConfig(logSvc.LogServiceConfig{TlogManager: mgr, StoreManager: stores})
+	encoded, err := delegation.Format(f.dlg)
+	require.NoError(t, err)
+
+	handler := restoreHandler(f.service.DID().String(), logService, nil)
+	res, _, err := handler(context.Background(),
+		ucan.NewCapability("tlog/restore", f.spaceDID, capabilities.RestoreCaveats{
+			Delegation:         encoded,
+			IndexCID:           f.indexCID,
+			RevocationIndexCID: &f.revocationIndexCID,
+		}),
+		f.invoke(t, "tlog/restore"), nil)
+	require.NoError(t, err)
+
+	_, failure := result.Unwrap(res)
+	require.Equal(t, "DelegationRevoked", failure.Name())
+	require.Contains(t, failure.Error(), "has been revoked")
+	f.requireNotWritten(t, stores)
+}
+
+// TestMigrateHandler_RejectsRevokedDelegation verifies that a delegation
+// revoked in the space's revocation log cannot take over the log, although
+// the revocation log is only migrated with it.
+func TestMigrateHandler_RejectsRevokedDelegation(t *testing.T) {
+	_, keyA, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, keyB, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	f := newRevokedRestoreFixture(t, keyA, "service-a")
+
+	mgr, stores := newTestTlogManager(t, keyB, "service-b", f.service, f.blobs)
+	logService := logSvc.NewLogServiceWithConfig(logSvc.LogServiceConfig{TlogManager: mgr, StoreManager: stores})
+	encoded, err := delegation.Format(f.dlg)
+	require.NoError(t, err)
+
+	handler := migrateHandler(f.service.DID().String(), logService, nil)
+	res, _, err := handler(context.Background(),
+		ucan.NewCapability("tlog/migrate", f.spaceDID, capabilities.MigrateCaveats{
+			Delegation:         encoded,
+			PreviousKey:        f.verifierKey,
+			IndexCID:           f.indexCID,
+			RevocationIndexCID: &f.revocationIndexCID,
+		}),
+		f.invoke(t, "tlog/migrate"), nil)
+	require.NoError(t, err)
+
+	_, failure := result.Unwrap(res)
+	require.Equal(t, "DelegationRevoked", failure.Name())
+	require.Contains(t, failure.Error(), "has been revoked")
+	f.requireNotWritten(t, stores)
+}
@@ -140,6 +140,14 @@ func newServerDirect(
 				appendHandler(serviceDID, logService, storeManager, validator),
 			),
 		),
+		// Register tlog/append-batch handler
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogAppendBatch.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogAppendBatch,
+				appendBatchHandler(serviceDID, logService, storeManager, validator),
+			),
+		),
 		// Register tlog/read handler - public read, no authorization needed
 		ucantoServer.WithServiceMethod(
 			capabilities.TlogRead.Can(),
@@ -148,6 +156,22 @@ func newServerDirect(
 				readHandler(logService, validator),
 			),
 		),
+		// Register tlog/prove handler - public like tlog/read
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogProve.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogProve,
+				proveHandler(logService, validator),
+			),
+		),
+		// Register tlog/consistency handler - public like tlog/read
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogConsistency.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogConsistency,
+				consistencyHandler(logService, validator),
+			),
+		),
 		// Register tlog/admin/revoke handler
 		ucantoServer.WithServiceMethod(
 			capabilities.TlogRevoke.Can(),
@@ -164,5 +188,74 @@ func newServerDirect(
 				garbageHandler(serviceDID, logService, validator),
 			),
 		),
+		// Register tlog/gc/schedule and tlog/gc/unschedule handlers
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogGCSchedule.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogGCSchedule,
+				gcScheduleHandler(serviceDID, logService, validator),
+			),
+		),
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogGCUnschedule.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogGCUnschedule,
+				gcUnscheduleHandler(serviceDID, logService, validator),
+			),
+		),
+		// Register tlog/revocations/rebuild handler
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogRebuildRevocations.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogRebuildRevocations,
+				rebuildRevocationsHandler(serviceDID, logService, validator),
+			),
+		),
+		// Register tlog/attribution handler - public like tlog/read
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogAttribution.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogAttribution,
+				attributionHandler(logService, validator),
+			),
+		),
+		// Register tlog/restore handler
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogRestore.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogRestore,
+				restoreHandler(serviceDID, logService, validator),
+			),
+		),
+		// Register tlog/migrate handler
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogMigrate.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogMigrate,
+				migrateHandler(serviceDID, logService, validator),
+			),
+		),
+		// Register tlog/webhook/* handlers
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogWebhookAdd.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogWebhookAdd,
+				webhookAddHandler(serviceDID, logService, validator),
+			),
+		),
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogWebhookRemove.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogWebhookRemove,
+				webhookRemoveHandler(serviceDID, logService, validator),
+			),
+		),
+		ucantoServer.WithServiceMethod(
+			capabilities.TlogWebhookList.Can(),
+			ProvideWithoutAuth(
+				capabilities.TlogWebhookList,
+				webhookListHandler(serviceDID, logService, validator),
+			),
+		),
 	)
 }
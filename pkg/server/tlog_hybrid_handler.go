@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/transparency-dev/tessera/api/layout"
+
+	"github.com/relves/ucanlog/internal/storage/blobcache"
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+// TlogHybridHandler serves the tlog-tiles API from the service's live state.
+// Paths are resolved through the log's cid_index in SQLite, which is updated
+// as entries are integrated, so responses track the head rather than the last
+// persisted index CAR. Blobs are read from the blob cache and fetched by CID
+// on a miss. Logs without local state fall back to the IPFS handler.
+type TlogHybridHandler struct {
+	storeManager *sqlite.StoreManager
+	blobs        tlog.BlobFetcher
+	blobCache    *blobcache.Cache // Optional; see WithBlobCache
+	fallback     *TlogIPFSHandler // Optional; serves logs without local state
+}
+
+// NewTlogHybridHandler creates a handler that serves tlog-tiles data through
+// the live CID index, fetching blobs from blobs. If fallback is nil, logs
+// without local state are not found.
+func NewTlogHybridHandler(storeManager *sqlite.StoreManager, blobs tlog.BlobFetcher, fallback *TlogIPFSHandler) *TlogHybridHandler {
+	return &TlogHybridHandler{
+		storeManager: storeManager,
+		blobs:        blobs,
+		fallback:     fallback,
+	}
+}
+
+// WithBlobCache reads blobs from a local blob cache before fetching them, and
+// caches the blobs fetched.
+func (h *TlogHybridHandler) WithBlobCache(cache *blobcache.Cache) *TlogHybridHandler {
+	h.blobCache = cache
+	return h
+}
+
+// HandleCheckpoint serves GET /logs/<logID>/checkpoint. The checkpoint's CID
+// is its ETag, so clients polling with If-None-Match get 304 Not Modified
+// until a new checkpoint is published.
+func (h *TlogHybridHandler) HandleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	if logID == "" {
+		http.Error(w, "missing logID", http.StatusBadRequest)
+		return
+	}
+	if !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+
+	var fallback http.HandlerFunc
+	if h.fallback != nil {
+		fallback = h.fallback.HandleCheckpoint
+	}
+	h.serve(w, r, logID, layout.CheckpointPath, nil, fallback, "text/plain; charset=utf-8", "public, max-age=5")
+}
+
+// HandleTile serves GET /logs/<logID>/tile/<L>/<N>[.p/<W>]
+func (h *TlogHybridHandler) HandleTile(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	levelStr := r.PathValue("level")
+	tilePath := r.PathValue("tilePath")
+
+	if logID == "" || levelStr == "" || tilePath == "" {
+		http.Error(w, "missing required path parameters", http.StatusBadRequest)
+		return
+	}
+	if !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+
+	level, err := strconv.ParseUint(levelStr, 10, 64)
+	if err != nil || level > 63 {
+		http.Error(w, "invalid level (must be 0-63)", http.StatusBadRequest)
+		return
+	}
+	index, partialWidth, err := parseTilePath(tilePath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid tile path: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	inTree := func(size uint64) bool { return tileInTree(level, index, partialWidth, size) }
+	var fallback http.HandlerFunc
+	if h.fallback != nil {
+		fallback = h.fallback.HandleTile
+	}
+	h.serve(w, r, logID, layout.TilePath(level, index, partialWidth), inTree, fallback,
+		"application/octet-stream", "public, max-age=31536000, immutable")
+}
+
+// HandleEntries serves GET /logs/<logID>/tile/entries/<N>[.p/<W>]
+func (h *TlogHybridHandler) HandleEntries(w http.ResponseWriter, r *http.Request) {
+	logID := r.PathValue("logID")
+	entryPath := r.PathValue("entryPath")
+
+	if logID == "" || entryPath == "" {
+		http.Error(w, "missing required path parameters", http.StatusBadRequest)
+		return
+	}
+	if !isValidLogID(logID) {
+		http.Error(w, "invalid logID", http.StatusBadRequest)
+		return
+	}
+
+	index, partialWidth, err := parseTilePath(entryPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid entry path: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	inTree := func(size uint64) bool { return tileInTree(0, index, partialWidth, size) }
+	var fallback http.HandlerFunc
+	if h.fallback != nil {
+		fallback = h.fallback.HandleEntries
+	}
+	h.serve(w, r, logID, layout.EntriesPath(index, partialWidth), inTree, fallback,
+		"application/octet-stream", "public, max-age=31536000, immutable")
+}
+
+// serve writes the blob indexed at path in the log's live CID index. Paths
+// outside the tree reported by tree_state are not found without consulting
+// storage, when inTree is set. Logs without local state are passed to
+// fallback.
+func (h *TlogHybridHandler) serve(w http.ResponseWriter, r *http.Request, logID, path string, inTree func(size uint64) bool, fallback http.HandlerFunc, contentType, cacheControl string) {
+	ctx := r.Context()
+
+	store, err := h.storeManager.GetStore(logID)
+	if err != nil {
+		slog.Error("failed to get store", "logID", logID, "error", err)
+		http.Error(w, "failed to get store", http.StatusInternalServerError)
+		return
+	}
+	if _, err := store.GetLogRecord(ctx, logID); err != nil {
+		if !errors.Is(err, sqlite.ErrNotFound) {
+			slog.Error("failed to get log record", "logID", logID, "error", err)
+			http.Error(w, "failed to get log record", http.StatusInternalServerError)
+			return
+		}
+		if fallback == nil {
+			http.Error(w, "log not found", http.StatusNotFound)
+			return
+		}
+		fallback(w, r)
+		return
+	}
+
+	if inTree != nil {
+		size, _, err := store.GetTreeState(ctx, logID)
+		if err != nil {
+			slog.Error("failed to get tree state", "logID", logID, "error", err)
+			http.Error(w, "failed to get tree state", http.StatusInternalServerError)
+			return
+		}
+		if !inTree(size) {
+			http.Error(w, fmt.Sprintf("%s is beyond tree size %d", path, size), http.StatusNotFound)
+			return
+		}
+	}
+
+	blobCID, err := store.GetCID(ctx, logID, path)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			http.Error(w, fmt.Sprintf("%s not found", path), http.StatusNotFound)
+			return
+		}
+		slog.Error("failed to get CID", "logID", logID, "path", path, "error", err)
+		http.Error(w, "failed to get CID", http.StatusInternalServerError)
+		return
+	}
+
+	// Blobs are content-addressed, so the CID is a strong validator
+	etag := `"` + blobCID + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := h.fetchBlob(ctx, blobCID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to fetch %s: %v", path, err), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// fetchBlob returns a blob from the blob cache, fetching it by CID on a miss.
+// Fetched blobs are checked against their CID.
+func (h *TlogHybridHandler) fetchBlob(ctx context.Context, blobCID string) ([]byte, error) {
+	if h.blobCache != nil {
+		if data, ok := h.blobCache.Get(blobCID); ok {
+			return data, nil
+		}
+	}
+	data, err := h.blobs.FetchBlob(ctx, blobCID)
+	if err != nil {
+		return nil, err
+	}
+	if err := blobcache.Verify(blobCID, data); err != nil {
+		return nil, err
+	}
+	if h.blobCache != nil {
+		if err := h.blobCache.Put(blobCID, data); err != nil {
+			slog.Warn("failed to cache blob", "cid", blobCID, "error", err)
+		}
+	}
+	return data, nil
+}
+
+// tileInTree reports whether a tree of the given size contains the tile, or
+// entry bundle at level 0. Full tiles exist once all their hashes do; partial
+// tiles exist up to the level's width.
+func tileInTree(level, index uint64, partialWidth uint8, size uint64) bool {
+	width := size >> (level * layout.TileHeight)
+	if partialWidth == 0 {
+		return index < width/layout.TileWidth
+	}
+	return index <= width/layout.TileWidth && index*layout.TileWidth+uint64(partialWidth) <= width
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
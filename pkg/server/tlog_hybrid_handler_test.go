@@ -0,0 +1,106 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/blobcache"
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/pkg/server"
+)
+
+func TestTlogHybridHandler(t *testing.T) {
+	ctx := context.Background()
+	logDID := "did:key:z6MkHybridLog"
+
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	cache, err := blobcache.New(filepath.Join(t.TempDir(), "cache"), 1<<20, nil)
+	require.NoError(t, err)
+
+	// A log of 300 entries in the live index
+	store, err := manager.GetStore(logDID)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateLogRecord(ctx, logDID))
+	require.NoError(t, store.SetTreeState(ctx, logDID, 300, make([]byte, 32)))
+	blobCIDs := make(map[string]string)
+	for _, path := range []string{"checkpoint", "tile/0/000", "tile/0/001.p/44", "tile/entries/001.p/44"} {
+		cid, err := blobs.UploadBlob(ctx, "did:key:space", []byte(path+" data"), nil)
+		require.NoError(t, err)
+		require.NoError(t, store.SetCID(ctx, logDID, path, cid))
+		blobCIDs[path] = cid
+	}
+
+	// Logs without local state are proxied to the gateway
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("gateway checkpoint"))
+	}))
+	defer gateway.Close()
+	fallback := server.NewTlogIPFSHandler(staticCIDStore("bafyRoot"), gateway.URL, nil)
+	handler := server.NewTlogHybridHandler(manager, blobs, fallback).WithBlobCache(cache)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /logs/{logID}/checkpoint", handler.HandleCheckpoint)
+	mux.HandleFunc("GET /logs/{logID}/tile/{level}/{tilePath...}", handler.HandleTile)
+	mux.HandleFunc("GET /logs/{logID}/tile/entries/{entryPath...}", handler.HandleEntries)
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/logs/"+logDID+"/checkpoint", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "checkpoint data", rec.Body.String())
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, `"`+blobCIDs["checkpoint"]+`"`, etag)
+	assert.Equal(t, "public, max-age=5", rec.Header().Get("Cache-Control"))
+	_, ok := cache.Get(blobCIDs["checkpoint"])
+	assert.True(t, ok)
+
+	rec = get("/logs/"+logDID+"/checkpoint", http.Header{"If-None-Match": {`"bafyOther", ` + etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+	for path, want := range map[string]int{
+		"tile/0/000":            http.StatusOK,
+		"tile/0/001.p/44":       http.StatusOK,
+		"tile/entries/001.p/44": http.StatusOK,
+		"tile/0/001":            http.StatusNotFound, // Beyond the tree
+		"tile/0/001.p/45":       http.StatusNotFound,
+		"tile/0/002.p/1":        http.StatusNotFound,
+		"tile/1/000":            http.StatusNotFound,
+		"tile/entries/002":      http.StatusNotFound,
+		"tile/0/000.p/10":       http.StatusNotFound, // In the tree but no longer stored
+		"tile/0/x000/000":       http.StatusOK,
+	} {
+		rec := get("/logs/"+logDID+"/"+path, nil)
+		assert.Equal(t, want, rec.Code, path)
+	}
+
+	rec = get("/logs/did:key:z6MkElsewhere/checkpoint", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gateway checkpoint", rec.Body.String())
+
+	// Without a fallback, logs without local state are not found
+	handler = server.NewTlogHybridHandler(manager, blobs, nil)
+	req := httptest.NewRequest("GET", "/logs/did:key:z6MkElsewhere/checkpoint", nil)
+	req.SetPathValue("logID", "did:key:z6MkElsewhere")
+	rec = httptest.NewRecorder()
+	handler.HandleCheckpoint(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -2,19 +2,27 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
+	"github.com/relves/ucanlog/internal/storage/blobcache"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
 	"github.com/relves/ucanlog/pkg/tlog"
 )
 
+// maxGatewayBlockSize bounds blocks fetched into the blob cache. Tiles and
+// entry bundles are far smaller.
+const maxGatewayBlockSize = 16 << 20
+
 // TlogIPFSHandler provides public HTTP GET endpoints for the tlog-tiles API
 // by proxying requests to IPFS gateway using the latest index CAR root CID.
 type TlogIPFSHandler struct {
 	cidStore   tlog.CIDStore
 	gatewayURL string // e.g., "https://ipfs.w3s.link"
 	httpClient *http.Client
+	blobCache  *blobcache.Cache // Optional; see WithBlobCache
 }
 
 // NewTlogIPFSHandler creates a new IPFS-backed handler for tlog-tiles API endpoints.
@@ -29,6 +37,15 @@ func NewTlogIPFSHandler(cidStore tlog.CIDStore, gatewayURL string, httpClient *h
 	}
 }
 
+// WithBlobCache serves tlog-tiles data from a local blob cache. Paths are
+// resolved through the directory blocks of the index CAR, and both those
+// blocks and the served blobs are fetched from the gateway by CID only on a
+// cache miss. Blobs the service uploaded itself are usually already cached.
+func (h *TlogIPFSHandler) WithBlobCache(cache *blobcache.Cache) *TlogIPFSHandler {
+	h.blobCache = cache
+	return h
+}
+
 // HandleCheckpoint serves GET /logs/<logID>/checkpoint by proxying to IPFS gateway.
 func (h *TlogIPFSHandler) HandleCheckpoint(w http.ResponseWriter, r *http.Request) {
 	logID := r.PathValue("logID")
@@ -52,8 +69,8 @@ func (h *TlogIPFSHandler) HandleCheckpoint(w http.ResponseWriter, r *http.Reques
 
 	// Fetch from IPFS gateway
 	url := fmt.Sprintf("%s/ipfs/%s/checkpoint", h.gatewayURL, rootCID)
-	if err := h.proxyFromGateway(w, r.Context(), url, "text/plain; charset=utf-8", "public, max-age=5"); err != nil {
-		http.Error(w, fmt.Sprintf("failed to fetch checkpoint: %v", err), http.StatusBadGateway)
+	if err := h.serve(w, r.Context(), rootCID, "checkpoint", url, "text/plain; charset=utf-8", "public, max-age=5"); err != nil {
+		http.Error(w, fmt.Sprintf("failed to fetch checkpoint: %v", err), httpStatusFor(err))
 		return
 	}
 }
@@ -84,9 +101,10 @@ func (h *TlogIPFSHandler) HandleTile(w http.ResponseWriter, r *http.Request) {
 
 	// Build IPFS gateway URL preserving the tile path exactly as received
 	// tilePath includes the x000/x001/234 format and optional .p/128 suffix
-	url := fmt.Sprintf("%s/ipfs/%s/tile/%s/%s", h.gatewayURL, rootCID, levelStr, tilePath)
-	if err := h.proxyFromGateway(w, r.Context(), url, "application/octet-stream", "public, max-age=31536000, immutable"); err != nil {
-		http.Error(w, fmt.Sprintf("failed to fetch tile: %v", err), http.StatusBadGateway)
+	path := fmt.Sprintf("tile/%s/%s", levelStr, tilePath)
+	url := fmt.Sprintf("%s/ipfs/%s/%s", h.gatewayURL, rootCID, path)
+	if err := h.serve(w, r.Context(), rootCID, path, url, "application/octet-stream", "public, max-age=31536000, immutable"); err != nil {
+		http.Error(w, fmt.Sprintf("failed to fetch tile: %v", err), httpStatusFor(err))
 		return
 	}
 }
@@ -115,13 +133,76 @@ func (h *TlogIPFSHandler) HandleEntries(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Build IPFS gateway URL preserving the entry path exactly as received
-	url := fmt.Sprintf("%s/ipfs/%s/tile/entries/%s", h.gatewayURL, rootCID, entryPath)
-	if err := h.proxyFromGateway(w, r.Context(), url, "application/octet-stream", "public, max-age=31536000, immutable"); err != nil {
-		http.Error(w, fmt.Sprintf("failed to fetch entry bundle: %v", err), http.StatusBadGateway)
+	path := "tile/entries/" + entryPath
+	url := fmt.Sprintf("%s/ipfs/%s/%s", h.gatewayURL, rootCID, path)
+	if err := h.serve(w, r.Context(), rootCID, path, url, "application/octet-stream", "public, max-age=31536000, immutable"); err != nil {
+		http.Error(w, fmt.Sprintf("failed to fetch entry bundle: %v", err), httpStatusFor(err))
 		return
 	}
 }
 
+// serve writes the blob at path in the index CAR rooted at rootCID, from the
+// blob cache when configured and otherwise by proxying url.
+func (h *TlogIPFSHandler) serve(w http.ResponseWriter, ctx context.Context, rootCID, path, url, contentType, cacheControl string) error {
+	if h.blobCache == nil {
+		return h.proxyFromGateway(w, ctx, url, contentType, cacheControl)
+	}
+
+	blobCID, err := indexpersist.ResolvePath(ctx, rootCID, path, h.fetchBlock)
+	if err != nil {
+		return err
+	}
+	data, err := h.fetchBlock(ctx, blobCID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(data)
+	return err
+}
+
+// fetchBlock returns a block from the blob cache, fetching it from the
+// gateway by CID on a miss. The cache rejects blocks that do not match their
+// CID.
+func (h *TlogIPFSHandler) fetchBlock(ctx context.Context, blockCID string) ([]byte, error) {
+	if data, ok := h.blobCache.Get(blockCID); ok {
+		return data, nil
+	}
+
+	url := fmt.Sprintf("%s/ipfs/%s?format=raw", h.gatewayURL, blockCID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gateway returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGatewayBlockSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block %s: %w", blockCID, err)
+	}
+	if err := h.blobCache.Put(blockCID, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// httpStatusFor maps a serve error to a response status.
+func httpStatusFor(err error) int {
+	if errors.Is(err, indexpersist.ErrPathNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
+
 // proxyFromGateway fetches data from IPFS gateway and streams it to the response.
 func (h *TlogIPFSHandler) proxyFromGateway(w http.ResponseWriter, ctx context.Context, url string, contentType string, cacheControl string) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
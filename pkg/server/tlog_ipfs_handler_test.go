@@ -0,0 +1,111 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/storacha/go-ucanto/core/car"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/blobcache"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+	"github.com/relves/ucanlog/pkg/server"
+)
+
+// staticCIDStore returns the same index CID for every log.
+type staticCIDStore string
+
+func (s staticCIDStore) GetLatestCID(logID string) (string, error) { return string(s), nil }
+func (s staticCIDStore) SetLatestCID(logID, cid string) error      { return nil }
+
+func TestTlogIPFSHandler_BlobCache(t *testing.T) {
+	ctx := context.Background()
+
+	// A gateway serving the blocks of an index CAR and the blobs it links
+	blocks := make(map[string][]byte)
+	tile := bytes.Repeat([]byte{7}, 64)
+	tileCID, _, err := storacha.ComputeCID(tile)
+	require.NoError(t, err)
+	blocks[tileCID] = tile
+	carData, rootCID, err := indexpersist.BuildIndexCAR(ctx, map[string]string{"tile/0/x001/002": tileCID})
+	require.NoError(t, err)
+	_, carBlocks, err := car.Decode(bytes.NewReader(carData))
+	require.NoError(t, err)
+	for blk, err := range carBlocks {
+		require.NoError(t, err)
+		blocks[blk.Link().(cidlink.Link).Cid.String()] = blk.Bytes()
+	}
+
+	var requests atomic.Int32
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		data, ok := blocks[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer gateway.Close()
+
+	cache, err := blobcache.New(t.TempDir(), 1<<20, nil)
+	require.NoError(t, err)
+	handler := server.NewTlogIPFSHandler(staticCIDStore(rootCID), gateway.URL, nil).WithBlobCache(cache)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /logs/{logID}/tile/{level}/{tilePath...}", handler.HandleTile)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get("/logs/did:key:z6MkTest/tile/0/x001/002")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, _ := io.ReadAll(rec.Body)
+	assert.Equal(t, tile, body)
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	fetched := requests.Load()
+	assert.Positive(t, fetched)
+
+	// Served locally the second time
+	rec = get("/logs/did:key:z6MkTest/tile/0/x001/002")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, fetched, requests.Load())
+
+	// Paths missing from the index are not found rather than a gateway error
+	rec = get("/logs/did:key:z6MkTest/tile/0/x001/003")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Forged content is rejected and not cached
+	forgedCID, _, err := storacha.ComputeCID([]byte("other"))
+	require.NoError(t, err)
+	carData, forgedRoot, err := indexpersist.BuildIndexCAR(ctx, map[string]string{"tile/0/000": forgedCID})
+	require.NoError(t, err)
+	_, carBlocks, err = car.Decode(bytes.NewReader(carData))
+	require.NoError(t, err)
+	for blk, err := range carBlocks {
+		require.NoError(t, err)
+		blocks[blk.Link().(cidlink.Link).Cid.String()] = blk.Bytes()
+	}
+	blocks[forgedCID] = []byte("forged")
+	handler = server.NewTlogIPFSHandler(staticCIDStore(forgedRoot), gateway.URL, nil).WithBlobCache(cache)
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/logs/did:key:z6MkTest/tile/0/000", nil)
+	req.SetPathValue("logID", "did:key:z6MkTest")
+	req.SetPathValue("level", "0")
+	req.SetPathValue("tilePath", "000")
+	handler.HandleTile(rec, req)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	_, ok := cache.Get(forgedCID)
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/relves/ucanlog/pkg/log"
+	"github.com/relves/ucanlog/pkg/tlog"
 )
 
 // TlogTilesHandler provides public HTTP GET endpoints for the tlog-tiles API
@@ -179,12 +180,18 @@ func parseTilePath(path string) (index uint64, partialWidth uint8, err error) {
 	return index, partialWidth, nil
 }
 
-// isValidLogID validates a logID to prevent path traversal attacks
+// isValidLogID validates a logID to prevent path traversal attacks.
+// Named logs are addressed as "{spaceDID}/{name}", with the slash
+// percent-encoded in URLs (e.g. /logs/did:key:z6Mk...%2Fevidence/checkpoint).
 func isValidLogID(logID string) bool {
-	// LogID should not contain path separators or special characters
+	spaceDID, name, named := strings.Cut(logID, "/")
+	if named && tlog.ValidateLogName(name) != nil {
+		return false
+	}
+	// The space DID should not contain path separators or special characters
 	// Allow alphanumeric, hyphens, underscores, and colons (for DID format)
-	if strings.ContainsAny(logID, "/\\.") {
+	if strings.ContainsAny(spaceDID, "/\\.") {
 		return false
 	}
-	return len(logID) > 0 && len(logID) < 256
+	return len(spaceDID) > 0 && len(logID) < 256
 }
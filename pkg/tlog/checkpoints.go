@@ -0,0 +1,89 @@
+package tlog
+
+import (
+	"sync"
+)
+
+// checkpointBuffer is the number of checkpoints buffered per subscriber.
+const checkpointBuffer = 16
+
+// CheckpointEvent is a signed checkpoint published by a log.
+type CheckpointEvent struct {
+	LogID      string
+	TreeSize   uint64
+	Checkpoint []byte
+}
+
+// checkpointHub fans out published checkpoints to subscribers. The zero value
+// is ready to use.
+type checkpointHub struct {
+	mu   sync.RWMutex
+	subs map[*checkpointSub]struct{}
+}
+
+type checkpointSub struct {
+	logs map[string]bool // Nil subscribes to every log
+	ch   chan CheckpointEvent
+}
+
+// publish delivers a checkpoint to the subscribers of its log without
+// blocking. A subscriber whose buffer is full loses its oldest checkpoint,
+// which the new one supersedes.
+func (h *checkpointHub) publish(logID string, size uint64, checkpoint []byte) {
+	ev := CheckpointEvent{LogID: logID, TreeSize: size, Checkpoint: checkpoint}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		if sub.logs != nil && !sub.logs[logID] {
+			continue
+		}
+		for sent := false; !sent; {
+			select {
+			case sub.ch <- ev:
+				sent = true
+			default:
+				select {
+				case <-sub.ch:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (h *checkpointHub) subscribe(logIDs []string) (<-chan CheckpointEvent, func()) {
+	sub := &checkpointSub{ch: make(chan CheckpointEvent, checkpointBuffer)}
+	if len(logIDs) > 0 {
+		sub.logs = make(map[string]bool, len(logIDs))
+		for _, id := range logIDs {
+			sub.logs[id] = true
+		}
+	}
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[*checkpointSub]struct{})
+	}
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, sub)
+			h.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// SubscribeCheckpoints returns a channel receiving the checkpoints published
+// by the given logs, or by every log when none are given, as entries are
+// integrated. Only checkpoints published by this process are delivered. A
+// subscriber that falls behind skips to the latest checkpoints. The returned
+// function cancels the subscription and closes the channel.
+func (m *Manager) SubscribeCheckpoints(logIDs ...string) (<-chan CheckpointEvent, func()) {
+	return m.checkpoints.subscribe(logIDs)
+}
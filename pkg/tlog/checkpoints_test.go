@@ -0,0 +1,94 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SubscribeCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkStreamTest"
+	otherID := "did:key:z6MkStreamOther"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	m, _ := newRestoreTestManager(t, privKey, blobs)
+
+	events, cancel := m.SubscribeCheckpoints(logID)
+	all, cancelAll := m.SubscribeCheckpoints()
+	defer cancelAll()
+
+	require.NoError(t, m.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	require.NoError(t, m.CreateLogWithDelegation(ctx, otherID, otherID, dlg))
+	_, err = m.AddEntryWithDelegation(ctx, logID, []byte("entry"), dlg)
+	require.NoError(t, err)
+	_, err = m.AddEntryWithDelegation(ctx, otherID, []byte("entry"), dlg)
+	require.NoError(t, err)
+
+	next := func(ch <-chan CheckpointEvent) CheckpointEvent {
+		t.Helper()
+		select {
+		case ev := <-ch:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("no checkpoint published")
+			return CheckpointEvent{}
+		}
+	}
+	ev := next(events)
+	assert.Equal(t, logID, ev.LogID)
+	assert.Equal(t, uint64(1), ev.TreeSize)
+	cp, err := ParseCheckpoint(ev.Checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, m.LogOrigin(logID), cp.Origin)
+	assert.Equal(t, uint64(1), cp.Size)
+
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		seen[next(all).LogID] = true
+	}
+	assert.True(t, seen[logID] && seen[otherID])
+
+	// Only the subscribed log is delivered
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected checkpoint for %s", ev.LogID)
+	default:
+	}
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+	cancel()
+}
+
+func TestCheckpointHub_DropsOldestWhenFull(t *testing.T) {
+	var hub checkpointHub
+	events, cancel := hub.subscribe(nil)
+	defer cancel()
+
+	for size := uint64(1); size <= checkpointBuffer+5; size++ {
+		hub.publish("did:key:z6MkTest", size, nil)
+	}
+	first := <-events
+	assert.Equal(t, uint64(6), first.TreeSize)
+	for i := 1; i < checkpointBuffer; i++ {
+		<-events
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected checkpoint of size %d", ev.TreeSize)
+	default:
+	}
+}
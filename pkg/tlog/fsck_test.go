@@ -0,0 +1,50 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Fsck(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkFsckTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	mgr, _ := newRestoreTestManager(t, privKey, blobs)
+
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	for i := 0; i < 4; i++ {
+		_, err := mgr.AddEntryWithDelegation(ctx, logID, []byte{byte(i)}, dlg)
+		require.NoError(t, err)
+	}
+	waitForIntegration(t, ctx, mgr, logID, 4)
+	require.Eventually(t, func() bool {
+		cpRaw, err := mgr.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			return false
+		}
+		cp, err := ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == 4
+	}, 5*time.Second, 50*time.Millisecond)
+
+	report, err := mgr.Fsck(ctx, logID, false, nil)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report.Problems)
+	assert.Equal(t, uint64(4), report.CheckpointSize)
+	assert.Equal(t, report.Root, report.ComputedRoot)
+
+	_, err = mgr.Fsck(ctx, logID, true, nil)
+	require.ErrorContains(t, err, "delegation required")
+}
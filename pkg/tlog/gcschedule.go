@@ -0,0 +1,273 @@
+package tlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/transparency-dev/tessera/api/layout"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+)
+
+// ErrGCNotScheduled is returned when unscheduling garbage collection for a
+// log that has no stored GC delegation.
+var ErrGCNotScheduled = errors.New("garbage collection not scheduled")
+
+// GCSchedulerConfig configures background garbage collection of the logs
+// whose space owner stored a space/blob/remove delegation with ScheduleGC.
+type GCSchedulerConfig struct {
+	// Interval is the time between scheduled runs for a log. A run that
+	// stops at the manager's GC MaxBundles limit is followed by another one
+	// after the GC MinInterval instead, until the log has caught up.
+	// Default: 1h
+	Interval time.Duration
+
+	// PollInterval is how often stored delegations are checked for expiry
+	// and revocation, and logs due for a run are collected.
+	// Default: 1m
+	PollInterval time.Duration
+
+	// IsRevoked reports whether a stored delegation has been revoked. If
+	// nil, delegations are only dropped when they expire.
+	IsRevoked func(ctx context.Context, logID string, dlg delegation.Delegation) (bool, error)
+
+	// OnCollected, if set, is called after each successful scheduled run.
+	OnCollected func(ctx context.Context, logID string, result *storacha.GCResult)
+}
+
+// ScheduleGC stores a direct space/blob/remove delegation from the space
+// owner, with which the GC scheduler collects the log in the background. It
+// replaces any delegation stored before. The caller validates dlg.
+func (m *Manager) ScheduleGC(ctx context.Context, logID string, dlg delegation.Delegation, createdBy string) (*sqlite.GCSchedule, error) {
+	store, err := m.gcScheduleStore(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := delegation.Format(dlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delegation: %w", err)
+	}
+	sched := sqlite.GCSchedule{
+		Delegation:    encoded,
+		DelegationCID: dlg.Link().String(),
+		CreatedBy:     createdBy,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+	if exp := dlg.Expiration(); exp != nil {
+		expiresAt := time.Unix(int64(*exp), 0).UTC()
+		sched.ExpiresAt = &expiresAt
+	}
+	if err := store.SetGCSchedule(ctx, logID, sched); err != nil {
+		return nil, fmt.Errorf("failed to store GC delegation: %w", err)
+	}
+
+	m.logger.Info("scheduled garbage collection", "logID", logID, "delegation", sched.DelegationCID)
+	return &sched, nil
+}
+
+// UnscheduleGC drops the stored GC delegation of a log.
+func (m *Manager) UnscheduleGC(ctx context.Context, logID string) error {
+	store, err := m.gcScheduleStore(ctx, logID)
+	if err != nil {
+		return err
+	}
+	err = store.DeleteGCSchedule(ctx, logID)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return fmt.Errorf("%w for log %s", ErrGCNotScheduled, logID)
+	}
+	return err
+}
+
+// gcScheduleStore returns the store holding the GC schedule of an existing log.
+func (m *Manager) gcScheduleStore(ctx context.Context, logID string) (*sqlite.LogStore, error) {
+	if m.storeManager == nil {
+		return nil, fmt.Errorf("garbage collection scheduling requires SQLite state storage")
+	}
+	if _, err := m.GetLogInstance(ctx, logID); err != nil {
+		return nil, fmt.Errorf("log %s not found: %w", logID, err)
+	}
+	return m.storeManager.GetStore(logID)
+}
+
+// StartGCScheduler collects the logs with a stored GC delegation in the
+// background until ctx is canceled. Runs resume from each log's GC progress
+// and process at most MaxBundles bundles, like a tlog/gc invocation.
+// Delegations that have expired, have been revoked or no longer parse are
+// dropped.
+func (m *Manager) StartGCScheduler(ctx context.Context, cfg GCSchedulerConfig) error {
+	if m.storeManager == nil {
+		return fmt.Errorf("garbage collection scheduling requires SQLite state storage")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+
+	s := &gcScheduler{
+		mgr:     m,
+		cfg:     cfg,
+		backlog: make(map[string]bool),
+	}
+	go s.run(ctx)
+	return nil
+}
+
+// gcScheduler runs scheduled garbage collection for a Manager.
+type gcScheduler struct {
+	mgr *Manager
+	cfg GCSchedulerConfig
+
+	mu      sync.Mutex
+	backlog map[string]bool // Logs whose last run stopped at MaxBundles
+}
+
+func (s *gcScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll checks the GC schedule of every log and collects those due.
+func (s *gcScheduler) poll(ctx context.Context) {
+	logIDs, err := s.mgr.storeManager.LogDIDs()
+	if err != nil {
+		s.mgr.logger.Warn("failed to list logs for scheduled GC", "error", err)
+		return
+	}
+	for _, logID := range logIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if IsRevocationLog(logID) {
+			continue
+		}
+		s.collect(ctx, logID)
+	}
+}
+
+// collect runs garbage collection for a log if it has a usable stored
+// delegation and is due.
+func (s *gcScheduler) collect(ctx context.Context, logID string) {
+	logger := s.mgr.logger.With("logID", logID)
+
+	store, err := s.mgr.storeManager.GetStore(logID)
+	if err != nil {
+		logger.Warn("failed to open store for scheduled GC", "error", err)
+		return
+	}
+	sched, err := store.GetGCSchedule(ctx, logID)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		logger.Warn("failed to read GC schedule", "error", err)
+		return
+	}
+
+	now := time.Now()
+	dlg, reason := s.checkDelegation(ctx, logID, sched, now)
+	if reason != "" {
+		if err := store.DeleteGCSchedule(ctx, logID); err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+			logger.Warn("failed to drop GC delegation", "error", err)
+			return
+		}
+		s.setBacklog(logID, false)
+		logger.Info("dropped GC delegation", "delegation", sched.DelegationCID, "reason", reason)
+		return
+	}
+	if dlg == nil || !s.due(logID, sched, now) {
+		return
+	}
+
+	result, runErr := s.mgr.RunGC(ctx, logID, dlg)
+	var errMsg string
+	if runErr != nil {
+		errMsg = runErr.Error()
+		logger.Warn("scheduled garbage collection failed", "error", runErr)
+	}
+	if err := store.RecordGCScheduleRun(ctx, logID, now, errMsg); err != nil {
+		logger.Warn("failed to record scheduled GC run", "error", err)
+	}
+	if runErr != nil {
+		s.setBacklog(logID, false)
+		return
+	}
+
+	// A run that stopped short of the last complete bundle hit MaxBundles
+	treeSize, _, err := store.GetTreeState(ctx, logID)
+	if err != nil {
+		logger.Warn("failed to read tree state after scheduled GC", "error", err)
+	}
+	complete := treeSize / layout.EntryBundleWidth * layout.EntryBundleWidth
+	s.setBacklog(logID, result.NewGCPosition < complete)
+
+	logger.Debug("scheduled garbage collection completed",
+		"bundles", result.BundlesProcessed, "blobs", result.BlobsRemoved, "position", result.NewGCPosition)
+	if s.cfg.OnCollected != nil {
+		s.cfg.OnCollected(ctx, logID, result)
+	}
+}
+
+// checkDelegation parses a stored delegation. It returns a non-empty reason
+// when the delegation must be dropped, and a nil delegation without reason
+// when it could not be checked this time.
+func (s *gcScheduler) checkDelegation(ctx context.Context, logID string, sched *sqlite.GCSchedule, now time.Time) (delegation.Delegation, string) {
+	if sched.ExpiresAt != nil && now.After(*sched.ExpiresAt) {
+		return nil, "expired"
+	}
+	dlg, err := delegation.Parse(sched.Delegation)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid delegation: %v", err)
+	}
+	if s.cfg.IsRevoked != nil {
+		revoked, err := s.cfg.IsRevoked(ctx, logID, dlg)
+		if err != nil {
+			s.mgr.logger.Warn("failed to check GC delegation revocation", "logID", logID, "error", err)
+			return nil, ""
+		}
+		if revoked {
+			return nil, "revoked"
+		}
+	}
+	return dlg, ""
+}
+
+// due reports whether a log's next scheduled run should start at now.
+func (s *gcScheduler) due(logID string, sched *sqlite.GCSchedule, now time.Time) bool {
+	if sched.LastRunAt == nil {
+		return true
+	}
+	interval := s.cfg.Interval
+	s.mu.Lock()
+	if s.backlog[logID] {
+		interval = s.mgr.gc.MinInterval
+	}
+	s.mu.Unlock()
+	return !now.Before(sched.LastRunAt.Add(interval))
+}
+
+func (s *gcScheduler) setBacklog(logID string, behind bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if behind {
+		s.backlog[logID] = true
+	} else {
+		delete(s.backlog, logID)
+	}
+}
@@ -0,0 +1,304 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+	ed25519signer "github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/gc"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+)
+
+// gcTestConfig collects at most one bundle per GC run.
+var gcTestConfig = gc.Config{MaxBundles: 1, MinInterval: 10 * time.Millisecond}
+
+// newGCTestManager creates a manager storing blobs in blobs that collects
+// with cfg.
+func newGCTestManager(t *testing.T, blobs storacha.StorachaClient, cfg gc.Config) (*Manager, *sqlite.StoreManager) {
+	t.Helper()
+	dataDir := t.TempDir()
+	storeManager := sqlite.NewStoreManager(dataDir)
+	t.Cleanup(func() { storeManager.CloseAll() })
+
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signer, err := NewEd25519Signer(privKey, "test")
+	require.NoError(t, err)
+	serviceSigner, err := ed25519signer.Generate()
+	require.NoError(t, err)
+
+	mgr, err := NewDelegatedManager(DelegatedManagerConfig{
+		BasePath:      dataDir,
+		Signer:        signer,
+		PrivateKey:    privKey,
+		OriginPrefix:  "test",
+		ServiceSigner: serviceSigner,
+		CIDStore:      NewStateStoreCIDStore(storeManager.GetStateStore),
+		StoreManager:  storeManager,
+		StorageClient: blobs,
+		GC:            cfg,
+	})
+	require.NoError(t, err)
+	return mgr, storeManager
+}
+
+// appendEntries appends n entries in batches of at most MaxBatchEntries and
+// waits for them to be integrated into a tree of size total.
+func appendEntries(t *testing.T, ctx context.Context, mgr *Manager, logID string, dlg delegation.Delegation, n int, total uint64) {
+	t.Helper()
+	entries := make([][]byte, n)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d/%d", total, i))
+	}
+	for len(entries) > 0 {
+		batch := entries[:min(len(entries), MaxBatchEntries)]
+		entries = entries[len(batch):]
+		_, err := mgr.AddEntriesWithDelegation(ctx, logID, batch, dlg)
+		require.NoError(t, err)
+	}
+	waitForIntegration(t, ctx, mgr, logID, total)
+}
+
+func TestManager_GCScheduler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkGCScheduleTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, storeManager := newGCTestManager(t, blobs, gcTestConfig)
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+
+	// Two integrations leave a partial version of the first two bundles
+	appendEntries(t, ctx, mgr, logID, dlg, 200, 200)
+	appendEntries(t, ctx, mgr, logID, dlg, 400, 600)
+
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+	partials := func() map[string]string {
+		index, err := store.GetCIDIndex(ctx, logID)
+		require.NoError(t, err)
+		found := make(map[string]string)
+		for path, cid := range index {
+			if strings.HasPrefix(path, "tile/entries/000.p/") || strings.HasPrefix(path, "tile/entries/001.p/") {
+				found[path] = cid
+			}
+		}
+		return found
+	}
+	before := partials()
+	require.NotEmpty(t, before)
+
+	_, err = mgr.ScheduleGC(ctx, logID, dlg, "did:key:z6MkOwner")
+	require.NoError(t, err)
+
+	collected := make(chan *storacha.GCResult, 4)
+	require.NoError(t, mgr.StartGCScheduler(ctx, GCSchedulerConfig{
+		PollInterval: 20 * time.Millisecond,
+		OnCollected: func(ctx context.Context, id string, result *storacha.GCResult) {
+			assert.Equal(t, logID, id)
+			collected <- result
+		},
+	}))
+
+	// MaxBundles is one, so the log catches up over two runs
+	for _, position := range []uint64{256, 512} {
+		select {
+		case result := <-collected:
+			assert.Equal(t, 1, result.BundlesProcessed)
+			assert.Equal(t, position, result.NewGCPosition)
+			assert.NotZero(t, result.BytesFreed)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no scheduled GC run up to position %d", position)
+		}
+	}
+
+	progress, err := store.GetGCProgress(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(512), progress)
+	assert.Empty(t, partials())
+	for path, cid := range before {
+		_, err := blobs.FetchBlob(ctx, cid)
+		assert.Error(t, err, "partial %s was not removed", path)
+	}
+
+	sched, err := store.GetGCSchedule(ctx, logID)
+	require.NoError(t, err)
+	require.NotNil(t, sched.LastRunAt)
+	assert.Empty(t, sched.LastError)
+
+	// Caught up: the next run waits for the interval
+	select {
+	case result := <-collected:
+		t.Fatalf("unexpected scheduled GC run: %+v", result)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, mgr.UnscheduleGC(ctx, logID))
+	assert.ErrorIs(t, mgr.UnscheduleGC(ctx, logID), ErrGCNotScheduled)
+}
+
+func TestManager_PlanGC(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkGCPlanTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, storeManager := newGCTestManager(t, blobs, gcTestConfig)
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	appendEntries(t, ctx, mgr, logID, dlg, 200, 200)
+	appendEntries(t, ctx, mgr, logID, dlg, 100, 300)
+
+	plan, err := mgr.PlanGC(ctx, logID)
+	require.NoError(t, err)
+	assert.True(t, plan.DryRun)
+	assert.Equal(t, 1, plan.BundlesProcessed)
+	assert.Equal(t, uint64(256), plan.NewGCPosition)
+	assert.Zero(t, plan.BlobsRemoved)
+	assert.Zero(t, plan.UnknownSizes)
+	require.NotEmpty(t, plan.Blobs)
+
+	// Sizes recorded at upload match the stored blobs, which are kept
+	var total uint64
+	for _, blob := range plan.Blobs {
+		assert.True(t, strings.Contains(blob.Path, ".p/"), "%s is not a partial", blob.Path)
+		data, err := blobs.FetchBlob(ctx, blob.CID)
+		require.NoError(t, err)
+		require.True(t, blob.SizeKnown)
+		assert.Equal(t, uint64(len(data)), blob.Size)
+		total += blob.Size
+	}
+	assert.Equal(t, total, plan.BytesFreed)
+
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+	progress, err := store.GetGCProgress(ctx, logID)
+	require.NoError(t, err)
+	assert.Zero(t, progress)
+
+	// The real run removes exactly what the dry run listed
+	result, err := mgr.RunGC(ctx, logID, dlg)
+	require.NoError(t, err)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, len(plan.Blobs), result.BlobsRemoved)
+	assert.Equal(t, plan.BytesFreed, result.BytesFreed)
+	for _, blob := range plan.Blobs {
+		_, err := blobs.FetchBlob(ctx, blob.CID)
+		assert.Error(t, err, "%s was not removed", blob.Path)
+	}
+}
+
+func TestManager_GCCollectsOldHeads(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkGCHeadsTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, storeManager := newGCTestManager(t, blobs, gc.Config{HeadRetention: 2})
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	for size := uint64(1); size <= 4; size++ {
+		appendEntries(t, ctx, mgr, logID, dlg, 1, size)
+	}
+
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+	history, err := store.GetHeadHistory(ctx, logID)
+	require.NoError(t, err)
+	checkpoints := make(map[uint64]string)
+	for _, head := range history {
+		require.NotEmpty(t, head.CheckpointCID)
+		checkpoints[head.TreeSize] = head.CheckpointCID
+	}
+	require.Len(t, checkpoints, 4)
+
+	// Only the checkpoints of heads older than the two most recent are listed
+	plan, err := mgr.PlanGC(ctx, logID)
+	require.NoError(t, err)
+	var planned []uint64
+	for _, blob := range plan.Blobs {
+		if blob.Kind != gc.KindCheckpoint {
+			continue
+		}
+		assert.Equal(t, checkpoints[blob.TreeSize], blob.CID)
+		data, err := blobs.FetchBlob(ctx, blob.CID)
+		require.NoError(t, err)
+		require.True(t, blob.SizeKnown)
+		assert.Equal(t, uint64(len(data)), blob.Size)
+		planned = append(planned, blob.TreeSize)
+	}
+	assert.Equal(t, []uint64{1, 2}, planned)
+
+	_, err = mgr.RunGC(ctx, logID, dlg)
+	require.NoError(t, err)
+	for size, cid := range checkpoints {
+		_, err := blobs.FetchBlob(ctx, cid)
+		if size <= 2 {
+			assert.Error(t, err, "checkpoint of size %d was not removed", size)
+		} else {
+			assert.NoError(t, err, "checkpoint of size %d was removed", size)
+		}
+	}
+
+	// Collected heads keep their root but no longer resolve by CID
+	head, err := store.GetHeadAtSize(ctx, logID, 1)
+	require.NoError(t, err)
+	assert.Empty(t, head.CheckpointCID)
+	assert.NotEmpty(t, head.Root)
+	_, err = store.GetHeadByCID(ctx, logID, checkpoints[1])
+	assert.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	// The latest checkpoint is still served
+	_, err = mgr.ReadCheckpoint(ctx, logID)
+	assert.NoError(t, err)
+}
+
+func TestManager_GCSchedulerDropsDelegation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	expiredLog := "did:key:z6MkGCExpired"
+	revokedLog := "did:key:z6MkGCRevoked"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, storeManager := newGCTestManager(t, blobs, gcTestConfig)
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, expiredLog, expiredLog, dlg))
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, revokedLog, revokedLog, dlg))
+
+	expired := storachatest.MockDelegation(delegation.WithExpiration(int(time.Now().Add(time.Second).Unix())))
+	_, err = mgr.ScheduleGC(ctx, expiredLog, expired, "did:key:z6MkOwner")
+	require.NoError(t, err)
+	revoked := storachatest.MockDelegation()
+	_, err = mgr.ScheduleGC(ctx, revokedLog, revoked, "did:key:z6MkOwner")
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.StartGCScheduler(ctx, GCSchedulerConfig{
+		PollInterval: 20 * time.Millisecond,
+		IsRevoked: func(ctx context.Context, logID string, d delegation.Delegation) (bool, error) {
+			return d.Link().String() == revoked.Link().String(), nil
+		},
+	}))
+
+	for _, logID := range []string{expiredLog, revokedLog} {
+		store, err := storeManager.GetStore(logID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			_, err := store.GetGCSchedule(ctx, logID)
+			return err == sqlite.ErrNotFound
+		}, 5*time.Second, 20*time.Millisecond, "GC delegation of %s was not dropped", logID)
+	}
+}
@@ -0,0 +1,154 @@
+package tlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/client"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+)
+
+var (
+	// ErrHistoryGap is returned by VerifyIndexHistory when a previous index
+	// root or its checkpoint cannot be fetched.
+	ErrHistoryGap = indexpersist.ErrHistoryGap
+
+	// ErrHistoryFork is returned by VerifyIndexHistory when a head is not
+	// consistent with the head indexed after it.
+	ErrHistoryFork = errors.New("index history forks")
+)
+
+// HistoryHead is a head in the linked history of a log's index CARs.
+type HistoryHead struct {
+	IndexCID         string // Root of the index CAR
+	PreviousIndexCID string // Root of the index CAR uploaded before, empty for the first
+	CheckpointCID    string
+	TreeSize         uint64
+	RootHash         []byte
+	MigrationCID     string // Latest migration record in the index, if any
+}
+
+// VerifyIndexHistory walks the history of a log's index CARs back from
+// latestCID, through the link each index root holds to the previous one,
+// and returns the heads newest first. Only blobs fetched through fetcher are
+// used, so anyone holding the latest index CID can check the history.
+//
+// Every checkpoint must be signed by verifier for origin, and each head must
+// be consistent with the one indexed after it: the tree never shrinks, and a
+// consistency proof built from the hash tiles of the later index ties the
+// two root hashes together. A head that fails these checks is reported as
+// ErrHistoryFork; an index root or checkpoint that cannot be fetched, such
+// as one removed by garbage collection, as ErrHistoryGap.
+//
+// The walk stops after the index written by the latest tlog/migrate: earlier
+// heads are signed by the previous service, under another origin, and can be
+// verified by calling VerifyIndexHistory again from PreviousIndexCID.
+func VerifyIndexHistory(ctx context.Context, latestCID string, fetcher BlobFetcher, origin string, verifier note.Verifier) ([]HistoryHead, error) {
+	var heads []HistoryHead
+	err := indexpersist.WalkHistory(ctx, latestCID, fetcher.FetchBlob, func(ih *indexpersist.IndexHead) error {
+		migrationCID, err := indexpersist.ResolvePath(ctx, ih.RootCID, MigrationPath, fetcher.FetchBlob)
+		if errors.Is(err, indexpersist.ErrPathNotFound) {
+			migrationCID = ""
+		} else if err != nil {
+			return fmt.Errorf("failed to read migration record of index %s: %w", ih.RootCID, err)
+		}
+		if len(heads) > 0 {
+			newer := heads[len(heads)-1]
+			if newer.MigrationCID != "" && newer.MigrationCID != migrationCID {
+				return indexpersist.ErrStopWalk // newer is the first head after a migration
+			}
+		}
+
+		if ih.CheckpointCID == "" {
+			return fmt.Errorf("index %s has no checkpoint", ih.RootCID)
+		}
+		cpRaw, err := fetcher.FetchBlob(ctx, ih.CheckpointCID)
+		if err != nil {
+			return fmt.Errorf("%w: checkpoint %s of index %s: %v", ErrHistoryGap, ih.CheckpointCID, ih.RootCID, err)
+		}
+		cp, _, _, err := log.ParseCheckpoint(cpRaw, origin, verifier)
+		if err != nil {
+			return fmt.Errorf("%w: invalid checkpoint %s in index %s: %v", ErrHistoryFork, ih.CheckpointCID, ih.RootCID, err)
+		}
+
+		head := HistoryHead{
+			IndexCID:         ih.RootCID,
+			PreviousIndexCID: ih.PreviousRootCID,
+			CheckpointCID:    ih.CheckpointCID,
+			TreeSize:         cp.Size,
+			RootHash:         cp.Hash,
+			MigrationCID:     migrationCID,
+		}
+		if len(heads) > 0 {
+			if err := checkConsistentHeads(ctx, head, heads[len(heads)-1], heads[0].IndexCID, fetcher); err != nil {
+				return err
+			}
+		}
+		heads = append(heads, head)
+		return nil
+	})
+	return heads, err
+}
+
+// checkConsistentHeads checks that the tree of older is a prefix of the tree
+// of newer, with a consistency proof built from the hash tiles newer indexes.
+// Garbage collection removes partial tiles once their tile is complete, so a
+// partial tile that cannot be fetched falls back to the full tile, which has
+// the same node prefix, from newer or from the latest index of the walk.
+func checkConsistentHeads(ctx context.Context, older, newer HistoryHead, latestCID string, fetcher BlobFetcher) error {
+	switch {
+	case older.TreeSize > newer.TreeSize:
+		return fmt.Errorf("%w: tree shrinks from %d in index %s to %d in index %s",
+			ErrHistoryFork, older.TreeSize, older.IndexCID, newer.TreeSize, newer.IndexCID)
+	case older.TreeSize == newer.TreeSize:
+		if !bytes.Equal(older.RootHash, newer.RootHash) {
+			return fmt.Errorf("%w: indexes %s and %s have different roots at size %d",
+				ErrHistoryFork, older.IndexCID, newer.IndexCID, newer.TreeSize)
+		}
+		return nil
+	case older.TreeSize == 0:
+		return nil
+	}
+
+	fetchIndexed := func(ctx context.Context, indexCID, path string) ([]byte, error) {
+		tileCID, err := indexpersist.ResolvePath(ctx, indexCID, path, fetcher.FetchBlob)
+		if err != nil {
+			return nil, err
+		}
+		return fetcher.FetchBlob(ctx, tileCID)
+	}
+	fetchTile := func(ctx context.Context, level, idx uint64, p uint8) ([]byte, error) {
+		tile, err := fetchIndexed(ctx, newer.IndexCID, layout.TilePath(level, idx, p))
+		if err == nil || p == 0 {
+			return tile, err
+		}
+		for _, indexCID := range []string{newer.IndexCID, latestCID} {
+			if full, fullErr := fetchIndexed(ctx, indexCID, layout.TilePath(level, idx, 0)); fullErr == nil {
+				return full, nil
+			}
+		}
+		return nil, err
+	}
+
+	pb, err := client.NewProofBuilder(ctx, newer.TreeSize, fetchTile)
+	if err != nil {
+		return fmt.Errorf("failed to create proof builder for index %s: %w", newer.IndexCID, err)
+	}
+	hashes, err := pb.ConsistencyProof(ctx, older.TreeSize, newer.TreeSize)
+	if err != nil {
+		return fmt.Errorf("failed to build consistency proof from index %s: %w", newer.IndexCID, err)
+	}
+	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, older.TreeSize, newer.TreeSize, hashes, older.RootHash, newer.RootHash); err != nil {
+		return fmt.Errorf("%w: index %s at size %d is not consistent with index %s at size %d: %v",
+			ErrHistoryFork, older.IndexCID, older.TreeSize, newer.IndexCID, newer.TreeSize, err)
+	}
+	return nil
+}
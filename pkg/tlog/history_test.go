@@ -0,0 +1,249 @@
+package tlog
+
+import (
+	"context"
+	"crypto/rand"
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage"
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/gc"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/formats/log"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// historyFixture is a log whose index is snapshotted after every append.
+type historyFixture struct {
+	mgr      *Manager
+	blobs    *storacha.FSClient
+	logID    string
+	indexes  []map[string]string
+	verifier note.Verifier
+}
+
+func newHistoryFixture(t *testing.T, ctx context.Context, logID string, sizes ...int) *historyFixture {
+	t.Helper()
+	return newHistoryFixtureWithGC(t, ctx, gcTestConfig, logID, sizes...)
+}
+
+// newHistoryFixtureWithGC is newHistoryFixture for a manager that collects
+// with cfg.
+func newHistoryFixtureWithGC(t *testing.T, ctx context.Context, cfg gc.Config, logID string, sizes ...int) *historyFixture {
+	t.Helper()
+	dlg := storachatest.MockDelegation()
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	mgr, storeManager := newGCTestManager(t, blobs, cfg)
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+
+	f := &historyFixture{mgr: mgr, blobs: blobs, logID: logID}
+	var total uint64
+	for _, n := range sizes {
+		total += uint64(n)
+		appendEntries(t, ctx, mgr, logID, dlg, n, total)
+		f.indexes = append(f.indexes, waitForIndexedCheckpoint(t, ctx, store, blobs, logID, total))
+	}
+
+	key, err := mgr.LogVerifierKey(logID)
+	require.NoError(t, err)
+	f.verifier, err = note.NewVerifier(key)
+	require.NoError(t, err)
+	return f
+}
+
+// waitForIndexedCheckpoint waits until the CID index of a log holds a
+// checkpoint for size, and returns a copy of the index.
+func waitForIndexedCheckpoint(t *testing.T, ctx context.Context, store *sqlite.LogStore, blobs BlobFetcher, logID string, size uint64) map[string]string {
+	t.Helper()
+	var index map[string]string
+	require.Eventually(t, func() bool {
+		var err error
+		index, err = store.GetCIDIndex(ctx, logID)
+		if err != nil || index["checkpoint"] == "" {
+			return false
+		}
+		cpRaw, err := blobs.FetchBlob(ctx, index["checkpoint"])
+		if err != nil {
+			return false
+		}
+		cp, err := ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == size
+	}, 5*time.Second, 50*time.Millisecond)
+	return maps.Clone(index)
+}
+
+// upload uploads index as the next index CAR after previousRoot.
+func (f *historyFixture) upload(t *testing.T, ctx context.Context, b *indexpersist.Builder, index map[string]string, previousRoot string) string {
+	t.Helper()
+	built, err := b.Build(ctx, index, previousRoot)
+	require.NoError(t, err)
+	rootCID, err := f.blobs.UploadCAR(ctx, SpaceDIDForLog(f.logID), built.Data, nil)
+	require.NoError(t, err)
+	require.Equal(t, built.RootCID, rootCID)
+	b.Commit(built)
+	return rootCID
+}
+
+// forgeCheckpoint uploads a checkpoint signed by the log's key for a tree of
+// size with a random root hash.
+func (f *historyFixture) forgeCheckpoint(t *testing.T, ctx context.Context, size uint64) string {
+	t.Helper()
+	hash := make([]byte, 32)
+	_, err := rand.Read(hash)
+	require.NoError(t, err)
+	signer, err := f.mgr.logSigner(f.logID)
+	require.NoError(t, err)
+	cp := log.Checkpoint{Origin: f.mgr.LogOrigin(f.logID), Size: size, Hash: hash}
+	cpRaw, err := note.Sign(&note.Note{Text: string(cp.Marshal())}, signer)
+	require.NoError(t, err)
+	cpCID, err := f.blobs.UploadBlob(ctx, SpaceDIDForLog(f.logID), cpRaw, nil)
+	require.NoError(t, err)
+	return cpCID
+}
+
+func TestVerifyIndexHistory(t *testing.T) {
+	ctx := context.Background()
+	f := newHistoryFixture(t, ctx, "did:key:z6MkHistoryTest", 1, 2, 300)
+
+	b := indexpersist.NewBuilder(0)
+	var roots []string
+	previous := ""
+	for _, index := range f.indexes {
+		previous = f.upload(t, ctx, b, index, previous)
+		roots = append(roots, previous)
+	}
+
+	heads, err := VerifyIndexHistory(ctx, roots[2], f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.NoError(t, err)
+	require.Len(t, heads, 3)
+	for i, size := range []uint64{303, 3, 1} {
+		assert.Equal(t, roots[2-i], heads[i].IndexCID)
+		assert.Equal(t, size, heads[i].TreeSize)
+		assert.Equal(t, f.indexes[2-i]["checkpoint"], heads[i].CheckpointCID)
+	}
+	assert.Equal(t, roots[1], heads[0].PreviousIndexCID)
+	assert.Empty(t, heads[2].PreviousIndexCID)
+
+	// The walk can start anywhere in the history
+	heads, err = VerifyIndexHistory(ctx, roots[1], f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.NoError(t, err)
+	assert.Len(t, heads, 2)
+
+	// Checkpoints must be signed by the log's key
+	_, err = VerifyIndexHistory(ctx, roots[2], f.blobs, "test/logs/other", f.verifier)
+	require.ErrorIs(t, err, ErrHistoryFork)
+}
+
+func TestVerifyIndexHistory_DetectsForks(t *testing.T) {
+	ctx := context.Background()
+	f := newHistoryFixture(t, ctx, "did:key:z6MkHistoryFork", 1, 2)
+
+	// A head whose root is not a prefix of the next head's tree
+	b := indexpersist.NewBuilder(0)
+	first := f.upload(t, ctx, b, f.indexes[0], "")
+	forged := maps.Clone(f.indexes[0])
+	forged["checkpoint"] = f.forgeCheckpoint(t, ctx, 2)
+	forgedRoot := f.upload(t, ctx, b, forged, first)
+	latest := f.upload(t, ctx, b, f.indexes[1], forgedRoot)
+
+	heads, err := VerifyIndexHistory(ctx, latest, f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.ErrorIs(t, err, ErrHistoryFork)
+	require.Len(t, heads, 1)
+	assert.Equal(t, latest, heads[0].IndexCID)
+
+	// Two heads at the same size with different roots
+	b = indexpersist.NewBuilder(0)
+	first = f.upload(t, ctx, b, f.indexes[1], "")
+	forged = maps.Clone(f.indexes[1])
+	forged["checkpoint"] = f.forgeCheckpoint(t, ctx, 3)
+	latest = f.upload(t, ctx, b, forged, first)
+	_, err = VerifyIndexHistory(ctx, latest, f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.ErrorIs(t, err, ErrHistoryFork)
+
+	// A tree that shrinks
+	b = indexpersist.NewBuilder(0)
+	first = f.upload(t, ctx, b, f.indexes[1], "")
+	latest = f.upload(t, ctx, b, f.indexes[0], first)
+	_, err = VerifyIndexHistory(ctx, latest, f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.ErrorIs(t, err, ErrHistoryFork)
+}
+
+func TestVerifyIndexHistory_DetectsGaps(t *testing.T) {
+	ctx := context.Background()
+	f := newHistoryFixture(t, ctx, "did:key:z6MkHistoryGap", 1)
+
+	// Link to an index root that was never uploaded
+	missing := maps.Clone(f.indexes[0])
+	missing["unrelated"] = f.indexes[0]["checkpoint"]
+	built, err := indexpersist.NewBuilder(0).Build(ctx, missing, "")
+	require.NoError(t, err)
+	latest := f.upload(t, ctx, indexpersist.NewBuilder(0), f.indexes[0], built.RootCID)
+
+	heads, err := VerifyIndexHistory(ctx, latest, f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+	require.ErrorIs(t, err, ErrHistoryGap)
+	require.Len(t, heads, 1)
+	assert.Equal(t, built.RootCID, heads[0].PreviousIndexCID)
+}
+
+func TestVerifyIndexHistory_GarbageCollection(t *testing.T) {
+	ctx := context.Background()
+	dlg := storachatest.MockDelegation()
+
+	for _, tc := range []struct {
+		name  string
+		cfg   gc.Config
+		heads int
+	}{
+		// By default every index root and checkpoint is kept
+		{"default keeps the history", gcTestConfig, 3},
+		// Head retention removes the checkpoints of older heads
+		{"head retention truncates it", gc.Config{MaxBundles: 1, MinInterval: gcTestConfig.MinInterval, HeadRetention: 1}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newHistoryFixtureWithGC(t, ctx, tc.cfg, "did:key:z6MkHistoryGC", 1, 2, 300)
+			store, err := f.mgr.storeManager.GetStore(f.logID)
+			require.NoError(t, err)
+
+			// Upload the indexes as the index persistence manager does, so
+			// garbage collection knows their CARs
+			b := indexpersist.NewBuilder(0)
+			latest := ""
+			for _, index := range f.indexes {
+				built, err := b.Build(ctx, index, latest)
+				require.NoError(t, err)
+				latest = f.upload(t, ctx, b, index, latest)
+				require.NoError(t, store.SetHeadIndexCID(ctx, f.logID, built.CheckpointCID, latest))
+				require.NoError(t, store.RecordIndexCAR(ctx, f.logID, storage.IndexCAR{
+					RootCID:       latest,
+					ShardCID:      built.ShardCID,
+					Size:          uint64(len(built.Data)),
+					CheckpointCID: built.CheckpointCID,
+					Shards:        built.Shards,
+				}))
+			}
+
+			_, err = f.mgr.RunGC(ctx, f.logID, dlg)
+			require.NoError(t, err)
+
+			heads, err := VerifyIndexHistory(ctx, latest, f.blobs, f.mgr.LogOrigin(f.logID), f.verifier)
+			require.Len(t, heads, tc.heads)
+			assert.Equal(t, uint64(303), heads[0].TreeSize)
+			if tc.heads == len(f.indexes) {
+				require.NoError(t, err)
+				assert.Empty(t, heads[len(heads)-1].PreviousIndexCID)
+			} else {
+				require.ErrorIs(t, err, ErrHistoryGap)
+			}
+		})
+	}
+}
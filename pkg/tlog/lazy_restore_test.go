@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
 	ed25519signer "github.com/storacha/go-ucanto/principal/ed25519/signer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -145,3 +147,55 @@ func TestManager_GetLogInstance_NotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
+
+// TestLocalStorageRestoreAfterRestart writes a log through the local
+// filesystem backend and reads it back after a restart without network access.
+func TestLocalStorageRestoreAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	logID := "did:key:z6MkLocalStorage"
+
+	storeManager := sqlite.NewStoreManager(tmpDir)
+	defer storeManager.CloseAll()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(tmpDir, "blobs"))
+	require.NoError(t, err)
+	serviceSigner, err := ed25519signer.Generate()
+	require.NoError(t, err)
+
+	newManager := func() *Manager {
+		mgr, err := NewDelegatedManager(DelegatedManagerConfig{
+			BasePath:      tmpDir,
+			Signer:        testSigner(t),
+			OriginPrefix:  "test",
+			ServiceSigner: serviceSigner,
+			CIDStore:      NewStateStoreCIDStore(storeManager.GetStateStore),
+			StoreManager:  storeManager,
+			StorageClient: blobs,
+		})
+		require.NoError(t, err)
+		return mgr
+	}
+
+	dlg := storachatest.MockDelegation()
+	mgr := newManager()
+	require.NoError(t, mgr.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	for i := 0; i < 3; i++ {
+		index, err := mgr.AddEntryWithDelegation(ctx, logID, []byte{byte(i)}, dlg)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), index)
+	}
+	waitForIntegration(t, ctx, mgr, logID, 3)
+
+	checkpoint, err := mgr.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+
+	restarted := newManager()
+	restored, err := restarted.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, restored)
+
+	bundle, err := restarted.ReadEntryBundle(ctx, logID, 0, 3)
+	require.NoError(t, err)
+	assert.NotEmpty(t, bundle)
+}
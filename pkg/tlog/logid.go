@@ -0,0 +1,63 @@
+package tlog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// logNameSeparator separates the space DID from the log name in a named log ID.
+const logNameSeparator = "/"
+
+// maxLogNameLength bounds the length of a log name.
+const maxLogNameLength = 64
+
+// ErrInvalidLogName is returned when a log name cannot be used to address a log.
+var ErrInvalidLogName = errors.New("invalid log name")
+
+// LogID returns the ID of a log in a space. The default log of a space has
+// no name and is identified by the space DID itself; named logs are
+// identified as "{spaceDID}/{name}".
+func LogID(spaceDID, name string) string {
+	if name == "" {
+		return spaceDID
+	}
+	return spaceDID + logNameSeparator + name
+}
+
+// SplitLogID splits a log ID into the space DID that stores the log and the
+// log name. The name is empty for the default log of a space.
+func SplitLogID(logID string) (spaceDID, name string) {
+	spaceDID, name, _ = strings.Cut(logID, logNameSeparator)
+	return spaceDID, name
+}
+
+// SpaceDIDForLog returns the DID of the space storing a log. Revocation logs
+// belong to the space of their main log.
+func SpaceDIDForLog(logID string) string {
+	spaceDID, _ := SplitLogID(strings.TrimSuffix(logID, revocationLogSuffix))
+	return spaceDID
+}
+
+// ValidateLogName checks that name can address a named log. Names are used as
+// directory names on disk and as path segments in HTTP routes, so they are
+// limited to letters, digits, '-' and '_'.
+func ValidateLogName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidLogName)
+	}
+	if len(name) > maxLogNameLength {
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidLogName, maxLogNameLength)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return fmt.Errorf("%w: %q contains %q", ErrInvalidLogName, name, r)
+		}
+	}
+	if strings.HasSuffix(name, revocationLogSuffix) {
+		return fmt.Errorf("%w: name must not end with %q", ErrInvalidLogName, revocationLogSuffix)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package tlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	ed25519signer "github.com/storacha/go-ucanto/principal/ed25519/signer"
+)
+
+func TestLogID(t *testing.T) {
+	const space = "did:key:z6MkSpace"
+
+	require.Equal(t, space, LogID(space, ""))
+	require.Equal(t, space+"/evidence", LogID(space, "evidence"))
+
+	spaceDID, name := SplitLogID(space + "/evidence")
+	require.Equal(t, space, spaceDID)
+	require.Equal(t, "evidence", name)
+
+	spaceDID, name = SplitLogID(space)
+	require.Equal(t, space, spaceDID)
+	require.Empty(t, name)
+
+	require.Equal(t, space, SpaceDIDForLog(space+"/evidence"))
+	require.Equal(t, space, SpaceDIDForLog(space+revocationLogSuffix))
+
+	// Every log in a space shares the space's revocation log
+	require.Equal(t, RevocationLogID(space), RevocationLogID(space+"/evidence"))
+}
+
+func TestValidateLogName(t *testing.T) {
+	for _, name := range []string{"evidence", "audit-2025", "Stream_1"} {
+		require.NoError(t, ValidateLogName(name), name)
+	}
+	for _, name := range []string{"", "a/b", "..", "log.db", "x-revocations", "sp ace", string(make([]byte, maxLogNameLength+1))} {
+		require.ErrorIs(t, ValidateLogName(name), ErrInvalidLogName, name)
+	}
+}
+
+func TestLazyRestoreNamedLog(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	spaceDID := "did:key:z6MkNamedSpace"
+	logID := LogID(spaceDID, "evidence")
+
+	storeManager := sqlite.NewStoreManager(tmpDir)
+	defer storeManager.CloseAll()
+
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateLogRecord(ctx, logID))
+
+	serviceSigner, err := ed25519signer.Generate()
+	require.NoError(t, err)
+	mgr, err := NewDelegatedManager(DelegatedManagerConfig{
+		BasePath:      tmpDir,
+		Signer:        testSigner(t),
+		OriginPrefix:  "test",
+		ServiceSigner: serviceSigner,
+		CIDStore:      NewStateStoreCIDStore(storeManager.GetStateStore),
+		StoreManager:  storeManager,
+	})
+	require.NoError(t, err)
+
+	instance, err := mgr.GetLogInstance(ctx, logID)
+	require.NoError(t, err)
+	require.Equal(t, spaceDID, instance.SpaceDID)
+
+	// The space's default log was never created
+	_, err = mgr.GetLogInstance(ctx, spaceDID)
+	require.Error(t, err)
+}
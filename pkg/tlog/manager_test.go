@@ -4,6 +4,7 @@ package tlog
 import (
 	"context"
 	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -311,6 +312,137 @@ integrated:
 	}
 }
 
+func TestManager_AddEntriesWithDelegation_Contiguous(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkBatchContiguous"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, _ := newGCTestManager(t, blobs, gcTestConfig)
+	if err := manager.CreateLogWithDelegation(ctx, logID, logID, dlg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Submit batches and single appends concurrently and verify no batch is
+	// interleaved with other entries
+	const numBatches = 4
+	const batchSize = 3
+	const numSingles = 8
+	results := make([][]uint64, numBatches)
+	errs := make([]error, numBatches)
+	singles := make([]uint64, numSingles)
+	singleErrs := make([]error, numSingles)
+	var wg sync.WaitGroup
+	for b := 0; b < numBatches; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			entries := make([][]byte, batchSize)
+			for i := range entries {
+				entries[i] = []byte(fmt.Sprintf("batch %d entry %d", b, i))
+			}
+			results[b], errs[b] = manager.AddEntriesWithDelegation(ctx, logID, entries, dlg)
+		}(b)
+	}
+	for s := 0; s < numSingles; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			singles[s], singleErrs[s] = manager.AddEntryWithDelegation(ctx, logID, []byte(fmt.Sprintf("single %d", s)), dlg)
+		}(s)
+	}
+	wg.Wait()
+
+	seen := make(map[uint64]bool)
+	for s, err := range singleErrs {
+		if err != nil {
+			t.Fatalf("single append %d failed: %v", s, err)
+		}
+		seen[singles[s]] = true
+	}
+	for b := 0; b < numBatches; b++ {
+		if errs[b] != nil {
+			t.Fatalf("batch %d failed: %v", b, errs[b])
+		}
+		for i, idx := range results[b] {
+			if idx != results[b][0]+uint64(i) {
+				t.Errorf("batch %d entry %d got index %d, want %d", b, i, idx, results[b][0]+uint64(i))
+			}
+			if seen[idx] {
+				t.Errorf("index %d assigned twice", idx)
+			}
+			seen[idx] = true
+		}
+	}
+	if want := numBatches*batchSize + numSingles; len(seen) != want {
+		t.Errorf("got %d distinct indices, want %d", len(seen), want)
+	}
+}
+
+func TestManager_AddEntriesWithDelegation_AfterShutdown(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkBatchShutdown"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, _ := newGCTestManager(t, blobs, gcTestConfig)
+	if err := manager.CreateLogWithDelegation(ctx, logID, logID, dlg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.AddEntriesWithDelegation(ctx, logID, [][]byte{[]byte("a"), []byte("b")}, dlg); err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := manager.GetLogInstance(ctx, logID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := instance.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Batches bypass the appender's Add, but must be refused like it
+	_, err = manager.AddEntriesWithDelegation(ctx, logID, [][]byte{[]byte("c")}, dlg)
+	if !errors.Is(err, storacha.ErrShutDown) {
+		t.Errorf("expected ErrShutDown for a batch after shutdown, got %v", err)
+	}
+	if _, err := manager.AddEntryWithDelegation(ctx, logID, []byte("d"), dlg); err == nil {
+		t.Error("expected error for an append after shutdown")
+	}
+}
+
+func TestManager_AddEntriesWithDelegation_Validation(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tlog-batch-validate-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manager := testManager(t, tmpDir)
+	ctx := context.Background()
+
+	if _, err := manager.AddEntriesWithDelegation(ctx, "test-log", nil, storachatest.MockDelegation()); err == nil {
+		t.Error("expected error for empty batch")
+	}
+
+	// testManager has no client pool, so delegated writes are rejected
+	_, err = manager.AddEntriesWithDelegation(ctx, "test-log", [][]byte{[]byte("a")}, storachatest.MockDelegation())
+	if err == nil {
+		t.Error("expected error when delegated storage is not configured")
+	}
+
+	_, err = manager.AddEntriesWithDelegation(ctx, "test-log", make([][]byte, MaxBatchEntries+1), storachatest.MockDelegation())
+	if !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("expected ErrBatchTooLarge for an oversized batch, got %v", err)
+	}
+}
+
 func TestManager_LargeLog(t *testing.T) {
 	// Skip: mock storage doesn't support the tile lookups needed for 256+ entries
 	// This test requires proper tile persistence across bundle boundaries
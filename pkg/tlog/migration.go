@@ -0,0 +1,395 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+)
+
+// migrationType is the second line of a migration record note.
+const migrationType = "log-migration"
+
+// MigrationPath is the index CAR path of a log's latest migration record.
+const MigrationPath = "migration"
+
+// Migration records the handoff of a log from one service to another. The new
+// service signs it with its key for the log, binding the previous operator's
+// final checkpoint to the first checkpoint under the new origin. Both
+// checkpoints commit to the same tree, so a verifier holding the previous
+// verifier key can follow the log across operators.
+//
+// Migration records are serialised as signed notes (c2sp.org/signed-note)
+// with the body
+//
+//	<origin>
+//	log-migration
+//	<previous verifier key>
+//	<previous checkpoint CID>
+//	<checkpoint CID>
+//	<tree size>
+//	<base64 root hash>
+//	<previous migration record CID, or "-">
+//	<RFC 3339 time>
+//
+// The record is stored in the log's space and linked from the index CAR under
+// MigrationPath. A log migrated more than once links to its earlier records,
+// so the chain leads back to the log's first operator.
+type Migration struct {
+	Origin             string
+	PreviousKey        string // Verifier key of the previous operator's checkpoints
+	PreviousCheckpoint string // CID of the previous operator's final checkpoint
+	Checkpoint         string // CID of the new operator's first checkpoint
+	TreeSize           uint64
+	RootHash           []byte
+	PreviousMigration  string // CID of the previous migration record, if any
+	Timestamp          time.Time
+}
+
+// PreviousOrigin returns the origin of the log at the previous operator.
+func (r Migration) PreviousOrigin() (string, error) {
+	verifier, err := note.NewVerifier(r.PreviousKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid previous verifier key: %w", err)
+	}
+	return verifier.Name(), nil
+}
+
+// Marshal returns the note body of the migration record.
+func (r Migration) Marshal() []byte {
+	prev := r.PreviousMigration
+	if prev == "" {
+		prev = "-"
+	}
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%d\n%s\n%s\n%s\n",
+		r.Origin,
+		migrationType,
+		r.PreviousKey,
+		r.PreviousCheckpoint,
+		r.Checkpoint,
+		r.TreeSize,
+		base64.StdEncoding.EncodeToString(r.RootHash),
+		prev,
+		r.Timestamp.UTC().Format(time.RFC3339)))
+}
+
+// Sign returns the migration record as a note signed by signer.
+func (r Migration) Sign(signer Signer) ([]byte, error) {
+	signed, err := note.Sign(&note.Note{Text: string(r.Marshal())}, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign migration record: %w", err)
+	}
+	return signed, nil
+}
+
+// ParseMigration parses the body of a migration record note. Signatures are
+// not verified.
+func ParseMigration(body []byte) (*Migration, error) {
+	lines := strings.Split(string(body), "\n")
+	if len(lines) != 10 || lines[9] != "" {
+		return nil, fmt.Errorf("malformed migration record: want 9 lines")
+	}
+	if lines[1] != migrationType {
+		return nil, fmt.Errorf("malformed migration record: unexpected type %q", lines[1])
+	}
+	size, err := strconv.ParseUint(lines[5], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed migration tree size: %w", err)
+	}
+	root, err := base64.StdEncoding.DecodeString(lines[6])
+	if err != nil || len(root) != rfc6962.DefaultHasher.Size() {
+		return nil, fmt.Errorf("malformed migration root hash")
+	}
+	ts, err := time.Parse(time.RFC3339, lines[8])
+	if err != nil {
+		return nil, fmt.Errorf("malformed migration time: %w", err)
+	}
+	prev := lines[7]
+	if prev == "-" {
+		prev = ""
+	}
+	return &Migration{
+		Origin:             lines[0],
+		PreviousKey:        lines[2],
+		PreviousCheckpoint: lines[3],
+		Checkpoint:         lines[4],
+		TreeSize:           size,
+		RootHash:           root,
+		PreviousMigration:  prev,
+		Timestamp:          ts,
+	}, nil
+}
+
+// VerifyMigration checks the signature of a migration record note against
+// verifierKey, the new operator's verifier key for the log, and returns the
+// parsed record. The linked checkpoints are not fetched: verifiers check the
+// previous checkpoint against PreviousKey and both against TreeSize and
+// RootHash themselves.
+func VerifyMigration(signed []byte, verifierKey string) (*Migration, error) {
+	verifier, err := note.NewVerifier(verifierKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier key: %w", err)
+	}
+	n, err := note.Open(signed, note.VerifierList(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify migration record: %w", err)
+	}
+	r, err := ParseMigration([]byte(n.Text))
+	if err != nil {
+		return nil, err
+	}
+	if r.Origin != verifier.Name() {
+		return nil, fmt.Errorf("migration origin %q does not match key name %q", r.Origin, verifier.Name())
+	}
+	return r, nil
+}
+
+// MigrateLogResult describes a log taken over from another service.
+type MigrateLogResult struct {
+	LogID              string
+	IndexCID           string // Root CID of the index CAR written after the migration
+	TreeSize           uint64
+	Root               []byte
+	PreviousOrigin     string
+	PreviousCheckpoint string // CID of the previous operator's final checkpoint
+	Checkpoint         string // CID of the checkpoint signed by this service
+	MigrationCID       string // CID of the signed migration record
+	Migration          []byte // Signed migration record note
+
+	// RevocationLog is the handoff of the space's revocation log, nil if it
+	// already existed on this service.
+	RevocationLog *MigrateLogResult
+}
+
+// MigrateLog takes over a log from another ucanlog service. Like RestoreLog it
+// imports the log's state from the index CAR in its space, but the index's
+// checkpoint is verified against previousKey, the signed-note verifier key of
+// the previous operator, rather than this service's key.
+//
+// The log is then re-signed under this service's origin: a checkpoint for the
+// same tree and a migration record linking it to the previous checkpoint are
+// uploaded to the space, and a new index CAR pointing at both is written.
+// The previous operator should stop appending before the handoff; entries it
+// integrates later are not part of the migrated log.
+//
+// Unless the space's revocation log exists on this service, it is handed off
+// first in the same way from the index CAR at revocationIndexCID, verified
+// with previousKey under the revocation log's origin, and the revocations
+// table is rebuilt from it before the log is written. authorize, if not nil,
+// is then called and the migration is refused if it returns an error.
+func (m *Manager) MigrateLog(ctx context.Context, logID, indexCID, revocationIndexCID, previousKey string, dlg delegation.Delegation, authorize AuthorizeFunc) (*MigrateLogResult, error) {
+	if m.privateKey == nil {
+		return nil, fmt.Errorf("migration requires the log signing key")
+	}
+	if IsRevocationLog(logID) {
+		return nil, fmt.Errorf("%s is a revocation log, migrate its main log instead", logID)
+	}
+	prevVerifier, err := note.NewVerifier(previousKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous verifier key: %w", err)
+	}
+	if prevVerifier.Name() == m.LogOrigin(logID) {
+		return nil, fmt.Errorf("previous origin %q is this service's origin for the log; configure a distinct TLOG_ORIGIN_PREFIX", prevVerifier.Name())
+	}
+
+	st, err := m.readIndexedLog(ctx, logID, indexCID, dlg, func(cpRaw []byte) (*log.Checkpoint, error) {
+		return parsePreviousCheckpoint(cpRaw, prevVerifier)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revocations, err := m.migrateRevocationLog(ctx, RevocationLogID(logID), revocationIndexCID, logID, previousKey, dlg)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.CheckRevocations(ctx, logID); err != nil {
+		return nil, fmt.Errorf("failed to rebuild revocations: %w", err)
+	}
+	if authorize != nil {
+		if err := authorize(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := m.handOffIndexedLog(ctx, logID, previousKey, st, dlg)
+	if err != nil {
+		return nil, err
+	}
+	res.RevocationLog = revocations
+	return res, nil
+}
+
+// migrateRevocationLog hands off a space's revocation log from the index CAR
+// at indexCID unless it exists locally. The previous operator's key for the
+// revocation log is derived from previousKey, its key for logID. Returns nil
+// if nothing was migrated. The space's revocations are reloaded on their next
+// check.
+func (m *Manager) migrateRevocationLog(ctx context.Context, revocationLogID, indexCID, logID, previousKey string, dlg delegation.Delegation) (*MigrateLogResult, error) {
+	store, err := m.storeManager.GetStore(revocationLogID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state store: %w", err)
+	}
+	if _, err := store.GetLogRecord(ctx, revocationLogID); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, sqlite.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get revocation log record: %w", err)
+	}
+	if indexCID == "" {
+		return nil, fmt.Errorf("revocation log %s does not exist locally, the index CID of its latest index CAR is required", revocationLogID)
+	}
+
+	previousKey, err = renameVerifierKey(previousKey, logID, revocationLogID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive previous key of revocation log: %w", err)
+	}
+	prevVerifier, err := note.NewVerifier(previousKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous verifier key: %w", err)
+	}
+
+	st, err := m.readIndexedLog(ctx, revocationLogID, indexCID, dlg, func(cpRaw []byte) (*log.Checkpoint, error) {
+		return parsePreviousCheckpoint(cpRaw, prevVerifier)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revocation log: %w", err)
+	}
+	res, err := m.handOffIndexedLog(ctx, revocationLogID, previousKey, st, dlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate revocation log: %w", err)
+	}
+
+	m.revocationsMu.Lock()
+	delete(m.revocationsLoaded, SpaceDIDForLog(revocationLogID))
+	m.revocationsMu.Unlock()
+	return res, nil
+}
+
+// handOffIndexedLog re-signs a log read with readIndexedLog under this
+// service's origin, uploads the checkpoint, migration record and index CAR
+// of the handoff to its space, and writes the log's local state.
+func (m *Manager) handOffIndexedLog(ctx context.Context, logID, previousKey string, st *indexedLog, dlg delegation.Delegation) (*MigrateLogResult, error) {
+	origin := m.LogOrigin(logID)
+	prevOrigin := st.checkpoint.Origin
+	signer, err := m.logSigner(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer for %s: %w", logID, err)
+	}
+	if dlg != nil {
+		ctx = storacha.WithDelegation(ctx, dlg)
+	}
+	spaceDID := SpaceDIDForLog(logID)
+
+	cp := log.Checkpoint{Origin: origin, Size: st.checkpoint.Size, Hash: st.checkpoint.Hash}
+	cpRaw, err := note.Sign(&note.Note{Text: string(cp.Marshal())}, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+	cpCID, err := st.client.UploadBlob(ctx, spaceDID, cpRaw, dlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload checkpoint: %w", err)
+	}
+
+	rec := Migration{
+		Origin:             origin,
+		PreviousKey:        previousKey,
+		PreviousCheckpoint: st.checkpointCID,
+		Checkpoint:         cpCID,
+		TreeSize:           cp.Size,
+		RootHash:           cp.Hash,
+		PreviousMigration:  st.index[MigrationPath],
+		Timestamp:          time.Now().UTC().Truncate(time.Second),
+	}
+	signed, err := rec.Sign(signer)
+	if err != nil {
+		return nil, err
+	}
+	recCID, err := st.client.UploadBlob(ctx, spaceDID, signed, dlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload migration record: %w", err)
+	}
+
+	prevCheckpoint := st.checkpointCID
+	st.index[layout.CheckpointPath] = cpCID
+	st.index[MigrationPath] = recCID
+	// The new index links to the previous service's, so the head history
+	// continues across the migration
+	built, err := indexpersist.NewBuilder(0).Build(ctx, st.index, st.indexCID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build index CAR: %w", err)
+	}
+	newIndexCID, err := st.client.UploadCAR(ctx, spaceDID, built.Data, dlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload index CAR: %w", err)
+	}
+	st.indexCID = newIndexCID
+	st.checkpointCID = cpCID
+	st.checkpointRaw = cpRaw
+	st.checkpoint = &cp
+
+	if err := m.writeIndexedLog(ctx, logID, st); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("migrated log", "logID", logID, "previousOrigin", prevOrigin, "origin", origin,
+		"treeSize", cp.Size, "migrationCID", recCID, "indexCID", newIndexCID)
+	return &MigrateLogResult{
+		LogID:              logID,
+		IndexCID:           newIndexCID,
+		TreeSize:           cp.Size,
+		Root:               cp.Hash,
+		PreviousOrigin:     prevOrigin,
+		PreviousCheckpoint: prevCheckpoint,
+		Checkpoint:         cpCID,
+		MigrationCID:       recCID,
+		Migration:          signed,
+	}, nil
+}
+
+// parsePreviousCheckpoint verifies a checkpoint of the previous operator,
+// whose origin is the name of its verifier key.
+func parsePreviousCheckpoint(cpRaw []byte, verifier note.Verifier) (*log.Checkpoint, error) {
+	cp, _, _, err := log.ParseCheckpoint(cpRaw, verifier.Name(), verifier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// renameVerifierKey returns the Ed25519 verifier key for the same public key
+// as vkey, the previous operator's key for logID, named after its origin for
+// otherLogID. Services name a log's key after the log's origin,
+// "<prefix>/logs/<logID>", so only the log ID part of the name changes.
+func renameVerifierKey(vkey, logID, otherLogID string) (string, error) {
+	name, rest, ok := strings.Cut(vkey, "+")
+	if !ok {
+		return "", fmt.Errorf("malformed verifier key")
+	}
+	prefix, ok := strings.CutSuffix(name, "/logs/"+logID)
+	if !ok {
+		return "", fmt.Errorf("verifier key name %q is not an origin of log %s", name, logID)
+	}
+	_, key, ok := strings.Cut(rest, "+")
+	if !ok {
+		return "", fmt.Errorf("malformed verifier key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 1+ed25519.PublicKeySize || raw[0] != 1 {
+		return "", fmt.Errorf("verifier key is not an Ed25519 key")
+	}
+	return VerifierKey(prefix+"/logs/"+otherLogID, ed25519.PublicKey(raw[1:]))
+}
@@ -0,0 +1,210 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/relves/ucanlog/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/formats/log"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestManager_MigrateLog(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkMigrateTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, keyA, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, keyB, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// Service A writes the log and persists its index
+	serviceA, storesA := newServiceTestManager(t, keyA, "service-a", blobs)
+	require.NoError(t, serviceA.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	for i := 0; i < 3; i++ {
+		_, err := serviceA.AddEntryWithDelegation(ctx, logID, []byte{byte(i)}, dlg)
+		require.NoError(t, err)
+	}
+	waitForIntegration(t, ctx, serviceA, logID, 3)
+	require.Eventually(t, func() bool {
+		cpRaw, err := serviceA.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			return false
+		}
+		cp, err := ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == 3
+	}, 5*time.Second, 50*time.Millisecond)
+	indexCID := uploadIndexCAR(t, ctx, storesA, blobs, logID)
+	keyAVerifier, err := serviceA.LogVerifierKey(logID)
+	require.NoError(t, err)
+
+	// A's revocation log holds a revocation
+	revocationLogID := RevocationLogID(logID)
+	require.NoError(t, serviceA.CreateLogWithDelegation(ctx, revocationLogID, logID, dlg))
+	revocation, err := (&types.RevocationEntry{Type: types.RevokeUCAN, Target: []byte("bafyRevoked"), Timestamp: time.Now()}).Serialize()
+	require.NoError(t, err)
+	_, err = serviceA.AddEntryWithDelegation(ctx, revocationLogID, revocation, dlg)
+	require.NoError(t, err)
+	storeA, err := storesA.GetStore(logID)
+	require.NoError(t, err)
+	waitForIndexedCheckpoint(t, ctx, storeA, blobs, revocationLogID, 1)
+	revocationIndexCID := uploadIndexCAR(t, ctx, storesA, blobs, revocationLogID)
+
+	// Service B cannot take over under A's origin
+	sameOrigin, _ := newServiceTestManager(t, keyB, "service-a", blobs)
+	_, err = sameOrigin.MigrateLog(ctx, logID, indexCID, revocationIndexCID, keyAVerifier, dlg, nil)
+	require.ErrorContains(t, err, "distinct TLOG_ORIGIN_PREFIX")
+
+	// Service B cannot take over the log without its revocations
+	serviceB, storesB := newServiceTestManager(t, keyB, "service-b", blobs)
+	_, err = serviceB.MigrateLog(ctx, logID, indexCID, "", keyAVerifier, dlg, nil)
+	require.ErrorContains(t, err, "revocation log")
+
+	// The request is authorized against the migrated revocations, and the
+	// log is not handed off if it is refused
+	refusing, refusingStores := newServiceTestManager(t, keyB, "service-c", blobs)
+	refusingStore, err := refusingStores.GetStore(logID)
+	require.NoError(t, err)
+	refused := errors.New("delegation revoked")
+	_, err = refusing.MigrateLog(ctx, logID, indexCID, revocationIndexCID, keyAVerifier, dlg, func(ctx context.Context) error {
+		revoked, err := refusingStore.IsRevoked(ctx, "bafyRevoked")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		return refused
+	})
+	require.ErrorIs(t, err, refused)
+	_, err = refusingStore.GetLogRecord(ctx, logID)
+	require.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	// Service B takes over
+	res, err := serviceB.MigrateLog(ctx, logID, indexCID, revocationIndexCID, keyAVerifier, dlg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), res.TreeSize)
+	assert.Equal(t, "service-a/logs/"+logID, res.PreviousOrigin)
+	assert.NotEqual(t, indexCID, res.IndexCID)
+
+	// The revocation log is handed off with its own signed record, verified
+	// with A's key for the revocation log, and its revocations are loaded
+	require.NotNil(t, res.RevocationLog)
+	assert.Equal(t, "service-a/logs/"+revocationLogID, res.RevocationLog.PreviousOrigin)
+	revocationKeyB, err := serviceB.LogVerifierKey(revocationLogID)
+	require.NoError(t, err)
+	revocationRec, err := VerifyMigration(res.RevocationLog.Migration, revocationKeyB)
+	require.NoError(t, err)
+	revocationKeyA, err := serviceA.LogVerifierKey(revocationLogID)
+	require.NoError(t, err)
+	assert.Equal(t, revocationKeyA, revocationRec.PreviousKey)
+	assert.Equal(t, uint64(1), revocationRec.TreeSize)
+	storeB, err := storesB.GetStore(logID)
+	require.NoError(t, err)
+	revoked, err := storeB.IsRevoked(ctx, "bafyRevoked")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revocationCP, err := serviceB.ReadCheckpoint(ctx, revocationLogID)
+	require.NoError(t, err)
+	_, err = serviceB.verifyCheckpoint(revocationLogID, revocationCP)
+	require.NoError(t, err)
+
+	// The migration record verifies with B's key and links both checkpoints
+	keyBVerifier, err := serviceB.LogVerifierKey(logID)
+	require.NoError(t, err)
+	rec, err := VerifyMigration(res.Migration, keyBVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, keyAVerifier, rec.PreviousKey)
+	assert.Equal(t, res.PreviousCheckpoint, rec.PreviousCheckpoint)
+	assert.Equal(t, res.Checkpoint, rec.Checkpoint)
+	assert.Equal(t, res.Root, rec.RootHash)
+	assert.Empty(t, rec.PreviousMigration)
+	_, err = VerifyMigration(res.Migration, keyAVerifier)
+	require.Error(t, err)
+
+	stored, err := blobs.FetchBlob(ctx, res.MigrationCID)
+	require.NoError(t, err)
+	assert.Equal(t, res.Migration, stored)
+
+	// A's final checkpoint verifies with A's key and commits to the same tree
+	prevRaw, err := blobs.FetchBlob(ctx, rec.PreviousCheckpoint)
+	require.NoError(t, err)
+	verifierA, err := note.NewVerifier(keyAVerifier)
+	require.NoError(t, err)
+	prevCP, _, _, err := log.ParseCheckpoint(prevRaw, res.PreviousOrigin, verifierA)
+	require.NoError(t, err)
+	assert.Equal(t, rec.TreeSize, prevCP.Size)
+	assert.Equal(t, rec.RootHash, prevCP.Hash)
+
+	// B's index history links back to A's, where verification switches keys
+	verifierB, err := note.NewVerifier(keyBVerifier)
+	require.NoError(t, err)
+	heads, err := VerifyIndexHistory(ctx, res.IndexCID, blobs, serviceB.LogOrigin(logID), verifierB)
+	require.NoError(t, err)
+	require.Len(t, heads, 1)
+	assert.Equal(t, res.MigrationCID, heads[0].MigrationCID)
+	assert.Equal(t, indexCID, heads[0].PreviousIndexCID)
+	heads, err = VerifyIndexHistory(ctx, heads[0].PreviousIndexCID, blobs, res.PreviousOrigin, verifierA)
+	require.NoError(t, err)
+	require.Len(t, heads, 1)
+	assert.Equal(t, uint64(3), heads[0].TreeSize)
+
+	// B now serves the log under its own origin
+	cpRaw, err := serviceB.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+	cp, _, _, err := log.ParseCheckpoint(cpRaw, serviceB.LogOrigin(logID), verifierB)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), cp.Size)
+
+	entries, err := serviceB.ReadRange(ctx, logID, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, entries[2])
+
+	index, err := serviceB.AddEntryWithDelegation(ctx, logID, []byte{3}, dlg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), index)
+	waitForIntegration(t, ctx, serviceB, logID, 4)
+
+	// Migrations are not applied over an existing log
+	_, err = serviceB.MigrateLog(ctx, logID, indexCID, revocationIndexCID, keyAVerifier, dlg, nil)
+	require.ErrorIs(t, err, ErrLogExists)
+}
+
+func TestMigration_RoundTrip(t *testing.T) {
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signer, err := NewEd25519Signer(privKey, "service-b/logs/test")
+	require.NoError(t, err)
+	vkey, err := signer.VerifierKey()
+	require.NoError(t, err)
+
+	rec := Migration{
+		Origin:             "service-b/logs/test",
+		PreviousKey:        "service-a/logs/test+1234abcd+AQ==",
+		PreviousCheckpoint: "bafkprev",
+		Checkpoint:         "bafknew",
+		TreeSize:           7,
+		RootHash:           make([]byte, 32),
+		PreviousMigration:  "bafkolder",
+		Timestamp:          time.Now().UTC().Truncate(time.Second),
+	}
+	signed, err := rec.Sign(signer)
+	require.NoError(t, err)
+
+	got, err := VerifyMigration(signed, vkey)
+	require.NoError(t, err)
+	assert.Equal(t, rec, *got)
+
+	tampered := []byte(string(signed))
+	tampered[len("service-b/logs/test\nlog-migration\n")] ^= 1
+	_, err = VerifyMigration(tampered, vkey)
+	require.Error(t, err)
+}
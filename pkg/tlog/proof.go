@@ -0,0 +1,168 @@
+package tlog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/client"
+)
+
+// ParseCheckpoint parses the body of a signed checkpoint note.
+// Signatures are not verified.
+func ParseCheckpoint(raw []byte) (*log.Checkpoint, error) {
+	cp := &log.Checkpoint{}
+	if _, err := cp.Unmarshal(raw); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// InclusionProof computes the Merkle inclusion proof for the leaf at index in
+// the tree of the given size. Node hashes are read from the log's hash tiles.
+func (m *Manager) InclusionProof(ctx context.Context, logID string, index, treeSize uint64) ([][]byte, error) {
+	if index >= treeSize {
+		return nil, fmt.Errorf("index %d is outside tree of size %d", index, treeSize)
+	}
+
+	fetcher, err := m.proofTileFetcher(ctx, logID, treeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pb, err := client.NewProofBuilder(ctx, treeSize, fetcher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof builder: %w", err)
+	}
+
+	proof, err := pb.InclusionProof(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inclusion proof: %w", err)
+	}
+	return proof, nil
+}
+
+// ConsistencyProof computes the Merkle consistency proof between two tree
+// sizes of a log, showing that the tree of size from is a prefix of the tree
+// of size to. Node hashes are read from the log's hash tiles.
+func (m *Manager) ConsistencyProof(ctx context.Context, logID string, from, to uint64) ([][]byte, error) {
+	if from > to {
+		return nil, fmt.Errorf("from size %d is larger than to size %d", from, to)
+	}
+
+	fetcher, err := m.proofTileFetcher(ctx, logID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	pb, err := client.NewProofBuilder(ctx, to, fetcher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof builder: %w", err)
+	}
+
+	proof, err := pb.ConsistencyProof(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build consistency proof: %w", err)
+	}
+	return proof, nil
+}
+
+// RootHashAt computes the Merkle root hash of the first treeSize leaves of a log.
+// This allows proofs to be checked against tree sizes older than the latest checkpoint.
+func (m *Manager) RootHashAt(ctx context.Context, logID string, treeSize uint64) ([]byte, error) {
+	if treeSize == 0 {
+		return rfc6962.DefaultHasher.EmptyRoot(), nil
+	}
+
+	fetcher, err := m.proofTileFetcher(ctx, logID, treeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := client.FetchRangeNodes(ctx, treeSize, fetcher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch range nodes: %w", err)
+	}
+
+	rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	r, err := rf.NewRange(0, treeSize, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build compact range: %w", err)
+	}
+	return r.GetRootHash(nil)
+}
+
+// ReadEntry reads the entry at index from the log.
+// Only the bundle containing the entry is fetched.
+func (m *Manager) ReadEntry(ctx context.Context, logID string, index uint64) ([]byte, error) {
+	reader, err := m.GetReader(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	logSize, err := reader.IntegratedSize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log size: %w", err)
+	}
+	if index >= logSize {
+		return nil, fmt.Errorf("index %d is outside log of size %d", index, logSize)
+	}
+
+	bundleIdx := index / entriesPerBundle
+	p := layout.PartialTileSize(0, bundleIdx, logSize)
+	bundle, err := reader.ReadEntryBundle(ctx, bundleIdx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %d: %w", bundleIdx, err)
+	}
+
+	entries, err := parseBundleEntries(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %d: %w", bundleIdx, err)
+	}
+
+	offset := index % entriesPerBundle
+	if offset >= uint64(len(entries)) {
+		return nil, fmt.Errorf("entry %d missing from bundle %d", index, bundleIdx)
+	}
+	return entries[offset], nil
+}
+
+// proofTileFetcher returns a tile fetcher for building proofs at treeSize.
+//
+// Partial tiles for a historical tree size may have been superseded (and
+// garbage collected) once the tile grew. Hash tiles only ever append nodes, so
+// when the requested partial tile is missing the full tile, or the partial
+// tile for the current log size, contains the same prefix of node hashes.
+func (m *Manager) proofTileFetcher(ctx context.Context, logID string, treeSize uint64) (client.TileFetcherFunc, error) {
+	reader, err := m.GetReader(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	logSize, err := reader.IntegratedSize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log size: %w", err)
+	}
+	if treeSize > logSize {
+		return nil, fmt.Errorf("tree size %d exceeds log size %d", treeSize, logSize)
+	}
+
+	return func(ctx context.Context, level, index uint64, p uint8) ([]byte, error) {
+		tile, err := reader.ReadTile(ctx, level, index, p)
+		if err == nil || p == 0 {
+			return tile, err
+		}
+		if full, fullErr := reader.ReadTile(ctx, level, index, 0); fullErr == nil {
+			return full, nil
+		}
+		if current := layout.PartialTileSize(level, index, logSize); current != p {
+			if partial, partialErr := reader.ReadTile(ctx, level, index, current); partialErr == nil {
+				return partial, nil
+			}
+		}
+		return nil, err
+	}, nil
+}
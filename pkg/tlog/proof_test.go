@@ -0,0 +1,135 @@
+package tlog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+)
+
+// waitForIntegration blocks until the log has integrated at least size entries.
+func waitForIntegration(t *testing.T, ctx context.Context, manager *Manager, logID string, size uint64) {
+	t.Helper()
+	reader, err := manager.GetReader(ctx, logID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		integrated, err := reader.IntegratedSize(ctx)
+		return err == nil && integrated >= size
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestManager_InclusionProof(t *testing.T) {
+	manager := testManager(t, t.TempDir())
+
+	ctx := storacha.WithDelegation(context.Background(), storachatest.MockDelegation())
+	logID := "test-log-proof"
+	require.NoError(t, manager.CreateLog(ctx, logID))
+
+	entries := make([][]byte, 7)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d", i))
+	}
+	_, err := manager.addEntriesBatch(ctx, logID, entries)
+	require.NoError(t, err)
+	waitForIntegration(t, ctx, manager, logID, uint64(len(entries)))
+
+	// The recomputed root must match the signed checkpoint
+	cpRaw, err := manager.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+	cp, err := ParseCheckpoint(cpRaw)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(entries)), cp.Size)
+	cpRoot, err := manager.RootHashAt(ctx, logID, cp.Size)
+	require.NoError(t, err)
+	require.Equal(t, cp.Hash, cpRoot)
+
+	// Prove every entry against the full tree and against an older tree size
+	for _, treeSize := range []uint64{uint64(len(entries)), 4} {
+		root, err := manager.RootHashAt(ctx, logID, treeSize)
+		require.NoError(t, err)
+
+		for index := uint64(0); index < treeSize; index++ {
+			hashes, err := manager.InclusionProof(ctx, logID, index, treeSize)
+			require.NoError(t, err)
+
+			data, err := manager.ReadEntry(ctx, logID, index)
+			require.NoError(t, err)
+			require.Equal(t, entries[index], data)
+
+			leafHash := rfc6962.DefaultHasher.HashLeaf(data)
+			require.NoError(t, proof.VerifyInclusion(rfc6962.DefaultHasher, index, treeSize, leafHash, hashes, root),
+				"index %d size %d", index, treeSize)
+		}
+	}
+
+	t.Run("index outside tree is rejected", func(t *testing.T) {
+		_, err := manager.InclusionProof(ctx, logID, 4, 4)
+		require.Error(t, err)
+	})
+
+	t.Run("tree size beyond log is rejected", func(t *testing.T) {
+		_, err := manager.InclusionProof(ctx, logID, 0, uint64(len(entries))+1)
+		require.Error(t, err)
+	})
+}
+
+func TestManager_ConsistencyProof(t *testing.T) {
+	manager := testManager(t, t.TempDir())
+
+	ctx := storacha.WithDelegation(context.Background(), storachatest.MockDelegation())
+	logID := "test-log-consistency"
+	require.NoError(t, manager.CreateLog(ctx, logID))
+
+	entries := make([][]byte, 9)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d", i))
+	}
+	_, err := manager.addEntriesBatch(ctx, logID, entries)
+	require.NoError(t, err)
+	waitForIntegration(t, ctx, manager, logID, uint64(len(entries)))
+
+	to := uint64(len(entries))
+	toRoot, err := manager.RootHashAt(ctx, logID, to)
+	require.NoError(t, err)
+
+	for from := uint64(1); from <= to; from++ {
+		fromRoot, err := manager.RootHashAt(ctx, logID, from)
+		require.NoError(t, err)
+
+		hashes, err := manager.ConsistencyProof(ctx, logID, from, to)
+		require.NoError(t, err)
+		require.NoError(t, proof.VerifyConsistency(rfc6962.DefaultHasher, from, to, hashes, fromRoot, toRoot),
+			"from %d to %d", from, to)
+	}
+
+	t.Run("rewritten history does not verify", func(t *testing.T) {
+		hashes, err := manager.ConsistencyProof(ctx, logID, 3, to)
+		require.NoError(t, err)
+		forged := rfc6962.DefaultHasher.HashLeaf([]byte("forged"))
+		require.Error(t, proof.VerifyConsistency(rfc6962.DefaultHasher, 3, to, hashes, forged, toRoot))
+	})
+
+	t.Run("from larger than to is rejected", func(t *testing.T) {
+		_, err := manager.ConsistencyProof(ctx, logID, 5, 4)
+		require.Error(t, err)
+	})
+}
+
+func TestParseCheckpoint(t *testing.T) {
+	cp, err := ParseCheckpoint([]byte("ucanlog/logs/test\n3\nAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n\n— ucanlog/logs/test abc\n"))
+	require.NoError(t, err)
+	require.Equal(t, "ucanlog/logs/test", cp.Origin)
+	require.Equal(t, uint64(3), cp.Size)
+	require.Len(t, cp.Hash, 32)
+
+	_, err = ParseCheckpoint([]byte("garbage"))
+	require.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package tlog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/transparency-dev/merkle/rfc6962"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// receiptType is the second line of a receipt note. It keeps receipts from
+// being mistaken for checkpoints, which are signed with the same key.
+const receiptType = "append-receipt"
+
+// Receipt is the service's promise to include an entry in a log, similar to a
+// signed entry timestamp. It is issued once the entry has been sequenced and
+// its batch flushed to storage, and binds the entry's leaf hash to the index
+// it was assigned, so a submitter holds evidence of the append even before a
+// checkpoint covering it has been published.
+//
+// Receipts are serialised as signed notes (c2sp.org/signed-note) with the body
+//
+//	<origin>
+//	append-receipt
+//	<index>
+//	<base64 leaf hash>
+//	<RFC 3339 time>
+//
+// and signed with the log's checkpoint key, so they verify offline with the
+// same verifier key as the log's checkpoints.
+type Receipt struct {
+	Origin    string
+	Index     uint64
+	LeafHash  []byte // RFC 6962 leaf hash of the entry
+	Timestamp time.Time
+}
+
+// NewReceipt returns the receipt for entry data sequenced at index.
+func NewReceipt(origin string, index uint64, data []byte) Receipt {
+	return Receipt{
+		Origin:    origin,
+		Index:     index,
+		LeafHash:  rfc6962.DefaultHasher.HashLeaf(data),
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// Marshal returns the note body of the receipt.
+func (r Receipt) Marshal() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%d\n%s\n%s\n",
+		r.Origin,
+		receiptType,
+		r.Index,
+		base64.StdEncoding.EncodeToString(r.LeafHash),
+		r.Timestamp.UTC().Format(time.RFC3339)))
+}
+
+// Sign returns the receipt as a note signed by signer.
+func (r Receipt) Sign(signer Signer) ([]byte, error) {
+	signed, err := note.Sign(&note.Note{Text: string(r.Marshal())}, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	return signed, nil
+}
+
+// CoversEntry reports whether the receipt was issued for entry data.
+func (r Receipt) CoversEntry(data []byte) bool {
+	return bytes.Equal(r.LeafHash, rfc6962.DefaultHasher.HashLeaf(data))
+}
+
+// ParseReceipt parses the body of a receipt note. Signatures are not verified.
+func ParseReceipt(body []byte) (*Receipt, error) {
+	lines := strings.Split(string(body), "\n")
+	if len(lines) != 6 || lines[5] != "" {
+		return nil, fmt.Errorf("malformed receipt: want 5 lines")
+	}
+	if lines[1] != receiptType {
+		return nil, fmt.Errorf("malformed receipt: unexpected type %q", lines[1])
+	}
+	index, err := strconv.ParseUint(lines[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed receipt index: %w", err)
+	}
+	leafHash, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(leafHash) != rfc6962.DefaultHasher.Size() {
+		return nil, fmt.Errorf("malformed receipt leaf hash")
+	}
+	ts, err := time.Parse(time.RFC3339, lines[4])
+	if err != nil {
+		return nil, fmt.Errorf("malformed receipt time: %w", err)
+	}
+	return &Receipt{
+		Origin:    lines[0],
+		Index:     index,
+		LeafHash:  leafHash,
+		Timestamp: ts,
+	}, nil
+}
+
+// VerifyReceipt checks the signature of a receipt note against verifierKey,
+// a signed-note verifier key such as returned by VerifierKey, and returns the
+// parsed receipt. It needs no access to the service.
+func VerifyReceipt(signed []byte, verifierKey string) (*Receipt, error) {
+	verifier, err := note.NewVerifier(verifierKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier key: %w", err)
+	}
+	n, err := note.Open(signed, note.VerifierList(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify receipt: %w", err)
+	}
+	r, err := ParseReceipt([]byte(n.Text))
+	if err != nil {
+		return nil, err
+	}
+	if r.Origin != verifier.Name() {
+		return nil, fmt.Errorf("receipt origin %q does not match key name %q", r.Origin, verifier.Name())
+	}
+	return r, nil
+}
+
+// VerifierKey returns the signed-note verifier key for checkpoints and
+// receipts of the log with the given origin, signed by publicKey.
+func VerifierKey(origin string, publicKey ed25519.PublicKey) (string, error) {
+	return note.NewEd25519VerifierKey(origin, publicKey)
+}
+
+// LogOrigin returns the Tessera origin of a log, which is also the name of
+// the key signing its checkpoints and receipts.
+func (m *Manager) LogOrigin(logID string) string {
+	return fmt.Sprintf("%s/logs/%s", m.originPrefix, logID)
+}
+
+// logSigner returns the signer of a log's checkpoints and receipts: the
+// service key named after the log's origin, or the manager's signer when no
+// private key is configured.
+func (m *Manager) logSigner(logID string) (Signer, error) {
+	if m.privateKey == nil {
+		return m.signer, nil
+	}
+	return NewEd25519Signer(m.privateKey, m.LogOrigin(logID))
+}
+
+// LogVerifierKey returns the signed-note verifier key for a log's checkpoints,
+// receipts and migration records. It requires the service's private key.
+func (m *Manager) LogVerifierKey(logID string) (string, error) {
+	if m.privateKey == nil {
+		return "", fmt.Errorf("verifier key requires the log signing key")
+	}
+	signer, err := NewEd25519Signer(m.privateKey, m.LogOrigin(logID))
+	if err != nil {
+		return "", fmt.Errorf("failed to create signer for %s: %w", logID, err)
+	}
+	return signer.VerifierKey()
+}
+
+// ReceiptIssuer signs the append receipts of one log.
+type ReceiptIssuer struct {
+	origin string
+	signer Signer
+}
+
+// Issue signs a receipt for entry data sequenced at index.
+func (r *ReceiptIssuer) Issue(index uint64, data []byte) ([]byte, error) {
+	return NewReceipt(r.origin, index, data).Sign(r.signer)
+}
+
+// ReceiptIssuer returns the issuer of a log's append receipts, which signs
+// with the same per-log key that signs the log's checkpoints. Like
+// LogVerifierKey it requires the service's private key: without one there is
+// no per-log key for a receipt to verify against, so none is issued.
+// Callers get the issuer before appending, so an append is refused rather
+// than committed without its receipts.
+func (m *Manager) ReceiptIssuer(logID string) (*ReceiptIssuer, error) {
+	if m.privateKey == nil {
+		return nil, fmt.Errorf("receipts require the log signing key")
+	}
+	signer, err := m.logSigner(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer for %s: %w", logID, err)
+	}
+	return &ReceiptIssuer{origin: m.LogOrigin(logID), signer: signer}, nil
+}
+
+// IssueReceipt signs a receipt for entry data sequenced at index in a log.
+func (m *Manager) IssueReceipt(logID string, index uint64, data []byte) ([]byte, error) {
+	issuer, err := m.ReceiptIssuer(logID)
+	if err != nil {
+		return nil, err
+	}
+	return issuer.Issue(index, data)
+}
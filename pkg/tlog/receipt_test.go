@@ -0,0 +1,59 @@
+package tlog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueReceipt_VerifiesOffline(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	manager := &Manager{privateKey: priv, originPrefix: "test", signer: &dummySigner{}}
+	logID := "did:key:z6MkReceipt/evidence"
+	entry := []byte("evidence entry")
+
+	signed, err := manager.IssueReceipt(logID, 42, entry)
+	require.NoError(t, err)
+
+	vkey, err := VerifierKey(manager.LogOrigin(logID), pub)
+	require.NoError(t, err)
+
+	receipt, err := VerifyReceipt(signed, vkey)
+	require.NoError(t, err)
+	require.Equal(t, "test/logs/"+logID, receipt.Origin)
+	require.Equal(t, uint64(42), receipt.Index)
+	require.True(t, receipt.CoversEntry(entry))
+	require.False(t, receipt.CoversEntry([]byte("other entry")))
+	require.False(t, receipt.Timestamp.IsZero())
+
+	t.Run("tampered index", func(t *testing.T) {
+		tampered := bytes.Replace(signed, []byte("\n42\n"), []byte("\n43\n"), 1)
+		_, err := VerifyReceipt(tampered, vkey)
+		require.Error(t, err)
+	})
+
+	t.Run("other log's key", func(t *testing.T) {
+		otherKey, err := VerifierKey(manager.LogOrigin("did:key:z6MkOther"), pub)
+		require.NoError(t, err)
+		_, err = VerifyReceipt(signed, otherKey)
+		require.Error(t, err)
+	})
+
+	t.Run("checkpoint is not a receipt", func(t *testing.T) {
+		_, err := ParseReceipt([]byte("test/logs/" + logID + "\n3\nAAAA\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("no signing key", func(t *testing.T) {
+		keyless := &Manager{originPrefix: "test", signer: &dummySigner{}}
+		_, err := keyless.IssueReceipt(logID, 42, entry)
+		require.Error(t, err)
+		_, err = keyless.ReceiptIssuer(logID)
+		require.Error(t, err, "appends must be able to refuse up front")
+	})
+}
@@ -0,0 +1,296 @@
+package tlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/transparency-dev/formats/log"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/tessera/api/layout"
+	"github.com/transparency-dev/tessera/client"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+)
+
+// ErrLogExists is returned when restoring a log that is already known locally.
+var ErrLogExists = errors.New("log already exists")
+
+// AuthorizeFunc checks that a request may write a log once the space's
+// revocations are loaded, e.g. that none of its delegations is revoked.
+type AuthorizeFunc func(ctx context.Context) error
+
+// RestoreLogResult describes a log restored from its index CAR.
+type RestoreLogResult struct {
+	LogID    string
+	IndexCID string // Root CID of the index CAR the log was restored from
+	TreeSize uint64
+	Root     []byte
+	Paths    int // Path->CID mappings restored
+
+	// RevocationIndexCID is the root CID of the index CAR the space's
+	// revocation log was restored from, empty if it was already present.
+	RevocationIndexCID string
+}
+
+// RestoreLog rebuilds the local state of a log from the index CAR stored in
+// its space, for when the data directory was lost or the log moves to another
+// service.
+//
+// The index's checkpoint must carry a valid signature of the log's key and
+// its root hash must match the root recomputed from the indexed hash tiles;
+// only then are the log, CID index, tree state and head rows written, in one
+// transaction. Logs that already exist locally are not overwritten.
+//
+// Delegations for the log are only trusted with the space's revocations, so
+// unless the space's revocation log exists locally it is restored first from
+// the index CAR at revocationIndexCID, and the revocations table is rebuilt
+// from it before the log is written. The restore fails if that is not
+// possible. authorize, if not nil, is then called and the restore is refused
+// if it returns an error.
+func (m *Manager) RestoreLog(ctx context.Context, logID, indexCID, revocationIndexCID string, dlg delegation.Delegation, authorize AuthorizeFunc) (*RestoreLogResult, error) {
+	if m.privateKey == nil {
+		return nil, fmt.Errorf("checkpoint verification requires the log signing key")
+	}
+	if IsRevocationLog(logID) {
+		return nil, fmt.Errorf("%s is a revocation log, restore its main log instead", logID)
+	}
+
+	st, err := m.readIndexedLog(ctx, logID, indexCID, dlg, func(cpRaw []byte) (*log.Checkpoint, error) {
+		return m.verifyCheckpoint(logID, cpRaw)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revocationLogID := RevocationLogID(logID)
+	revocationIndexCID, err = m.restoreRevocationLog(ctx, revocationLogID, revocationIndexCID, dlg)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.CheckRevocations(ctx, logID); err != nil {
+		return nil, fmt.Errorf("failed to rebuild revocations: %w", err)
+	}
+	if authorize != nil {
+		if err := authorize(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.writeIndexedLog(ctx, logID, st); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("restored log from index CAR", "logID", logID, "indexCID", st.indexCID, "treeSize", st.checkpoint.Size, "paths", len(st.index))
+	return &RestoreLogResult{
+		LogID:              logID,
+		IndexCID:           st.indexCID,
+		TreeSize:           st.checkpoint.Size,
+		Root:               st.checkpoint.Hash,
+		Paths:              len(st.index),
+		RevocationIndexCID: revocationIndexCID,
+	}, nil
+}
+
+// restoreRevocationLog restores a space's revocation log from the index CAR at
+// indexCID unless it exists locally, and returns indexCID, or "" if nothing
+// was restored. The space's revocations are reloaded on their next check.
+func (m *Manager) restoreRevocationLog(ctx context.Context, revocationLogID, indexCID string, dlg delegation.Delegation) (string, error) {
+	store, err := m.storeManager.GetStore(revocationLogID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get state store: %w", err)
+	}
+	if _, err := store.GetLogRecord(ctx, revocationLogID); err == nil {
+		return "", nil
+	} else if !errors.Is(err, sqlite.ErrNotFound) {
+		return "", fmt.Errorf("failed to get revocation log record: %w", err)
+	}
+	if indexCID == "" {
+		return "", fmt.Errorf("revocation log %s does not exist locally, the index CID of its latest index CAR is required", revocationLogID)
+	}
+
+	st, err := m.readIndexedLog(ctx, revocationLogID, indexCID, dlg, func(cpRaw []byte) (*log.Checkpoint, error) {
+		return m.verifyCheckpoint(revocationLogID, cpRaw)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read revocation log: %w", err)
+	}
+	if err := m.writeIndexedLog(ctx, revocationLogID, st); err != nil {
+		return "", fmt.Errorf("failed to restore revocation log: %w", err)
+	}
+
+	m.revocationsMu.Lock()
+	delete(m.revocationsLoaded, SpaceDIDForLog(revocationLogID))
+	m.revocationsMu.Unlock()
+
+	m.logger.Info("restored revocation log from index CAR", "logID", revocationLogID, "indexCID", indexCID, "treeSize", st.checkpoint.Size)
+	return indexCID, nil
+}
+
+// indexedLog is the state of a log read from an index CAR.
+type indexedLog struct {
+	store         *sqlite.LogStore
+	client        storacha.StorachaClient
+	indexCID      string
+	index         map[string]string
+	checkpointCID string
+	checkpointRaw []byte
+	checkpoint    *log.Checkpoint
+}
+
+// readIndexedLog reads the state of a log that does not exist locally from an
+// index CAR, verifying its checkpoint with verify and the checkpoint's root
+// against the indexed hash tiles. Nothing is written.
+func (m *Manager) readIndexedLog(ctx context.Context, logID, indexCID string, dlg delegation.Delegation, verify func([]byte) (*log.Checkpoint, error)) (*indexedLog, error) {
+	if m.storeManager == nil {
+		return nil, fmt.Errorf("store manager not configured")
+	}
+
+	m.mu.RLock()
+	_, loaded := m.logs[logID]
+	m.mu.RUnlock()
+	if loaded {
+		return nil, fmt.Errorf("%w: %s", ErrLogExists, logID)
+	}
+
+	store, err := m.storeManager.GetStore(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state store: %w", err)
+	}
+	if _, err := store.GetLogRecord(ctx, logID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrLogExists, logID)
+	} else if !errors.Is(err, sqlite.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get log record: %w", err)
+	}
+
+	if indexCID == "" {
+		return nil, fmt.Errorf("index CID required to restore log %s", logID)
+	}
+
+	blobClient := m.storachaClient
+	if m.storageClient != nil || m.clientPool != nil {
+		blobClient, err = m.storageClientFor(logID, SpaceDIDForLog(logID), dlg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+	}
+	if dlg != nil {
+		ctx = storacha.WithDelegation(ctx, dlg)
+	}
+
+	index, err := indexpersist.ReadIndex(ctx, indexCID, blobClient.FetchBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index CAR %s: %w", indexCID, err)
+	}
+
+	checkpointCID, ok := index[layout.CheckpointPath]
+	if !ok {
+		return nil, fmt.Errorf("index CAR %s has no checkpoint", indexCID)
+	}
+	cpRaw, err := blobClient.FetchBlob(ctx, checkpointCID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint: %w", err)
+	}
+	cp, err := verify(cpRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := indexedRootHash(ctx, index, blobClient, cp.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recompute root hash: %w", err)
+	}
+	if !bytes.Equal(root, cp.Hash) {
+		return nil, fmt.Errorf("root hash of indexed tiles %x does not match checkpoint root %x", root, cp.Hash)
+	}
+
+	return &indexedLog{
+		store:         store,
+		client:        blobClient,
+		indexCID:      indexCID,
+		index:         index,
+		checkpointCID: checkpointCID,
+		checkpointRaw: cpRaw,
+		checkpoint:    cp,
+	}, nil
+}
+
+// writeIndexedLog writes the log, CID index, tree state and head rows of a
+// log read with readIndexedLog in a single transaction.
+func (m *Manager) writeIndexedLog(ctx context.Context, logID string, st *indexedLog) error {
+	cp := st.checkpoint
+	if err := st.store.RestoreLog(ctx, logID, sqlite.RestoredLog{
+		Index:          st.index,
+		TreeSize:       cp.Size,
+		Root:           cp.Hash,
+		CheckpointCID:  st.checkpointCID,
+		CheckpointSize: uint64(len(st.checkpointRaw)),
+		IndexCID:       st.indexCID,
+	}); err != nil {
+		return fmt.Errorf("failed to write restored log: %w", err)
+	}
+	if m.cidStore != nil {
+		if err := m.cidStore.SetLatestCID(logID, st.indexCID); err != nil {
+			m.logger.Warn("failed to update CID store", "logID", logID, "error", err)
+		}
+	}
+	return nil
+}
+
+// verifyCheckpoint checks that a checkpoint is signed by the log's key for its
+// origin and returns the parsed checkpoint.
+func (m *Manager) verifyCheckpoint(logID string, cpRaw []byte) (*log.Checkpoint, error) {
+	origin := m.LogOrigin(logID)
+	vkey, err := m.LogVerifierKey(logID)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := note.NewVerifier(vkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verifier: %w", err)
+	}
+	cp, _, _, err := log.ParseCheckpoint(cpRaw, origin, verifier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// indexedRootHash computes the root hash of the first treeSize leaves from the
+// hash tiles listed in a path->CID index. Like proofTileFetcher, a missing
+// partial tile falls back to the full tile, which has the same node prefix.
+func indexedRootHash(ctx context.Context, index map[string]string, fetcher BlobFetcher, treeSize uint64) ([]byte, error) {
+	if treeSize == 0 {
+		return rfc6962.DefaultHasher.EmptyRoot(), nil
+	}
+
+	fetchTile := func(ctx context.Context, level, idx uint64, p uint8) ([]byte, error) {
+		path := layout.TilePath(level, idx, p)
+		tileCID, ok := index[path]
+		if !ok && p != 0 {
+			tileCID, ok = index[layout.TilePath(level, idx, 0)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("tile %s missing from index", path)
+		}
+		return fetcher.FetchBlob(ctx, tileCID)
+	}
+
+	nodes, err := client.FetchRangeNodes(ctx, treeSize, fetchTile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch range nodes: %w", err)
+	}
+
+	rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	r, err := rf.NewRange(0, treeSize, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build compact range: %w", err)
+	}
+	return r.GetRootHash(nil)
+}
@@ -0,0 +1,245 @@
+package tlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/relves/ucanlog/pkg/types"
+	ed25519signer "github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRestoreTestManager creates a manager with its own data directory that
+// stores blobs in blobs, standing in for the customer's space.
+func newRestoreTestManager(t *testing.T, privKey ed25519.PrivateKey, blobs storacha.StorachaClient) (*Manager, *sqlite.StoreManager) {
+	t.Helper()
+	return newServiceTestManager(t, privKey, "test", blobs)
+}
+
+// newServiceTestManager is newRestoreTestManager for a service with its own
+// origin prefix.
+func newServiceTestManager(t *testing.T, privKey ed25519.PrivateKey, originPrefix string, blobs storacha.StorachaClient) (*Manager, *sqlite.StoreManager) {
+	t.Helper()
+	dataDir := t.TempDir()
+	storeManager := sqlite.NewStoreManager(dataDir)
+	t.Cleanup(func() { storeManager.CloseAll() })
+
+	signer, err := NewEd25519Signer(privKey, originPrefix)
+	require.NoError(t, err)
+	serviceSigner, err := ed25519signer.Generate()
+	require.NoError(t, err)
+
+	mgr, err := NewDelegatedManager(DelegatedManagerConfig{
+		BasePath:      dataDir,
+		Signer:        signer,
+		PrivateKey:    privKey,
+		OriginPrefix:  originPrefix,
+		ServiceSigner: serviceSigner,
+		CIDStore:      NewStateStoreCIDStore(storeManager.GetStateStore),
+		StoreManager:  storeManager,
+		StorageClient: blobs,
+	})
+	require.NoError(t, err)
+	return mgr, storeManager
+}
+
+// uploadIndexCAR uploads the current index of a log as an index CAR.
+func uploadIndexCAR(t *testing.T, ctx context.Context, storeManager *sqlite.StoreManager, blobs storacha.StorachaClient, logID string) string {
+	t.Helper()
+	store, err := storeManager.GetStore(logID)
+	require.NoError(t, err)
+	index, err := store.GetCIDIndex(ctx, logID)
+	require.NoError(t, err)
+
+	carData, _, err := indexpersist.BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	rootCID, err := blobs.UploadCAR(ctx, SpaceDIDForLog(logID), carData, nil)
+	require.NoError(t, err)
+	return rootCID
+}
+
+func TestManager_RestoreLogFromIndexCAR(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkRestoreTest"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// Write a log and persist its index
+	original, originalStores := newRestoreTestManager(t, privKey, blobs)
+	require.NoError(t, original.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	for i := 0; i < 5; i++ {
+		_, err := original.AddEntryWithDelegation(ctx, logID, []byte{byte(i)}, dlg)
+		require.NoError(t, err)
+	}
+	waitForIntegration(t, ctx, original, logID, 5)
+	require.Eventually(t, func() bool {
+		cpRaw, err := original.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			return false
+		}
+		cp, err := ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == 5
+	}, 5*time.Second, 50*time.Millisecond)
+	checkpoint, err := original.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+	indexCID := uploadIndexCAR(t, ctx, originalStores, blobs, logID)
+
+	// Revoke a delegation and persist the revocation log's index
+	revocationLogID := RevocationLogID(logID)
+	require.NoError(t, original.CreateLogWithDelegation(ctx, revocationLogID, logID, dlg))
+	revocation, err := (&types.RevocationEntry{Type: types.RevokeUCAN, Target: []byte("bafyRevoked"), Timestamp: time.Now()}).Serialize()
+	require.NoError(t, err)
+	_, err = original.AddEntryWithDelegation(ctx, revocationLogID, revocation, dlg)
+	require.NoError(t, err)
+	originalStore, err := originalStores.GetStore(logID)
+	require.NoError(t, err)
+	waitForIndexedCheckpoint(t, ctx, originalStore, blobs, revocationLogID, 1)
+	revocationIndexCID := uploadIndexCAR(t, ctx, originalStores, blobs, revocationLogID)
+
+	// Restore into an empty data directory
+	restored, restoredStores := newRestoreTestManager(t, privKey, blobs)
+	_, err = restored.GetLogInstance(ctx, logID)
+	require.Error(t, err)
+
+	store, err := restoredStores.GetStore(logID)
+	require.NoError(t, err)
+
+	// The revocation log must be restored with the log, so revoked
+	// delegations stay revoked
+	_, err = restored.RestoreLog(ctx, logID, indexCID, "", dlg, nil)
+	require.ErrorContains(t, err, "revocation log")
+	_, err = store.GetLogRecord(ctx, logID)
+	require.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	// The request is authorized against the restored revocations, and the
+	// log is not written if it is refused
+	refusing, refusingStores := newRestoreTestManager(t, privKey, blobs)
+	refusingStore, err := refusingStores.GetStore(logID)
+	require.NoError(t, err)
+	refused := errors.New("delegation revoked")
+	_, err = refusing.RestoreLog(ctx, logID, indexCID, revocationIndexCID, dlg, func(ctx context.Context) error {
+		revoked, err := refusingStore.IsRevoked(ctx, "bafyRevoked")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		return refused
+	})
+	require.ErrorIs(t, err, refused)
+	_, err = refusingStore.GetLogRecord(ctx, logID)
+	require.ErrorIs(t, err, sqlite.ErrNotFound)
+
+	res, err := restored.RestoreLog(ctx, logID, indexCID, revocationIndexCID, dlg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), res.TreeSize)
+	assert.Equal(t, indexCID, res.IndexCID)
+	assert.Equal(t, revocationIndexCID, res.RevocationIndexCID)
+
+	revoked, err := store.IsRevoked(ctx, "bafyRevoked")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	require.NoError(t, restored.CheckRevocations(ctx, logID))
+	size, root, err := store.GetTreeState(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), size)
+	assert.Equal(t, res.Root, root)
+	headCID, headSize, err := store.GetHead(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, indexCID, headCID)
+	assert.Equal(t, uint64(5), headSize)
+
+	got, err := restored.ReadCheckpoint(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, got)
+	entries, err := restored.ReadRange(ctx, logID, 0, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, []byte{4}, entries[4])
+
+	// The restored log accepts new entries
+	index, err := restored.AddEntryWithDelegation(ctx, logID, []byte{5}, dlg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), index)
+
+	// Existing logs are never overwritten
+	_, err = restored.RestoreLog(ctx, logID, indexCID, revocationIndexCID, dlg, nil)
+	require.ErrorIs(t, err, ErrLogExists)
+
+	// Logs are only restored from an explicit index
+	fresh, _ := newRestoreTestManager(t, privKey, blobs)
+	_, err = fresh.RestoreLog(ctx, logID, "", revocationIndexCID, dlg, nil)
+	require.ErrorContains(t, err, "index CID required")
+
+	// A checkpoint signed by another key is rejected
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	other, otherStores := newRestoreTestManager(t, otherKey, blobs)
+	_, err = other.RestoreLog(ctx, logID, indexCID, revocationIndexCID, dlg, nil)
+	require.ErrorContains(t, err, "invalid checkpoint")
+	otherStore, err := otherStores.GetStore(logID)
+	require.NoError(t, err)
+	_, err = otherStore.GetLogRecord(ctx, logID)
+	require.ErrorIs(t, err, sqlite.ErrNotFound)
+}
+
+func TestManager_RestoreLogRejectsMismatchedTiles(t *testing.T) {
+	ctx := context.Background()
+	logID := "did:key:z6MkRestoreTampered"
+	dlg := storachatest.MockDelegation()
+
+	blobs, err := storacha.NewFSClient(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	_, privKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	original, originalStores := newRestoreTestManager(t, privKey, blobs)
+	require.NoError(t, original.CreateLogWithDelegation(ctx, logID, logID, dlg))
+	_, err = original.AddEntryWithDelegation(ctx, logID, []byte("entry"), dlg)
+	require.NoError(t, err)
+	waitForIntegration(t, ctx, original, logID, 1)
+	require.Eventually(t, func() bool {
+		cpRaw, err := original.ReadCheckpoint(ctx, logID)
+		if err != nil {
+			return false
+		}
+		cp, err := ParseCheckpoint(cpRaw)
+		return err == nil && cp.Size == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	// Let the background index upload finish before the test cleans up
+	require.Eventually(t, func() bool {
+		cid, err := original.cidStore.GetLatestCID(logID)
+		return err == nil && cid != ""
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Point the level 0 tile at a blob with a different leaf hash
+	store, err := originalStores.GetStore(logID)
+	require.NoError(t, err)
+	forged, err := blobs.UploadBlob(ctx, logID, make([]byte, 32), nil)
+	require.NoError(t, err)
+	index, err := store.GetCIDIndex(ctx, logID)
+	require.NoError(t, err)
+	for path := range index {
+		if path == "tile/0/000" || path == "tile/0/000.p/1" {
+			index[path] = forged
+		}
+	}
+	carData, _, err := indexpersist.BuildIndexCAR(ctx, index)
+	require.NoError(t, err)
+	indexCID, err := blobs.UploadCAR(ctx, logID, carData, nil)
+	require.NoError(t, err)
+
+	restored, _ := newRestoreTestManager(t, privKey, blobs)
+	_, err = restored.RestoreLog(ctx, logID, indexCID, "", dlg, nil)
+	require.ErrorContains(t, err, "does not match checkpoint root")
+}
@@ -0,0 +1,180 @@
+package tlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/pkg/types"
+)
+
+// revocationLogSuffix is appended to a log ID to form the ID of its revocation log.
+const revocationLogSuffix = "-revocations"
+
+// RevocationLogID returns the ID of the revocation log paired with a log.
+// Delegations grant access to a whole space, so every log in a space shares
+// the revocation log of the space's default log.
+func RevocationLogID(logID string) string {
+	return SpaceDIDForLog(logID) + revocationLogSuffix
+}
+
+// IsRevocationLog reports whether logID names a revocation log.
+func IsRevocationLog(logID string) bool {
+	return strings.HasSuffix(logID, revocationLogSuffix)
+}
+
+// RebuildRevocationsResult reports the outcome of rebuilding a revocations table.
+type RebuildRevocationsResult struct {
+	Scanned int // Revocation log entries read
+	Added   int // Revocations missing from SQLite that were restored
+}
+
+// RebuildRevocations repopulates the SQLite revocations table of a log from its
+// Tessera revocation log. The revocation log is the source of truth; SQLite is
+// only a lookup cache, so rows already present are kept and the rebuild can be
+// repeated safely.
+func (m *Manager) RebuildRevocations(ctx context.Context, logID string) (*RebuildRevocationsResult, error) {
+	if m.storeManager == nil {
+		return nil, fmt.Errorf("store manager not configured")
+	}
+	if IsRevocationLog(logID) {
+		return nil, fmt.Errorf("%s is a revocation log, use the main log ID", logID)
+	}
+	// Revocations are stored with the space's default log
+	logID = SpaceDIDForLog(logID)
+
+	store, err := m.storeManager.GetStore(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state store: %w", err)
+	}
+
+	revocationLogID := RevocationLogID(logID)
+	reader, err := m.GetReader(ctx, revocationLogID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open revocation log: %w", err)
+	}
+	size, err := reader.IntegratedSize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revocation log size: %w", err)
+	}
+
+	data, err := m.ReadRange(ctx, revocationLogID, 0, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revocation log: %w", err)
+	}
+
+	result := &RebuildRevocationsResult{Scanned: len(data)}
+	for i, raw := range data {
+		var entry types.RevocationEntry
+		if err := entry.Deserialize(raw); err != nil {
+			return nil, fmt.Errorf("failed to parse revocation entry %d: %w", i, err)
+		}
+		target := string(entry.Target)
+		var revoked bool
+		switch entry.Type {
+		case types.RevokeUCAN:
+			revoked, err = store.IsRevoked(ctx, target)
+		case types.RevokeAccount, types.RevokeCapability:
+			revoked, err = store.IsScopedRevoked(ctx, string(entry.Type), target)
+		default:
+			m.logger.Warn("skipping unsupported revocation entry", "logID", logID, "index", i, "type", entry.Type)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check revocation %s: %w", target, err)
+		}
+		if revoked {
+			continue
+		}
+
+		if entry.Type == types.RevokeUCAN {
+			err = store.AddRevocationAt(ctx, target, entry.Timestamp)
+		} else {
+			err = store.AddScopedRevocation(ctx, string(entry.Type), target, entry.Timestamp)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore revocation %s: %w", target, err)
+		}
+		result.Added++
+	}
+
+	m.logger.Info("rebuilt revocations from revocation log", "logID", logID, "scanned", result.Scanned, "added", result.Added)
+	return result, nil
+}
+
+// ErrRevocationsUnavailable is returned by CheckRevocations when the
+// revocations of a space cannot be loaded, so delegations for it must not be
+// trusted.
+var ErrRevocationsUnavailable = errors.New("revocations unavailable")
+
+// CheckRevocations makes sure the revocations table of a log's space is
+// complete before delegations are checked against it. The first check for a
+// space in a process replays its revocation log with RebuildRevocations, so
+// revocations missing from SQLite, e.g. after the database was recreated, are
+// restored from the revocation log rather than silently dropped.
+//
+// A space with logs but no revocation log cannot be checked: its revocation
+// log was lost with the database and must be restored with RestoreLog first.
+// This returns ErrRevocationsUnavailable, as does a failed rebuild. Spaces
+// without any logs have nothing to check.
+func (m *Manager) CheckRevocations(ctx context.Context, logID string) error {
+	if m.storeManager == nil {
+		return nil
+	}
+	spaceDID := SpaceDIDForLog(logID)
+
+	m.revocationsMu.Lock()
+	defer m.revocationsMu.Unlock()
+	if m.revocationsLoaded[spaceDID] {
+		return nil
+	}
+
+	store, err := m.storeManager.GetStore(spaceDID)
+	if err != nil {
+		return fmt.Errorf("failed to get state store: %w", err)
+	}
+	if _, err := store.GetLogRecord(ctx, RevocationLogID(spaceDID)); errors.Is(err, sqlite.ErrNotFound) {
+		hasLogs, err := m.spaceHasLogs(ctx, spaceDID)
+		if err != nil {
+			return err
+		}
+		if hasLogs {
+			return fmt.Errorf("%w: space %s has logs but no revocation log, restore it with tlog/restore", ErrRevocationsUnavailable, spaceDID)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get revocation log record: %w", err)
+	}
+
+	if _, err := m.RebuildRevocations(ctx, spaceDID); err != nil {
+		return fmt.Errorf("%w: %v", ErrRevocationsUnavailable, err)
+	}
+	m.revocationsLoaded[spaceDID] = true
+	return nil
+}
+
+// spaceHasLogs reports whether the default log or a named log of a space has
+// a log record.
+func (m *Manager) spaceHasLogs(ctx context.Context, spaceDID string) (bool, error) {
+	logDIDs, err := m.storeManager.LogDIDs()
+	if err != nil {
+		return false, fmt.Errorf("failed to list logs: %w", err)
+	}
+	for _, logDID := range logDIDs {
+		if logDID != spaceDID && !strings.HasPrefix(logDID, spaceDID+"/") {
+			continue
+		}
+		store, err := m.storeManager.GetStore(logDID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get state store: %w", err)
+		}
+		if _, err := store.GetLogRecord(ctx, logDID); err == nil {
+			return true, nil
+		} else if !errors.Is(err, sqlite.ErrNotFound) {
+			return false, fmt.Errorf("failed to get log record: %w", err)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,111 @@
+package tlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/storachatest"
+	"github.com/relves/ucanlog/pkg/types"
+)
+
+func TestManager_RebuildRevocations(t *testing.T) {
+	manager := testManager(t, t.TempDir())
+
+	ctx := storacha.WithDelegation(context.Background(), storachatest.MockDelegation())
+	logID := "test-log-revoked"
+	require.NoError(t, manager.CreateLog(ctx, logID))
+	require.NoError(t, manager.CreateLog(ctx, RevocationLogID(logID)))
+
+	revokedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var entries [][]byte
+	for _, entry := range []types.RevocationEntry{
+		{Type: types.RevokeUCAN, Target: []byte("bafyRevoked1"), Timestamp: revokedAt},
+		{Type: types.RevokeAccount, Target: []byte("did:key:z6MkAccount"), Timestamp: revokedAt},
+		{Type: types.RevokeUCAN, Target: []byte("bafyRevoked2"), Timestamp: revokedAt},
+	} {
+		data, err := entry.Serialize()
+		require.NoError(t, err)
+		entries = append(entries, data)
+	}
+	_, err := manager.addEntriesBatch(ctx, RevocationLogID(logID), entries)
+	require.NoError(t, err)
+	waitForIntegration(t, ctx, manager, RevocationLogID(logID), uint64(len(entries)))
+
+	store, err := manager.storeManager.GetStore(logID)
+	require.NoError(t, err)
+
+	// One revocation already made it into SQLite
+	require.NoError(t, store.AddRevocation(ctx, "bafyRevoked1"))
+
+	res, err := manager.RebuildRevocations(ctx, logID)
+	require.NoError(t, err)
+	require.Equal(t, 3, res.Scanned)
+	require.Equal(t, 2, res.Added)
+
+	for _, cid := range []string{"bafyRevoked1", "bafyRevoked2"} {
+		revoked, err := store.IsRevoked(ctx, cid)
+		require.NoError(t, err)
+		require.True(t, revoked, cid)
+	}
+	revoked, err := store.IsScopedRevoked(ctx, string(types.RevokeAccount), "did:key:z6MkAccount")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	t.Run("rebuild is idempotent", func(t *testing.T) {
+		res, err := manager.RebuildRevocations(ctx, logID)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Added)
+	})
+
+	t.Run("revocation log ID is rejected", func(t *testing.T) {
+		_, err := manager.RebuildRevocations(ctx, RevocationLogID(logID))
+		require.Error(t, err)
+	})
+}
+
+func TestManager_CheckRevocations(t *testing.T) {
+	manager := testManager(t, t.TempDir())
+
+	ctx := storacha.WithDelegation(context.Background(), storachatest.MockDelegation())
+	logID := "test-log-revoked-check"
+	require.NoError(t, manager.CreateLog(ctx, logID))
+	require.NoError(t, manager.CreateLog(ctx, RevocationLogID(logID)))
+
+	var entries [][]byte
+	for _, target := range []string{"bafyRevoked1", "bafyRevoked2"} {
+		data, err := (&types.RevocationEntry{Type: types.RevokeUCAN, Target: []byte(target), Timestamp: time.Now()}).Serialize()
+		require.NoError(t, err)
+		entries = append(entries, data)
+	}
+	_, err := manager.addEntriesBatch(ctx, RevocationLogID(logID), entries)
+	require.NoError(t, err)
+	waitForIntegration(t, ctx, manager, RevocationLogID(logID), 2)
+
+	store, err := manager.storeManager.GetStore(logID)
+	require.NoError(t, err)
+
+	// A table that is missing some revocations is completed, not only an empty one
+	require.NoError(t, store.AddRevocation(ctx, "bafyRevoked1"))
+	require.NoError(t, manager.CheckRevocations(ctx, logID))
+	revoked, err := store.IsRevoked(ctx, "bafyRevoked2")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	t.Run("named logs check their space", func(t *testing.T) {
+		require.NoError(t, manager.CheckRevocations(ctx, LogID(logID, "audit")))
+	})
+
+	t.Run("logs without a revocation log fail closed", func(t *testing.T) {
+		require.NoError(t, manager.CreateLog(ctx, "test-log-unrevocable"))
+		err := manager.CheckRevocations(ctx, "test-log-unrevocable")
+		require.ErrorIs(t, err, ErrRevocationsUnavailable)
+	})
+
+	t.Run("unknown spaces have nothing to check", func(t *testing.T) {
+		require.NoError(t, manager.CheckRevocations(ctx, "test-log-unknown"))
+	})
+}
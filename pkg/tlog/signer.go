@@ -60,3 +60,8 @@ func (s *Ed25519Signer) KeyHash() uint32 {
 func (s *Ed25519Signer) PublicKey() ed25519.PublicKey {
 	return s.publicKey
 }
+
+// VerifierKey returns the signed-note verifier key for notes signed by s.
+func (s *Ed25519Signer) VerifierKey() (string, error) {
+	return VerifierKey(s.name, s.publicKey)
+}
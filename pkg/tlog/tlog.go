@@ -3,6 +3,7 @@ package tlog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,11 +12,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/relves/ucanlog/internal/storage/blobcache"
 	"github.com/relves/ucanlog/internal/storage/sqlite"
 	"github.com/relves/ucanlog/internal/storage/storacha"
+	"github.com/relves/ucanlog/internal/storage/storacha/gc"
 	"github.com/relves/ucanlog/internal/storage/storacha/indexpersist"
 	"github.com/storacha/go-ucanto/core/delegation"
 	"github.com/storacha/go-ucanto/principal"
+	"github.com/transparency-dev/formats/log"
 	"github.com/transparency-dev/tessera"
 )
 
@@ -51,8 +55,34 @@ type LogInstance struct {
 	Reader   tessera.LogReader
 	Driver   tessera.Driver // Store driver for reuse in RecreateAppender
 	SpaceDID string         // Customer's space DID (for delegated storage)
+
+	// Shutdown waits until the entries added so far are integrated, then
+	// makes further appends, single or batched, fail.
+	Shutdown func(ctx context.Context) error
+
+	// appendMu keeps a batch append from interleaving with other writes:
+	// single appends share it so they can be batched together, while a
+	// batch append holds it exclusively to get a contiguous index range.
+	appendMu sync.RWMutex
 }
 
+// Appends to a log are batched so that concurrent requests share one
+// integration and one set of tile uploads. A batch is flushed when it is
+// full or its oldest entry has waited appendBatchMaxAge.
+const (
+	appendBatchMaxSize = 256
+	appendBatchMaxAge  = 100 * time.Millisecond
+)
+
+// MaxBatchEntries is the most entries AddEntriesWithDelegation appends in one
+// call. A batch fits in one integration, so it is appended in full or not at
+// all.
+const MaxBatchEntries = appendBatchMaxSize
+
+// ErrBatchTooLarge is returned for a batch append of more than
+// MaxBatchEntries entries.
+var ErrBatchTooLarge = errors.New("batch too large")
+
 // Manager handles Tessera tlog operations.
 type Manager struct {
 	basePath       string
@@ -68,8 +98,19 @@ type Manager struct {
 	logger         *slog.Logger
 
 	// For customer-delegated storage
-	serviceSigner principal.Signer     // Service's identity for signing invocations
-	clientPool    *storacha.ClientPool // Pool of per-log delegated clients
+	serviceSigner principal.Signer        // Service's identity for signing invocations
+	clientPool    *storacha.ClientPool    // Pool of per-log delegated clients
+	storageClient storacha.StorachaClient // Overrides clientPool and gateway reads when set
+
+	blobCache *blobcache.Cache // Optional on-disk blob cache shared by all logs
+	gc        gc.Config        // Garbage collection settings for every log
+
+	checkpoints checkpointHub // Fans out published checkpoints to subscribers
+
+	// revocationsLoaded holds the spaces whose revocations were rebuilt by
+	// CheckRevocations in this process.
+	revocationsMu     sync.Mutex
+	revocationsLoaded map[string]bool
 }
 
 // NewManager creates a new tlog manager.
@@ -120,6 +161,8 @@ func NewStorachaManager(basePath string, signer Signer, privateKey []byte, origi
 		cidStore:       cidStore,
 		storeManager:   storeManager,
 		logger:         slog.Default(),
+
+		revocationsLoaded: make(map[string]bool),
 	}, nil
 }
 
@@ -133,6 +176,21 @@ type DelegatedManagerConfig struct {
 	CIDStore      CIDStore
 	StoreManager  *sqlite.StoreManager // Optional: if nil, will be created from BasePath
 	Logger        *slog.Logger
+
+	// StorageClient, if set, stores the blobs of every log instead of the
+	// customers' Storacha spaces, e.g. a storacha.FSClient for local
+	// development. Delegations are still required and validated.
+	StorageClient storacha.StorachaClient
+
+	// BlobCache, if set, keeps fetched and uploaded blobs on disk so that logs
+	// restored after a restart read their tiles locally instead of from the
+	// gateway.
+	BlobCache *blobcache.Cache
+
+	// GC configures garbage collection of obsolete partial bundles and tiles,
+	// run by tlog/gc invocations and the GC scheduler. Zero fields take the
+	// gc package defaults.
+	GC gc.Config
 }
 
 // NewDelegatedManager creates a tlog manager that uses customer-delegated Storacha storage.
@@ -153,6 +211,10 @@ func NewDelegatedManager(cfg DelegatedManagerConfig) (*Manager, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = slog.Default()
 	}
+	if cfg.GC.Logger == nil {
+		cfg.GC.Logger = cfg.Logger
+	}
+	cfg.GC.ApplyDefaults()
 
 	// Create client pool for managing per-log delegated clients
 	clientPool, err := storacha.NewClientPool(storacha.ClientPoolConfig{
@@ -180,6 +242,11 @@ func NewDelegatedManager(cfg DelegatedManagerConfig) (*Manager, error) {
 		logger:        cfg.Logger,
 		serviceSigner: cfg.ServiceSigner,
 		clientPool:    clientPool,
+		storageClient: cfg.StorageClient,
+		blobCache:     cfg.BlobCache,
+		gc:            cfg.GC,
+
+		revocationsLoaded: make(map[string]bool),
 	}, nil
 }
 
@@ -228,7 +295,10 @@ func (m *Manager) CreateLog(ctx context.Context, logID string) error {
 					}
 				},
 			},
-			Logger: m.logger,
+			GC:           m.gcConfig(),
+			BlobCache:    m.blobCache,
+			OnCheckpoint: m.checkpoints.publish,
+			Logger:       m.logger,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create Storacha driver: %w", err)
@@ -239,23 +309,16 @@ func (m *Manager) CreateLog(ctx context.Context, logID string) error {
 	}
 
 	// Create per-log signer with unique origin
-	logOrigin := fmt.Sprintf("%s/logs/%s", m.originPrefix, logID)
-	var logSigner Signer
-	if m.privateKey != nil {
-		var err error
-		logSigner, err = NewEd25519Signer(m.privateKey, logOrigin)
-		if err != nil {
-			return fmt.Errorf("failed to create per-log signer: %w", err)
-		}
-	} else {
-		logSigner = m.signer
+	logSigner, err := m.logSigner(logID)
+	if err != nil {
+		return fmt.Errorf("failed to create per-log signer: %w", err)
 	}
 
 	// Create Tessera appender with per-log signer
 	opts := tessera.NewAppendOptions().
 		WithCheckpointSigner(logSigner).
 		WithCheckpointInterval(time.Second).
-		WithBatching(1, 100*time.Millisecond).
+		WithBatching(appendBatchMaxSize, appendBatchMaxAge).
 		WithCheckpointRepublishInterval(24 * time.Hour)
 
 	// Create Tessera appender with per-log signer
@@ -292,7 +355,7 @@ func (m *Manager) CreateLog(ctx context.Context, logID string) error {
 		m.logger.Debug("configured witnesses", "logID", logID, "timeout", witnessOpts.Timeout, "failOpen", witnessOpts.FailOpen)
 	}
 
-	appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
+	appender, shutdown, reader, err := storacha.NewAppender(ctx, driver, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create appender: %w", err)
 	}
@@ -300,6 +363,7 @@ func (m *Manager) CreateLog(ctx context.Context, logID string) error {
 	m.logs[logID] = &LogInstance{
 		Appender: appender,
 		Reader:   reader,
+		Shutdown: shutdown,
 		Driver:   driver,
 	}
 	return nil
@@ -336,7 +400,7 @@ func (m *Manager) CreateLogWithDelegation(ctx context.Context, logID string, spa
 	}
 
 	// Get or create a delegated client for this log's space
-	client, err := m.clientPool.GetClient(logID, spaceDID, dlg)
+	client, err := m.storageClientFor(logID, spaceDID, dlg)
 	if err != nil {
 		return fmt.Errorf("failed to get delegated client: %w", err)
 	}
@@ -356,28 +420,25 @@ func (m *Manager) CreateLogWithDelegation(ctx context.Context, logID string, spa
 				}
 			},
 		},
-		Logger: m.logger,
+		GC:           m.gcConfig(),
+		BlobCache:    m.blobCache,
+		OnCheckpoint: m.checkpoints.publish,
+		Logger:       m.logger,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create Storacha driver: %w", err)
 	}
 
 	// Create per-log signer
-	logOrigin := fmt.Sprintf("%s/logs/%s", m.originPrefix, logID)
-	var logSigner Signer
-	if m.privateKey != nil {
-		logSigner, err = NewEd25519Signer(m.privateKey, logOrigin)
-		if err != nil {
-			return fmt.Errorf("failed to create per-log signer: %w", err)
-		}
-	} else {
-		logSigner = m.signer
+	logSigner, err := m.logSigner(logID)
+	if err != nil {
+		return fmt.Errorf("failed to create per-log signer: %w", err)
 	}
 
 	opts := tessera.NewAppendOptions().
 		WithCheckpointSigner(logSigner).
 		WithCheckpointInterval(time.Second).
-		WithBatching(1, 100*time.Millisecond).
+		WithBatching(appendBatchMaxSize, appendBatchMaxAge).
 		WithCheckpointRepublishInterval(24 * time.Hour)
 
 	// Configure witness policy if exists
@@ -393,7 +454,7 @@ func (m *Manager) CreateLogWithDelegation(ctx context.Context, logID string, spa
 		})
 	}
 
-	appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
+	appender, shutdown, reader, err := storacha.NewAppender(ctx, driver, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create appender: %w", err)
 	}
@@ -401,6 +462,7 @@ func (m *Manager) CreateLogWithDelegation(ctx context.Context, logID string, spa
 	m.logs[logID] = &LogInstance{
 		Appender: appender,
 		Reader:   reader,
+		Shutdown: shutdown,
 		Driver:   driver,
 		SpaceDID: spaceDID,
 	}
@@ -458,7 +520,7 @@ func (m *Manager) GetBlobFetcher(ctx context.Context, logID string, spaceDID str
 		return nil, fmt.Errorf("delegated storage not configured - use NewDelegatedManager")
 	}
 
-	client, err := m.clientPool.GetClient(logID, spaceDID, dlg)
+	client, err := m.storageClientFor(logID, spaceDID, dlg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
@@ -466,6 +528,19 @@ func (m *Manager) GetBlobFetcher(ctx context.Context, logID string, spaceDID str
 	return client, nil
 }
 
+// storageClientFor returns the client storing a log's blobs: the configured
+// StorageClient, or the pooled delegated client for the log's space.
+func (m *Manager) storageClientFor(logID string, spaceDID string, dlg delegation.Delegation) (storacha.StorachaClient, error) {
+	if m.storageClient != nil {
+		return m.storageClient, nil
+	}
+	client, err := m.clientPool.GetClient(logID, spaceDID, dlg)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 // BlobFetcher is an interface for fetching blobs by CID.
 type BlobFetcher interface {
 	FetchBlob(ctx context.Context, cid string) ([]byte, error)
@@ -499,7 +574,8 @@ func (m *Manager) restoreLog(ctx context.Context, logID string) (*LogInstance, e
 	}
 
 	// Verify log directory exists
-	logDir := filepath.Join(m.basePath, "logs", logID)
+	// Revocation logs share the directory of their main log
+	logDir := filepath.Join(m.basePath, "logs", strings.TrimSuffix(logID, revocationLogSuffix))
 	if _, err := os.Stat(logDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("log %s not found", logID)
 	}
@@ -520,18 +596,24 @@ func (m *Manager) restoreLog(ctx context.Context, logID string) (*LogInstance, e
 	if envURL := os.Getenv("IPFS_GATEWAY_URL"); envURL != "" {
 		gatewayURL = envURL
 	}
-	readOnlyClient := storacha.NewGatewayClient(gatewayURL)
+	var readOnlyClient storacha.StorachaClient = storacha.NewGatewayClient(gatewayURL)
+	if m.storageClient != nil {
+		readOnlyClient = m.storageClient
+	}
 
 	// Create Storacha driver with read-only client
-	// spaceDID == logID in our architecture
 	// NOTE: Index persistence is disabled for read-only mode - it will be enabled
 	// when the client is upgraded to delegated mode via AddEntryWithDelegation
+	spaceDID := SpaceDIDForLog(logID)
 	driver, err := storacha.New(ctx, storacha.Config{
-		SpaceDID:   logID,
-		StateStore: stateStore,
-		LogDID:     logID,
-		Client:     readOnlyClient,
-		Logger:     m.logger,
+		SpaceDID:     spaceDID,
+		StateStore:   stateStore,
+		LogDID:       logID,
+		Client:       readOnlyClient,
+		GC:           m.gcConfig(),
+		BlobCache:    m.blobCache,
+		OnCheckpoint: m.checkpoints.publish,
+		Logger:       m.logger,
 		// IndexPersistence is nil - disabled for read-only mode
 	})
 	if err != nil {
@@ -539,21 +621,15 @@ func (m *Manager) restoreLog(ctx context.Context, logID string) (*LogInstance, e
 	}
 
 	// Create per-log signer
-	logOrigin := fmt.Sprintf("%s/logs/%s", m.originPrefix, logID)
-	var logSigner Signer
-	if m.privateKey != nil {
-		logSigner, err = NewEd25519Signer(m.privateKey, logOrigin)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create signer for %s: %w", logID, err)
-		}
-	} else {
-		logSigner = m.signer
+	logSigner, err := m.logSigner(logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer for %s: %w", logID, err)
 	}
 
 	opts := tessera.NewAppendOptions().
 		WithCheckpointSigner(logSigner).
 		WithCheckpointInterval(time.Second).
-		WithBatching(1, 100*time.Millisecond).
+		WithBatching(appendBatchMaxSize, appendBatchMaxAge).
 		WithCheckpointRepublishInterval(24 * time.Hour)
 
 	// Configure witness policy if exists
@@ -569,7 +645,7 @@ func (m *Manager) restoreLog(ctx context.Context, logID string) (*LogInstance, e
 		})
 	}
 
-	appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
+	appender, shutdown, reader, err := storacha.NewAppender(ctx, driver, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create appender for %s: %w", logID, err)
 	}
@@ -577,8 +653,9 @@ func (m *Manager) restoreLog(ctx context.Context, logID string) (*LogInstance, e
 	instance := &LogInstance{
 		Appender: appender,
 		Reader:   reader,
+		Shutdown: shutdown,
 		Driver:   driver,
-		SpaceDID: logID,
+		SpaceDID: spaceDID,
 	}
 	m.logs[logID] = instance
 
@@ -659,18 +736,75 @@ func (m *Manager) upgradeLogClient(logID string, client storacha.StorachaClient)
 // The delegation is passed through the context to ensure each write uses its own delegation.
 // If the log was lazily restored with a read-only client, this upgrades it to a delegated client.
 func (m *Manager) AddEntryWithDelegation(ctx context.Context, logID string, data []byte, dlg delegation.Delegation) (uint64, error) {
+	ctx, instance, err := m.prepareDelegatedWrite(ctx, logID, dlg)
+	if err != nil {
+		return 0, err
+	}
+
+	instance.appendMu.RLock()
+	seq, err := m.addEntry(ctx, logID, data)
+	instance.appendMu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// Trigger async index persistence using this delegation
+	// The persistence runs in background; write latency is not affected
+	m.triggerIndexPersistence(ctx, instance)
+
+	return seq, nil
+}
+
+// AddEntriesWithDelegation adds multiple entries to a log using a delegated client.
+// The delegation is validated and the client upgraded once for the whole batch.
+// Entries are sequenced in order and receive a contiguous range of indices;
+// the returned slice holds the index assigned to each entry.
+func (m *Manager) AddEntriesWithDelegation(ctx context.Context, logID string, entries [][]byte, dlg delegation.Delegation) ([]uint64, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("at least one entry is required")
+	}
+	if len(entries) > MaxBatchEntries {
+		return nil, fmt.Errorf("%w: batch of %d entries exceeds the maximum of %d", ErrBatchTooLarge, len(entries), MaxBatchEntries)
+	}
+
+	ctx, instance, err := m.prepareDelegatedWrite(ctx, logID, dlg)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.appendMu.Lock()
+	indices, err := m.addEntriesBatch(ctx, logID, entries)
+	instance.appendMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range indices {
+		if indices[i] != indices[0]+uint64(i) {
+			return nil, fmt.Errorf("batch indices are not contiguous: entry %d has index %d, want %d",
+				i, indices[i], indices[0]+uint64(i))
+		}
+	}
+
+	m.triggerIndexPersistence(ctx, instance)
+
+	return indices, nil
+}
+
+// prepareDelegatedWrite resolves the delegated client for a write, upgrades the
+// log's driver to use it and returns a context carrying the delegation.
+func (m *Manager) prepareDelegatedWrite(ctx context.Context, logID string, dlg delegation.Delegation) (context.Context, *LogInstance, error) {
 	if m.clientPool == nil {
-		return 0, fmt.Errorf("delegated storage not configured")
+		return nil, nil, fmt.Errorf("delegated storage not configured")
 	}
 	if dlg == nil {
-		return 0, fmt.Errorf("delegation required for write operations")
+		return nil, nil, fmt.Errorf("delegation required for write operations")
 	}
 
-	// Get or create delegated client from pool
-	// spaceDID == logID in our architecture
-	client, err := m.clientPool.GetClient(logID, logID, dlg)
+	// Get or create delegated client from pool for the space storing the log
+	client, err := m.storageClientFor(logID, SpaceDIDForLog(logID), dlg)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get delegated client: %w", err)
+		return nil, nil, fmt.Errorf("failed to get delegated client: %w", err)
 	}
 
 	// Upgrade the log's driver to use the delegated client
@@ -678,34 +812,31 @@ func (m *Manager) AddEntryWithDelegation(ctx context.Context, logID string, data
 	if err := m.upgradeLogClient(logID, client); err != nil {
 		// Log might not exist yet - try to restore it first
 		if _, restoreErr := m.GetLogInstance(ctx, logID); restoreErr != nil {
-			return 0, fmt.Errorf("log %s not found: %w", logID, restoreErr)
+			return nil, nil, fmt.Errorf("log %s not found: %w", logID, restoreErr)
 		}
 		// Retry upgrade after restoration
 		if err := m.upgradeLogClient(logID, client); err != nil {
-			return 0, fmt.Errorf("failed to upgrade client: %w", err)
+			return nil, nil, fmt.Errorf("failed to upgrade client: %w", err)
 		}
 	}
 
-	// Add delegation to context for the write operation
-	ctx = storacha.WithDelegation(ctx, dlg)
-
-	seq, err := m.addEntry(ctx, logID, data)
-	if err != nil {
-		return 0, err
-	}
-
-	// Trigger async index persistence using this delegation
-	// The persistence runs in background; write latency is not affected
-	m.mu.Lock()
+	m.mu.RLock()
 	instance, exists := m.logs[logID]
-	m.mu.Unlock()
-	if exists {
-		if storage, ok := instance.Driver.(*storacha.Storage); ok {
-			storage.TriggerIndexPersistence(ctx)
-		}
+	m.mu.RUnlock()
+	if !exists {
+		return nil, nil, fmt.Errorf("log %s not found", logID)
 	}
 
-	return seq, nil
+	// Add delegation to context for the write operation
+	return storacha.WithDelegation(ctx, dlg), instance, nil
+}
+
+// triggerIndexPersistence schedules async index CAR persistence for a log
+// using the delegation carried by ctx.
+func (m *Manager) triggerIndexPersistence(ctx context.Context, instance *LogInstance) {
+	if storage, ok := instance.Driver.(*storacha.Storage); ok {
+		storage.TriggerIndexPersistence(ctx)
+	}
 }
 
 // RunGC runs garbage collection for a log using the provided delegation.
@@ -716,18 +847,17 @@ func (m *Manager) RunGC(ctx context.Context, logID string, dlg delegation.Delega
 		return nil, fmt.Errorf("delegation required for GC operations")
 	}
 
-	// Get the log instance
-	m.mu.RLock()
-	instance, exists := m.logs[logID]
-	m.mu.RUnlock()
-
-	if !exists {
-		// Try to restore the log first
-		var err error
+	// Blobs can only be removed through a delegated client: a log restored
+	// after a restart still reads through the gateway until it is upgraded.
+	var instance *LogInstance
+	var err error
+	if m.clientPool != nil {
+		ctx, instance, err = m.prepareDelegatedWrite(ctx, logID, dlg)
+	} else {
 		instance, err = m.GetLogInstance(ctx, logID)
-		if err != nil {
-			return nil, fmt.Errorf("log %s not found: %w", logID, err)
-		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("log %s not found: %w", logID, err)
 	}
 
 	// Type assert to get the concrete Storage type
@@ -745,6 +875,79 @@ func (m *Manager) RunGC(ctx context.Context, logID string, dlg delegation.Delega
 	return result, nil
 }
 
+// PlanGC lists the blobs the next garbage collection run of a log would
+// remove and their total size, without removing anything.
+func (m *Manager) PlanGC(ctx context.Context, logID string) (*storacha.GCResult, error) {
+	instance, err := m.GetLogInstance(ctx, logID)
+	if err != nil {
+		return nil, fmt.Errorf("log %s not found: %w", logID, err)
+	}
+
+	storage, ok := instance.Driver.(*storacha.Storage)
+	if !ok {
+		return nil, fmt.Errorf("log %s does not use Storacha storage", logID)
+	}
+
+	result, err := storage.PlanGC(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("garbage collection dry run failed for log %s: %w", logID, err)
+	}
+
+	return result, nil
+}
+
+// gcConfig returns a copy of the GC settings for a new log driver.
+func (m *Manager) gcConfig() *gc.Config {
+	cfg := m.gc
+	return &cfg
+}
+
+// FsckReport is the result of an integrity check of a log.
+type FsckReport = storacha.FsckReport
+
+// Fsck checks the integrity of a log's CID index, entry bundles and hash
+// tiles against its tree state and latest checkpoint. When the service's
+// private key is configured, the checkpoint's signature is verified too.
+//
+// With repair set, missing or corrupt hash tiles are re-derived from the
+// entry bundles and uploaded, which requires a delegation for the space.
+func (m *Manager) Fsck(ctx context.Context, logID string, repair bool, dlg delegation.Delegation) (*FsckReport, error) {
+	if repair && dlg == nil {
+		return nil, fmt.Errorf("delegation required to repair log %s", logID)
+	}
+
+	var instance *LogInstance
+	var err error
+	if dlg != nil {
+		ctx, instance, err = m.prepareDelegatedWrite(ctx, logID, dlg)
+	} else {
+		instance, err = m.GetLogInstance(ctx, logID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("log %s not found: %w", logID, err)
+	}
+
+	storage, ok := instance.Driver.(*storacha.Storage)
+	if !ok {
+		return nil, fmt.Errorf("log %s does not use Storacha storage", logID)
+	}
+
+	opts := storacha.FsckOptions{Repair: repair}
+	if m.privateKey != nil {
+		opts.OpenCheckpoint = func(raw []byte) (*log.Checkpoint, error) {
+			return m.verifyCheckpoint(logID, raw)
+		}
+	}
+	report, err := storage.Fsck(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("integrity check failed for log %s: %w", logID, err)
+	}
+	if len(report.Repaired) > 0 {
+		m.triggerIndexPersistence(ctx, instance)
+	}
+	return report, nil
+}
+
 // RecreateAppender recreates the appender for a log to avoid state corruption.
 // It reuses the existing driver to preserve the index persistence manager's
 // connection to the same objStore and its onDirty callback.
@@ -768,23 +971,16 @@ func (m *Manager) RecreateAppender(ctx context.Context, logID string) error {
 	m.logger.Debug("recreate appender called", "logID", logID, "basePath", m.basePath)
 
 	// Create per-log signer with unique origin
-	logOrigin := fmt.Sprintf("%s/logs/%s", m.originPrefix, logID)
-	var logSigner Signer
-	if m.privateKey != nil {
-		var err error
-		logSigner, err = NewEd25519Signer(m.privateKey, logOrigin)
-		if err != nil {
-			return fmt.Errorf("failed to create per-log signer: %w", err)
-		}
-	} else {
-		logSigner = m.signer
+	logSigner, err := m.logSigner(logID)
+	if err != nil {
+		return fmt.Errorf("failed to create per-log signer: %w", err)
 	}
 
 	// For options see https://pkg.go.dev/github.com/transparency-dev/tessera@main#AppendOptions
 	opts := tessera.NewAppendOptions().
 		WithCheckpointSigner(logSigner).
 		WithCheckpointInterval(time.Second).
-		WithBatching(1, 100*time.Millisecond)
+		WithBatching(appendBatchMaxSize, appendBatchMaxAge)
 
 	// Configure witness policy if witness_policy.txt exists
 	witnessPolicyPath := fmt.Sprintf("%s/witness_policy.txt", m.basePath)
@@ -811,7 +1007,7 @@ func (m *Manager) RecreateAppender(ctx context.Context, logID string) error {
 		m.logger.Debug("recreate appender configured witnesses", "logID", logID)
 	}
 
-	appender, _, reader, err := tessera.NewAppender(ctx, driver, opts)
+	appender, shutdown, reader, err := storacha.NewAppender(ctx, driver, opts)
 	if err != nil {
 		return fmt.Errorf("failed to recreate appender: %w", err)
 	}
@@ -819,21 +1015,40 @@ func (m *Manager) RecreateAppender(ctx context.Context, logID string) error {
 	// Update appender/reader, keep the same driver
 	instance.Appender = appender
 	instance.Reader = reader
+	instance.Shutdown = shutdown
 
 	return nil
 }
 
-// addEntriesBatch is an internal method that adds multiple entries sequentially.
-// It expects delegation to already be in the context.
-// External callers should use AddEntryWithDelegation in a loop instead.
+// addEntriesBatch is an internal method that adds multiple entries as one
+// batch, integrated together. It expects delegation to already be in the context.
+// External callers should use AddEntriesWithDelegation instead.
 func (m *Manager) addEntriesBatch(ctx context.Context, logID string, entries [][]byte) ([]uint64, error) {
-	indices := make([]uint64, len(entries))
+	instance, err := m.GetLogInstance(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	storage, ok := instance.Driver.(*storacha.Storage)
+	if !ok {
+		return nil, fmt.Errorf("log %s does not use Storacha storage", logID)
+	}
+
+	batch := make([]*tessera.Entry, len(entries))
 	for i, data := range entries {
-		index, err := m.addEntry(ctx, logID, data)
+		batch[i] = tessera.NewEntry(data)
+	}
+	futures, err := storage.AddBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]uint64, len(entries))
+	for i, future := range futures {
+		index, err := future()
 		if err != nil {
 			return nil, fmt.Errorf("failed to add entry %d: %w", i, err)
 		}
-		indices[i] = index
+		indices[i] = index.Index
 	}
 	return indices, nil
 }
@@ -15,6 +15,8 @@ type LogEntry struct {
 }
 
 // LogEntryWithProof includes the Merkle inclusion proof.
+// InclusionProof is the RFC 6962 audit path encoded as concatenated 32-byte
+// SHA-256 node hashes, ordered from the leaf towards the root.
 type LogEntryWithProof struct {
 	Entry          LogEntry `json:"entry"`
 	InclusionProof []byte   `json:"inclusion_proof"`
@@ -48,3 +50,24 @@ func (e *RevocationEntry) Serialize() ([]byte, error) {
 func (e *RevocationEntry) Deserialize(data []byte) error {
 	return json.Unmarshal(data, e)
 }
+
+// AttributedEntry is the envelope committed to a log in place of the appended
+// data when the submitter asks for the entry's attribution to be part of the
+// log itself rather than only recorded by the service.
+type AttributedEntry struct {
+	Data          []byte    `json:"data"`
+	InvocationCID string    `json:"invocation_cid"`
+	IssuerDID     string    `json:"issuer_did"`
+	DelegationCID string    `json:"delegation_cid"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
+
+// Serialize converts an AttributedEntry to JSON bytes for storage.
+func (e *AttributedEntry) Serialize() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Deserialize populates an AttributedEntry from JSON bytes.
+func (e *AttributedEntry) Deserialize(data []byte) error {
+	return json.Unmarshal(data, e)
+}
@@ -34,6 +34,35 @@ func CapabilityAllows(held, required, resource string) bool {
 	return false
 }
 
+// AbilityCovers reports whether ability a covers ability b: they are equal,
+// a is "*", a is a wildcard such as "space/*" over b, or b is nested under a.
+// Unlike CapabilityAllows it applies to any namespace, not only tlog.
+func AbilityCovers(a, b string) bool {
+	if a == b || a == "*" {
+		return true
+	}
+	prefix := strings.TrimSuffix(a, "*")
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return strings.HasPrefix(b, prefix)
+}
+
+// CoveringAbilities returns every ability that covers ability per
+// AbilityCovers: the ability itself, "*", and each namespace it is nested
+// under, bare or as a wildcard. For "tlog/append" these are "tlog/append",
+// "*", "tlog", "tlog/", "tlog*" and "tlog/*". It lets a lookup match the
+// abilities covering a grant by equality.
+func CoveringAbilities(ability string) []string {
+	covering := []string{ability, "*"}
+	segments := strings.Split(ability, "/")
+	for i := 1; i < len(segments); i++ {
+		prefix := strings.Join(segments[:i], "/")
+		covering = append(covering, prefix, prefix+"/", prefix+"*", prefix+"/*")
+	}
+	return covering
+}
+
 // RequiredCapability returns the required capability for an operation.
 func RequiredCapability(operation string) string {
 	switch operation {
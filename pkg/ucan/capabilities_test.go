@@ -0,0 +1,39 @@
+package ucan
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbilityCovers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"tlog/append", "tlog/append", true},
+		{"*", "space/blob/add", true},
+		{"space/*", "space/blob/add", true},
+		{"space/blob", "space/blob/add", true},
+		{"tlog/*", "space/blob/add", false},
+		{"tlog/append", "tlog/append-batch", false},
+		{"space/blob/add", "space/blob", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, AbilityCovers(tt.a, tt.b), "%s covers %s", tt.a, tt.b)
+	}
+}
+
+func TestCoveringAbilities(t *testing.T) {
+	candidates := []string{
+		"*", "tlog", "tlog/", "tlog*", "tlog/*", "tlog/append", "tlog/append/*",
+		"tlog/append-batch", "tlog/revoke", "space", "space/*", "tlog/append/x",
+	}
+	for _, b := range []string{"tlog/append", "tlog/*", "*", "tlog/append/x"} {
+		covering := CoveringAbilities(b)
+		for _, a := range candidates {
+			assert.Equal(t, AbilityCovers(a, b), slices.Contains(covering, a), "%s covers %s", a, b)
+		}
+	}
+}
@@ -8,6 +8,10 @@ import (
 
 	"github.com/storacha/go-ucanto/core/dag/blockstore"
 	"github.com/storacha/go-ucanto/core/delegation"
+	"github.com/storacha/go-ucanto/core/result/failure"
+	"github.com/storacha/go-ucanto/core/schema"
+	"github.com/storacha/go-ucanto/principal/ed25519/verifier"
+	"github.com/storacha/go-ucanto/validator"
 )
 
 // DelegationError represents an error with delegation validation.
@@ -38,6 +42,7 @@ const (
 	ErrCodeDelegationFetchError        = "DELEGATION_FETCH_ERROR"
 	ErrCodeInvocationNotAuthorized     = "INVOCATION_NOT_AUTHORIZED"
 	ErrCodeDelegationNoAuthority       = "DELEGATION_NO_AUTHORITY"
+	ErrCodeDelegationTimeBounds        = "DELEGATION_INVALID_TIME_BOUNDS"
 	ErrCodeGCDelegationNotDirect       = "GC_DELEGATION_NOT_DIRECT"
 	ErrCodeGCFailed                    = "GC_FAILED"
 )
@@ -276,67 +281,111 @@ func ValidateInvocationAuthority(invocationIssuerDID string, dlg delegation.Dele
 // 2. Chain: Space owner → Agent → Service (proof chain traces to spaceDID)
 // 3. Sub-delegation: Space owner → Agent → FriendB → Service (proof chain traces to spaceDID)
 //
-// Invalid scenario:
+// Invalid scenarios:
 // - Eve creates delegation for Alice's space with no proofs (issuer != spaceDID, no proof chain)
+// - Eve embeds a forged Alice → Eve delegation as proof (signature does not verify)
+// - A proof grants a narrower ability than the delegation passes on (escalation)
+// - A link expires after, or becomes valid before, the proof it derives from
+//
+// Every capability the delegation grants over the space is claimed with
+// go-ucanto's validator, which verifies the signature, principal alignment,
+// time bounds and attenuation of each link until it reaches a delegation
+// self-issued by the space owner.
 func ValidateProofChain(dlg delegation.Delegation, spaceDID string) error {
-	issuerDID := dlg.Issuer().DID().String()
+	if err := verifyDelegationSignature(dlg); err != nil {
+		return err
+	}
 
-	// Case 1: Issuer is the space owner - direct delegation
-	if issuerDID == spaceDID {
-		return nil
+	// Storacha spaces are did:key principals, so the space is its own authority
+	authority, err := verifier.Parse(spaceDID)
+	if err != nil {
+		return NewDelegationError(ErrCodeDelegationNoAuthority,
+			fmt.Sprintf("invalid space DID %s: %v", spaceDID, err))
+	}
+
+	cctx := validator.NewClaimContext(
+		authority,
+		validator.IsSelfIssued[any],
+		func(context.Context, validator.Authorization[any]) validator.Revoked {
+			return nil // Revocations are checked against the revocation log by the handlers
+		},
+		validator.ProofUnavailable,
+		verifier.Parse,
+		validator.FailDIDKeyResolution,
+		validator.NotExpiredNotTooEarly,
+	)
+
+	claimed := 0
+	for _, c := range dlg.Capabilities() {
+		if c.With() != spaceDID {
+			continue
+		}
+		claimed++
+
+		capability := validator.NewCapability(c.Can(), schema.Literal(spaceDID), anyCaveats{}, nil)
+		auth, err := validator.Claim(context.Background(), capability, []delegation.Proof{delegation.FromDelegation(dlg)}, cctx)
+		if err != nil {
+			return NewDelegationError(ErrCodeDelegationNoAuthority,
+				fmt.Sprintf("delegation issuer %s has no authority to delegate %s over space %s (no valid proof chain): %v",
+					dlg.Issuer().DID().String(), c.Can(), spaceDID, err))
+		}
+		if err := validateTimeBoundsNest(auth); err != nil {
+			return err
+		}
 	}
 
-	// Case 2: Check proof chain for authority from space owner
-	if hasAuthorityFromSpace(dlg, spaceDID) {
-		return nil
+	if claimed == 0 {
+		return NewDelegationError(ErrCodeDelegationNoAuthority,
+			fmt.Sprintf("delegation grants no capabilities over space %s", spaceDID))
 	}
 
-	return NewDelegationError(ErrCodeDelegationNoAuthority,
-		fmt.Sprintf("delegation issuer %s has no authority over space %s (no valid proof chain)",
-			issuerDID, spaceDID))
+	return nil
 }
 
-// hasAuthorityFromSpace checks if the delegation has a proof chain back to the space owner.
-func hasAuthorityFromSpace(dlg delegation.Delegation, spaceDID string) bool {
-	proofLinks := dlg.Proofs()
-	if len(proofLinks) == 0 {
-		return false
-	}
-
-	// Create a block reader from the delegation's blocks to resolve proofs
-	bs, err := blockstore.NewBlockReader(blockstore.WithBlocksIterator(dlg.Blocks()))
+// verifyDelegationSignature checks that dlg was signed by its issuer.
+func verifyDelegationSignature(dlg delegation.Delegation) error {
+	issuerDID := dlg.Issuer().DID().String()
+	vfr, err := verifier.Parse(issuerDID)
 	if err != nil {
-		return false
+		return NewDelegationError(ErrCodeDelegationInvalidSignature,
+			fmt.Sprintf("cannot verify signature of delegation issued by %s: %v", issuerDID, err))
 	}
+	if _, err := validator.VerifySignature(dlg, vfr); err != nil {
+		return NewDelegationError(ErrCodeDelegationInvalidSignature,
+			fmt.Sprintf("delegation signature is invalid: %v", err))
+	}
+	return nil
+}
 
-	// Create proofs view to resolve proof delegations
-	proofs := delegation.NewProofsView(proofLinks, bs)
-
-	// Check each proof in the chain
-	for _, proof := range proofs {
-		proofDlg, ok := proof.Delegation()
-		if !ok {
-			continue
-		}
-
-		// Verify this proof is addressed to the current delegation's issuer
-		// (i.e., the proof grants authority to the delegation issuer)
-		if proofDlg.Audience().DID().String() != dlg.Issuer().DID().String() {
-			continue
+// validateTimeBoundsNest checks that each link in an authorized proof chain is
+// only valid within the time window of the proof it derives from.
+func validateTimeBoundsNest(auth validator.Authorization[any]) error {
+	for len(auth.Proofs()) > 0 {
+		child := auth.Delegation()
+		parent := auth.Proofs()[0].Delegation()
+
+		if exp := parent.Expiration(); exp != nil {
+			if child.Expiration() == nil || *child.Expiration() > *exp {
+				return NewDelegationError(ErrCodeDelegationTimeBounds,
+					fmt.Sprintf("delegation %s expires after its proof %s", child.Link(), parent.Link()))
+			}
 		}
-
-		// If the proof issuer is the space owner, we found valid authority
-		if proofDlg.Issuer().DID().String() == spaceDID {
-			return true
+		if child.NotBefore() < parent.NotBefore() {
+			return NewDelegationError(ErrCodeDelegationTimeBounds,
+				fmt.Sprintf("delegation %s becomes valid before its proof %s", child.Link(), parent.Link()))
 		}
 
-		// Recursively check if this proof has authority from the space
-		if hasAuthorityFromSpace(proofDlg, spaceDID) {
-			return true
-		}
+		auth = auth.Proofs()[0]
 	}
+	return nil
+}
 
-	return false
+// anyCaveats accepts any caveats. Caveats on storage capabilities are
+// interpreted by Storacha, not by the log service.
+type anyCaveats struct{}
+
+func (anyCaveats) Read(input any) (any, failure.Failure) {
+	return input, nil
 }
 
 // ValidateRevocationAuthority checks if revokerDID has authority to revoke the delegation.
@@ -397,6 +446,59 @@ func isUpstreamAuthority(revokerDID string, dlg delegation.Delegation) bool {
 	return false
 }
 
+// ValidateScopedRevocationAuthority checks if revokerDID may revoke every
+// delegation of an account or every grant of an ability in a space.
+// Scoped revocations apply regardless of who issued the affected delegations,
+// so only the space owner, or a principal the space owner delegated to
+// directly, may issue them. storageDlg is the revoker's storage delegation;
+// its proof chain shows how the revoker is related to the space.
+func ValidateScopedRevocationAuthority(revokerDID, spaceDID string, storageDlg delegation.Delegation) error {
+	if revokerDID == spaceDID {
+		return nil
+	}
+
+	if isDirectSpaceDelegate(revokerDID, spaceDID, storageDlg) {
+		return nil
+	}
+
+	return NewDelegationError(ErrCodeRevocationNotAuthorized,
+		fmt.Sprintf("principal %s is not authorized to revoke accounts or abilities in space %s (must be the space owner or delegated to directly by it)",
+			revokerDID, spaceDID))
+}
+
+// isDirectSpaceDelegate checks if the proof chain contains a delegation issued
+// by the space owner directly to principalDID. The matching delegation's
+// signature is verified, since the blocks may hold proofs outside the
+// validated chain.
+func isDirectSpaceDelegate(principalDID, spaceDID string, dlg delegation.Delegation) bool {
+	if dlg.Issuer().DID().String() == spaceDID && dlg.Audience().DID().String() == principalDID &&
+		verifyDelegationSignature(dlg) == nil {
+		return true
+	}
+
+	proofLinks := dlg.Proofs()
+	if len(proofLinks) == 0 {
+		return false
+	}
+
+	bs, err := blockstore.NewBlockReader(blockstore.WithBlocksIterator(dlg.Blocks()))
+	if err != nil {
+		return false
+	}
+
+	for _, proof := range delegation.NewProofsView(proofLinks, bs) {
+		proofDlg, ok := proof.Delegation()
+		if !ok {
+			continue
+		}
+		if isDirectSpaceDelegate(principalDID, spaceDID, proofDlg) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ValidateGCDelegation validates a delegation for garbage collection operations.
 // This requires space/blob/remove capability and stricter validation than regular operations.
 //
@@ -432,6 +534,10 @@ func ValidateGCDelegation(dlg delegation.Delegation, serviceDID string, spaceDID
 			fmt.Sprintf("GC delegation must be issued by space owner %s, but was issued by %s", spaceDID, issuerDID))
 	}
 
+	if err := verifyDelegationSignature(dlg); err != nil {
+		return err
+	}
+
 	// Verify no proof chain (direct delegation only)
 	proofLinks := dlg.Proofs()
 	if len(proofLinks) > 0 {
@@ -453,6 +453,33 @@ func TestValidateRevocationAuthority(t *testing.T) {
 	})
 }
 
+func TestValidateScopedRevocationAuthority(t *testing.T) {
+	// SpaceOwner -> UserB -> UserC -> Service
+	spaceOwner, userB, userC, service, _, _, cToService := createDelegationChain(t)
+	spaceDID := spaceOwner.DID().String()
+
+	t.Run("space owner can revoke", func(t *testing.T) {
+		err := ValidateScopedRevocationAuthority(spaceDID, spaceDID, cToService)
+		assert.NoError(t, err)
+	})
+
+	t.Run("direct delegate of the space owner can revoke", func(t *testing.T) {
+		err := ValidateScopedRevocationAuthority(userB.DID().String(), spaceDID, cToService)
+		assert.NoError(t, err)
+	})
+
+	t.Run("indirect delegates cannot revoke", func(t *testing.T) {
+		for _, principal := range []string{userC.DID().String(), service.DID().String()} {
+			err := ValidateScopedRevocationAuthority(principal, spaceDID, cToService)
+			assert.Error(t, err)
+
+			var dlgErr *DelegationError
+			assert.ErrorAs(t, err, &dlgErr)
+			assert.Equal(t, ErrCodeRevocationNotAuthorized, dlgErr.Code)
+		}
+	})
+}
+
 func TestValidateInvocationAuthority(t *testing.T) {
 	// Create test signers
 	aliceSigner, _ := signer.Generate()
@@ -680,6 +707,135 @@ func TestValidateProofChain(t *testing.T) {
 	})
 }
 
+// impersonator signs with its own key but claims another principal's DID.
+type impersonator struct {
+	principal.Signer
+	did did.DID
+}
+
+func (i impersonator) DID() did.DID {
+	return i.did
+}
+
+func TestValidateProofChain_VerifiesEveryLink(t *testing.T) {
+	spaceOwnerSigner, _ := signer.Generate()
+	agentSigner, _ := signer.Generate()
+	serviceSigner, _ := signer.Generate()
+	eveSigner, _ := signer.Generate()
+
+	spaceDID := spaceOwnerSigner.DID().String()
+
+	delegateToService := func(t *testing.T, issuer principal.Signer, can string, proof delegation.Delegation, opts ...delegation.Option) delegation.Delegation {
+		opts = append(opts, delegation.WithProof(delegation.FromDelegation(proof)))
+		dlg, err := delegation.Delegate(
+			issuer,
+			serviceSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability(can, spaceDID, ucan.NoCaveats{}),
+			},
+			opts...,
+		)
+		require.NoError(t, err)
+		return dlg
+	}
+
+	requireCode := func(t *testing.T, err error, code string) {
+		require.Error(t, err)
+		var dlgErr *DelegationError
+		require.ErrorAs(t, err, &dlgErr)
+		assert.Equal(t, code, dlgErr.Code)
+	}
+
+	t.Run("forged space owner proof is rejected", func(t *testing.T) {
+		// Eve signs a "space owner" → Eve delegation with her own key
+		forged, err := delegation.Delegate(
+			impersonator{Signer: eveSigner, did: spaceOwnerSigner.DID()},
+			eveSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, spaceDID, forged.Issuer().DID().String())
+
+		err = ValidateProofChain(delegateToService(t, eveSigner, "space/blob/add", forged), spaceDID)
+		requireCode(t, err, ErrCodeDelegationNoAuthority)
+	})
+
+	t.Run("forged top-level delegation is rejected", func(t *testing.T) {
+		forged, err := delegation.Delegate(
+			impersonator{Signer: eveSigner, did: spaceOwnerSigner.DID()},
+			serviceSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+
+		err = ValidateProofChain(forged, spaceDID)
+		requireCode(t, err, ErrCodeDelegationInvalidSignature)
+	})
+
+	t.Run("escalated capability is rejected", func(t *testing.T) {
+		ownerToAgent, err := delegation.Delegate(
+			spaceOwnerSigner,
+			agentSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+
+		// Agent passes on an ability it was never granted
+		err = ValidateProofChain(delegateToService(t, agentSigner, "space/index/add", ownerToAgent), spaceDID)
+		requireCode(t, err, ErrCodeDelegationNoAuthority)
+	})
+
+	t.Run("wildcard grant covers attenuated capability", func(t *testing.T) {
+		ownerToAgent, err := delegation.Delegate(
+			spaceOwnerSigner,
+			agentSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/*", spaceDID, ucan.NoCaveats{}),
+			},
+		)
+		require.NoError(t, err)
+
+		err = ValidateProofChain(delegateToService(t, agentSigner, "space/blob/add", ownerToAgent), spaceDID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired proof is rejected", func(t *testing.T) {
+		ownerToAgent, err := delegation.Delegate(
+			spaceOwnerSigner,
+			agentSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+			},
+			delegation.WithExpiration(int(time.Now().Add(-time.Minute).Unix())),
+		)
+		require.NoError(t, err)
+
+		err = ValidateProofChain(delegateToService(t, agentSigner, "space/blob/add", ownerToAgent), spaceDID)
+		requireCode(t, err, ErrCodeDelegationNoAuthority)
+	})
+
+	t.Run("link outliving its proof is rejected", func(t *testing.T) {
+		ownerToAgent, err := delegation.Delegate(
+			spaceOwnerSigner,
+			agentSigner.DID(),
+			[]ucan.Capability[ucan.NoCaveats]{
+				ucan.NewCapability("space/blob/add", spaceDID, ucan.NoCaveats{}),
+			},
+			delegation.WithExpiration(int(time.Now().Add(time.Hour).Unix())),
+		)
+		require.NoError(t, err)
+
+		err = ValidateProofChain(delegateToService(t, agentSigner, "space/blob/add", ownerToAgent, delegation.WithNoExpiration()), spaceDID)
+		requireCode(t, err, ErrCodeDelegationTimeBounds)
+	})
+}
+
 func TestValidateGCDelegation(t *testing.T) {
 	// Create test signers
 	spaceOwnerSigner, _ := signer.Generate()
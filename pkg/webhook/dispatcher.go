@@ -0,0 +1,552 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/storacha/go-ucanto/principal"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+const (
+	// DefaultMaxAttempts is the number of delivery attempts after which a
+	// notification is dropped.
+	DefaultMaxAttempts = 10
+
+	// DefaultPollInterval is how often the outbox is checked for retries
+	// that have become due.
+	DefaultPollInterval = 5 * time.Second
+
+	// DefaultSweepInterval is how often logs are checked for entries that
+	// have not been reported, e.g. because their checkpoint was missed.
+	DefaultSweepInterval = time.Minute
+
+	// DefaultWorkers is the number of logs whose outboxes are delivered
+	// concurrently.
+	DefaultWorkers = 8
+
+	// maxBackoff caps the delay between delivery attempts.
+	maxBackoff = time.Hour
+
+	// deliveryTimeout bounds a single delivery attempt.
+	deliveryTimeout = 10 * time.Second
+
+	// deliveryBatch is the number of due deliveries read from an outbox at once.
+	deliveryBatch = 100
+
+	// maxEventEntries bounds the entries in one append event. Larger appends
+	// are split across several events sharing the same head.
+	maxEventEntries = 1000
+)
+
+// LogReader provides the checkpoints and entries append events are built from.
+type LogReader interface {
+	SubscribeCheckpoints(logIDs ...string) (<-chan tlog.CheckpointEvent, func())
+	ReadCheckpoint(ctx context.Context, logID string) ([]byte, error)
+	ReadRange(ctx context.Context, logID string, startIndex, endIndex uint64) ([][]byte, error)
+}
+
+// Config configures a Dispatcher.
+type Config struct {
+	// StoreManager holds the webhooks and outbox of each log (required).
+	StoreManager *sqlite.StoreManager
+
+	// Logs is the source of append events. Without it only revocation and
+	// GC events are delivered.
+	Logs LogReader
+
+	// Signer signs payloads of webhooks using SigningUCAN.
+	Signer principal.Signer
+
+	// HTTPClient sends deliveries. Defaults to NewHTTPClient, which only
+	// connects to public addresses and does not follow redirects.
+	HTTPClient *http.Client
+
+	// MaxAttempts defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// PollInterval defaults to DefaultPollInterval.
+	PollInterval time.Duration
+
+	// SweepInterval defaults to DefaultSweepInterval.
+	SweepInterval time.Duration
+
+	// Workers defaults to DefaultWorkers.
+	Workers int
+
+	Logger *slog.Logger
+}
+
+// Dispatcher queues log events in the outbox of each log and delivers them
+// to the log's webhooks. Each log's outbox is delivered by one of a bounded
+// number of workers, so a slow endpoint only holds up its own log.
+type Dispatcher struct {
+	cfg     Config
+	wake    chan struct{}
+	workers chan struct{} // Holds a token for each log being delivered
+
+	mu      sync.Mutex
+	pending map[string]bool // Logs whose outbox may hold deliveries
+	active  map[string]bool // Logs being delivered by a worker
+}
+
+// NewDispatcher creates a Dispatcher. Call Start to begin delivering.
+func NewDispatcher(cfg Config) (*Dispatcher, error) {
+	if cfg.StoreManager == nil {
+		return nil, fmt.Errorf("StoreManager is required")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = NewHTTPClient()
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = DefaultSweepInterval
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Dispatcher{
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		workers: make(chan struct{}, cfg.Workers),
+		pending: make(map[string]bool),
+		active:  make(map[string]bool),
+	}, nil
+}
+
+// SignerDID returns the DID UCAN-signed payloads verify against, or an empty
+// string if UCAN signing is not configured.
+func (d *Dispatcher) SignerDID() string {
+	if d.cfg.Signer == nil {
+		return ""
+	}
+	return d.cfg.Signer.DID().String()
+}
+
+// Start resumes delivery of the notifications left in the outboxes by a
+// previous run and delivers new ones until ctx is done.
+func (d *Dispatcher) Start(ctx context.Context) error {
+	logDIDs, err := d.cfg.StoreManager.LogDIDs()
+	if err != nil {
+		return fmt.Errorf("failed to list logs: %w", err)
+	}
+	for _, logDID := range logDIDs {
+		store, err := d.cfg.StoreManager.GetStore(logDID)
+		if err != nil {
+			return fmt.Errorf("failed to get store for %s: %w", logDID, err)
+		}
+		n, err := store.CountWebhookDeliveries(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to count webhook deliveries for %s: %w", logDID, err)
+		}
+		if n > 0 {
+			d.pending[logDID] = true
+		}
+	}
+
+	if d.cfg.Logs != nil {
+		checkpoints, cancel := d.cfg.Logs.SubscribeCheckpoints()
+		go d.watchAppends(ctx, checkpoints, cancel)
+	}
+	go d.run(ctx)
+	return nil
+}
+
+// Emit queues an event for the webhooks of a log subscribed to its type.
+func (d *Dispatcher) Emit(ctx context.Context, logID, eventType string, data any) error {
+	store, err := d.cfg.StoreManager.GetStore(logID)
+	if err != nil {
+		return fmt.Errorf("failed to get store: %w", err)
+	}
+	body, ev, err := NewEvent(logID, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	n, err := store.EnqueueWebhookEvent(ctx, logID, ev.ID, eventType, body)
+	if n > 0 {
+		d.pending[logID] = true
+	}
+	d.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to queue %s event: %w", eventType, err)
+	}
+
+	if n > 0 {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run hands the logs with due notifications to workers whenever an event is
+// queued, a worker finishes or the poll interval elapses. A log is delivered
+// by at most one worker at a time.
+func (d *Dispatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.mu.Lock()
+		logDIDs := make([]string, 0, len(d.pending))
+		for logDID := range d.pending {
+			if !d.active[logDID] {
+				logDIDs = append(logDIDs, logDID)
+			}
+		}
+		d.mu.Unlock()
+
+		for _, logDID := range logDIDs {
+			select {
+			case <-ctx.Done():
+				return
+			case d.workers <- struct{}{}:
+			}
+			d.mu.Lock()
+			d.active[logDID] = true
+			d.mu.Unlock()
+			go d.work(ctx, logDID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// work delivers a log's outbox, then releases its worker and wakes run, as
+// events queued meanwhile were not handed to another worker.
+func (d *Dispatcher) work(ctx context.Context, logDID string) {
+	defer func() {
+		d.mu.Lock()
+		delete(d.active, logDID)
+		d.mu.Unlock()
+		<-d.workers
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}()
+	if err := d.deliverDue(ctx, logDID); err != nil && ctx.Err() == nil {
+		d.cfg.Logger.Warn("failed to deliver webhooks", "log", logDID, "error", err)
+	}
+}
+
+// deliverDue attempts every due delivery in a log's outbox, and forgets the
+// log once its outbox is empty. After a failed attempt, the webhook's other
+// deliveries wait for its retry instead of being attempted in the same pass.
+func (d *Dispatcher) deliverDue(ctx context.Context, logDID string) error {
+	store, err := d.cfg.StoreManager.GetStore(logDID)
+	if err != nil {
+		return err
+	}
+
+	failed := make(map[string]bool) // Webhooks that failed in this pass
+	for {
+		due, err := store.DueWebhookDeliveries(ctx, time.Now(), deliveryBatch)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range due {
+			if failed[delivery.Webhook.ID] {
+				continue // Postponed by the failed attempt
+			}
+			ok, err := d.deliver(ctx, store, logDID, delivery)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				failed[delivery.Webhook.ID] = true
+			}
+		}
+		if len(due) < deliveryBatch {
+			break
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n, err := store.CountWebhookDeliveries(ctx, "")
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		delete(d.pending, logDID)
+	}
+	return nil
+}
+
+// deliver makes one attempt at a delivery, then removes it from the outbox
+// or schedules a retry, and reports whether it succeeded. After a failure
+// the webhook's other due deliveries are postponed by the same backoff.
+// Errors are only returned for outbox updates.
+func (d *Dispatcher) deliver(ctx context.Context, store *sqlite.LogStore, logDID string, delivery sqlite.WebhookDelivery) (bool, error) {
+	err := d.post(ctx, delivery)
+	if err == nil {
+		return true, store.CompleteWebhookDelivery(ctx, delivery.ID)
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	attempts := delivery.Attempts + 1
+	next := time.Now().Add(backoff(attempts))
+	if attempts >= d.cfg.MaxAttempts {
+		d.cfg.Logger.Warn("dropping webhook delivery",
+			"log", logDID, "webhook", delivery.Webhook.ID, "event", delivery.EventID,
+			"attempts", attempts, "error", err)
+		if err := store.CompleteWebhookDelivery(ctx, delivery.ID); err != nil {
+			return false, err
+		}
+	} else {
+		d.cfg.Logger.Debug("webhook delivery failed",
+			"log", logDID, "webhook", delivery.Webhook.ID, "event", delivery.EventID,
+			"attempts", attempts, "error", err)
+		if err := store.RetryWebhookDelivery(ctx, delivery.ID, next, err.Error()); err != nil {
+			return false, err
+		}
+	}
+	return false, store.PostponeWebhookDeliveries(ctx, delivery.Webhook.ID, next)
+}
+
+// backoff returns the delay before the next attempt after a number of failed
+// attempts: 2s, 4s, 8s... up to maxBackoff.
+func backoff(attempts int) time.Duration {
+	if attempts >= 12 {
+		return maxBackoff
+	}
+	return min(time.Duration(1<<attempts)*time.Second, maxBackoff)
+}
+
+// post sends a signed delivery to its webhook.
+func (d *Dispatcher) post(ctx context.Context, delivery sqlite.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+
+	switch delivery.Webhook.Signing {
+	case SigningHMAC:
+		sig, err := SignHMAC(delivery.Webhook.Secret, delivery.Payload)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(HeaderSignature, sig)
+	case SigningUCAN:
+		if d.cfg.Signer == nil {
+			return fmt.Errorf("UCAN signing is not configured")
+		}
+		req.Header.Set(HeaderSignature, SignUCAN(d.cfg.Signer, delivery.Payload))
+		req.Header.Set(HeaderSigner, d.cfg.Signer.DID().String())
+	default:
+		return fmt.Errorf("unknown signing scheme %q", delivery.Webhook.Signing)
+	}
+
+	resp, err := d.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// watchAppends queues append events for each checkpoint published. The
+// checkpoint subscription drops checkpoints when it falls behind, so logs are
+// also swept at startup and every SweepInterval for entries not yet reported.
+// Both run on this goroutine, so a log's append events are queued in order.
+func (d *Dispatcher) watchAppends(ctx context.Context, checkpoints <-chan tlog.CheckpointEvent, cancel func()) {
+	defer cancel()
+	ticker := time.NewTicker(d.cfg.SweepInterval)
+	defer ticker.Stop()
+	for {
+		d.sweepAppends(ctx)
+		for swept := false; !swept; {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-checkpoints:
+				if !ok {
+					return
+				}
+				if err := d.queueAppends(ctx, ev); err != nil && ctx.Err() == nil {
+					d.cfg.Logger.Warn("failed to queue append events", "log", ev.LogID, "size", ev.TreeSize, "error", err)
+				}
+			case <-ticker.C:
+				swept = true
+			}
+		}
+	}
+}
+
+// sweepAppends queues append events for every log whose webhook cursor is
+// behind its integrated tree size, up to the log's latest checkpoint.
+func (d *Dispatcher) sweepAppends(ctx context.Context) {
+	logDIDs, err := d.cfg.StoreManager.LogDIDs()
+	if err != nil {
+		d.cfg.Logger.Warn("failed to list logs", "error", err)
+		return
+	}
+	for _, logDID := range logDIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := d.catchUp(ctx, logDID); err != nil && ctx.Err() == nil {
+			d.cfg.Logger.Warn("failed to queue append events", "log", logDID, "error", err)
+		}
+	}
+}
+
+// catchUp queues append events for a log up to its latest checkpoint if its
+// webhook cursor is behind the tree state persisted at integration.
+func (d *Dispatcher) catchUp(ctx context.Context, logDID string) error {
+	if tlog.IsRevocationLog(logDID) {
+		return nil
+	}
+	store, err := d.cfg.StoreManager.GetStore(logDID)
+	if err != nil {
+		return err
+	}
+	cursor, err := store.GetWebhookCursor(ctx, logDID)
+	if errors.Is(err, sqlite.ErrNotFound) {
+		return nil // No webhook was ever added
+	}
+	if err != nil {
+		return err
+	}
+	size, _, err := store.GetTreeState(ctx, logDID)
+	if err != nil {
+		return err
+	}
+	if size <= cursor {
+		return nil
+	}
+
+	raw, err := d.cfg.Logs.ReadCheckpoint(ctx, logDID)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	cp, err := tlog.ParseCheckpoint(raw)
+	if err != nil {
+		return err
+	}
+	return d.queueAppends(ctx, tlog.CheckpointEvent{LogID: logDID, TreeSize: cp.Size, Checkpoint: raw})
+}
+
+// queueAppends queues append events for the entries between the log's webhook
+// cursor and a published checkpoint. Entries are only reported once a
+// checkpoint includes them, so each event carries a head that proves them.
+// The cursor is persisted, so entries integrated while events could not be
+// queued are reported with the next checkpoint or sweep. LogService.AddWebhook
+// starts the cursor at the log's size; without one, the log's entries are
+// reported from the start.
+func (d *Dispatcher) queueAppends(ctx context.Context, ev tlog.CheckpointEvent) error {
+	if tlog.IsRevocationLog(ev.LogID) {
+		return nil // Revocations are reported by LogService
+	}
+	store, err := d.cfg.StoreManager.GetStore(ev.LogID)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := store.GetWebhookCursor(ctx, ev.LogID)
+	noCursor := errors.Is(err, sqlite.ErrNotFound)
+	if err != nil && !noCursor {
+		return err
+	}
+	if ev.TreeSize <= cursor {
+		return nil
+	}
+
+	webhooks, err := store.ListWebhooks(ctx, ev.LogID)
+	if err != nil {
+		return err
+	}
+	if !subscribed(webhooks, EventAppend) {
+		if noCursor {
+			return nil // No webhook was ever added
+		}
+		return store.SetWebhookCursor(ctx, ev.LogID, ev.TreeSize)
+	}
+
+	cp, err := tlog.ParseCheckpoint(ev.Checkpoint)
+	if err != nil {
+		return err
+	}
+	head := Head{
+		TreeSize:   cp.Size,
+		RootHash:   cp.Hash,
+		Checkpoint: string(ev.Checkpoint),
+	}
+
+	for from := cursor; from < ev.TreeSize; {
+		to := min(from+maxEventEntries, ev.TreeSize)
+		data, err := d.cfg.Logs.ReadRange(ctx, ev.LogID, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to read entries %d-%d: %w", from, to, err)
+		}
+		if uint64(len(data)) != to-from {
+			return fmt.Errorf("read %d entries from %d, want %d", len(data), from, to-from)
+		}
+		entries := make([]AppendedEntry, len(data))
+		for i, entry := range data {
+			entries[i] = AppendedEntry{
+				Index:    from + uint64(i),
+				LeafHash: rfc6962.DefaultHasher.HashLeaf(entry),
+			}
+		}
+		if err := d.Emit(ctx, ev.LogID, EventAppend, AppendData{Entries: entries, Head: head}); err != nil {
+			return err
+		}
+		if err := store.SetWebhookCursor(ctx, ev.LogID, to); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// subscribed reports whether any of webhooks subscribes to event.
+func subscribed(webhooks []sqlite.Webhook, event string) bool {
+	for _, w := range webhooks {
+		for _, e := range w.Events {
+			if e == event {
+				return true
+			}
+		}
+	}
+	return false
+}
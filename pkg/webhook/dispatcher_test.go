@@ -0,0 +1,513 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/storacha/go-ucanto/principal/ed25519/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	"github.com/relves/ucanlog/internal/storage/sqlite"
+	"github.com/relves/ucanlog/pkg/tlog"
+)
+
+// delivery is a notification received by a test endpoint.
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a test webhook endpoint answering with status.
+type receiver struct {
+	deliveries chan delivery
+}
+
+// loopbackClient delivers like NewHTTPClient, but to the loopback test
+// receivers too.
+var loopbackClient = newHTTPClient(nil)
+
+func newReceiver(t *testing.T, status int) (*receiver, string) {
+	r := &receiver{deliveries: make(chan delivery, 16)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.deliveries <- delivery{header: req.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv.URL
+}
+
+func (r *receiver) next(t *testing.T) delivery {
+	t.Helper()
+	select {
+	case d := <-r.deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivered")
+		return delivery{}
+	}
+}
+
+func newTestStore(t *testing.T, manager *sqlite.StoreManager, logID string) *sqlite.LogStore {
+	store, err := manager.GetStore(logID)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateLogRecord(context.Background(), logID))
+	return store
+}
+
+func addTestWebhook(t *testing.T, store *sqlite.LogStore, logID, url, signing string, events ...string) sqlite.Webhook {
+	id, err := NewID()
+	require.NoError(t, err)
+	w := sqlite.Webhook{
+		ID:        id,
+		URL:       url,
+		Events:    events,
+		Signing:   signing,
+		CreatedBy: "did:key:z6MkOwner",
+		CreatedAt: time.Now(),
+	}
+	if signing == SigningHMAC {
+		w.Secret, err = NewSecret()
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.AddWebhook(context.Background(), logID, w))
+	return w
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	serviceSigner, err := signer.Generate()
+	require.NoError(t, err)
+	hmacRecv, hmacURL := newReceiver(t, http.StatusOK)
+	ucanRecv, ucanURL := newReceiver(t, http.StatusNoContent)
+	hmacHook := addTestWebhook(t, store, logID, hmacURL, SigningHMAC, EventGC)
+	addTestWebhook(t, store, logID, ucanURL, SigningUCAN, EventRevocation, EventGC)
+
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient, Signer: serviceSigner})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+
+	require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{BlobsRemoved: 3, GCPosition: 256}))
+
+	got := hmacRecv.next(t)
+	assert.Equal(t, EventGC, got.header.Get(HeaderEvent))
+	assert.NoError(t, VerifyHMAC(hmacHook.Secret, got.header.Get(HeaderSignature), got.body))
+	assert.Error(t, VerifyHMAC(hmacHook.Secret, got.header.Get(HeaderSignature), append(got.body, ' ')))
+	var ev Event
+	require.NoError(t, json.Unmarshal(got.body, &ev))
+	assert.Equal(t, got.header.Get(HeaderDelivery), ev.ID)
+	assert.Equal(t, logID, ev.LogID)
+	var data GCData
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	assert.Equal(t, GCData{BlobsRemoved: 3, GCPosition: 256}, data)
+
+	got = ucanRecv.next(t)
+	assert.Equal(t, serviceSigner.DID().String(), got.header.Get(HeaderSigner))
+	assert.NoError(t, VerifyUCAN(serviceSigner.DID().String(), got.header.Get(HeaderSignature), got.body))
+	other, err := signer.Generate()
+	require.NoError(t, err)
+	assert.Error(t, VerifyUCAN(other.DID().String(), got.header.Get(HeaderSignature), got.body))
+
+	// Events are only delivered to subscribed webhooks
+	require.NoError(t, d.Emit(ctx, logID, EventRevocation, RevocationData{Type: "ucan", Target: "bafyRevoked"}))
+	got = ucanRecv.next(t)
+	assert.Equal(t, EventRevocation, got.header.Get(HeaderEvent))
+	select {
+	case <-hmacRecv.deliveries:
+		t.Fatal("revocation delivered to a webhook not subscribed to it")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.Eventually(t, func() bool {
+		n, err := store.CountWebhookDeliveries(ctx, "")
+		return err == nil && n == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcher_RetriesAndDrops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	recv, url := newReceiver(t, http.StatusInternalServerError)
+	w := addTestWebhook(t, store, logID, url, SigningHMAC, EventGC)
+
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient, MaxAttempts: 2, PollInterval: 100 * time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+	require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{}))
+
+	// A failed attempt is rescheduled with backoff
+	first := recv.next(t)
+	var pending []sqlite.WebhookDelivery
+	require.Eventually(t, func() bool {
+		pending, err = store.DueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 10)
+		return err == nil && len(pending) == 1 && pending[0].Attempts == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, w.ID, pending[0].Webhook.ID)
+	assert.Equal(t, "webhook returned status 500", pending[0].LastError)
+	due, err := store.DueWebhookDeliveries(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// The retry carries the same event, and is dropped after MaxAttempts
+	second := recv.next(t)
+	assert.Equal(t, first.header.Get(HeaderDelivery), second.header.Get(HeaderDelivery))
+	assert.Equal(t, first.body, second.body)
+	require.Eventually(t, func() bool {
+		n, err := store.CountWebhookDeliveries(ctx, "")
+		return err == nil && n == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcher_PostponesFailingWebhook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	recv, url := newReceiver(t, http.StatusInternalServerError)
+	addTestWebhook(t, store, logID, url, SigningHMAC, EventGC)
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{BundlesProcessed: i}))
+	}
+	require.NoError(t, d.Start(ctx))
+
+	// The first failure postpones the webhook's other deliveries
+	recv.next(t)
+	require.Eventually(t, func() bool {
+		due, err := store.DueWebhookDeliveries(ctx, time.Now(), 10)
+		return err == nil && len(due) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	n, err := store.CountWebhookDeliveries(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	select {
+	case <-recv.deliveries:
+		t.Fatal("postponed delivery attempted")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDispatcher_SlowEndpointDoesNotBlockOtherLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slowLog := "did:key:z6MkSlowLog"
+	fastLog := "did:key:z6MkFastLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+
+	// The slow endpoint never answers within the delivery timeout
+	hung := make(chan struct{}, 16)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hung <- struct{}{}
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	addTestWebhook(t, newTestStore(t, manager, slowLog), slowLog, srv.URL, SigningHMAC, EventGC)
+	recv, url := newReceiver(t, http.StatusOK)
+	addTestWebhook(t, newTestStore(t, manager, fastLog), fastLog, url, SigningHMAC, EventGC)
+
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+
+	require.NoError(t, d.Emit(ctx, slowLog, EventGC, GCData{}))
+	select {
+	case <-hung:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow endpoint not called")
+	}
+
+	require.NoError(t, d.Emit(ctx, fastLog, EventGC, GCData{}))
+	var ev Event
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	assert.Equal(t, fastLog, ev.LogID)
+}
+
+func TestDispatcher_ResumesOutboxAfterRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog/evidence"
+	basePath := t.TempDir()
+
+	// Queue an event without delivering it, as if the service stopped
+	manager := sqlite.NewStoreManager(basePath)
+	store := newTestStore(t, manager, logID)
+	recv, url := newReceiver(t, http.StatusOK)
+	addTestWebhook(t, store, logID, url, SigningHMAC, EventGC)
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient})
+	require.NoError(t, err)
+	require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{BundlesProcessed: 1}))
+	require.NoError(t, manager.CloseAll())
+
+	manager = sqlite.NewStoreManager(basePath)
+	defer manager.CloseAll()
+	d, err = NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+
+	var ev Event
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	assert.Equal(t, logID, ev.LogID)
+	assert.Equal(t, EventGC, ev.Type)
+}
+
+// fakeLogReader publishes checkpoints and serves entries "entry-{index}".
+// Its latest checkpoint has size latest.
+type fakeLogReader struct {
+	checkpoints chan tlog.CheckpointEvent
+	latest      atomic.Uint64
+}
+
+func (r *fakeLogReader) SubscribeCheckpoints(logIDs ...string) (<-chan tlog.CheckpointEvent, func()) {
+	return r.checkpoints, func() {}
+}
+
+func (r *fakeLogReader) ReadCheckpoint(ctx context.Context, logID string) ([]byte, error) {
+	return testCheckpoint(logID, r.latest.Load()).Checkpoint, nil
+}
+
+func (r *fakeLogReader) ReadRange(ctx context.Context, logID string, startIndex, endIndex uint64) ([][]byte, error) {
+	var entries [][]byte
+	for i := startIndex; i < endIndex; i++ {
+		entries = append(entries, []byte(fmt.Sprintf("entry-%d", i)))
+	}
+	return entries, nil
+}
+
+func testCheckpoint(logID string, size uint64) tlog.CheckpointEvent {
+	root := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	cp := fmt.Sprintf("test/logs/%s\n%d\n%s\n\n— test sig\n", logID, size, root)
+	return tlog.CheckpointEvent{LogID: logID, TreeSize: size, Checkpoint: []byte(cp)}
+}
+
+func TestDispatcher_AppendEventsFromCheckpoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	recv, url := newReceiver(t, http.StatusOK)
+	addTestWebhook(t, store, logID, url, SigningHMAC, EventAppend)
+	logs := &fakeLogReader{checkpoints: make(chan tlog.CheckpointEvent)}
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient, Logs: logs})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+
+	// Without a cursor, entries are reported from the start of the log
+	logs.checkpoints <- testCheckpoint(logID, 2)
+	logs.checkpoints <- testCheckpoint(logID+"-revocations", 1)
+	logs.checkpoints <- testCheckpoint(logID, 5)
+
+	var ev Event
+	got := recv.next(t)
+	assert.Equal(t, EventAppend, got.header.Get(HeaderEvent))
+	require.NoError(t, json.Unmarshal(got.body, &ev))
+	var data AppendData
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	require.Len(t, data.Entries, 2)
+	assert.Equal(t, uint64(0), data.Entries[0].Index)
+	assert.Equal(t, uint64(1), data.Entries[1].Index)
+	assert.Equal(t, uint64(2), data.Head.TreeSize)
+
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	require.Len(t, data.Entries, 3)
+	for i, entry := range data.Entries {
+		index := uint64(2 + i)
+		assert.Equal(t, index, entry.Index)
+		assert.Equal(t, rfc6962.DefaultHasher.HashLeaf([]byte(fmt.Sprintf("entry-%d", index))), entry.LeafHash)
+	}
+	assert.Equal(t, uint64(5), data.Head.TreeSize)
+	assert.Equal(t, make([]byte, 32), data.Head.RootHash)
+	assert.Equal(t, string(testCheckpoint(logID, 5).Checkpoint), data.Head.Checkpoint)
+
+	// Checkpoints already reported are skipped
+	logs.checkpoints <- testCheckpoint(logID, 5)
+	logs.checkpoints <- testCheckpoint(logID, 6)
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	require.Len(t, data.Entries, 1)
+	assert.Equal(t, uint64(5), data.Entries[0].Index)
+
+	cursor, err := store.GetWebhookCursor(ctx, logID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), cursor)
+}
+
+func TestDispatcher_SweepsMissedCheckpoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	// The webhook was added when the log held 3 entries
+	recv, url := newReceiver(t, http.StatusOK)
+	addTestWebhook(t, store, logID, url, SigningHMAC, EventAppend)
+	require.NoError(t, store.SetWebhookCursor(ctx, logID, 3))
+
+	// Entries were integrated and checkpointed, but no checkpoint was received
+	require.NoError(t, store.SetTreeState(ctx, logID, 5, make([]byte, 32)))
+	logs := &fakeLogReader{checkpoints: make(chan tlog.CheckpointEvent)}
+	logs.latest.Store(5)
+	d, err := NewDispatcher(Config{
+		StoreManager:  manager,
+		HTTPClient:    loopbackClient,
+		Logs:          logs,
+		SweepInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+
+	var ev Event
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	var data AppendData
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	require.Len(t, data.Entries, 2)
+	assert.Equal(t, uint64(3), data.Entries[0].Index)
+	assert.Equal(t, uint64(5), data.Head.TreeSize)
+
+	// Entries integrated but not yet checkpointed are reported once they are
+	require.NoError(t, store.SetTreeState(ctx, logID, 6, make([]byte, 32)))
+	time.Sleep(50 * time.Millisecond)
+	logs.latest.Store(6)
+	require.NoError(t, json.Unmarshal(recv.next(t).body, &ev))
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	require.Len(t, data.Entries, 1)
+	assert.Equal(t, uint64(5), data.Entries[0].Index)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, backoff(1))
+	assert.Equal(t, 8*time.Second, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(12))
+	assert.Equal(t, maxBackoff, backoff(100))
+}
+
+func TestValidateEvents(t *testing.T) {
+	events, err := ValidateEvents(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Events, events)
+
+	events, err = ValidateEvents([]string{EventGC, EventAppend, EventGC})
+	require.NoError(t, err)
+	assert.Equal(t, []string{EventGC, EventAppend}, events)
+
+	_, err = ValidateEvents([]string{"delete"})
+	assert.Error(t, err)
+
+	assert.NoError(t, ValidateURL("https://example.com/hook"))
+	assert.NoError(t, ValidateURL("https://93.184.216.34:8443/hook"))
+	assert.Error(t, ValidateURL("ftp://example.com/hook"))
+	assert.Error(t, ValidateURL("/hook"))
+	for _, rawURL := range []string{
+		"http://localhost/hook",
+		"http://api.localhost./hook",
+		"http://127.0.0.1:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://[64:ff9b::a00:1]/hook",
+	} {
+		assert.Error(t, ValidateURL(rawURL), rawURL)
+	}
+}
+
+func TestDispatcher_RefusesNonPublicAddresses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	// The receiver is registered directly, as a host name that resolves to
+	// a loopback address would pass ValidateURL
+	recv, url := newReceiver(t, http.StatusOK)
+	addTestWebhook(t, store, logID, url, SigningHMAC, EventGC)
+
+	d, err := NewDispatcher(Config{StoreManager: manager, PollInterval: time.Hour})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+	require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{}))
+
+	var pending []sqlite.WebhookDelivery
+	require.Eventually(t, func() bool {
+		pending, err = store.DueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 10)
+		return err == nil && len(pending) == 1 && pending[0].Attempts == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, pending[0].LastError, "is not public")
+	select {
+	case <-recv.deliveries:
+		t.Fatal("webhook delivered to a loopback address")
+	default:
+	}
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logID := "did:key:z6MkWebhookLog"
+	manager := sqlite.NewStoreManager(t.TempDir())
+	defer manager.CloseAll()
+	store := newTestStore(t, manager, logID)
+
+	target, targetURL := newReceiver(t, http.StatusOK)
+	redirector := httptest.NewServer(http.RedirectHandler(targetURL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirector.Close)
+	addTestWebhook(t, store, logID, redirector.URL, SigningHMAC, EventGC)
+
+	d, err := NewDispatcher(Config{StoreManager: manager, HTTPClient: loopbackClient, PollInterval: time.Hour})
+	require.NoError(t, err)
+	require.NoError(t, d.Start(ctx))
+	require.NoError(t, d.Emit(ctx, logID, EventGC, GCData{}))
+
+	var pending []sqlite.WebhookDelivery
+	require.Eventually(t, func() bool {
+		pending, err = store.DueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 10)
+		return err == nil && len(pending) == 1 && pending[0].Attempts == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "webhook returned status 307", pending[0].LastError)
+	select {
+	case <-target.deliveries:
+		t.Fatal("webhook delivery followed a redirect")
+	default:
+	}
+}
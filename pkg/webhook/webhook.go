@@ -0,0 +1,308 @@
+// Package webhook notifies HTTP endpoints registered on a log of appends,
+// revocations and garbage collection runs.
+//
+// Notifications are queued in the log's SQLite outbox before delivery, so
+// they survive restarts, and are retried with exponential backoff until the
+// endpoint answers with a 2xx status. Delivery is at least once: receivers
+// should deduplicate on the event ID.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/storacha/go-ucanto/principal"
+	"github.com/storacha/go-ucanto/principal/ed25519/verifier"
+	"github.com/storacha/go-ucanto/ucan/crypto/signature"
+)
+
+// Event types a webhook can subscribe to
+const (
+	EventAppend     = "append"     // Entries were integrated into the log
+	EventRevocation = "revocation" // A revocation was written for the log's space
+	EventGC         = "gc"         // A garbage collection run completed
+)
+
+// Events lists every event type, in the order they are documented.
+var Events = []string{EventAppend, EventRevocation, EventGC}
+
+// Payload signing schemes
+const (
+	// SigningHMAC signs payloads with HMAC-SHA256 under a per-webhook secret.
+	SigningHMAC = "hmac"
+	// SigningUCAN signs payloads with the service's UCAN principal key.
+	SigningUCAN = "ucan"
+)
+
+// Headers sent with each delivery
+const (
+	HeaderEvent     = "X-Ucanlog-Event"     // Event type
+	HeaderDelivery  = "X-Ucanlog-Delivery"  // Event ID, identical across retries
+	HeaderSignature = "X-Ucanlog-Signature" // "hmac-sha256=<hex>" or "ucan=<base64 Ed25519 signature>"
+	HeaderSigner    = "X-Ucanlog-Signer"    // Service DID, for UCAN signatures
+)
+
+// Event is the JSON body of a notification.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	LogID     string          `json:"log_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// AppendData is the data of an "append" event.
+type AppendData struct {
+	Entries []AppendedEntry `json:"entries"`
+	Head    Head            `json:"head"`
+}
+
+// AppendedEntry identifies an entry integrated into the log.
+type AppendedEntry struct {
+	Index    uint64 `json:"index"`
+	LeafHash []byte `json:"leaf_hash"` // RFC 6962 leaf hash, base64 in JSON
+}
+
+// Head is the checkpoint that first includes the entries of an append event.
+type Head struct {
+	TreeSize   uint64 `json:"tree_size"`
+	RootHash   []byte `json:"root_hash"`  // Base64 in JSON
+	Checkpoint string `json:"checkpoint"` // Signed checkpoint note
+}
+
+// RevocationData is the data of a "revocation" event.
+type RevocationData struct {
+	Index     uint64    `json:"index"`  // Index in the space's revocation log
+	Type      string    `json:"type"`   // "ucan", "account" or "capability"
+	Target    string    `json:"target"` // Revoked delegation CID, account DID or ability
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// GCData is the data of a "gc" event.
+type GCData struct {
+	BundlesProcessed int    `json:"bundles_processed"`
+	BlobsRemoved     int    `json:"blobs_removed"`
+	BytesFreed       uint64 `json:"bytes_freed"`
+	GCPosition       uint64 `json:"gc_position"`
+}
+
+// NewEvent builds the JSON body of an event of type eventType on a log.
+func NewEvent(logID, eventType string, data any) ([]byte, *Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal %s event data: %w", eventType, err)
+	}
+	id, err := NewID()
+	if err != nil {
+		return nil, nil, err
+	}
+	ev := &Event{
+		ID:        id,
+		Type:      eventType,
+		LogID:     logID,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return body, ev, nil
+}
+
+// NewID returns a random identifier for a webhook or event.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewSecret returns a random hex-encoded HMAC key.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateURL checks that rawURL is an absolute http or https URL that does
+// not name localhost or a non-public IP address. Host names are checked
+// again when a delivery dials them, since they may resolve anywhere.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("webhook URL must not point to localhost")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("webhook URL must not point to non-public address %s", addr)
+	}
+	return nil
+}
+
+// nonPublicPrefixes are special-purpose ranges not covered by the netip
+// predicates used in IsPublicAddr.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Shared address space (CGNAT)
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // Documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, may embed a private IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+}
+
+// IsPublicAddr reports whether webhooks may be delivered to addr: it is not a
+// loopback, private, link-local, multicast, unspecified or otherwise reserved
+// address.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicDialControl is a net.Dialer Control function refusing connections to
+// non-public addresses. It runs after name resolution, on the address
+// actually dialed, so a host name resolving to a private address is refused
+// even if it resolved to a public one when the webhook was registered.
+func publicDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook address %s: %w", address, err)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+	}
+	return nil
+}
+
+// NewHTTPClient returns the client webhooks are delivered with by default.
+// It only connects to public addresses, bypasses any proxy (which would hide
+// the address finally dialed) and does not follow redirects, so a receiver
+// cannot redirect deliveries to an internal service.
+func NewHTTPClient() *http.Client {
+	return newHTTPClient(publicDialControl)
+}
+
+// newHTTPClient returns a delivery client whose dialer checks addresses
+// with control.
+func newHTTPClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ValidateEvents checks that events only names known event types. An empty
+// list subscribes to every event type.
+func ValidateEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return slices.Clone(Events), nil
+	}
+	var valid []string
+	for _, ev := range events {
+		if !slices.Contains(Events, ev) {
+			return nil, fmt.Errorf("unknown webhook event %q (expected one of %s)", ev, strings.Join(Events, ", "))
+		}
+		if !slices.Contains(valid, ev) {
+			valid = append(valid, ev)
+		}
+	}
+	return valid, nil
+}
+
+// SignHMAC returns the signature header value of body under a hex secret.
+func SignHMAC(secret string, body []byte) (string, error) {
+	key, err := hex.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook secret: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "hmac-sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyHMAC checks the signature header of a delivery signed with SigningHMAC.
+func VerifyHMAC(secret, header string, body []byte) error {
+	want, err := SignHMAC(secret, body)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(header), []byte(want)) {
+		return fmt.Errorf("webhook signature does not match")
+	}
+	return nil
+}
+
+// SignUCAN returns the signature header value of body signed by signer. The
+// header carries the raw Ed25519 signature, so receivers without a UCAN
+// library can verify it with the public key in the signer's did:key.
+func SignUCAN(signer principal.Signer, body []byte) string {
+	return "ucan=" + base64.StdEncoding.EncodeToString(signer.Sign(body).Raw())
+}
+
+// VerifyUCAN checks the signature header of a delivery signed with
+// SigningUCAN by the service identified by signerDID. Receivers should pin
+// signerDID to the DID returned when the webhook was added.
+func VerifyUCAN(signerDID, header string, body []byte) error {
+	encoded, ok := strings.CutPrefix(header, "ucan=")
+	if !ok {
+		return fmt.Errorf("webhook signature is not a UCAN signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid webhook signature: %w", err)
+	}
+	vfr, err := verifier.Parse(signerDID)
+	if err != nil {
+		return fmt.Errorf("invalid signer DID %s: %w", signerDID, err)
+	}
+	if !vfr.Verify(body, signature.NewSignature(signature.EdDSA, raw)) {
+		return fmt.Errorf("webhook signature does not match")
+	}
+	return nil
+}